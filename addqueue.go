@@ -0,0 +1,399 @@
+package qbittorrent
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cehbz/qbittorrent/metainfo"
+)
+
+// AddRequest is a torrent waiting in an AddQueue, either a file or a URL
+type AddRequest struct {
+	ID        string             `json:"id"`
+	URL       string             `json:"url,omitempty"`  // URL or magnet link
+	File      string             `json:"file,omitempty"` // name of the torrent file
+	FileData  []byte             `json:"file_data,omitempty"`
+	Params    *TorrentsAddParams `json:"params,omitempty"`
+	Enqueued  time.Time          `json:"enqueued"`
+	Attempts  int                `json:"attempts"`
+	NextAt    time.Time          `json:"next_at"` // when the next attempt is due
+	LastError string             `json:"last_error,omitempty"`
+}
+
+// AddQueueStore persists the requests of an AddQueue, so they survive
+// restarts of the process
+type AddQueueStore interface {
+	// Load returns the stored requests
+	Load() ([]AddRequest, error)
+	// Save stores a new or updated request
+	Save(req AddRequest) error
+	// Delete removes a request
+	Delete(id string) error
+}
+
+// MemoryAddQueueStore is an AddQueueStore that doesn't persist anything. It
+// is safe for concurrent use.
+type MemoryAddQueueStore struct {
+	mu       sync.Mutex
+	requests map[string]AddRequest
+}
+
+// NewMemoryAddQueueStore returns an empty MemoryAddQueueStore
+func NewMemoryAddQueueStore() *MemoryAddQueueStore {
+	return &MemoryAddQueueStore{requests: make(map[string]AddRequest)}
+}
+
+// Load implements the AddQueueStore interface
+func (s *MemoryAddQueueStore) Load() ([]AddRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := make([]AddRequest, 0, len(s.requests))
+	for _, req := range s.requests {
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
+
+// Save implements the AddQueueStore interface
+func (s *MemoryAddQueueStore) Save(req AddRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[req.ID] = req
+	return nil
+}
+
+// Delete implements the AddQueueStore interface
+func (s *MemoryAddQueueStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.requests, id)
+	return nil
+}
+
+// FileAddQueueStore is an AddQueueStore keeping the requests in a JSON file,
+// which is rewritten atomically on every change. It is safe for concurrent
+// use, but not by several processes.
+type FileAddQueueStore struct {
+	memory MemoryAddQueueStore
+	path   string
+}
+
+// NewFileAddQueueStore returns a store for the file at path, loading the
+// requests in it if it exists
+func NewFileAddQueueStore(path string) (*FileAddQueueStore, error) {
+	s := &FileAddQueueStore{memory: MemoryAddQueueStore{requests: make(map[string]AddRequest)}, path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read add queue: %w", err)
+	}
+	var requests []AddRequest
+	if err := json.Unmarshal(data, &requests); err != nil {
+		return nil, fmt.Errorf("failed to decode add queue: %w", err)
+	}
+	for _, req := range requests {
+		s.memory.requests[req.ID] = req
+	}
+	return s, nil
+}
+
+// Load implements the AddQueueStore interface
+func (s *FileAddQueueStore) Load() ([]AddRequest, error) {
+	return s.memory.Load()
+}
+
+// Save implements the AddQueueStore interface
+func (s *FileAddQueueStore) Save(req AddRequest) error {
+	s.memory.mu.Lock()
+	defer s.memory.mu.Unlock()
+	s.memory.requests[req.ID] = req
+	return s.write()
+}
+
+// Delete implements the AddQueueStore interface
+func (s *FileAddQueueStore) Delete(id string) error {
+	s.memory.mu.Lock()
+	defer s.memory.mu.Unlock()
+	delete(s.memory.requests, id)
+	return s.write()
+}
+
+// write saves the requests to the file. s.memory.mu must be held.
+func (s *FileAddQueueStore) write() error {
+	requests := make([]AddRequest, 0, len(s.memory.requests))
+	for _, req := range s.memory.requests {
+		requests = append(requests, req)
+	}
+	sortAddRequests(requests)
+	data, err := json.MarshalIndent(requests, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode add queue: %w", err)
+	}
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return fmt.Errorf("failed to save add queue: %w", err)
+	}
+	return nil
+}
+
+// AddRetryPolicy controls how an AddQueue retries failed adds
+type AddRetryPolicy struct {
+	// MaxAttempts is the number of attempts before a request fails for good.
+	// Zero means unlimited.
+	MaxAttempts int
+	// Backoff is the wait after the first failure, doubled for every further
+	// failure up to MaxBackoff, or up to the MaxBackoff of
+	// DefaultAddRetryPolicy if zero
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultAddRetryPolicy retries for about a day, long enough to ride out
+// restarts and maintenance of the server
+var DefaultAddRetryPolicy = AddRetryPolicy{MaxAttempts: 60, Backoff: 30 * time.Second, MaxBackoff: 30 * time.Minute}
+
+// backoff returns the wait after the given number of failed attempts
+func (p AddRetryPolicy) backoff(attempts int) time.Duration {
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = max(DefaultAddRetryPolicy.MaxBackoff, p.Backoff)
+	}
+	d := p.Backoff
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		// Doubling past the cap could overflow
+		if d > maxBackoff/2 {
+			d = maxBackoff
+		} else {
+			d *= 2
+		}
+	}
+	return min(d, maxBackoff)
+}
+
+// AddQueue adds torrents in the background, persisting the requests in a
+// store and retrying those that fail because the server is unreachable,
+// restarting or erroring, so injection pipelines survive restarts of
+// qBittorrent and of the process. Requests the server rejects, such as
+// invalid torrent files, and those out of attempts fail for good and are
+// passed to the failure handlers. An AddQueue is safe for concurrent use.
+type AddQueue struct {
+	client *Client
+	store  AddQueueStore
+	policy AddRetryPolicy
+	wake   chan struct{}
+
+	mu       sync.Mutex
+	requests map[string]AddRequest
+	failed   []func(AddRequest, error)
+}
+
+// NewAddQueue returns a queue for c keeping its requests in store, which
+// resumes the requests stored already. A nil store keeps them in memory.
+func NewAddQueue(c *Client, store AddQueueStore, policy AddRetryPolicy) (*AddQueue, error) {
+	if store == nil {
+		store = NewMemoryAddQueueStore()
+	}
+	requests, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	q := &AddQueue{
+		client:   c,
+		store:    store,
+		policy:   policy,
+		wake:     make(chan struct{}, 1),
+		requests: make(map[string]AddRequest, len(requests)),
+	}
+	for _, req := range requests {
+		q.requests[req.ID] = req
+	}
+	return q, nil
+}
+
+// OnFailure registers a handler for requests that failed for good, which
+// have been removed from the queue. Handlers are called from Run and may use
+// the queue, e.g. to enqueue a fallback.
+func (q *AddQueue) OnFailure(handler func(req AddRequest, err error)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.failed = append(q.failed, handler)
+}
+
+// EnqueueFile queues a torrent file and returns the ID of the request.
+// Files that aren't valid torrents are refused right away. RewriteAnnounce of
+// params is applied to the file before it is queued, since functions can't be
+// persisted.
+func (q *AddQueue) EnqueueFile(torrentFile string, fileData []byte, params *TorrentsAddParams) (string, error) {
+	m, err := metainfo.Parse(fileData)
+	if err != nil {
+		return "", fmt.Errorf("EnqueueFile error: %w", err)
+	}
+	if params != nil && params.RewriteAnnounce != nil {
+		m.RewriteAnnounce(params.RewriteAnnounce)
+		if fileData, err = m.Bytes(); err != nil {
+			return "", fmt.Errorf("EnqueueFile error: %w", err)
+		}
+		rewritten := *params
+		rewritten.RewriteAnnounce = nil
+		params = &rewritten
+	}
+	return q.enqueue(AddRequest{File: torrentFile, FileData: fileData, Params: params})
+}
+
+// EnqueueURL queues a URL or magnet link and returns the ID of the request
+func (q *AddQueue) EnqueueURL(link string, params *TorrentsAddParams) (string, error) {
+	return q.enqueue(AddRequest{URL: link, Params: params})
+}
+
+// enqueue stores a new request and wakes up Run
+func (q *AddQueue) enqueue(req AddRequest) (string, error) {
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", err
+	}
+	req.ID = hex.EncodeToString(id[:])
+	req.Enqueued = q.client.Clock().Now()
+	req.NextAt = req.Enqueued
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.store.Save(req); err != nil {
+		return "", err
+	}
+	q.requests[req.ID] = req
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return req.ID, nil
+}
+
+// Pending returns the requests in the queue, oldest first
+func (q *AddQueue) Pending() []AddRequest {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	requests := make([]AddRequest, 0, len(q.requests))
+	for _, req := range q.requests {
+		requests = append(requests, req)
+	}
+	sortAddRequests(requests)
+	return requests
+}
+
+// Run attempts the due requests every interval, and right away when one is
+// enqueued, until ctx is done. Errors of the store end the loop.
+func (q *AddQueue) Run(ctx context.Context, interval time.Duration) error {
+	ticker := q.client.Clock().NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := q.Process(ctx); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		case <-q.wake:
+		}
+	}
+}
+
+// Process attempts the requests that are due once, oldest first
+func (q *AddQueue) Process(ctx context.Context) error {
+	now := q.client.Clock().Now()
+	for _, req := range q.Pending() {
+		if req.NextAt.After(now) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := q.attempt(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attempt adds a request and removes it from the queue or schedules a retry
+func (q *AddQueue) attempt(ctx context.Context, req AddRequest) error {
+	var params []*TorrentsAddParams
+	if req.Params != nil {
+		params = append(params, req.Params)
+	}
+	var err error
+	if req.URL != "" {
+		err = q.client.TorrentsAddURLsCtx(ctx, []string{req.URL}, params...)
+	} else {
+		err = q.client.TorrentsAddCtx(ctx, req.File, req.FileData, params...)
+	}
+	if err != nil && ctx.Err() != nil {
+		// shutting down, the attempt doesn't count
+		return nil
+	}
+
+	q.mu.Lock()
+	if err == nil {
+		defer q.mu.Unlock()
+		delete(q.requests, req.ID)
+		return q.store.Delete(req.ID)
+	}
+
+	req.Attempts++
+	req.LastError = err.Error()
+	if !retryableAdd(err) || q.policy.MaxAttempts > 0 && req.Attempts >= q.policy.MaxAttempts {
+		q.client.log().Error("add failed", "id", req.ID, "attempts", req.Attempts, "error", err)
+		delete(q.requests, req.ID)
+		if err := q.store.Delete(req.ID); err != nil {
+			q.mu.Unlock()
+			return err
+		}
+		// handlers may use the queue, e.g. to enqueue the request again
+		handlers := append([]func(AddRequest, error){}, q.failed...)
+		q.mu.Unlock()
+		for _, handler := range handlers {
+			handler(req, err)
+		}
+		return nil
+	}
+	defer q.mu.Unlock()
+
+	backoff := q.policy.backoff(req.Attempts)
+	req.NextAt = q.client.Clock().Now().Add(backoff)
+	q.client.log().Warn("add failed, retrying", "id", req.ID, "attempts", req.Attempts, "retry_in", backoff, "error", err)
+	q.requests[req.ID] = req
+	return q.store.Save(req)
+}
+
+// retryableAdd reports whether a failed add may succeed later: transport
+// errors, timeouts, open circuit breakers, rejected sessions and server
+// errors are retried, other responses of the server, such as 415 Unsupported
+// Media Type for an invalid torrent file, are not
+func retryableAdd(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return !errors.Is(err, ErrReadOnly)
+	}
+	return apiErr.StatusCode >= http.StatusInternalServerError || sessionRejected(apiErr.StatusCode)
+}
+
+// sortAddRequests sorts requests oldest first
+func sortAddRequests(requests []AddRequest) {
+	sort.Slice(requests, func(i, j int) bool {
+		if !requests[i].Enqueued.Equal(requests[j].Enqueued) {
+			return requests[i].Enqueued.Before(requests[j].Enqueued)
+		}
+		return requests[i].ID < requests[j].ID
+	})
+}
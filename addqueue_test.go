@@ -0,0 +1,285 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cehbz/qbittorrent/metainfo"
+)
+
+func TestAddQueue_Retry(t *testing.T) {
+	statuses := []int{http.StatusServiceUnavailable, http.StatusOK}
+	var categories []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		categories = append(categories, r.FormValue("category"))
+		w.WriteHeader(statuses[0])
+		statuses = statuses[1:]
+	}))
+	defer mockServer.Close()
+
+	clock := &stubClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true, clock: clock,
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	queue, err := NewAddQueue(client, nil, AddRetryPolicy{Backoff: 30 * time.Second})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	id, err := queue.EnqueueURL("magnet:?xt=urn:btih:abcdef", &TorrentsAddParams{Category: "tv"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ctx := context.Background()
+	if err := queue.Process(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	pending := queue.Pending()
+	if len(pending) != 1 || pending[0].ID != id || pending[0].Attempts != 1 || pending[0].LastError == "" {
+		t.Fatalf("expected the request to be retried, got %+v", pending)
+	}
+	if !pending[0].NextAt.Equal(clock.now.Add(30 * time.Second)) {
+		t.Errorf("expected a retry after the backoff, got %v", pending[0].NextAt)
+	}
+
+	// Not due yet
+	queue.Process(ctx)
+	if len(categories) != 1 {
+		t.Errorf("expected no attempt before the backoff, got %d", len(categories))
+	}
+
+	clock.now = clock.now.Add(30 * time.Second)
+	queue.Process(ctx)
+	if len(queue.Pending()) != 0 {
+		t.Errorf("expected the request to be done, got %+v", queue.Pending())
+	}
+	if len(categories) != 2 || categories[1] != "tv" {
+		t.Errorf("expected two attempts with the params, got %v", categories)
+	}
+}
+
+func TestAddQueue_Failures(t *testing.T) {
+	status := http.StatusUnsupportedMediaType
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer mockServer.Close()
+
+	clock := &stubClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true, clock: clock,
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	queue, err := NewAddQueue(client, nil, AddRetryPolicy{MaxAttempts: 2, Backoff: time.Second})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var failed []AddRequest
+	queue.OnFailure(func(req AddRequest, err error) {
+		failed = append(failed, req)
+	})
+	ctx := context.Background()
+
+	// Rejected by the server
+	if _, err := queue.EnqueueFile("show.torrent", []byte(testTorrentFile), nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	queue.Process(ctx)
+	if len(failed) != 1 || failed[0].Attempts != 1 || failed[0].File != "show.torrent" || len(queue.Pending()) != 0 {
+		t.Errorf("expected a terminal failure after one attempt, got %+v", failed)
+	}
+
+	// Out of attempts
+	status = http.StatusInternalServerError
+	queue.EnqueueURL("https://example.org/show.torrent", nil)
+	queue.Process(ctx)
+	clock.now = clock.now.Add(time.Second)
+	queue.Process(ctx)
+	if len(failed) != 2 || failed[1].Attempts != 2 || len(queue.Pending()) != 0 {
+		t.Errorf("expected a terminal failure after two attempts, got %+v", failed)
+	}
+
+	if _, err := queue.EnqueueFile("broken.torrent", []byte("nope"), nil); err == nil {
+		t.Errorf("expected an error for an invalid torrent file")
+	}
+}
+
+func TestAddQueue_FailureHandlerEnqueues(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true,
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	queue, err := NewAddQueue(client, nil, DefaultAddRetryPolicy)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var requeued string
+	queue.OnFailure(func(req AddRequest, err error) {
+		// falls back to the magnet link, using the queue from the handler
+		if req.URL != "" {
+			return
+		}
+		requeued, _ = queue.EnqueueURL("magnet:?xt=urn:btih:abcdef", req.Params)
+		queue.Pending()
+	})
+	queue.EnqueueFile("show.torrent", []byte(testTorrentFile), nil)
+
+	done := make(chan struct{})
+	go func() {
+		queue.Process(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the failure handler not to deadlock")
+	}
+	pending := queue.Pending()
+	if requeued == "" || len(pending) != 1 || pending[0].ID != requeued {
+		t.Errorf("expected the request to be enqueued again, got %+v", pending)
+	}
+}
+
+func TestAddQueue_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	store, err := NewFileAddQueueStore(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	client := &Client{bypassAuth: true}
+	queue, err := NewAddQueue(client, store, DefaultAddRetryPolicy)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	fileID, _ := queue.EnqueueFile("show.torrent", []byte(testTorrentFile), &TorrentsAddParams{Category: "tv", Tags: []string{"a"}})
+	urlID, _ := queue.EnqueueURL("magnet:?xt=urn:btih:abcdef", nil)
+
+	// A restarted process resumes the queue
+	store, err = NewFileAddQueueStore(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	queue, err = NewAddQueue(client, store, DefaultAddRetryPolicy)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	pending := queue.Pending()
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 requests, got %+v", pending)
+	}
+	byID := map[string]AddRequest{pending[0].ID: pending[0], pending[1].ID: pending[1]}
+	file := byID[fileID]
+	if string(file.FileData) != testTorrentFile || file.Params == nil || file.Params.Category != "tv" || len(file.Params.Tags) != 1 {
+		t.Errorf("expected the file request to be restored, got %+v", file)
+	}
+	if byID[urlID].URL != "magnet:?xt=urn:btih:abcdef" {
+		t.Errorf("expected the URL request to be restored, got %+v", byID[urlID])
+	}
+
+	if err := store.Delete(fileID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	store, _ = NewFileAddQueueStore(path)
+	if requests, _ := store.Load(); len(requests) != 1 {
+		t.Errorf("expected the deletion to be saved, got %+v", requests)
+	}
+}
+
+func TestAddQueue_RewriteAnnounce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	store, err := NewFileAddQueueStore(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	client := &Client{bypassAuth: true}
+	queue, err := NewAddQueue(client, store, DefaultAddRetryPolicy)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	params := &TorrentsAddParams{Category: "tv", RewriteAnnounce: metainfo.ReplacePasskey("PASSKEY", "secret")}
+	if _, err := queue.EnqueueFile("show.torrent", []byte(testTorrentFile), params); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if params.RewriteAnnounce == nil {
+		t.Error("expected the params of the caller to be unchanged")
+	}
+
+	// The rewrite survives a restart of the process
+	store, _ = NewFileAddQueueStore(path)
+	queue, _ = NewAddQueue(client, store, DefaultAddRetryPolicy)
+	pending := queue.Pending()
+	if len(pending) != 1 || pending[0].Params.RewriteAnnounce != nil || pending[0].Params.Category != "tv" {
+		t.Fatalf("expected the rewritten request, got %+v", pending)
+	}
+	m, err := metainfo.Parse(pending[0].FileData)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if m.Announce != "http://tracker.example.org/secret/announce" {
+		t.Errorf("expected the passkey to be replaced, got %s", m.Announce)
+	}
+}
+
+func TestAddRetryPolicy_Backoff(t *testing.T) {
+	policy := AddRetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := policy.backoff(i + 1); got != want {
+			t.Errorf("attempt %d: expected %v, got %v", i+1, want, got)
+		}
+	}
+
+	// Unlimited attempts without MaxBackoff stay at the default cap
+	unbounded := AddRetryPolicy{Backoff: 30 * time.Second}
+	for _, attempts := range []int{10, 29, 30, 100, 10000} {
+		if got := unbounded.backoff(attempts); got != DefaultAddRetryPolicy.MaxBackoff {
+			t.Errorf("attempt %d: expected %v, got %v", attempts, DefaultAddRetryPolicy.MaxBackoff, got)
+		}
+	}
+	huge := AddRetryPolicy{Backoff: time.Second, MaxBackoff: time.Duration(math.MaxInt64)}
+	if got := huge.backoff(100); got != huge.MaxBackoff {
+		t.Errorf("expected the backoff to stop at %v, got %v", huge.MaxBackoff, got)
+	}
+
+	if !retryableAdd(errors.New("connection refused")) || retryableAdd(&APIError{StatusCode: http.StatusBadRequest}) || !retryableAdd(&APIError{StatusCode: http.StatusBadGateway}) {
+		t.Errorf("unexpected retryable classification")
+	}
+}
+
+func TestAddQueue_Run(t *testing.T) {
+	added := make(chan struct{}, 1)
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		added <- struct{}{}
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+	queue, err := NewAddQueue(client, nil, DefaultAddRetryPolicy)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- queue.Run(ctx, time.Hour) }()
+
+	// Enqueuing wakes the loop up without waiting for the interval
+	queue.EnqueueURL("magnet:?xt=urn:btih:abcdef", nil)
+	select {
+	case <-added:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the request to be added right away")
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
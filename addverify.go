@@ -0,0 +1,81 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cehbz/qbittorrent/metainfo"
+)
+
+// AddStateAction is what VerifyAddedStateCtx did to a torrent
+type AddStateAction string
+
+const (
+	// AddStateUnchanged means the torrent was in the requested state
+	AddStateUnchanged AddStateAction = "unchanged"
+	// AddStateStopped means the torrent was running and had to be stopped
+	AddStateStopped AddStateAction = "stopped"
+	// AddStateStarted means the torrent was stopped and had to be started
+	AddStateStarted AddStateAction = "started"
+)
+
+// addVerifyInterval is how often VerifyAddedStateCtx looks for the torrent
+var addVerifyInterval = 500 * time.Millisecond
+
+// addVerifyPolls is how many times VerifyAddedStateCtx looks for the torrent
+// before giving up, since the server adds torrents asynchronously
+const addVerifyPolls = 10
+
+// VerifyAddedStateCtx checks that a newly added torrent is stopped if paused
+// is true and running otherwise, and corrects it if not. Depending on the
+// version and add parameters qBittorrent may start torrents added paused.
+func (c *Client) VerifyAddedStateCtx(ctx context.Context, hash string, paused bool) (AddStateAction, error) {
+	var torrent TorrentInfo
+	for attempt := 1; ; attempt++ {
+		torrents, err := c.TorrentsInfoCtx(ctx, &TorrentsInfoParams{Hashes: []string{hash}})
+		if err != nil {
+			return "", fmt.Errorf("VerifyAddedState error: %w", err)
+		}
+		if len(torrents) > 0 {
+			torrent = torrents[0]
+			break
+		}
+		if attempt == addVerifyPolls {
+			return "", fmt.Errorf("VerifyAddedState error: %s: %w", hash, ErrTorrentNotFound)
+		}
+		if err := sleepCtx(ctx, addVerifyInterval); err != nil {
+			return "", err
+		}
+	}
+
+	switch stopped := TorrentState(torrent.State).Stopped(); {
+	case paused && !stopped:
+		if err := c.TorrentsStopCtx(ctx, []string{hash}); err != nil {
+			return "", fmt.Errorf("VerifyAddedState error: %w", err)
+		}
+		c.log().Info("stopped torrent added paused", "hash", hash, "state", torrent.State)
+		return AddStateStopped, nil
+	case !paused && stopped:
+		if err := c.TorrentsStartCtx(ctx, []string{hash}); err != nil {
+			return "", fmt.Errorf("VerifyAddedState error: %w", err)
+		}
+		c.log().Info("started torrent added running", "hash", hash, "state", torrent.State)
+		return AddStateStarted, nil
+	}
+	return AddStateUnchanged, nil
+}
+
+// TorrentsAddVerifiedCtx adds a torrent file like TorrentsAddCtx, then makes
+// sure it is stopped or running as params asked with VerifyAddedStateCtx
+func (c *Client) TorrentsAddVerifiedCtx(ctx context.Context, torrentFile string, fileData []byte, params ...*TorrentsAddParams) (AddStateAction, error) {
+	m, err := metainfo.Parse(fileData)
+	if err != nil {
+		return "", fmt.Errorf("TorrentsAdd error: %w", err)
+	}
+	if err := c.TorrentsAddCtx(ctx, torrentFile, fileData, params...); err != nil {
+		return "", err
+	}
+	paused := len(params) > 0 && params[0] != nil && params[0].Paused
+	return c.VerifyAddedStateCtx(ctx, m.InfoHash(), paused)
+}
@@ -0,0 +1,107 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cehbz/qbittorrent/metainfo"
+)
+
+func TestVerifyAddedStateCtx(t *testing.T) {
+	addVerifyInterval = time.Millisecond
+	defer func() { addVerifyInterval = 500 * time.Millisecond }()
+
+	tests := []struct {
+		name     string
+		state    string
+		paused   bool
+		expected AddStateAction
+		request  string
+	}{
+		{"paused and stopped", "stoppedDL", true, AddStateUnchanged, ""},
+		{"paused but started", "downloading", true, AddStateStopped, "/api/v2/torrents/stop"},
+		{"running but stopped", "pausedUP", false, AddStateStarted, "/api/v2/torrents/start"},
+		{"running", "stalledUP", false, AddStateUnchanged, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			polls := 0
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v2/torrents/info" {
+					// The torrent shows up on the second poll
+					if polls++; polls == 1 {
+						w.Write([]byte(`[]`))
+						return
+					}
+					w.Write([]byte(`[{"hash":"abc","state":"` + tt.state + `"}]`))
+					return
+				}
+				requests = append(requests, r.URL.Path)
+			}))
+			defer mockServer.Close()
+			client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+			action, err := client.VerifyAddedStateCtx(context.Background(), "abc", tt.paused)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if action != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, action)
+			}
+			var expected []string
+			if tt.request != "" {
+				expected = []string{tt.request}
+			}
+			if !reflect.DeepEqual(requests, expected) {
+				t.Errorf("expected %v, got %v", expected, requests)
+			}
+		})
+	}
+}
+
+func TestTorrentsAddVerifiedCtx(t *testing.T) {
+	m, err := metainfo.Parse([]byte(testTorrentFile))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var hashes string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/torrents/info":
+			hashes = r.URL.Query().Get("hashes")
+			w.Write([]byte(`[{"hash":"` + hashes + `","state":"checkingResumeData"}]`))
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	action, err := client.TorrentsAddVerifiedCtx(context.Background(), "show.torrent", []byte(testTorrentFile), &TorrentsAddParams{Paused: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if action != AddStateStopped || hashes != m.InfoHash() {
+		t.Errorf("expected %s to be stopped, got %s for %s", m.InfoHash(), action, hashes)
+	}
+}
+
+func TestVerifyAddedStateCtx_NotFound(t *testing.T) {
+	addVerifyInterval = time.Millisecond
+	defer func() { addVerifyInterval = 500 * time.Millisecond }()
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	if _, err := client.VerifyAddedStateCtx(context.Background(), "abc", true); !errors.Is(err, ErrTorrentNotFound) {
+		t.Errorf("expected ErrTorrentNotFound, got %v", err)
+	}
+}
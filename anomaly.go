@@ -0,0 +1,256 @@
+package qbittorrent
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AnomalyKind identifies the condition an Anomaly reports
+type AnomalyKind int
+
+const (
+	// UploadStopped means the upload rate of all torrents dropped to zero
+	// after a period of uploading
+	UploadStopped AnomalyKind = iota
+	// PeersCollapsed means the number of connected peers dropped sharply
+	PeersCollapsed
+	// TrackerDomainDown means no active torrent of a tracker domain has a
+	// working tracker anymore
+	TrackerDomainDown
+)
+
+func (k AnomalyKind) String() string {
+	switch k {
+	case UploadStopped:
+		return "upload stopped"
+	case PeersCollapsed:
+		return "peers collapsed"
+	case TrackerDomainDown:
+		return "tracker domain down"
+	default:
+		return "unknown"
+	}
+}
+
+// Anomaly is emitted when an anomaly starts or stops holding
+type Anomaly struct {
+	Kind    AnomalyKind
+	Cleared bool // the condition no longer holds
+	At      time.Time
+	Domain  string // the tracker domain, for TrackerDomainDown
+	// Baseline is the average upload rate or peer count before the anomaly,
+	// or the number of torrents of the tracker domain
+	Baseline float64
+	// Current is the upload rate or peer count, or the number of torrents of
+	// the domain with a working tracker
+	Current float64
+}
+
+// AnomalyThresholds configures an AnomalyDetector. Zero values select the
+// defaults.
+type AnomalyThresholds struct {
+	Window        time.Duration // period the baseline is averaged over, 1 hour by default
+	MinUploadRate float64       // baseline bytes/s below which a stop isn't reported, 1 KiB/s by default
+	PeerDrop      float64       // fraction of the baseline peers that must be lost, 0.8 by default
+	MinPeers      float64       // baseline peers below which a collapse isn't reported, 10 by default
+	MinTorrents   int           // active torrents a domain needs to be reported down, 2 by default
+}
+
+// minBaselineSamples is the number of samples a baseline needs, so a single
+// busy poll after startup isn't mistaken for the norm
+const minBaselineSamples = 3
+
+// AnomalyDetector spots sudden changes in the samples of a HistoryRecorder:
+// uploads stopping, peers collapsing and tracker domains failing, which
+// usually mean a network, firewall or tracker outage. Use it as the recorder's
+// sink, or call Record from another sink. The baseline of an anomaly is kept
+// while it holds, so a lasting outage doesn't become the norm.
+// An AnomalyDetector is safe for concurrent use.
+type AnomalyDetector struct {
+	thresholds AnomalyThresholds
+	onAnomaly  func(Anomaly)
+
+	mu        sync.Mutex
+	samples   []activitySample
+	baselines map[AnomalyKind]float64 // of the active session-wide anomalies
+	domains   map[InfoHash]string     // tracker domain last seen per torrent
+	down      map[string]bool         // domains reported down
+}
+
+// activitySample is the activity of all torrents at one time
+type activitySample struct {
+	at     time.Time
+	upload int64
+	peers  int64
+}
+
+// NewAnomalyDetector returns a detector calling onAnomaly whenever an anomaly
+// starts or stops holding
+func NewAnomalyDetector(thresholds AnomalyThresholds, onAnomaly func(Anomaly)) *AnomalyDetector {
+	if thresholds.Window <= 0 {
+		thresholds.Window = time.Hour
+	}
+	if thresholds.MinUploadRate <= 0 {
+		thresholds.MinUploadRate = 1024
+	}
+	if thresholds.PeerDrop <= 0 || thresholds.PeerDrop > 1 {
+		thresholds.PeerDrop = 0.8
+	}
+	if thresholds.MinPeers <= 0 {
+		thresholds.MinPeers = 10
+	}
+	if thresholds.MinTorrents <= 0 {
+		thresholds.MinTorrents = 2
+	}
+	return &AnomalyDetector{
+		thresholds: thresholds,
+		onAnomaly:  onAnomaly,
+		baselines:  make(map[AnomalyKind]float64),
+		domains:    make(map[InfoHash]string),
+		down:       make(map[string]bool),
+	}
+}
+
+// Record implements the HistorySink interface. Empty batches are ignored, as
+// they carry no time.
+func (d *AnomalyDetector) Record(samples []TorrentSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	at := samples[0].Time
+
+	d.mu.Lock()
+	if n := len(d.samples); n > 0 && at.Before(d.samples[n-1].at) {
+		d.mu.Unlock()
+		return nil
+	}
+	current := activitySample{at: at}
+	for _, sample := range samples {
+		current.upload += sample.UpSpeed
+		current.peers += sample.NumSeeds + sample.NumLeechs
+	}
+
+	var anomalies []Anomaly
+	uploadBaseline, peersBaseline, ok := d.baseline(at)
+	anomalies = d.check(anomalies, UploadStopped, at, ok && uploadBaseline >= d.thresholds.MinUploadRate, uploadBaseline,
+		float64(current.upload), func(baseline, current float64) bool { return current == 0 })
+	anomalies = d.check(anomalies, PeersCollapsed, at, ok && peersBaseline >= d.thresholds.MinPeers, peersBaseline,
+		float64(current.peers), func(baseline, current float64) bool { return current <= baseline*(1-d.thresholds.PeerDrop) })
+	anomalies = append(anomalies, d.checkDomains(at, samples)...)
+
+	d.samples = append(d.samples, current)
+	cutoff := at.Add(-d.thresholds.Window)
+	drop := 0
+	for drop < len(d.samples) && d.samples[drop].at.Before(cutoff) {
+		drop++
+	}
+	d.samples = d.samples[drop:]
+	d.mu.Unlock()
+
+	// Call back without holding the lock so handlers may use the detector
+	if d.onAnomaly != nil {
+		for _, anomaly := range anomalies {
+			d.onAnomaly(anomaly)
+		}
+	}
+	return nil
+}
+
+// baseline returns the average upload rate and peer count of the window
+// before at, and false if there are too few samples
+func (d *AnomalyDetector) baseline(at time.Time) (upload, peers float64, ok bool) {
+	cutoff := at.Add(-d.thresholds.Window)
+	n := 0
+	for _, sample := range d.samples {
+		if sample.at.Before(cutoff) {
+			continue
+		}
+		upload += float64(sample.upload)
+		peers += float64(sample.peers)
+		n++
+	}
+	if n < minBaselineSamples {
+		return 0, 0, false
+	}
+	return upload / float64(n), peers / float64(n), true
+}
+
+// check appends an anomaly if kind started or stopped holding. An anomaly
+// starts if the baseline is significant and holds is true for it, and keeps
+// the baseline it started with until holds is false for it.
+func (d *AnomalyDetector) check(anomalies []Anomaly, kind AnomalyKind, at time.Time, significant bool, baseline, current float64, holds func(baseline, current float64) bool) []Anomaly {
+	if started, active := d.baselines[kind]; active {
+		if holds(started, current) {
+			return anomalies
+		}
+		delete(d.baselines, kind)
+		return append(anomalies, Anomaly{Kind: kind, Cleared: true, At: at, Baseline: started, Current: current})
+	}
+	if !significant || !holds(baseline, current) {
+		return anomalies
+	}
+	d.baselines[kind] = baseline
+	return append(anomalies, Anomaly{Kind: kind, At: at, Baseline: baseline, Current: current})
+}
+
+// checkDomains returns the anomalies of tracker domains whose active torrents
+// all lost their working tracker, or got it back. Torrents are attributed to
+// the domain of the tracker that last worked for them.
+func (d *AnomalyDetector) checkDomains(at time.Time, samples []TorrentSample) []Anomaly {
+	present := make(map[InfoHash]bool, len(samples))
+	total := make(map[string]int)
+	working := make(map[string]int)
+	for _, sample := range samples {
+		present[sample.Hash] = true
+		if domain := trackerDomain(sample.Tracker); domain != "" {
+			d.domains[sample.Hash] = domain
+		}
+		domain, ok := d.domains[sample.Hash]
+		if !ok || TorrentState(sample.State).Stopped() {
+			continue
+		}
+		total[domain]++
+		if sample.Tracker != "" {
+			working[domain]++
+		}
+	}
+	for hash := range d.domains {
+		if !present[hash] {
+			delete(d.domains, hash)
+		}
+	}
+
+	var anomalies []Anomaly
+	for domain, n := range total {
+		down := working[domain] == 0 && n >= d.thresholds.MinTorrents
+		if down == d.down[domain] || (!down && working[domain] == 0) {
+			continue
+		}
+		anomalies = append(anomalies, Anomaly{Kind: TrackerDomainDown, Cleared: !down, At: at, Domain: domain, Baseline: float64(n), Current: float64(working[domain])})
+		if down {
+			d.down[domain] = true
+		} else {
+			delete(d.down, domain)
+		}
+	}
+	// domains without active torrents are forgotten
+	for domain := range d.down {
+		if total[domain] == 0 {
+			delete(d.down, domain)
+		}
+	}
+	sort.Slice(anomalies, func(i, j int) bool { return anomalies[i].Domain < anomalies[j].Domain })
+	return anomalies
+}
+
+// trackerDomain returns the lowercase host of a tracker URL
+func trackerDomain(tracker string) string {
+	u, err := url.Parse(tracker)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
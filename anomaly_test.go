@@ -0,0 +1,109 @@
+package qbittorrent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnomalyDetector_UploadAndPeers(t *testing.T) {
+	var anomalies []Anomaly
+	detector := NewAnomalyDetector(AnomalyThresholds{Window: 10 * time.Minute}, func(a Anomaly) {
+		anomalies = append(anomalies, a)
+	})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	record := func(minute int, upload, peers int64) {
+		t.Helper()
+		samples := []TorrentSample{
+			{Time: start.Add(time.Duration(minute) * time.Minute), Hash: "a", UpSpeed: upload / 2, NumLeechs: peers / 2},
+			{Time: start.Add(time.Duration(minute) * time.Minute), Hash: "b", UpSpeed: upload / 2, NumSeeds: peers / 2},
+		}
+		if err := detector.Record(samples); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	// too few samples for a baseline
+	record(0, 100000, 100)
+	record(1, 0, 0)
+	if len(anomalies) != 0 {
+		t.Fatalf("expected no anomalies without a baseline, got %+v", anomalies)
+	}
+	record(2, 100000, 100)
+	record(3, 100000, 100)
+	record(4, 0, 10)
+	if len(anomalies) != 2 || anomalies[0].Kind != UploadStopped || anomalies[1].Kind != PeersCollapsed {
+		t.Fatalf("expected upload stopped and peers collapsed, got %+v", anomalies)
+	}
+	if anomalies[0].Baseline != 75000 || anomalies[0].Current != 0 || anomalies[1].Baseline != 75 || anomalies[1].Current != 10 {
+		t.Errorf("unexpected baselines %+v", anomalies)
+	}
+
+	// the outage lasts longer than the window without becoming the norm
+	for minute := 5; minute < 20; minute++ {
+		record(minute, 0, 10)
+	}
+	if len(anomalies) != 2 {
+		t.Fatalf("expected no more anomalies during the outage, got %+v", anomalies[2:])
+	}
+	record(20, 20000, 40)
+	if len(anomalies) != 4 || !anomalies[2].Cleared || anomalies[2].Kind != UploadStopped || !anomalies[3].Cleared || anomalies[3].Baseline != 75 {
+		t.Errorf("expected both anomalies to clear, got %+v", anomalies[2:])
+	}
+}
+
+func TestAnomalyDetector_InsignificantBaseline(t *testing.T) {
+	var anomalies []Anomaly
+	detector := NewAnomalyDetector(AnomalyThresholds{}, func(a Anomaly) { anomalies = append(anomalies, a) })
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, upload := range []int64{100, 200, 100, 0} {
+		detector.Record([]TorrentSample{{Time: start.Add(time.Duration(i) * time.Minute), Hash: "a", UpSpeed: upload, NumSeeds: 2}})
+	}
+	if len(anomalies) != 0 {
+		t.Errorf("expected no anomalies below the minimum rate and peers, got %+v", anomalies)
+	}
+}
+
+func TestAnomalyDetector_TrackerDomain(t *testing.T) {
+	var anomalies []Anomaly
+	detector := NewAnomalyDetector(AnomalyThresholds{}, func(a Anomaly) { anomalies = append(anomalies, a) })
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	record := func(minute int, trackers map[InfoHash]string, states map[InfoHash]string) {
+		var samples []TorrentSample
+		for _, hash := range []InfoHash{"a", "b", "c", "d"} {
+			tracker, ok := trackers[hash]
+			if !ok {
+				continue
+			}
+			state := states[hash]
+			if state == "" {
+				state = "uploading"
+			}
+			samples = append(samples, TorrentSample{Time: start.Add(time.Duration(minute) * time.Minute), Hash: hash, Tracker: tracker, State: state})
+		}
+		detector.Record(samples)
+	}
+
+	working := map[InfoHash]string{
+		"a": "https://tracker.example/announce/x",
+		"b": "https://Tracker.example/announce/y",
+		"c": "udp://other.example:6969/announce",
+		"d": "https://single.example/announce",
+	}
+	record(0, working, nil)
+	record(1, map[InfoHash]string{"a": "", "b": "https://tracker.example/announce/y", "c": "udp://other.example:6969/announce", "d": ""}, nil)
+	if len(anomalies) != 0 {
+		t.Fatalf("expected no anomaly while a torrent of the domain works or for a single torrent, got %+v", anomalies)
+	}
+	record(2, map[InfoHash]string{"a": "", "b": "", "c": "", "d": ""}, map[InfoHash]string{"c": "stoppedUP"})
+	if len(anomalies) != 1 || anomalies[0].Kind != TrackerDomainDown || anomalies[0].Domain != "tracker.example" || anomalies[0].Baseline != 2 || anomalies[0].Current != 0 {
+		t.Fatalf("expected tracker.example to be down, got %+v", anomalies)
+	}
+	record(3, map[InfoHash]string{"a": "", "b": "", "c": "", "d": ""}, nil)
+	if len(anomalies) != 1 {
+		t.Fatalf("expected no repeated anomaly, got %+v", anomalies[1:])
+	}
+	record(4, map[InfoHash]string{"a": "https://tracker.example/announce/x", "b": ""}, nil)
+	if len(anomalies) != 2 || !anomalies[1].Cleared || anomalies[1].Domain != "tracker.example" || anomalies[1].Current != 1 {
+		t.Errorf("expected tracker.example to recover, got %+v", anomalies[1:])
+	}
+}
@@ -0,0 +1,95 @@
+package qbittorrent
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Errors matched by an *APIError according to its status code
+var (
+	ErrBadRequest           = errors.New("bad request")
+	ErrForbidden            = errors.New("forbidden")
+	ErrNotFound             = errors.New("not found")
+	ErrConflict             = errors.New("conflict")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+// Errors matched by an *APIError according to the server's message
+var (
+	ErrQueueingDisabled  = errors.New("torrent queueing is disabled")
+	ErrMetadataNotReady  = errors.New("torrent metadata not downloaded yet")
+	ErrInvalidHash       = errors.New("incorrect torrent hash")
+	ErrInvalidCategory   = errors.New("invalid category")
+	ErrInvalidSavePath   = errors.New("invalid save path")
+	ErrInvalidTrackerURL = errors.New("invalid tracker URL")
+)
+
+// statusErrors maps status codes to their sentinel errors
+var statusErrors = map[int]error{
+	http.StatusBadRequest:           ErrBadRequest,
+	http.StatusForbidden:            ErrForbidden,
+	http.StatusNotFound:             ErrNotFound,
+	http.StatusConflict:             ErrConflict,
+	http.StatusUnsupportedMediaType: ErrUnsupportedMediaType,
+}
+
+// messageErrors maps the explanations qBittorrent returns, lowercased, to
+// their sentinel errors
+var messageErrors = map[string]error{
+	"torrent queueing must be enabled":          ErrQueueingDisabled,
+	"torrent's metadata has not yet downloaded": ErrMetadataNotReady,
+	"incorrect torrent hash":                    ErrInvalidHash,
+	"torrent hash is invalid":                   ErrInvalidHash,
+	"incorrect category name":                   ErrInvalidCategory,
+	"category name cannot be empty":             ErrInvalidCategory,
+	"category does not exist":                   ErrInvalidCategory,
+	"save path cannot be empty":                 ErrInvalidSavePath,
+	"save path is empty":                        ErrInvalidSavePath,
+	"cannot make save path":                     ErrInvalidSavePath,
+	"cannot write to directory":                 ErrInvalidSavePath,
+	"invalid tracker url":                       ErrInvalidTrackerURL,
+	"tracker url does not exist":                ErrInvalidTrackerURL,
+}
+
+// APIError is returned when qBittorrent answers a request with an unexpected
+// status. Use errors.Is with the Err* sentinels to check for specific
+// failures, such as ErrConflict or ErrQueueingDisabled.
+type APIError struct {
+	Method     string
+	Endpoint   string
+	StatusCode int
+	Message    string // the server's plain-text explanation, if any
+}
+
+// newAPIError returns the error for a response with an unexpected status.
+// Secrets such as passkeys of URLs in the body are redacted.
+func (c *Client) newAPIError(method, endpoint string, statusCode int, body []byte) *APIError {
+	return &APIError{
+		Method:     method,
+		Endpoint:   endpoint,
+		StatusCode: statusCode,
+		Message:    c.redact(strings.TrimSpace(string(body))),
+	}
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: unexpected response code %d", e.Method, e.Endpoint, e.StatusCode)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Unwrap returns the sentinels matching the status code and message
+func (e *APIError) Unwrap() []error {
+	var errs []error
+	if err, ok := statusErrors[e.StatusCode]; ok {
+		errs = append(errs, err)
+	}
+	if err, ok := messageErrors[strings.ToLower(strings.TrimSuffix(e.Message, "."))]; ok {
+		errs = append(errs, err)
+	}
+	return errs
+}
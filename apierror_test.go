@@ -0,0 +1,49 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/torrents/topPrio":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte("Torrent queueing must be enabled\n"))
+		case "/api/v2/torrents/info":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Something new"))
+		}
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	_, err := client.doPostValuesCtx(context.Background(), "/api/v2/torrents/topPrio", nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusConflict || apiErr.Message != "Torrent queueing must be enabled" || apiErr.Endpoint != "/api/v2/torrents/topPrio" {
+		t.Errorf("unexpected error %+v", apiErr)
+	}
+	if !errors.Is(err, ErrConflict) || !errors.Is(err, ErrQueueingDisabled) {
+		t.Errorf("expected ErrConflict and ErrQueueingDisabled, got %v", err)
+	}
+
+	_, err = client.TorrentsInfo()
+	if !errors.Is(err, ErrNotFound) || errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	_, err = client.doPostValuesCtx(context.Background(), "/api/v2/torrents/other", nil)
+	if !errors.Is(err, ErrBadRequest) || err.Error() != "POST /api/v2/torrents/other: unexpected response code 400: Something new" {
+		t.Errorf("expected ErrBadRequest with the message, got %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// AppVersionCtx returns the version of qBittorrent, e.g. "v4.6.4"
+func (c *Client) AppVersionCtx(ctx context.Context) (string, error) {
+	respData, err := c.doGetCtx(ctx, "/api/v2/app/version", nil)
+	if err != nil {
+		return "", fmt.Errorf("AppVersion error: %w", err)
+	}
+	return strings.TrimSpace(string(respData)), nil
+}
+
+// AppWebAPIVersionCtx returns the version of the WebUI API, e.g. "2.9.3"
+func (c *Client) AppWebAPIVersionCtx(ctx context.Context) (string, error) {
+	respData, err := c.doGetCtx(ctx, "/api/v2/app/webapiVersion", nil)
+	if err != nil {
+		return "", fmt.Errorf("AppWebAPIVersion error: %w", err)
+	}
+	return strings.TrimSpace(string(respData)), nil
+}
@@ -0,0 +1,105 @@
+package qbittorrent
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// AuditRecord describes a mutating request sent to the server
+type AuditRecord struct {
+	At         time.Time
+	Method     string
+	Endpoint   string
+	Params     url.Values // form fields, with uploaded files as their names and passwords and URL secrets redacted
+	StatusCode int        // zero if no response was received
+	Err        error      // transport error, if any
+	Duration   time.Duration
+}
+
+// AuditSink receives an AuditRecord for every mutating request. Audit is
+// called synchronously from concurrent requests, so it must be quick and safe
+// for concurrent use.
+type AuditSink interface {
+	Audit(AuditRecord)
+}
+
+// AuditFunc adapts a function to an AuditSink
+type AuditFunc func(AuditRecord)
+
+// Audit implements the AuditSink interface
+func (f AuditFunc) Audit(record AuditRecord) {
+	f(record)
+}
+
+// WithAuditSink passes every mutating request to sink once it completes, so
+// shared instances can tell which tool deleted or changed what. Requests are
+// mutating unless they only read, see WithReadOnly; requests refused by the
+// client or skipped in dry-run mode are not audited.
+func WithAuditSink(sink AuditSink) Option {
+	return func(c *Client) error {
+		c.audit = sink
+		return nil
+	}
+}
+
+// auditRequest passes a completed request to the audit sink, if it mutates
+func (c *Client) auditRequest(start time.Time, method, endpoint, contentType string, body []byte, resp *http.Response, err error) {
+	if c.audit == nil || readOnlyEndpoints[endpoint] {
+		return
+	}
+	var statusCode int
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	params := auditParams(contentType, body)
+	c.redactValues(params)
+	c.audit.Audit(AuditRecord{
+		At:         start,
+		Method:     method,
+		Endpoint:   endpoint,
+		Params:     params,
+		StatusCode: statusCode,
+		Err:        err,
+		Duration:   c.Clock().Now().Sub(start),
+	})
+}
+
+// auditParams decodes the form fields of a request body for auditing
+func auditParams(contentType string, body []byte) url.Values {
+	params := url.Values{}
+	mediaType, mediaParams, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		params, _ = url.ParseQuery(string(body))
+	case "multipart/form-data":
+		reader := multipart.NewReader(bytes.NewReader(body), mediaParams["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			if part.FileName() != "" {
+				params.Add(part.FormName(), part.FileName())
+				continue
+			}
+			value, _ := io.ReadAll(part)
+			params.Add(part.FormName(), string(value))
+		}
+	}
+
+	for key, values := range params {
+		for i, value := range values {
+			if sensitiveKey(key) {
+				values[i] = redacted
+			} else {
+				values[i] = redactJSONSecrets(value)
+			}
+		}
+	}
+	return params
+}
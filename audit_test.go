@@ -0,0 +1,61 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithAuditSink(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/torrents/info":
+			w.Write([]byte(`[]`))
+		case "/api/v2/torrents/setLocation":
+			w.WriteHeader(http.StatusConflict)
+		}
+	}))
+	defer mockServer.Close()
+
+	var records []AuditRecord
+	clock := &stubClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	client, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithBypassAuth(),
+		WithAuditSink(AuditFunc(func(record AuditRecord) { records = append(records, record) })),
+		WithClock(clock),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := client.TorrentsInfoCtx(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := client.TorrentsAddCtx(ctx, "show.torrent", []byte(testTorrentFile), &TorrentsAddParams{Category: "tv"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	client.TorrentsSetLocationCtx(ctx, "abc", "/data")
+	client.AppSetPreferencesCtx(ctx, map[string]interface{}{"web_ui_password": "secret", "dht": true})
+
+	if len(records) != 3 {
+		t.Fatalf("expected the mutating requests to be audited, got %+v", records)
+	}
+	add, move, prefs := records[0], records[1], records[2]
+	if add.Endpoint != "/api/v2/torrents/add" || add.Params.Get("torrents") != "show.torrent" || add.Params.Get("category") != "tv" || add.StatusCode != http.StatusOK {
+		t.Errorf("unexpected add record %+v", add)
+	}
+	if move.Params.Get("hashes") != "abc" || move.Params.Get("location") != "/data" || move.StatusCode != http.StatusConflict {
+		t.Errorf("unexpected setLocation record %+v", move)
+	}
+	if json := prefs.Params.Get("json"); json != `{"dht":true,"web_ui_password":"[REDACTED]"}` {
+		t.Errorf("expected the password to be redacted, got %s", json)
+	}
+	if !add.At.Equal(clock.now) || add.Duration != 0 || add.Method != "POST" {
+		t.Errorf("expected the time of the clock and the method to be recorded, got %+v", add)
+	}
+}
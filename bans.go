@@ -0,0 +1,269 @@
+package qbittorrent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Ban is a peer banned through a BanManager
+type Ban struct {
+	Peer      string    `json:"peer"` // "host:port" as passed to transfer/banPeers
+	Reason    string    `json:"reason,omitempty"`
+	BannedAt  time.Time `json:"banned_at"`
+	ExpiresAt time.Time `json:"expires_at"` // zero for a permanent ban
+}
+
+// IP returns the address part of the banned peer
+func (b Ban) IP() string {
+	host, _, err := net.SplitHostPort(b.Peer)
+	if err != nil {
+		return b.Peer
+	}
+	return host
+}
+
+// Expired reports whether the ban has expired at the given time
+func (b Ban) Expired(at time.Time) bool {
+	return !b.ExpiresAt.IsZero() && !at.Before(b.ExpiresAt)
+}
+
+// BanManager keeps track of banned peers with their reasons and expiry.
+// qBittorrent doesn't remember why or until when a peer was banned, and bans
+// may be lost when it restarts, so the manager persists its bans and Sync
+// re-applies those missing on the server. A BanManager is safe for concurrent use.
+type BanManager struct {
+	client *Client
+	path   string
+	now    func() time.Time
+
+	mu   sync.Mutex
+	bans map[string]Ban
+}
+
+// NewBanManager returns a manager for the bans of c. If path is not empty the
+// bans are loaded from and saved to that file.
+func NewBanManager(c *Client, path string) (*BanManager, error) {
+	m := &BanManager{
+		client: c,
+		path:   path,
+		now:    c.Clock().Now,
+		bans:   make(map[string]Ban),
+	}
+	if path == "" {
+		return m, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read bans: %w", err)
+	}
+	var bans []Ban
+	if err := json.Unmarshal(data, &bans); err != nil {
+		return nil, fmt.Errorf("failed to decode bans: %w", err)
+	}
+	for _, ban := range bans {
+		m.bans[ban.Peer] = ban
+	}
+	return m, nil
+}
+
+// Ban bans peer on the server and records it. A ttl of zero bans permanently.
+func (m *BanManager) Ban(ctx context.Context, peer, reason string, ttl time.Duration) error {
+	if err := m.client.TransferBanPeersCtx(ctx, []string{peer}); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ban := Ban{Peer: peer, Reason: reason, BannedAt: m.now()}
+	if ttl > 0 {
+		ban.ExpiresAt = ban.BannedAt.Add(ttl)
+	}
+	m.bans[peer] = ban
+	return m.save()
+}
+
+// Unban lifts the ban on peer. Since qBittorrent bans by address, the address
+// stays banned on the server while other banned peers share it.
+func (m *BanManager) Unban(ctx context.Context, peer string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ban, ok := m.bans[peer]
+	if !ok {
+		return fmt.Errorf("peer %s is not banned", peer)
+	}
+	if err := m.unbanIPs(ctx, []Ban{ban}); err != nil {
+		return err
+	}
+	delete(m.bans, peer)
+	return m.save()
+}
+
+// List returns the bans that haven't expired, oldest first
+func (m *BanManager) List() []Ban {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	var bans []Ban
+	for _, ban := range m.bans {
+		if !ban.Expired(now) {
+			bans = append(bans, ban)
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		if !bans[i].BannedAt.Equal(bans[j].BannedAt) {
+			return bans[i].BannedAt.Before(bans[j].BannedAt)
+		}
+		return bans[i].Peer < bans[j].Peer
+	})
+	return bans
+}
+
+// Sync lifts expired bans and re-applies bans missing from the server's banned
+// IPs, e.g. after qBittorrent restarted
+func (m *BanManager) Sync(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	var expired []Ban
+	for _, ban := range m.bans {
+		if ban.Expired(now) {
+			expired = append(expired, ban)
+		}
+	}
+	if len(expired) > 0 {
+		if err := m.unbanIPs(ctx, expired); err != nil {
+			return err
+		}
+		for _, ban := range expired {
+			delete(m.bans, ban.Peer)
+		}
+		if err := m.save(); err != nil {
+			return err
+		}
+	}
+
+	prefs, err := m.client.AppPreferencesCtx(ctx)
+	if err != nil {
+		return err
+	}
+	banned := make(map[string]bool)
+	for _, ip := range splitBannedIPs(prefs.BannedIPs) {
+		banned[ip] = true
+	}
+	var missing []string
+	for peer, ban := range m.bans {
+		if !banned[ban.IP()] {
+			missing = append(missing, peer)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return m.client.TransferBanPeersCtx(ctx, missing)
+}
+
+// Run calls Sync every interval until ctx is done
+func (m *BanManager) Run(ctx context.Context, interval time.Duration) error {
+	ticker := m.client.Clock().NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.Sync(ctx); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		}
+	}
+}
+
+// unbanIPs removes the addresses of bans from the server's banned IPs, keeping
+// addresses still used by other bans. m.mu must be held.
+func (m *BanManager) unbanIPs(ctx context.Context, bans []Ban) error {
+	peers := make(map[string]bool)
+	lifted := make(map[string]bool)
+	for _, ban := range bans {
+		peers[ban.Peer] = true
+		lifted[ban.IP()] = true
+	}
+	for _, ban := range m.bans {
+		if !peers[ban.Peer] {
+			delete(lifted, ban.IP())
+		}
+	}
+	if len(lifted) == 0 {
+		return nil
+	}
+	ips := make([]string, 0, len(lifted))
+	for ip := range lifted {
+		ips = append(ips, ip)
+	}
+	return m.client.UnbanIPsCtx(ctx, ips...)
+}
+
+// save writes the bans to the manager's file, if any. m.mu must be held.
+func (m *BanManager) save() error {
+	if m.path == "" {
+		return nil
+	}
+	bans := make([]Ban, 0, len(m.bans))
+	for _, ban := range m.bans {
+		bans = append(bans, ban)
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].Peer < bans[j].Peer })
+	data, err := json.MarshalIndent(bans, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bans: %w", err)
+	}
+
+	if err := writeFileAtomic(m.path, data); err != nil {
+		return fmt.Errorf("failed to save bans: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file first and renames it to
+// path, so a crash can't leave a truncated file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// splitBannedIPs splits the newline separated banned_IPs preference
+func splitBannedIPs(bannedIPs string) []string {
+	var ips []string
+	for _, ip := range strings.Split(bannedIPs, "\n") {
+		if ip = strings.TrimSpace(ip); ip != "" {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
@@ -0,0 +1,134 @@
+package qbittorrent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// banServer emulates the banned IPs kept by qBittorrent
+type banServer struct {
+	mu        sync.Mutex
+	bannedIPs []string
+	banCalls  []string
+}
+
+func (s *banServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.URL.Path {
+	case "/api/v2/transfer/banPeers":
+		peers := r.FormValue("peers")
+		s.banCalls = append(s.banCalls, peers)
+		for _, peer := range strings.Split(peers, "|") {
+			ban := Ban{Peer: peer}
+			s.bannedIPs = append(s.bannedIPs, ban.IP())
+		}
+	case "/api/v2/app/preferences":
+		json.NewEncoder(w).Encode(map[string]interface{}{"banned_IPs": strings.Join(s.bannedIPs, "\n")})
+	case "/api/v2/app/setPreferences":
+		var prefs map[string]string
+		json.Unmarshal([]byte(r.FormValue("json")), &prefs)
+		s.bannedIPs = splitBannedIPs(prefs["banned_IPs"])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestBanManager(t *testing.T) {
+	server := &banServer{}
+	mockServer := httptest.NewServer(server)
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	path := filepath.Join(t.TempDir(), "bans.json")
+	manager, err := NewBanManager(client, path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	manager.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	if err := manager.Ban(ctx, "10.0.0.1:6881", "fake client", 0); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := manager.Ban(ctx, "[2001:db8::1]:51413", "corrupt pieces", time.Hour); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if bans := manager.List(); len(bans) != 2 || bans[1].Reason != "corrupt pieces" {
+		t.Fatalf("expected 2 bans, got %+v", bans)
+	}
+
+	// The bans survive a restart of the manager, and are re-applied after
+	// qBittorrent forgot them
+	manager, err = NewBanManager(client, path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	manager.now = func() time.Time { return now }
+	server.bannedIPs = nil
+	server.banCalls = nil
+	if err := manager.Sync(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(server.banCalls) != 1 || server.banCalls[0] != "10.0.0.1:6881|[2001:db8::1]:51413" {
+		t.Errorf("expected bans to be re-applied, got %v", server.banCalls)
+	}
+	if err := manager.Sync(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(server.banCalls) != 1 {
+		t.Errorf("expected no bans to be re-applied, got %v", server.banCalls)
+	}
+
+	// Expired bans are lifted on the server
+	now = now.Add(2 * time.Hour)
+	if err := manager.Sync(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(server.bannedIPs, ",") != "10.0.0.1" {
+		t.Errorf("expected only 10.0.0.1 to stay banned, got %v", server.bannedIPs)
+	}
+
+	if err := manager.Unban(ctx, "10.0.0.1:6881"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(server.bannedIPs) != 0 || len(manager.List()) != 0 {
+		t.Errorf("expected no bans, got %v and %+v", server.bannedIPs, manager.List())
+	}
+	if err := manager.Unban(ctx, "10.0.0.1:6881"); err == nil {
+		t.Errorf("expected error unbanning a peer that isn't banned, got none")
+	}
+}
+
+func TestBanManager_SharedAddress(t *testing.T) {
+	server := &banServer{}
+	mockServer := httptest.NewServer(server)
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	manager, err := NewBanManager(client, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ctx := context.Background()
+	for _, peer := range []string{"10.0.0.1:6881", "10.0.0.1:6882"} {
+		if err := manager.Ban(ctx, peer, "", 0); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if err := manager.Unban(ctx, "10.0.0.1:6881"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(server.bannedIPs) == 0 {
+		t.Errorf("expected address to stay banned for the other peer")
+	}
+}
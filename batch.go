@@ -0,0 +1,192 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// AllTorrents in place of hashes makes a batch call act on every torrent,
+// e.g. TorrentsStopCtx(ctx, []string{AllTorrents})
+const AllTorrents = "all"
+
+// ErrAllTorrentsNotAllowed is returned for destructive calls on AllTorrents
+// unless the client was created with WithAllowAllTorrents
+var ErrAllTorrentsNotAllowed = errors.New("destructive call on all torrents not allowed")
+
+// allTorrentsGuarded are the endpoints that may only target AllTorrents with
+// WithAllowAllTorrents, since a mistake would be costly
+var allTorrentsGuarded = map[string]bool{
+	"/api/v2/torrents/delete":      true,
+	"/api/v2/torrents/setLocation": true,
+	"/api/v2/torrents/recheck":     true,
+}
+
+// WithAllowAllTorrents allows deleting, moving and rechecking AllTorrents.
+// Without it these calls fail with ErrAllTorrentsNotAllowed.
+func WithAllowAllTorrents() Option {
+	return func(c *Client) error {
+		c.allowAllTorrents = true
+		return nil
+	}
+}
+
+// checkAllTorrents refuses guarded requests whose form body targets all torrents
+func (c *Client) checkAllTorrents(endpoint, contentType string, body []byte) error {
+	if c.allowAllTorrents || !allTorrentsGuarded[endpoint] || contentType != "application/x-www-form-urlencoded" {
+		return nil
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil
+	}
+	if strings.EqualFold(strings.TrimSpace(form.Get("hashes")), AllTorrents) {
+		return fmt.Errorf("%s: %w", endpoint, ErrAllTorrentsNotAllowed)
+	}
+	return nil
+}
+
+// joinHashes joins hashes for the hashes parameter. AllTorrents can't be
+// combined with other hashes.
+func joinHashes(hashes []string) (string, error) {
+	if len(hashes) == 0 {
+		return "", errors.New("no torrents given")
+	}
+	for _, hash := range hashes {
+		if hash == AllTorrents && len(hashes) > 1 {
+			return "", fmt.Errorf("%q can't be combined with other hashes", AllTorrents)
+		}
+	}
+	return strings.Join(hashes, "|"), nil
+}
+
+// postHashes POSTs the hashes with the extra form values to endpoint
+func (c *Client) postHashes(ctx context.Context, endpoint string, hashes []string, data url.Values) error {
+	joined, err := joinHashes(hashes)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		data = url.Values{}
+	}
+	data.Set("hashes", joined)
+	_, err = c.doPostValuesCtx(ctx, endpoint, data)
+	return err
+}
+
+// postHashesRenamed POSTs to endpoint, or to legacy on servers that don't
+// have endpoint yet, such as pause and resume before qBittorrent 5.0 renamed
+// them to stop and start
+func (c *Client) postHashesRenamed(ctx context.Context, endpoint, legacy string, hashes []string) error {
+	err := c.postHashes(ctx, endpoint, hashes, nil)
+	if errors.Is(err, ErrNotFound) {
+		return c.postHashes(ctx, legacy, hashes, nil)
+	}
+	return err
+}
+
+// TorrentsStopCtx stops (pauses) the torrents
+func (c *Client) TorrentsStopCtx(ctx context.Context, hashes []string) error {
+	if err := c.postHashesRenamed(ctx, "/api/v2/torrents/stop", "/api/v2/torrents/pause", hashes); err != nil {
+		return fmt.Errorf("TorrentsStop error: %w", err)
+	}
+	return nil
+}
+
+// TorrentsStartCtx starts (resumes) the torrents
+func (c *Client) TorrentsStartCtx(ctx context.Context, hashes []string) error {
+	if err := c.postHashesRenamed(ctx, "/api/v2/torrents/start", "/api/v2/torrents/resume", hashes); err != nil {
+		return fmt.Errorf("TorrentsStart error: %w", err)
+	}
+	return nil
+}
+
+// TorrentsRecheckCtx rechecks the data of the torrents
+func (c *Client) TorrentsRecheckCtx(ctx context.Context, hashes []string) error {
+	if err := c.postHashes(ctx, "/api/v2/torrents/recheck", hashes, nil); err != nil {
+		return fmt.Errorf("TorrentsRecheck error: %w", err)
+	}
+	return nil
+}
+
+// TorrentsReannounceCtx reannounces the torrents to their trackers
+func (c *Client) TorrentsReannounceCtx(ctx context.Context, hashes []string) error {
+	if err := c.postHashes(ctx, "/api/v2/torrents/reannounce", hashes, nil); err != nil {
+		return fmt.Errorf("TorrentsReannounce error: %w", err)
+	}
+	return nil
+}
+
+// TorrentsSetAutoManagementCtx enables or disables automatic torrent
+// management of the torrents. Enabling it moves their data to the save path
+// of their category.
+func (c *Client) TorrentsSetAutoManagementCtx(ctx context.Context, hashes []string, enable bool) error {
+	data := url.Values{}
+	data.Set("enable", strconv.FormatBool(enable))
+	if err := c.postHashes(ctx, "/api/v2/torrents/setAutoManagement", hashes, data); err != nil {
+		return fmt.Errorf("TorrentsSetAutoManagement error: %w", err)
+	}
+	return nil
+}
+
+// TorrentsSetDownloadLimitCtx sets the download limit of the torrents in
+// bytes/s, zero for unlimited
+func (c *Client) TorrentsSetDownloadLimitCtx(ctx context.Context, hashes []string, limit int64) error {
+	data := url.Values{}
+	data.Set("limit", strconv.FormatInt(limit, 10))
+	if err := c.postHashes(ctx, "/api/v2/torrents/setDownloadLimit", hashes, data); err != nil {
+		return fmt.Errorf("TorrentsSetDownloadLimit error: %w", err)
+	}
+	return nil
+}
+
+// TorrentsSetUploadLimitCtx sets the upload limit of the torrents in
+// bytes/s, zero for unlimited
+func (c *Client) TorrentsSetUploadLimitCtx(ctx context.Context, hashes []string, limit int64) error {
+	data := url.Values{}
+	data.Set("limit", strconv.FormatInt(limit, 10))
+	if err := c.postHashes(ctx, "/api/v2/torrents/setUploadLimit", hashes, data); err != nil {
+		return fmt.Errorf("TorrentsSetUploadLimit error: %w", err)
+	}
+	return nil
+}
+
+// TorrentsDownloadLimitCtx returns the download limits of the torrents in
+// bytes/s, zero or less for unlimited
+func (c *Client) TorrentsDownloadLimitCtx(ctx context.Context, hashes []string) (map[InfoHash]int64, error) {
+	limits, err := c.getLimits(ctx, "/api/v2/torrents/downloadLimit", hashes)
+	if err != nil {
+		return nil, fmt.Errorf("TorrentsDownloadLimit error: %w", err)
+	}
+	return limits, nil
+}
+
+// TorrentsUploadLimitCtx returns the upload limits of the torrents in
+// bytes/s, zero or less for unlimited
+func (c *Client) TorrentsUploadLimitCtx(ctx context.Context, hashes []string) (map[InfoHash]int64, error) {
+	limits, err := c.getLimits(ctx, "/api/v2/torrents/uploadLimit", hashes)
+	if err != nil {
+		return nil, fmt.Errorf("TorrentsUploadLimit error: %w", err)
+	}
+	return limits, nil
+}
+
+// getLimits POSTs the hashes to a limit getter endpoint and decodes the limits
+func (c *Client) getLimits(ctx context.Context, endpoint string, hashes []string) (map[InfoHash]int64, error) {
+	joined, err := joinHashes(hashes)
+	if err != nil {
+		return nil, err
+	}
+	respData, err := c.doPostValuesCtx(ctx, endpoint, url.Values{"hashes": {joined}})
+	if err != nil {
+		return nil, err
+	}
+	var limits map[InfoHash]int64
+	if err := c.decodeJSON(endpoint, respData, &limits); err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
@@ -0,0 +1,74 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestTorrentsStopCtx(t *testing.T) {
+	var requests []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		requests = append(requests, r.URL.Path+" "+r.FormValue("hashes"))
+		if r.URL.Path == "/api/v2/torrents/stop" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	if err := client.TorrentsStopCtx(context.Background(), []string{AllTorrents}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []string{"/api/v2/torrents/stop all", "/api/v2/torrents/pause all"}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected %v, got %v", expected, requests)
+	}
+
+	if err := client.TorrentsStopCtx(context.Background(), []string{"a", AllTorrents}); err == nil {
+		t.Errorf("expected error for all combined with a hash, got none")
+	}
+}
+
+func TestAllTorrentsGuard(t *testing.T) {
+	var requests []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		requests = append(requests, r.URL.Path+" "+r.FormValue("hashes"))
+	}))
+	defer mockServer.Close()
+	ctx := context.Background()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+	if err := client.TorrentsRecheckCtx(ctx, []string{AllTorrents}); !errors.Is(err, ErrAllTorrentsNotAllowed) {
+		t.Errorf("expected ErrAllTorrentsNotAllowed for recheck, got %v", err)
+	}
+	if err := client.TorrentsDeleteCtx(ctx, "all"); !errors.Is(err, ErrAllTorrentsNotAllowed) {
+		t.Errorf("expected ErrAllTorrentsNotAllowed for delete, got %v", err)
+	}
+	if err := client.TorrentsSetUploadLimitCtx(ctx, []string{AllTorrents}, 1024); err != nil {
+		t.Errorf("expected limits on all torrents to be allowed, got %v", err)
+	}
+	if err := client.TorrentsRecheckCtx(ctx, []string{"a", "b"}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := WithAllowAllTorrents()(client); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := client.TorrentsRecheckCtx(ctx, []string{AllTorrents}); err != nil {
+		t.Errorf("expected no error with WithAllowAllTorrents, got %v", err)
+	}
+
+	expected := []string{
+		"/api/v2/torrents/setUploadLimit all",
+		"/api/v2/torrents/recheck a|b",
+		"/api/v2/torrents/recheck all",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected %v, got %v", expected, requests)
+	}
+}
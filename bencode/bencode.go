@@ -0,0 +1,201 @@
+// Package bencode encodes and decodes the BitTorrent bencode format used by
+// .torrent files, fastresume files and tracker responses.
+package bencode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Raw is an encoded bencode value. It is written as is by Encode.
+type Raw []byte
+
+// maxDepth limits the nesting of lists and dictionaries, so hostile data
+// such as tracker responses can't exhaust the stack
+const maxDepth = 512
+
+// decoder decodes bencode into int64, string, []interface{} and
+// map[string]interface{} values
+type decoder struct {
+	data  []byte
+	pos   int
+	depth int // of the lists and dictionaries being decoded
+}
+
+// Decode decodes a single value spanning all of data. Integers are decoded
+// as int64, strings as string, lists as []interface{} and dictionaries as
+// map[string]interface{}.
+func Decode(data []byte) (interface{}, error) {
+	d := &decoder{data: data}
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(data) {
+		return nil, fmt.Errorf("bencode: trailing data at offset %d", d.pos)
+	}
+	return v, nil
+}
+
+// DecodeRawDict decodes a dictionary spanning all of data into its encoded
+// values, e.g. to hash a value exactly as it was encoded
+func DecodeRawDict(data []byte) (map[string]Raw, error) {
+	if len(data) == 0 || data[0] != 'd' {
+		return nil, errors.New("bencode: not a dictionary")
+	}
+	d := &decoder{data: data, pos: 1}
+	dict := make(map[string]Raw)
+	for d.pos < len(data) && data[d.pos] != 'e' {
+		key, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+		start := d.pos
+		if _, err := d.value(); err != nil {
+			return nil, err
+		}
+		dict[key] = Raw(data[start:d.pos])
+	}
+	if d.pos >= len(data) {
+		return nil, errors.New("bencode: unterminated dictionary")
+	}
+	if d.pos+1 != len(data) {
+		return nil, fmt.Errorf("bencode: trailing data at offset %d", d.pos+1)
+	}
+	return dict, nil
+}
+
+func (d *decoder) value() (interface{}, error) {
+	if d.pos >= len(d.data) {
+		return nil, errors.New("bencode: unexpected end of data")
+	}
+	switch c := d.data[d.pos]; {
+	case c == 'i':
+		end := bytes.IndexByte(d.data[d.pos:], 'e')
+		if end < 0 {
+			return nil, errors.New("bencode: unterminated integer")
+		}
+		n, err := strconv.ParseInt(string(d.data[d.pos+1:d.pos+end]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bencode: invalid integer at offset %d", d.pos)
+		}
+		d.pos += end + 1
+		return n, nil
+	case c >= '0' && c <= '9':
+		return d.string()
+	case (c == 'l' || c == 'd') && d.depth >= maxDepth:
+		return nil, fmt.Errorf("bencode: nesting exceeds %d levels at offset %d", maxDepth, d.pos)
+	case c == 'l':
+		d.depth++
+		defer func() { d.depth-- }()
+		d.pos++
+		list := []interface{}{}
+		for d.pos < len(d.data) && d.data[d.pos] != 'e' {
+			v, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		if d.pos >= len(d.data) {
+			return nil, errors.New("bencode: unterminated list")
+		}
+		d.pos++
+		return list, nil
+	case c == 'd':
+		d.depth++
+		defer func() { d.depth-- }()
+		d.pos++
+		dict := map[string]interface{}{}
+		for d.pos < len(d.data) && d.data[d.pos] != 'e' {
+			key, err := d.string()
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			dict[key] = v
+		}
+		if d.pos >= len(d.data) {
+			return nil, errors.New("bencode: unterminated dictionary")
+		}
+		d.pos++
+		return dict, nil
+	default:
+		return nil, fmt.Errorf("bencode: invalid value at offset %d", d.pos)
+	}
+}
+
+func (d *decoder) string() (string, error) {
+	colon := bytes.IndexByte(d.data[d.pos:], ':')
+	if colon < 0 {
+		return "", errors.New("bencode: unterminated string length")
+	}
+	n, err := strconv.Atoi(string(d.data[d.pos : d.pos+colon]))
+	if err != nil || n < 0 {
+		return "", fmt.Errorf("bencode: invalid string length at offset %d", d.pos)
+	}
+	start := d.pos + colon + 1
+	if n > len(d.data)-start {
+		return "", errors.New("bencode: string exceeds data")
+	}
+	d.pos = start + n
+	return string(d.data[start:d.pos]), nil
+}
+
+// Encode encodes v, which may be composed of the types produced by Decode,
+// int, []byte and Raw. Dictionary keys are sorted as required.
+func Encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encode appends the encoding of v
+func encode(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case Raw:
+		buf.Write(v)
+	case int64:
+		fmt.Fprintf(buf, "i%de", v)
+	case int:
+		fmt.Fprintf(buf, "i%de", v)
+	case string:
+		fmt.Fprintf(buf, "%d:%s", len(v), v)
+	case []byte:
+		fmt.Fprintf(buf, "%d:", len(v))
+		buf.Write(v)
+	case []interface{}:
+		buf.WriteByte('l')
+		for _, item := range v {
+			if err := encode(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('e')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('d')
+		for _, key := range keys {
+			fmt.Fprintf(buf, "%d:%s", len(key), key)
+			if err := encode(buf, v[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('e')
+	default:
+		return fmt.Errorf("bencode: unsupported type %T", v)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package bencode_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cehbz/qbittorrent/bencode"
+)
+
+func TestRoundTrip(t *testing.T) {
+	value := map[string]interface{}{
+		"int":  int64(-42),
+		"str":  "spam",
+		"list": []interface{}{int64(1), "two", []interface{}{}},
+		"dict": map[string]interface{}{"b": "2", "a": "1"},
+	}
+	data, err := bencode.Encode(value)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := "d4:dictd1:a1:11:b1:2e3:inti-42e4:listli1e3:twolee3:str4:spame"
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	decoded, err := bencode.Decode(data)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(decoded, value) {
+		t.Errorf("expected %v, got %v", value, decoded)
+	}
+}
+
+func TestDecodeRawDict(t *testing.T) {
+	// Raw values are kept even if they aren't canonically encoded
+	data := "d1:xd1:bi1e1:ai2ee1:y3:abce"
+	dict, err := bencode.DecodeRawDict([]byte(data))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(dict["x"]) != "d1:bi1e1:ai2ee" || string(dict["y"]) != "3:abc" {
+		t.Errorf("unexpected raw values %q", dict)
+	}
+
+	encoded, err := bencode.Encode(map[string]interface{}{"x": dict["x"]})
+	if err != nil || string(encoded) != "d1:xd1:bi1e1:ai2eee" {
+		t.Errorf("expected raw value to be written as is, got %s, %v", encoded, err)
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	tests := []string{"", "i12", "ixe", "5:abc", "l", "d1:a", "di1ei2ee", "x", "i1ei2e", "-1:a", "9223372036854775807:abc"}
+	for _, data := range tests {
+		if _, err := bencode.Decode([]byte(data)); err == nil {
+			t.Errorf("expected error decoding %q, got none", data)
+		}
+	}
+}
+
+func TestDecode_Depth(t *testing.T) {
+	nested := func(depth int) []byte {
+		return []byte(strings.Repeat("l", depth) + strings.Repeat("e", depth))
+	}
+	if _, err := bencode.Decode(nested(512)); err != nil {
+		t.Errorf("expected no error at the depth limit, got %v", err)
+	}
+	if _, err := bencode.Decode(nested(513)); err == nil {
+		t.Error("expected error beyond the depth limit, got none")
+	}
+	// Deep enough to overflow the stack without the limit
+	if _, err := bencode.Decode([]byte(strings.Repeat("ld1:a", 10<<20))); err == nil {
+		t.Error("expected error for deeply nested data, got none")
+	}
+}
@@ -0,0 +1,49 @@
+package qbittorrent
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxPooledBuffer is the capacity above which buffers are left to the garbage
+// collector rather than pooled, so one huge upload doesn't pin its memory
+const maxPooledBuffer = 16 << 20
+
+// bufferPool holds request body buffers for reuse
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns a buffer to the pool. It must not be used afterwards.
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBuffer {
+		return
+	}
+	b.Reset()
+	bufferPool.Put(b)
+}
+
+// bufferBody reads a request body into memory so the request can be retried,
+// and returns a function releasing the memory once the request is done.
+// Bodies that are buffers already are used as they are.
+func bufferBody(body io.Reader) ([]byte, func(), error) {
+	switch body := body.(type) {
+	case nil:
+		return nil, func() {}, nil
+	case *bytes.Buffer:
+		return body.Bytes(), func() {}, nil
+	}
+
+	b := getBuffer()
+	if _, err := b.ReadFrom(body); err != nil {
+		putBuffer(b)
+		return nil, nil, err
+	}
+	return b.Bytes(), func() { putBuffer(b) }, nil
+}
@@ -0,0 +1,63 @@
+package qbittorrent
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBufferBody(t *testing.T) {
+	buf := bytes.NewBufferString("form=data")
+	data, release, err := bufferBody(buf)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	release()
+	if string(data) != "form=data" || &data[0] != &buf.Bytes()[0] {
+		t.Errorf("expected buffers to be used without copying, got %q", data)
+	}
+
+	data, release, err = bufferBody(strings.NewReader("a=b"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(data) != "a=b" {
+		t.Errorf("expected a=b, got %q", data)
+	}
+	release()
+
+	if data, _, err := bufferBody(nil); data != nil || err != nil {
+		t.Errorf("expected no body, got %q, %v", data, err)
+	}
+}
+
+func TestPutBuffer_DropsLargeBuffers(t *testing.T) {
+	b := getBuffer()
+	b.Grow(maxPooledBuffer + 1)
+	putBuffer(b)
+	if got := getBuffer(); got.Cap() > maxPooledBuffer {
+		t.Errorf("expected large buffer not to be pooled")
+	}
+}
+
+func BenchmarkTorrentsAdd(b *testing.B) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Ok."))
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	fileData := bytes.Repeat([]byte(testTorrentFile), 1000)
+	ctx := context.Background()
+	b.SetBytes(int64(len(fileData)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := client.TorrentsAddCtx(ctx, "show.torrent", fileData); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package qbittorrent
+
+import (
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTLs are the endpoints cached by WithCache unless given others
+var DefaultCacheTTLs = map[string]time.Duration{
+	"/api/v2/app/version":         10 * time.Minute,
+	"/api/v2/app/webapiVersion":   10 * time.Minute,
+	"/api/v2/app/preferences":     time.Minute,
+	"/api/v2/torrents/categories": 30 * time.Second,
+	"/api/v2/torrents/tags":       30 * time.Second,
+}
+
+// cacheInvalidations are the cached endpoints whose data a mutating endpoint changes
+var cacheInvalidations = map[string][]string{
+	"/api/v2/app/setPreferences":        {"/api/v2/app/preferences"},
+	"/api/v2/torrents/createCategory":   {"/api/v2/torrents/categories"},
+	"/api/v2/torrents/editCategory":     {"/api/v2/torrents/categories"},
+	"/api/v2/torrents/removeCategories": {"/api/v2/torrents/categories"},
+	"/api/v2/torrents/createTags":       {"/api/v2/torrents/tags"},
+	"/api/v2/torrents/deleteTags":       {"/api/v2/torrents/tags"},
+	"/api/v2/torrents/addTags":          {"/api/v2/torrents/tags"}, // creates missing tags
+	"/api/v2/torrents/setTags":          {"/api/v2/torrents/tags"},
+	"/api/v2/torrents/add":              {"/api/v2/torrents/categories", "/api/v2/torrents/tags"},
+}
+
+// WithCache caches the responses of rarely changing read endpoints, keyed by
+// endpoint, for the given time to live, or DefaultCacheTTLs if ttls is nil.
+// Calls through this client that change cached data invalidate it; changes
+// made by other clients show after the TTL, or InvalidateCache.
+func WithCache(ttls map[string]time.Duration) Option {
+	return func(c *Client) error {
+		if ttls == nil {
+			ttls = DefaultCacheTTLs
+		}
+		c.cache = newResponseCache(ttls)
+		return nil
+	}
+}
+
+// InvalidateCache drops the cached responses of the given endpoints, or all
+// cached responses if none are given
+func (c *Client) InvalidateCache(endpoints ...string) {
+	if c.cache == nil {
+		return
+	}
+	c.cache.invalidate(endpoints...)
+}
+
+// responseCache holds response bodies keyed by endpoint and query
+type responseCache struct {
+	ttls map[string]time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]map[string]cacheEntry // endpoint, encoded query
+}
+
+type cacheEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+func newResponseCache(ttls map[string]time.Duration) *responseCache {
+	copied := make(map[string]time.Duration, len(ttls))
+	for endpoint, ttl := range ttls {
+		copied[endpoint] = ttl
+	}
+	return &responseCache{
+		ttls:    copied,
+		now:     time.Now,
+		entries: make(map[string]map[string]cacheEntry),
+	}
+}
+
+// get returns the cached response, if it hasn't expired
+func (rc *responseCache) get(endpoint string, query url.Values) ([]byte, bool) {
+	if rc.ttls[endpoint] <= 0 {
+		return nil, false
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	entry, ok := rc.entries[endpoint][query.Encode()]
+	if !ok || !rc.now().Before(entry.expires) {
+		return nil, false
+	}
+	return entry.data, true
+}
+
+// put caches a response of a cached endpoint
+func (rc *responseCache) put(endpoint string, query url.Values, data []byte) {
+	ttl := rc.ttls[endpoint]
+	if ttl <= 0 {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.entries[endpoint] == nil {
+		rc.entries[endpoint] = make(map[string]cacheEntry)
+	}
+	rc.entries[endpoint][query.Encode()] = cacheEntry{data: data, expires: rc.now().Add(ttl)}
+}
+
+// invalidate drops the responses of the given endpoints, or all if none are given
+func (rc *responseCache) invalidate(endpoints ...string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(endpoints) == 0 {
+		rc.entries = make(map[string]map[string]cacheEntry)
+		return
+	}
+	for _, endpoint := range endpoints {
+		delete(rc.entries, endpoint)
+	}
+}
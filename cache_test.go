@@ -0,0 +1,75 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithCache(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/api/v2/app/version":
+			w.Write([]byte("v4.6.4"))
+		case "/api/v2/torrents/tags":
+			w.Write([]byte(`["a","b"]`))
+		case "/api/v2/torrents/info":
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithBypassAuth(),
+		WithCache(nil),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	client.cache.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if version, err := client.AppVersionCtx(ctx); err != nil || version != "v4.6.4" {
+			t.Fatalf("expected v4.6.4, got %q, %v", version, err)
+		}
+		if _, err := client.TorrentsGetAllTagsCtx(ctx); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := client.TorrentsInfoCtx(ctx); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if requests["/api/v2/app/version"] != 1 || requests["/api/v2/torrents/tags"] != 1 || requests["/api/v2/torrents/info"] != 3 {
+		t.Errorf("expected cached endpoints to be fetched once, got %v", requests)
+	}
+
+	// Creating tags invalidates the tags but not the version
+	if err := client.TorrentsCreateTagsCtx(ctx, "c"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	client.TorrentsGetAllTagsCtx(ctx)
+	client.AppVersionCtx(ctx)
+	if requests["/api/v2/torrents/tags"] != 2 || requests["/api/v2/app/version"] != 1 {
+		t.Errorf("expected tags to be refetched, got %v", requests)
+	}
+
+	now = now.Add(DefaultCacheTTLs["/api/v2/app/version"])
+	client.AppVersionCtx(ctx)
+	client.InvalidateCache()
+	client.TorrentsGetAllTagsCtx(ctx)
+	if requests["/api/v2/app/version"] != 2 || requests["/api/v2/torrents/tags"] != 3 {
+		t.Errorf("expected expired and invalidated responses to be refetched, got %v", requests)
+	}
+}
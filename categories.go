@@ -0,0 +1,184 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// TorrentsCategoriesCtx retrieves all categories keyed by name
+func (c *Client) TorrentsCategoriesCtx(ctx context.Context) (map[string]Category, error) {
+	respData, err := c.doGetCtx(ctx, "/api/v2/torrents/categories", nil)
+	if err != nil {
+		return nil, fmt.Errorf("TorrentsCategories error: %w", err)
+	}
+
+	var categories map[string]Category
+	if err := c.decodeJSON("/api/v2/torrents/categories", respData, &categories); err != nil {
+		return nil, fmt.Errorf("failed to decode categories response: %w", err)
+	}
+
+	return categories, nil
+}
+
+// TorrentsCreateCategoryCtx creates a category. An empty savePath uses the
+// default save path joined with the category name.
+func (c *Client) TorrentsCreateCategoryCtx(ctx context.Context, category, savePath string) error {
+	data := url.Values{}
+	data.Set("category", category)
+	data.Set("savePath", savePath)
+
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/torrents/createCategory", data)
+	if err != nil {
+		return fmt.Errorf("CreateCategory error: %w", err)
+	}
+	return nil
+}
+
+// TorrentsSetCategoryCtx sets the category of the torrents, or removes it for
+// an empty category. Torrents under automatic torrent management move to the
+// save path of the new category, see SetCategorySafeCtx. Categories that
+// don't exist fail with ErrConflict.
+func (c *Client) TorrentsSetCategoryCtx(ctx context.Context, hashes []string, category string) error {
+	data := url.Values{}
+	data.Set("category", category)
+	if err := c.postHashes(ctx, "/api/v2/torrents/setCategory", hashes, data); err != nil {
+		return fmt.Errorf("TorrentsSetCategory error: %w", err)
+	}
+	return nil
+}
+
+// CreateCategoryPathCtx creates a nested category such as "tv/kids" along
+// with any missing parent categories, which get the default save path.
+// Categories that already exist are left alone.
+func (c *Client) CreateCategoryPathCtx(ctx context.Context, category, savePath string) error {
+	existing, err := c.TorrentsCategoriesCtx(ctx)
+	if err != nil {
+		return err
+	}
+	parents := categoryParents(category)
+	for _, parent := range parents[:len(parents)-1] {
+		if _, ok := existing[parent]; ok {
+			continue
+		}
+		if err := c.TorrentsCreateCategoryCtx(ctx, parent, ""); err != nil {
+			return err
+		}
+	}
+	if _, ok := existing[category]; ok {
+		return nil
+	}
+	return c.TorrentsCreateCategoryCtx(ctx, category, savePath)
+}
+
+// categoryParents returns a category and its ancestors, outermost first, e.g.
+// "tv", "tv/kids", "tv/kids/cartoons"
+func categoryParents(category string) []string {
+	var parents []string
+	for i, r := range category {
+		if r == '/' {
+			parents = append(parents, category[:i])
+		}
+	}
+	return append(parents, category)
+}
+
+// CategoryNode is a category in the tree built by NewCategoryTree. Count and
+// Size cover the torrents in the category itself, TotalCount and TotalSize
+// also those in its subcategories.
+type CategoryNode struct {
+	Name       string // full name, e.g. "tv/kids"; empty for the root
+	Segment    string // last part of the name, e.g. "kids"
+	Category   Category
+	Children   []*CategoryNode // sorted by name
+	Count      int
+	Size       int64
+	TotalCount int
+	TotalSize  int64
+}
+
+// NewCategoryTree arranges categories by their "/" separated names and counts
+// the torrents in each. Parents missing from categories, and categories only
+// referenced by torrents, are included with a nil Category. Torrents without a
+// category are counted at the root.
+func NewCategoryTree(categories map[string]Category, torrents map[string]TorrentInfo) *CategoryNode {
+	root := &CategoryNode{}
+	nodes := map[string]*CategoryNode{"": root}
+	var node func(name string) *CategoryNode
+	node = func(name string) *CategoryNode {
+		if n, ok := nodes[name]; ok {
+			return n
+		}
+		parent := root
+		segment := name
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			parent = node(name[:i])
+			segment = name[i+1:]
+		}
+		n := &CategoryNode{Name: name, Segment: segment}
+		parent.Children = append(parent.Children, n)
+		nodes[name] = n
+		return n
+	}
+
+	for name, category := range categories {
+		node(name).Category = category
+	}
+	for _, torrent := range torrents {
+		n := node(torrent.Category)
+		n.Count++
+		n.Size += torrent.Size
+	}
+
+	root.Walk(func(n *CategoryNode) error {
+		sort.Slice(n.Children, func(i, j int) bool { return n.Children[i].Name < n.Children[j].Name })
+		return nil
+	})
+	root.total()
+	return root
+}
+
+// total computes the subtree totals
+func (n *CategoryNode) total() {
+	n.TotalCount, n.TotalSize = n.Count, n.Size
+	for _, child := range n.Children {
+		child.total()
+		n.TotalCount += child.TotalCount
+		n.TotalSize += child.TotalSize
+	}
+}
+
+// Walk calls fn for the node and its descendants, depth first, parents before
+// children. It stops at the first error and returns it.
+func (n *CategoryNode) Walk(fn func(*CategoryNode) error) error {
+	if err := fn(n); err != nil {
+		return err
+	}
+	for _, child := range n.Children {
+		if err := child.Walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find returns the descendant with the given full name, or nil
+func (n *CategoryNode) Find(name string) *CategoryNode {
+	node := n
+	for _, parent := range categoryParents(name) {
+		var next *CategoryNode
+		for _, child := range node.Children {
+			if child.Name == parent {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
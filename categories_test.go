@@ -0,0 +1,73 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewCategoryTree(t *testing.T) {
+	categories := map[string]Category{
+		"tv":      {"name": "tv", "savePath": "/data/tv"},
+		"tv/kids": {"name": "tv/kids"},
+		"movies":  {"name": "movies"},
+	}
+	torrents := map[string]TorrentInfo{
+		"a": {Category: "tv", Size: 10},
+		"b": {Category: "tv/kids", Size: 20},
+		"c": {Category: "tv/kids/cartoons", Size: 30},
+		"d": {Category: "", Size: 5},
+	}
+	root := NewCategoryTree(categories, torrents)
+
+	var names []string
+	root.Walk(func(n *CategoryNode) error {
+		names = append(names, n.Name)
+		return nil
+	})
+	expected := []string{"", "movies", "tv", "tv/kids", "tv/kids/cartoons"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected walk order %v, got %v", expected, names)
+	}
+
+	tv := root.Find("tv")
+	if tv == nil || tv.Count != 1 || tv.Size != 10 || tv.TotalCount != 3 || tv.TotalSize != 60 {
+		t.Errorf("expected tv subtree of 3 torrents and 60 bytes, got %+v", tv)
+	}
+	cartoons := root.Find("tv/kids/cartoons")
+	if cartoons == nil || cartoons.Segment != "cartoons" || cartoons.Category != nil || cartoons.TotalCount != 1 {
+		t.Errorf("expected implicit cartoons category, got %+v", cartoons)
+	}
+	if root.TotalCount != 4 || root.Count != 1 {
+		t.Errorf("expected 4 torrents with 1 uncategorized, got %d and %d", root.TotalCount, root.Count)
+	}
+	if root.Find("tv/news") != nil {
+		t.Errorf("expected missing category not to be found")
+	}
+}
+
+func TestCreateCategoryPath(t *testing.T) {
+	var created []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/torrents/categories":
+			w.Write([]byte(`{"tv":{"name":"tv","savePath":"/data/tv"}}`))
+		case "/api/v2/torrents/createCategory":
+			created = append(created, r.FormValue("category")+"="+r.FormValue("savePath"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	if err := client.CreateCategoryPathCtx(context.Background(), "tv/kids/cartoons", "/data/cartoons"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []string{"tv/kids=", "tv/kids/cartoons=/data/cartoons"}
+	if strings.Join(created, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected categories %v to be created, got %v", expected, created)
+	}
+}
@@ -0,0 +1,147 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the server while the circuit
+// breaker of an endpoint is open, see WithCircuitBreaker
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// WithCircuitBreaker fails requests to an endpoint fast with ErrCircuitOpen
+// after threshold consecutive failures (transport errors, timeouts and 5xx
+// responses), so callers of an instance that is down don't pile up timeouts.
+// After cooldown the breaker is half-open: a single request is let through as
+// a probe and closes the breaker if it succeeds or reopens it if it fails. A
+// successful PingCtx closes all breakers at once.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) error {
+		if threshold < 1 || cooldown <= 0 {
+			return fmt.Errorf("invalid circuit breaker threshold %d or cooldown %s", threshold, cooldown)
+		}
+		c.breakers = newCircuitBreakers(threshold, cooldown)
+		return nil
+	}
+}
+
+// PingCtx checks that the server responds, bypassing the circuit breakers and
+// the cache. If it does, all circuit breakers are closed.
+func (c *Client) PingCtx(ctx context.Context) error {
+	resp, err := c.doRequestCtx(context.WithValue(ctx, circuitProbeKey{}, true), "GET", "/api/v2/app/version", nil, "")
+	if err != nil {
+		return fmt.Errorf("Ping error: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := c.readBody("/api/v2/app/version", resp)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Ping error: %w", c.newAPIError("GET", "/api/v2/app/version", resp.StatusCode, respBody))
+	}
+	c.breakers.reset()
+	return nil
+}
+
+// circuitProbeKey marks requests that bypass open circuit breakers
+type circuitProbeKey struct{}
+
+// circuitBreakers tracks a breaker per endpoint
+type circuitBreakers struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	endpoints map[string]*circuitBreaker
+}
+
+// circuitBreaker is the state of the breaker of an endpoint. It is open while
+// openedAt is set and half-open while probing.
+type circuitBreaker struct {
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newCircuitBreakers(threshold int, cooldown time.Duration) *circuitBreakers {
+	return &circuitBreakers{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		endpoints: make(map[string]*circuitBreaker),
+	}
+}
+
+// allow returns ErrCircuitOpen if requests to endpoint must not be sent. Once
+// the cooldown has passed, the first caller is let through as the probe.
+func (b *circuitBreakers) allow(ctx context.Context, endpoint string) error {
+	if b == nil || ctx.Value(circuitProbeKey{}) != nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	breaker := b.endpoints[endpoint]
+	if breaker == nil || breaker.openedAt.IsZero() {
+		return nil
+	}
+	if breaker.probing || b.now().Sub(breaker.openedAt) < b.cooldown {
+		return fmt.Errorf("%s: %w", endpoint, ErrCircuitOpen)
+	}
+	breaker.probing = true
+	return nil
+}
+
+// record updates the breaker of endpoint with the outcome of a request sent
+// with ctx, the caller's context. Requests the caller canceled don't count, but
+// they end a probe so that the next request probes again.
+func (b *circuitBreakers) record(ctx context.Context, endpoint string, resp *http.Response, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	breaker := b.endpoints[endpoint]
+	switch {
+	case err != nil && ctx.Err() != nil:
+		if breaker != nil {
+			breaker.probing = false
+		}
+		return
+	case !requestFailed(resp, err):
+		delete(b.endpoints, endpoint)
+		return
+	}
+	if breaker == nil {
+		breaker = &circuitBreaker{}
+		b.endpoints[endpoint] = breaker
+	}
+	breaker.failures++
+	if breaker.probing || breaker.failures >= b.threshold {
+		breaker.openedAt = b.now()
+		breaker.probing = false
+	}
+}
+
+// reset closes all breakers
+func (b *circuitBreakers) reset() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.endpoints = make(map[string]*circuitBreaker)
+}
+
+// requestFailed reports whether a request counts as a failure of the server.
+// Errors of the re-authentication policy don't.
+func requestFailed(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, ErrNotRetried) && !errors.Is(err, ErrReauthBudgetExhausted)
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
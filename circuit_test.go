@@ -0,0 +1,114 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var requests int32
+	var down atomic.Bool
+	down.Store(true)
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+
+	now := time.Unix(1700000000, 0)
+	breakers := newCircuitBreakers(2, time.Minute)
+	breakers.now = func() time.Time { return now }
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), breakers: breakers}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.TorrentsInfo(); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("expected the server error, got %v", err)
+		}
+	}
+	if _, err := client.TorrentsInfo(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected the open breaker not to send requests, got %d", requests)
+	}
+	// Other endpoints have their own breakers
+	if _, err := client.TorrentsCategoriesCtx(context.Background()); errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected a closed breaker for another endpoint, got %v", err)
+	}
+
+	// The failed probe reopens the breaker
+	now = now.Add(time.Minute)
+	if _, err := client.TorrentsInfo(); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the probe to fail with the server error, got %v", err)
+	}
+	if _, err := client.TorrentsInfo(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen after the failed probe, got %v", err)
+	}
+
+	// A successful probe closes it
+	down.Store(false)
+	now = now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		if _, err := client.TorrentsInfo(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+}
+
+func TestPingCtx(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithBypassAuth(),
+		WithCircuitBreaker(1, time.Hour),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	client.TorrentsInfo()
+	if _, err := client.TorrentsInfo(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if err := client.PingCtx(context.Background()); err == nil {
+		t.Fatalf("expected error while the server is down, got none")
+	}
+
+	down.Store(false)
+	if err := client.PingCtx(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.TorrentsInfo(); err != nil {
+		t.Errorf("expected the ping to close the breaker, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_CanceledRequestsDontCount(t *testing.T) {
+	breakers := newCircuitBreakers(1, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	breakers.record(ctx, "/api/v2/torrents/info", nil, context.Canceled)
+	if err := breakers.allow(context.Background(), "/api/v2/torrents/info"); err != nil {
+		t.Errorf("expected a closed breaker, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CleanupOptions configures CleanupTagsCtx and CleanupCategoriesCtx
+type CleanupOptions struct {
+	Exclude []string `json:"exclude,omitempty"` // names never deleted
+	DryRun  bool     `json:"dry_run,omitempty"` // only report what would be deleted
+}
+
+// CleanupTagsCtx deletes the tags that no torrent has and returns them, sorted
+func (c *Client) CleanupTagsCtx(ctx context.Context, opts CleanupOptions) ([]string, error) {
+	data, err := c.SyncMainDataCtx(ctx, 0)
+	if err != nil {
+		return nil, fmt.Errorf("CleanupTags error: %w", err)
+	}
+
+	used := make(map[string]bool)
+	for _, torrent := range data.Torrents {
+		for _, tag := range torrent.Tags {
+			used[tag] = true
+		}
+	}
+	unused := unusedNames(data.Tags, used, opts.Exclude)
+	if len(unused) == 0 || opts.DryRun {
+		return unused, nil
+	}
+	if err := c.TorrentsDeleteTagsCtx(ctx, strings.Join(unused, ",")); err != nil {
+		return nil, err
+	}
+	return unused, nil
+}
+
+// CleanupCategoriesCtx deletes the categories that have no torrents and
+// returns them, sorted. A category with a populated subcategory, such as "tv"
+// for torrents in "tv/kids", is not empty.
+func (c *Client) CleanupCategoriesCtx(ctx context.Context, opts CleanupOptions) ([]string, error) {
+	data, err := c.SyncMainDataCtx(ctx, 0)
+	if err != nil {
+		return nil, fmt.Errorf("CleanupCategories error: %w", err)
+	}
+
+	used := make(map[string]bool)
+	for _, torrent := range data.Torrents {
+		if torrent.Category == "" {
+			continue
+		}
+		for _, category := range categoryParents(torrent.Category) {
+			used[category] = true
+		}
+	}
+	names := make([]string, 0, len(data.Categories))
+	for name := range data.Categories {
+		names = append(names, name)
+	}
+	unused := unusedNames(names, used, opts.Exclude)
+	if len(unused) == 0 || opts.DryRun {
+		return unused, nil
+	}
+	if err := c.TorrentsRemoveCategoriesCtx(ctx, unused); err != nil {
+		return nil, err
+	}
+	return unused, nil
+}
+
+// unusedNames returns the sorted names that are neither used nor excluded
+func unusedNames(names []string, used map[string]bool, exclude []string) []string {
+	excluded := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		excluded[name] = true
+	}
+	var unused []string
+	for _, name := range names {
+		if !used[name] && !excluded[name] {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
@@ -0,0 +1,64 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newCleanupServer(t *testing.T, posts *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/sync/maindata" {
+			w.Write([]byte(`{"rid":1,"full_update":true,
+				"torrents":{"a":{"tags":"keep, used","category":"tv/kids"},"b":{"tags":"","category":"movies"}},
+				"tags":["keep","used","stale","old","pinned"],
+				"categories":{"tv":{},"tv/kids":{},"tv/news":{},"movies":{},"music":{},"archive":{}}}`))
+			return
+		}
+		r.ParseForm()
+		*posts = append(*posts, r.URL.Path+" "+r.PostForm.Encode())
+	}))
+}
+
+func TestCleanupTags(t *testing.T) {
+	var posts []string
+	mockServer := newCleanupServer(t, &posts)
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	deleted, err := client.CleanupTagsCtx(context.Background(), CleanupOptions{Exclude: []string{"pinned"}, DryRun: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(deleted, ",") != "old,stale" || len(posts) != 0 {
+		t.Errorf("expected old and stale to be reported without deleting, got %v and %v", deleted, posts)
+	}
+
+	if _, err := client.CleanupTagsCtx(context.Background(), CleanupOptions{Exclude: []string{"pinned"}}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(posts) != 1 || posts[0] != "/api/v2/torrents/deleteTags tags=old%2Cstale" {
+		t.Errorf("expected old and stale to be deleted, got %v", posts)
+	}
+}
+
+func TestCleanupCategories(t *testing.T) {
+	var posts []string
+	mockServer := newCleanupServer(t, &posts)
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	deleted, err := client.CleanupCategoriesCtx(context.Background(), CleanupOptions{Exclude: []string{"archive"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(deleted, ",") != "music,tv/news" {
+		t.Errorf("expected music and tv/news to be deleted, got %v", deleted)
+	}
+	if len(posts) != 1 || posts[0] != "/api/v2/torrents/removeCategories categories=music%0Atv%2Fnews" {
+		t.Errorf("expected a single removeCategories request, got %v", posts)
+	}
+}
@@ -1,28 +1,72 @@
 package qbittorrent
 
+//go:generate go run ./internal/gendeprecated -o deprecated.go AuthLogin TorrentsExport TorrentsAdd TorrentsDelete SetForceStart TorrentsDownload TorrentsInfo TorrentsTrackers TorrentsAddTags TorrentsRemoveTags TorrentsGetTags TorrentsGetAllTags TorrentsCreateTags TorrentsDeleteTags SyncMainData SyncTorrentPeers
+
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/cehbz/qbittorrent/metainfo"
 )
 
 type InfoHash string
 
-// Client is used to interact with the qBittorrent API
+// Client is used to interact with the qBittorrent API.
+//
+// A Client is safe for concurrent use by multiple goroutines. Its configuration
+// is fixed once the constructor returns, apart from the credentials, see
+// SetCredentials. Mutable state such as the session cookie is guarded by mu,
+// and re-authentication is serialized by authMu so that concurrent requests
+// rejected with the same session trigger a single login.
 type Client struct {
-	username string
-	password string
+	username string // guarded by mu
+	password string // guarded by mu
 	client   *http.Client
 	baseURL  string
-	sid      string // store the SID cookie
-	mu       sync.RWMutex
+	sid      string       // store the SID cookie, guarded by mu
+	mu       sync.RWMutex // guards mutable client state
+	authMu   sync.Mutex   // serializes re-authentication
+
+	credentials      CredentialsProvider // supplies the login credentials, if set
+	basicAuth        *basicAuth          // credentials for a reverse proxy, if any
+	userAgent        string              // empty for DefaultUserAgent
+	bypassAuth       bool                // never log in, the server doesn't require it
+	customBaseURL    bool                // baseURL was given by WithBaseURL
+	transport        *transportOptions   // only used while constructing the client
+	clock            Clock               // source of time, see WithClock
+	timeout          time.Duration       // default per-request timeout, zero for none
+	maxResponseSize  int64               // limit of response bodies, zero for none
+	syncTimeout      *time.Duration      // timeout of sync requests, nil for the default
+	dryRun           bool                // skip destructive requests, see WithDryRun
+	readOnly         bool                // refuse mutating requests, see WithReadOnly
+	allowAllTorrents bool                // allow destructive calls on AllTorrents
+	strictDecoding   bool                // reject unknown response fields
+	noRedaction      bool                // keep secrets in errors and logs, see WithoutRedaction
+	drift            *schemaDrift        // collects unknown response fields, see WithSchemaDriftReporting
+	cache            *responseCache      // cached read responses, see WithCache
+	validators       *validatorStore     // validators of GET responses, see WithConditionalRequests
+	breakers         *circuitBreakers    // per-endpoint circuit breakers, see WithCircuitBreaker
+	queue            *requestQueue       // limits requests in flight by priority, see WithRequestQueue
+	dump             *debugDump          // dumps requests and responses, see WithDebugDump
+	audit            AuditSink           // receives mutating requests, see WithAuditSink
+	metrics          MetricsSink         // receives response sizes and decode times, see WithMetrics
+	reauth           *ReauthPolicy       // nil for DefaultReauthPolicy
+	reauths          []time.Time         // logins within the policy window, guarded by authMu
+	loginAt          time.Time           // when sid was issued, guarded by mu
+	logger           *slog.Logger
 }
 
 // TorrentInfo represents the structured information of a torrent from the qBittorrent API
@@ -39,6 +83,7 @@ type TorrentInfo struct {
 	DLSpeed            int64    `json:"dlspeed"`
 	Downloaded         int64    `json:"downloaded"`
 	DownloadedSession  int64    `json:"downloaded_session"`
+	DownloadPath       string   `json:"download_path"` // of incomplete torrents, if enabled
 	ETA                int64    `json:"eta"`
 	FirstLastPiecePrio bool     `json:"f_l_piece_prio"`
 	ForceStart         bool     `json:"force_start"`
@@ -79,7 +124,7 @@ type TorrentInfo struct {
 func (t *TorrentInfo) UnmarshalJSON(data []byte) error {
 	type Alias TorrentInfo
 	aux := &struct {
-		RawTags string `json:"tags"`
+		RawTags *string `json:"tags"`
 		*Alias
 	}{
 		Alias: (*Alias)(t),
@@ -87,21 +132,89 @@ func (t *TorrentInfo) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &aux); err != nil {
 		return err
 	}
-	if aux.RawTags == "" {
+	// Like the other fields, keep the tags if absent, e.g. in partial sync updates
+	if aux.RawTags != nil {
+		t.Tags = splitTags(*aux.RawTags)
+	} else if t.Tags == nil {
 		t.Tags = []string{}
-	} else {
-		t.Tags = strings.Split(aux.RawTags, ",")
 	}
 	return nil
 }
 
+// MarshalJSON encodes the torrent like the API does, with the tags as a
+// comma-separated list, so that it decodes to the same TorrentInfo
+func (t TorrentInfo) MarshalJSON() ([]byte, error) {
+	type Alias TorrentInfo
+	return json.Marshal(struct {
+		Tags string `json:"tags"`
+		Alias
+	}{
+		Tags:  strings.Join(t.Tags, ", "),
+		Alias: Alias(t),
+	})
+}
+
+// splitTags splits a tag list as returned by the API, e.g. "tag1, tag2"
+func splitTags(rawTags string) []string {
+	if rawTags == "" {
+		return []string{}
+	}
+	tags := strings.Split(rawTags, ",")
+	for i, tag := range tags {
+		tags[i] = strings.TrimSpace(tag)
+	}
+	return tags
+}
+
 // TrackerInfo represents a tracker info for a torrent
 type TrackerInfo struct {
-	URL      string `json:"url"`
-	Status   int    `json:"status"`
-	Tier     int    `json:"tier"`
-	NumPeers int    `json:"num_peers"`
-	Msg      string `json:"msg"`
+	URL      string        `json:"url"`
+	Status   TrackerStatus `json:"status"`
+	Tier     int           `json:"tier"`
+	NumPeers int           `json:"num_peers"`
+	Msg      string        `json:"msg"`
+}
+
+// TorrentsProperties represents the generic properties of a torrent
+type TorrentsProperties struct {
+	AdditionDate           int64   `json:"addition_date"`
+	Comment                string  `json:"comment"`
+	CompletionDate         int64   `json:"completion_date"`
+	CreatedBy              string  `json:"created_by"`
+	CreationDate           int64   `json:"creation_date"`
+	DLLimit                int64   `json:"dl_limit"`
+	DLSpeed                int64   `json:"dl_speed"`
+	DLSpeedAvg             int64   `json:"dl_speed_avg"`
+	DownloadPath           string  `json:"download_path"`
+	ETA                    int64   `json:"eta"`
+	Hash                   string  `json:"hash"`
+	InfohashV1             string  `json:"infohash_v1"`
+	InfohashV2             string  `json:"infohash_v2"`
+	LastSeen               int64   `json:"last_seen"`
+	Name                   string  `json:"name"`
+	NbConnections          int     `json:"nb_connections"`
+	NbConnectionsLimit     int     `json:"nb_connections_limit"`
+	Peers                  int     `json:"peers"`
+	PeersTotal             int     `json:"peers_total"`
+	PieceSize              int64   `json:"piece_size"`
+	PiecesHave             int     `json:"pieces_have"`
+	PiecesNum              int     `json:"pieces_num"`
+	Reannounce             int64   `json:"reannounce"`
+	SavePath               string  `json:"save_path"`
+	SeedingTime            int64   `json:"seeding_time"`
+	Seeds                  int     `json:"seeds"`
+	SeedsTotal             int     `json:"seeds_total"`
+	ShareRatio             float64 `json:"share_ratio"`
+	TimeElapsed            int64   `json:"time_elapsed"`
+	TotalDownloaded        int64   `json:"total_downloaded"`
+	TotalDownloadedSession int64   `json:"total_downloaded_session"`
+	TotalSize              int64   `json:"total_size"`
+	TotalUploaded          int64   `json:"total_uploaded"`
+	TotalUploadedSession   int64   `json:"total_uploaded_session"`
+	TotalWasted            int64   `json:"total_wasted"`
+	UpLimit                int64   `json:"up_limit"`
+	UpSpeed                int64   `json:"up_speed"`
+	UpSpeedAvg             int64   `json:"up_speed_avg"`
 }
 
 type Category map[string]interface{} // no idea what this should be, category=CategoryName&savePath=/path/to/dir
@@ -111,7 +224,7 @@ type Category map[string]interface{} // no idea what this should be, category=Ca
 // MainData is the data returned by the /api/v2/sync/maindata endpoint
 type MainData struct {
 	Categories        map[string]Category    `json:"categories"`
-	CategoriesRemoved []Category             `json:"categories_removed"`
+	CategoriesRemoved []string               `json:"categories_removed"`
 	FullUpdate        bool                   `json:"full_update"`
 	Rid               int                    `json:"rid"`
 	ServerState       ServerState            `json:"server_state"`
@@ -120,34 +233,35 @@ type MainData struct {
 	Torrents          map[string]TorrentInfo `json:"torrents"`
 	TorrentsRemoved   []string               `json:"torrents_removed"`
 	Trackers          map[string][]InfoHash  `json:"trackers"` // maps trackers to infohashes
+	TrackersRemoved   []string               `json:"trackers_removed"`
 }
 
 type ServerState struct {
-	AllTimeDL            int64  `json:"alltime_dl"`
-	AllTimeUL            int64  `json:"alltime_ul"`
-	AverageTimeQueue     int    `json:"average_time_queue"`
-	ConnectionStatus     string `json:"connection_status"`
-	DHTNodes             int    `json:"dht_nodes"`
-	DLInfoData           int64  `json:"dl_info_data"`
-	DLInfoSpeed          int    `json:"dl_info_speed"`
-	DLRateLimit          int    `json:"dl_rate_limit"`
-	FreeSpaceOnDisk      int64  `json:"free_space_on_disk"`
-	GlobalRatio          string `json:"global_ratio"`
-	QueuedIOJobs         int    `json:"queued_io_jobs"`
-	Queueing             bool   `json:"queueing"`
-	ReadCacheHits        string `json:"read_cache_hits"`
-	ReadCacheOverload    string `json:"read_cache_overload"`
-	RefreshInterval      int    `json:"refresh_interval"`
-	TotalBuffersSize     int64  `json:"total_buffers_size"`
-	TotalPeerConnections int    `json:"total_peer_connections"`
-	TotalQueuedSize      int64  `json:"total_queued_size"`
-	TotalWastedSession   int64  `json:"total_wasted_session"`
-	UpInfoData           int64  `json:"up_info_data"`
-	UpInfoSpeed          int    `json:"up_info_speed"`
-	UpRateLimit          int    `json:"up_rate_limit"`
-	UseAltSpeedLimits    bool   `json:"use_alt_speed_limits"`
-	UseSubcategories     bool   `json:"use_subcategories"`
-	WriteCacheOverload   string `json:"write_cache_overload"`
+	AllTimeDL            int64            `json:"alltime_dl"`
+	AllTimeUL            int64            `json:"alltime_ul"`
+	AverageTimeQueue     int              `json:"average_time_queue"`
+	ConnectionStatus     ConnectionStatus `json:"connection_status"`
+	DHTNodes             int              `json:"dht_nodes"`
+	DLInfoData           int64            `json:"dl_info_data"`
+	DLInfoSpeed          int              `json:"dl_info_speed"`
+	DLRateLimit          int              `json:"dl_rate_limit"`
+	FreeSpaceOnDisk      int64            `json:"free_space_on_disk"`
+	GlobalRatio          string           `json:"global_ratio"`
+	QueuedIOJobs         int              `json:"queued_io_jobs"`
+	Queueing             bool             `json:"queueing"`
+	ReadCacheHits        string           `json:"read_cache_hits"`
+	ReadCacheOverload    string           `json:"read_cache_overload"`
+	RefreshInterval      int              `json:"refresh_interval"`
+	TotalBuffersSize     int64            `json:"total_buffers_size"`
+	TotalPeerConnections int              `json:"total_peer_connections"`
+	TotalQueuedSize      int64            `json:"total_queued_size"`
+	TotalWastedSession   int64            `json:"total_wasted_session"`
+	UpInfoData           int64            `json:"up_info_data"`
+	UpInfoSpeed          int              `json:"up_info_speed"`
+	UpRateLimit          int              `json:"up_rate_limit"`
+	UseAltSpeedLimits    bool             `json:"use_alt_speed_limits"`
+	UseSubcategories     bool             `json:"use_subcategories"`
+	WriteCacheOverload   string           `json:"write_cache_overload"`
 }
 
 type TorrentPeer struct {
@@ -170,60 +284,103 @@ type TorrentPeer struct {
 }
 
 type TorrentPeers struct {
-	FullUpdate bool                   `json:"full_update"`
-	Peers      map[string]TorrentPeer `json:"peers"`
-	// PeersRemoved map[string][]string    `json:"peers_removed"`
-	Rid       int  `json:"rid"`
-	ShowFlags bool `json:"show_flags"`
+	FullUpdate   bool                   `json:"full_update"`
+	Peers        map[string]TorrentPeer `json:"peers"`         // keyed by "ip:port"
+	PeersRemoved []string               `json:"peers_removed"` // keys of peers that disconnected
+	Rid          int                    `json:"rid"`
+	ShowFlags    bool                   `json:"show_flags"`
 }
 
 // NewClient initializes a new qBittorrent client.
 // If httpClient is nil, http.DefaultClient is used.
 func NewClient(username, password, addr, port string, httpClient ...*http.Client) (*Client, error) {
-	// Use the provided http.Client if given, otherwise use http.DefaultClient
-	client := http.DefaultClient
-	if len(httpClient) > 0 && httpClient[0] != nil {
-		client = httpClient[0]
+	var opts []Option
+	if len(httpClient) > 0 {
+		opts = append(opts, WithHTTPClient(httpClient[0]))
 	}
+	return NewClientWithOptions(username, password, addr, port, opts...)
+}
 
-	// Create and return the Client instance
+// NewClientWithOptions initializes a new qBittorrent client configured by opts.
+func NewClientWithOptions(username, password, addr, port string, opts ...Option) (*Client, error) {
 	qbClient := &Client{
-		username: username,
-		password: password,
-		client:   client,
-		baseURL:  fmt.Sprintf("http://%s:%s", addr, port),
+		username:        username,
+		password:        password,
+		baseURL:         fmt.Sprintf("http://%s:%s", addr, port),
+		timeout:         DefaultTimeout,
+		maxResponseSize: DefaultMaxResponseSize,
+	}
+	for _, opt := range opts {
+		if err := opt(qbClient); err != nil {
+			return nil, err
+		}
+	}
+
+	// Components created by options use the clock of the client
+	if qbClient.cache != nil {
+		qbClient.cache.now = qbClient.Clock().Now
+	}
+	if qbClient.breakers != nil {
+		qbClient.breakers.now = qbClient.Clock().Now
+	}
+	if qbClient.dump != nil {
+		qbClient.dump.now = qbClient.Clock().Now
 	}
 
-	// Authenticate if username and password are provided
-	if username != "" && password != "" {
-		if err := qbClient.AuthLogin(); err != nil {
-			return nil, fmt.Errorf("AuthLogin error: %v", err)
+	// Build a transport for TLS and similar options
+	if qbClient.transport != nil {
+		httpClient, err := qbClient.buildHTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		qbClient.client = httpClient
+		qbClient.transport = nil
+	}
+
+	// Use the provided http.Client if given, otherwise use http.DefaultClient
+	if qbClient.client == nil {
+		qbClient.client = http.DefaultClient
+	}
+
+	// Authenticate if username and password or a provider are given
+	if (username != "" && password != "" || qbClient.credentials != nil) && !qbClient.bypassAuth {
+		if err := qbClient.AuthLoginCtx(context.Background()); err != nil {
+			return nil, fmt.Errorf("AuthLogin error: %w", err)
 		}
 	}
 
 	return qbClient, nil
 }
 
-// AuthLogin logs in to the qBittorrent Web API
-func (c *Client) AuthLogin() error {
+// authLoginEndpoint is never retried on 403, which means the login was refused
+const authLoginEndpoint = "/api/v2/auth/login"
+
+// AuthLoginCtx logs in to the qBittorrent Web API
+func (c *Client) AuthLoginCtx(ctx context.Context) error {
+	username, password, err := c.loginCredentials(ctx)
+	if err != nil {
+		return fmt.Errorf("AuthLogin error: %w", err)
+	}
 	data := url.Values{}
-	data.Set("username", c.username)
-	data.Set("password", c.password)
+	data.Set("username", username)
+	data.Set("password", password)
 
-	resp, err := c.doPostResponse("/api/v2/auth/login", strings.NewReader(data.Encode()), "application/x-www-form-urlencoded")
+	resp, err := c.doPostResponseCtx(ctx, authLoginEndpoint, strings.NewReader(data.Encode()), "application/x-www-form-urlencoded")
 	if err != nil {
-		return fmt.Errorf("AuthLogin error: %v", err)
-	} else if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("AuthLogin error (%d): %s", resp.StatusCode, string(respBody))
+		return fmt.Errorf("AuthLogin error: %w", err)
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := c.readBody(authLoginEndpoint, resp)
+		return fmt.Errorf("AuthLogin error: %w", c.newAPIError("POST", authLoginEndpoint, resp.StatusCode, respBody))
+	}
 
 	// Extract the SID cookie from the response
 	for _, cookie := range resp.Cookies() {
 		if cookie.Name == "SID" {
 			c.mu.Lock()
 			c.sid = cookie.Value
+			c.loginAt = c.Clock().Now()
 			c.mu.Unlock()
 			break
 		}
@@ -232,69 +389,265 @@ func (c *Client) AuthLogin() error {
 	return nil
 }
 
-// TorrentsExport retrieves the .torrent file for a given torrent hash
-func (c *Client) TorrentsExport(hash string) ([]byte, error) {
+// session returns the current SID cookie value
+func (c *Client) session() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sid
+}
+
+// TorrentsExportCtx retrieves the .torrent file for a given torrent hash
+func (c *Client) TorrentsExportCtx(ctx context.Context, hash string) ([]byte, error) {
 	params := url.Values{}
 	params.Set("hash", hash)
 
 	// Use the GET request helper
-	return c.doPostValues("/api/v2/torrents/export", params)
+	return c.doPostValuesCtx(ctx, "/api/v2/torrents/export", params)
 }
 
-// TorrentsAdd adds a torrent to qBittorrent via Web API using multipart/form-data
-func (c *Client) TorrentsAdd(torrentFile string, fileData []byte) error {
-	var body bytes.Buffer
-	writer := multipart.NewWriter(&body)
+// TorrentsAddParams holds optional settings for TorrentsAddCtx and TorrentsAddURLsCtx
+type TorrentsAddParams struct {
+	SavePath string
+	Category string
+	Tags     []string
+	Paused   bool     // add the torrent without starting it
+	Trackers []string // appended to the trackers of the torrent, e.g. backup trackers
+	Rename   string   // name of the torrent instead of the one in its metadata
+	// SkipChecking skips the hash check of existing data. Unset, it defaults
+	// to true for TorrentsAddCtx and to the server's default for URLs.
+	SkipChecking       *bool
+	Sequential         bool // download the pieces in order
+	FirstLastPiecePrio bool // download the first and last pieces of each file first
+	Forced             bool // force start, ignoring the queue
+	AddToTopOfQueue    bool
+	// RewriteAnnounce, if set, rewrites the tracker URLs in the .torrent file
+	// before it is uploaded, e.g. metainfo.ReplacePasskey to substitute a passkey.
+	// AddQueue.EnqueueFile applies it before queueing the file.
+	RewriteAnnounce func(announce string) string `json:"-"`
+}
+
+// TorrentsAddCtx adds a torrent to qBittorrent via Web API using multipart/form-data
+func (c *Client) TorrentsAddCtx(ctx context.Context, torrentFile string, fileData []byte, params ...*TorrentsAddParams) error {
+	var p TorrentsAddParams
+	if len(params) > 0 && params[0] != nil {
+		p = *params[0]
+	}
+	if p.RewriteAnnounce != nil {
+		m, err := metainfo.Parse(fileData)
+		if err != nil {
+			return fmt.Errorf("TorrentsAdd error: %w", err)
+		}
+		m.RewriteAnnounce(p.RewriteAnnounce)
+		if fileData, err = m.Bytes(); err != nil {
+			return fmt.Errorf("TorrentsAdd error: %w", err)
+		}
+	}
+
+	if err := p.validate(1); err != nil {
+		return fmt.Errorf("TorrentsAdd error: %w", err)
+	}
+	if p.SkipChecking == nil {
+		skip := true // Avoid recheck
+		p.SkipChecking = &skip
+	}
+
+	body := getBuffer()
+	defer putBuffer(body)
+	writer := multipart.NewWriter(body)
 
 	part, err := writer.CreateFormFile("torrents", torrentFile)
 	if err != nil {
 		return fmt.Errorf("CreateFormFile error: %v", err)
 	}
-	if _, err := io.Copy(part, bytes.NewReader(fileData)); err != nil {
-		return fmt.Errorf("io.Copy error: %v", err)
+	if _, err := part.Write(fileData); err != nil {
+		return fmt.Errorf("Write error: %v", err)
 	}
 
-	_ = writer.WriteField("skip_checking", "true") // Avoid recheck
-	_ = writer.WriteField("paused", "false")
-	_ = writer.WriteField("autoTMM", "false")
+	writeAddFields(writer, p)
+	writer.Close()
+
+	_, err = c.doPostCtx(ctx, "/api/v2/torrents/add", body, writer.FormDataContentType())
+	if err != nil {
+		return fmt.Errorf("TorrentsAdd error: %w", err)
+	}
+	return nil
+}
+
+// TorrentsAddURLsCtx adds torrents by URL or magnet link. RewriteAnnounce
+// doesn't apply, the server fetches the torrents itself.
+func (c *Client) TorrentsAddURLsCtx(ctx context.Context, urls []string, params ...*TorrentsAddParams) error {
+	var p TorrentsAddParams
+	if len(params) > 0 && params[0] != nil {
+		p = *params[0]
+	}
+	if err := p.validate(len(urls)); err != nil {
+		return fmt.Errorf("TorrentsAddURLs error: %w", err)
+	}
+
+	body := getBuffer()
+	defer putBuffer(body)
+	writer := multipart.NewWriter(body)
+	_ = writer.WriteField("urls", strings.Join(urls, "\n"))
+	writeAddFields(writer, p)
 	writer.Close()
 
-	_, err = c.doPost("/api/v2/torrents/add", &body, writer.FormDataContentType())
+	_, err := c.doPostCtx(ctx, "/api/v2/torrents/add", body, writer.FormDataContentType())
 	if err != nil {
-		return fmt.Errorf("TorrentsAdd error: %v", err)
+		return fmt.Errorf("TorrentsAddURLs error: %w", err)
 	}
 	return nil
 }
 
-// TorrentsDelete deletes a torrent from qBittorrent by its hash
-func (c *Client) TorrentsDelete(infohash string) error {
+// writeAddFields writes the form fields shared by all ways of adding torrents
+func writeAddFields(writer *multipart.Writer, p TorrentsAddParams) {
+	// qBittorrent 5 renamed paused to stopped
+	_ = writer.WriteField("paused", strconv.FormatBool(p.Paused))
+	_ = writer.WriteField("stopped", strconv.FormatBool(p.Paused))
+	_ = writer.WriteField("autoTMM", "false")
+	if p.SavePath != "" {
+		_ = writer.WriteField("savepath", p.SavePath)
+	}
+	if p.Category != "" {
+		_ = writer.WriteField("category", p.Category)
+	}
+	if len(p.Tags) > 0 {
+		_ = writer.WriteField("tags", strings.Join(p.Tags, ","))
+	}
+	if len(p.Trackers) > 0 {
+		_ = writer.WriteField("addTrackers", strings.Join(p.Trackers, "\n"))
+	}
+	if p.Rename != "" {
+		_ = writer.WriteField("rename", p.Rename)
+	}
+	if p.SkipChecking != nil {
+		_ = writer.WriteField("skip_checking", strconv.FormatBool(*p.SkipChecking))
+	}
+	if p.Sequential {
+		_ = writer.WriteField("sequentialDownload", "true")
+	}
+	if p.FirstLastPiecePrio {
+		_ = writer.WriteField("firstLastPiecePrio", "true")
+	}
+	if p.Forced {
+		// qBittorrent 5 renamed forced to forceStart
+		_ = writer.WriteField("forced", "true")
+		_ = writer.WriteField("forceStart", "true")
+	}
+	if p.AddToTopOfQueue {
+		_ = writer.WriteField("addToTopOfQueue", "true")
+	}
+}
+
+// validate checks the params for adding count torrents
+func (p TorrentsAddParams) validate(count int) error {
+	if p.Rename != "" && count > 1 {
+		return errors.New("can't rename several torrents to the same name")
+	}
+	return nil
+}
+
+// TorrentsDeleteCtx deletes a torrent from qBittorrent by its hash
+func (c *Client) TorrentsDeleteCtx(ctx context.Context, infohash string) error {
 	data := url.Values{}
 	data.Set("hashes", infohash)
 	data.Set("deleteFiles", "true")
 
-	_, err := c.doPostValues("/api/v2/torrents/delete", data)
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/torrents/delete", data)
 	if err != nil {
-		return fmt.Errorf("TorrentsDelete error: %v", err)
+		return fmt.Errorf("TorrentsDelete error: %w", err)
 	}
 	return nil
 }
 
-// SetForceStart enables force start for the torrent
-func (c *Client) SetForceStart(hash string, value bool) error {
+// SetForceStartCtx enables force start for the torrent
+func (c *Client) SetForceStartCtx(ctx context.Context, hash string, value bool) error {
 	data := url.Values{}
 	data.Set("hashes", hash)
 	data.Set("value", fmt.Sprintf("%t", value))
 
-	_, err := c.doPostValues("/api/v2/torrents/setForceStart", data)
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/torrents/setForceStart", data)
 	if err != nil {
-		return fmt.Errorf("SetForceStart error: %v", err)
+		return fmt.Errorf("SetForceStart error: %w", err)
+	}
+	return nil
+}
+
+// SetForceStartMapCtx sets the force start state of each torrent to its
+// value, with one request for the torrents to force start and one for the
+// rest, so reconciliation loops can apply a desired state in one call
+func (c *Client) SetForceStartMapCtx(ctx context.Context, values map[InfoHash]bool) error {
+	var enable, disable []string
+	for hash, value := range values {
+		if value {
+			enable = append(enable, string(hash))
+		} else {
+			disable = append(disable, string(hash))
+		}
+	}
+	for _, group := range []struct {
+		hashes []string
+		value  bool
+	}{{enable, true}, {disable, false}} {
+		if len(group.hashes) == 0 {
+			continue
+		}
+		sort.Strings(group.hashes)
+		if err := c.SetForceStartCtx(ctx, strings.Join(group.hashes, "|"), group.value); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-// TorrentsDownload retrieves the torrent file by its hash from the qBittorrent server
-func (c *Client) TorrentsDownload(infohash string) ([]byte, error) {
-	return c.doGet("/api/v2/torrents/file", url.Values{"hashes": {infohash}})
+// TorrentsSetLocationCtx moves the data of the specified torrents to location
+func (c *Client) TorrentsSetLocationCtx(ctx context.Context, hashes, location string) error {
+	data := url.Values{}
+	data.Set("hashes", hashes)
+	data.Set("location", location)
+
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/torrents/setLocation", data)
+	if err != nil {
+		return fmt.Errorf("SetLocation error: %w", err)
+	}
+	return nil
+}
+
+// TorrentsRemoveCategoriesCtx deletes the given categories from qBittorrent
+func (c *Client) TorrentsRemoveCategoriesCtx(ctx context.Context, categories []string) error {
+	data := url.Values{}
+	data.Set("categories", strings.Join(categories, "\n"))
+
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/torrents/removeCategories", data)
+	if err != nil {
+		return fmt.Errorf("RemoveCategories error: %w", err)
+	}
+	return nil
+}
+
+// AppShutdownCtx shuts down the qBittorrent application
+func (c *Client) AppShutdownCtx(ctx context.Context) error {
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/app/shutdown", url.Values{})
+	if err != nil {
+		return fmt.Errorf("Shutdown error: %w", err)
+	}
+	return nil
+}
+
+// TransferBanPeersCtx bans peers given as "host:port" for the rest of the session
+func (c *Client) TransferBanPeersCtx(ctx context.Context, peers []string) error {
+	data := url.Values{}
+	data.Set("peers", strings.Join(peers, "|"))
+
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/transfer/banPeers", data)
+	if err != nil {
+		return fmt.Errorf("TransferBanPeers error: %w", err)
+	}
+	return nil
+}
+
+// TorrentsDownloadCtx retrieves the torrent file by its hash from the qBittorrent server
+func (c *Client) TorrentsDownloadCtx(ctx context.Context, infohash string) ([]byte, error) {
+	return c.doGetCtx(ctx, "/api/v2/torrents/file", url.Values{"hashes": {infohash}})
 }
 
 // TorrentsInfoParams holds the optional parameters for the TorrentsInfo method
@@ -309,8 +662,8 @@ type TorrentsInfoParams struct {
 	Hashes   []string
 }
 
-// TorrentsInfo retrieves a list of all torrents from the qBittorrent server
-func (c *Client) TorrentsInfo(params ...*TorrentsInfoParams) ([]TorrentInfo, error) {
+// TorrentsInfoCtx retrieves a list of all torrents from the qBittorrent server
+func (c *Client) TorrentsInfoCtx(ctx context.Context, params ...*TorrentsInfoParams) ([]TorrentInfo, error) {
 	var query url.Values
 	if len(params) > 0 && params[0] != nil {
 		query = url.Values{}
@@ -340,72 +693,91 @@ func (c *Client) TorrentsInfo(params ...*TorrentsInfoParams) ([]TorrentInfo, err
 		}
 	}
 
-	respData, err := c.doGet("/api/v2/torrents/info", query)
+	respData, err := c.doGetCtx(ctx, "/api/v2/torrents/info", query)
 	if err != nil {
 		return nil, err
 	}
 
 	var torrents []TorrentInfo
-	if err := json.Unmarshal(respData, &torrents); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
+	if err := c.decodeJSON("/api/v2/torrents/info", respData, &torrents); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return torrents, nil
 }
 
-// TorrentsTrackers retrieves the tracker info for a given torrent hash
-func (c *Client) TorrentsTrackers(hash string) ([]TrackerInfo, error) {
+// TorrentsTrackersCtx retrieves the tracker info for a given torrent hash
+func (c *Client) TorrentsTrackersCtx(ctx context.Context, hash string) ([]TrackerInfo, error) {
 	params := url.Values{}
 	params.Set("hash", hash)
 
-	respData, err := c.doGet("/api/v2/torrents/trackers", params)
+	respData, err := c.doGetCtx(ctx, "/api/v2/torrents/trackers", params)
 	if err != nil {
-		return nil, fmt.Errorf("TorrentsTrackers error: %v", err)
+		return nil, fmt.Errorf("TorrentsTrackers error: %w", err)
 	}
 
 	var trackers []TrackerInfo
-	if err := json.Unmarshal(respData, &trackers); err != nil {
-		return nil, fmt.Errorf("failed to decode trackers response: %v", err)
+	if err := c.decodeJSON("/api/v2/torrents/trackers", respData, &trackers); err != nil {
+		return nil, fmt.Errorf("failed to decode trackers response: %w", err)
 	}
 
 	return trackers, nil
 }
 
-// TorrentsAddTags adds tags to the specified torrents
-func (c *Client) TorrentsAddTags(hashes, tags string) error {
+// TorrentsPropertiesCtx retrieves the generic properties of a torrent
+func (c *Client) TorrentsPropertiesCtx(ctx context.Context, hash string) (*TorrentsProperties, error) {
+	params := url.Values{}
+	params.Set("hash", hash)
+
+	respData, err := c.doGetCtx(ctx, "/api/v2/torrents/properties", params)
+	if err != nil {
+		return nil, fmt.Errorf("TorrentsProperties error: %w", err)
+	}
+
+	var properties TorrentsProperties
+	if err := c.decodeJSON("/api/v2/torrents/properties", respData, &properties); err != nil {
+		return nil, fmt.Errorf("failed to decode properties response: %w", err)
+	}
+
+	return &properties, nil
+}
+
+// TorrentsAddTagsCtx adds tags to the specified torrents
+func (c *Client) TorrentsAddTagsCtx(ctx context.Context, hashes, tags string) error {
 	data := url.Values{}
 	data.Set("hashes", hashes)
 	data.Set("tags", tags)
 
-	_, err := c.doPostValues("/api/v2/torrents/addTags", data)
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/torrents/addTags", data)
 	if err != nil {
-		return fmt.Errorf("AddTags error: %v", err)
+		return fmt.Errorf("AddTags error: %w", err)
 	}
 	return nil
 }
 
-// TorrentsRemoveTags removes tags from the specified torrents
-func (c *Client) TorrentsRemoveTags(hashes, tags string) error {
+// TorrentsRemoveTagsCtx removes tags from the specified torrents
+func (c *Client) TorrentsRemoveTagsCtx(ctx context.Context, hashes, tags string) error {
 	data := url.Values{}
 	data.Set("hashes", hashes)
 	data.Set("tags", tags)
 
-	_, err := c.doPostValues("/api/v2/torrents/removeTags", data)
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/torrents/removeTags", data)
 	if err != nil {
-		return fmt.Errorf("RemoveTags error: %v", err)
+		return fmt.Errorf("RemoveTags error: %w", err)
 	}
 	return nil
 }
 
-// TorrentsGetTags retrieves the tags for the given torrent hashes
-func (c *Client) TorrentsGetTags(hashes string) ([]string, error) {
+// TorrentsGetTagsCtx retrieves the tags for the given torrent hashes, merged
+// into one set. Use TorrentsTagsByHashCtx for the tags of each torrent.
+func (c *Client) TorrentsGetTagsCtx(ctx context.Context, hashes string) ([]string, error) {
 	params := &TorrentsInfoParams{
 		Hashes: []string{hashes},
 	}
 
-	torrents, err := c.TorrentsInfo(params)
+	torrents, err := c.TorrentsInfoCtx(ctx, params)
 	if err != nil {
-		return nil, fmt.Errorf("TorrentsGetTags error: %v", err)
+		return nil, fmt.Errorf("TorrentsGetTags error: %w", err)
 	}
 
 	tagSet := make(map[string]struct{})
@@ -423,93 +795,178 @@ func (c *Client) TorrentsGetTags(hashes string) ([]string, error) {
 	return tags, nil
 }
 
-// TorrentsGetAllTags retrieves all tags from qBittorrent
-func (c *Client) TorrentsGetAllTags() ([]string, error) {
-	respData, err := c.doGet("/api/v2/torrents/tags", nil)
+// TorrentsGetAllTagsCtx retrieves all tags from qBittorrent
+func (c *Client) TorrentsGetAllTagsCtx(ctx context.Context) ([]string, error) {
+	respData, err := c.doGetCtx(ctx, "/api/v2/torrents/tags", nil)
 	if err != nil {
-		return nil, fmt.Errorf("GetAllTags error: %v", err)
+		return nil, fmt.Errorf("GetAllTags error: %w", err)
 	}
 
 	var tags []string
-	if err := json.Unmarshal(respData, &tags); err != nil {
-		return nil, fmt.Errorf("failed to decode tags response: %v", err)
+	if err := c.decodeJSON("/api/v2/torrents/tags", respData, &tags); err != nil {
+		return nil, fmt.Errorf("failed to decode tags response: %w", err)
 	}
 
 	return tags, nil
 }
 
-// TorrentsCreateTags creates new tags in qBittorrent
-func (c *Client) TorrentsCreateTags(tags string) error {
+// TorrentsCreateTagsCtx creates new tags in qBittorrent
+func (c *Client) TorrentsCreateTagsCtx(ctx context.Context, tags string) error {
 	data := url.Values{}
 	data.Set("tags", tags)
 
-	_, err := c.doPostValues("/api/v2/torrents/createTags", data)
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/torrents/createTags", data)
 	if err != nil {
-		return fmt.Errorf("CreateTags error: %v", err)
+		return fmt.Errorf("CreateTags error: %w", err)
 	}
 	return nil
 }
 
-// TorrentsDeleteTags deletes tags from qBittorrent
-func (c *Client) TorrentsDeleteTags(tags string) error {
+// TorrentsDeleteTagsCtx deletes tags from qBittorrent
+func (c *Client) TorrentsDeleteTagsCtx(ctx context.Context, tags string) error {
 	data := url.Values{}
 	data.Set("tags", tags)
 
-	_, err := c.doPostValues("/api/v2/torrents/deleteTags", data)
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/torrents/deleteTags", data)
 	if err != nil {
-		return fmt.Errorf("DeleteTags error: %v", err)
+		return fmt.Errorf("DeleteTags error: %w", err)
 	}
 	return nil
 }
 
-// doPostResponse POSTs to qBittorrent and returns the HTTP response
-func (c *Client) doPostResponse(endpoint string, body io.Reader, contentType string) (*http.Response, error) {
-	return c.doRequest("POST", endpoint, body, contentType)
+// doPostResponseCtx POSTs to qBittorrent and returns the HTTP response
+func (c *Client) doPostResponseCtx(ctx context.Context, endpoint string, body io.Reader, contentType string) (*http.Response, error) {
+	return c.doRequestCtx(ctx, "POST", endpoint, body, contentType)
 }
 
-// doPost makes POSTs to qBittorrent and returns the response body
-func (c *Client) doPost(endpoint string, body io.Reader, contentType string) ([]byte, error) {
-	resp, err := c.doPostResponse(endpoint, body, contentType)
+// doPostCtx makes POSTs to qBittorrent and returns the response body
+func (c *Client) doPostCtx(ctx context.Context, endpoint string, body io.Reader, contentType string) ([]byte, error) {
+	resp, err := c.doPostResponseCtx(ctx, endpoint, body, contentType)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	respBody, err := io.ReadAll(resp.Body)
+	respBody, err := c.readBody(endpoint, resp)
 	if err != nil {
 		return nil, err
 	} else if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("POST error (%d): %s", resp.StatusCode, string(respBody))
+		return nil, c.newAPIError("POST", endpoint, resp.StatusCode, respBody)
 	}
 	return respBody, nil
 }
 
-// doPostValues POSTs to qBittorrent with url.Values and returns the response body
-func (c *Client) doPostValues(endpoint string, data url.Values) ([]byte, error) {
-	return c.doPost(endpoint, strings.NewReader(data.Encode()), "application/x-www-form-urlencoded")
+// doPostValuesCtx POSTs to qBittorrent with url.Values and returns the response body
+func (c *Client) doPostValuesCtx(ctx context.Context, endpoint string, data url.Values) ([]byte, error) {
+	return c.doPostCtx(ctx, endpoint, strings.NewReader(data.Encode()), "application/x-www-form-urlencoded")
 }
 
-// doGet is a helper method for making GET requests to the qBittorrent API with query parameters
-func (c *Client) doGet(endpoint string, query url.Values) ([]byte, error) {
-	resp, err := c.doRequest("GET", endpoint, nil, "", withQuery(query))
+// doGetCtx is a helper method for making GET requests to the qBittorrent API with query parameters
+func (c *Client) doGetCtx(ctx context.Context, endpoint string, query url.Values) ([]byte, error) {
+	if c.cache != nil {
+		if data, ok := c.cache.get(endpoint, query); ok {
+			return data, nil
+		}
+	}
+
+	opts := []func(*http.Request) error{withQuery(query)}
+	var previous validated
+	var revalidate bool
+	if c.validators != nil {
+		if previous, revalidate = c.validators.get(endpoint, query); revalidate {
+			opts = append(opts, withValidators(previous))
+		}
+	}
+
+	resp, err := c.doRequestCtx(ctx, "GET", endpoint, nil, "", opts...)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && revalidate {
+		return previous.body, nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected response code: %d, response: %s", resp.StatusCode, string(respBody))
+		respBody, _ := c.readBody(endpoint, resp)
+		return nil, c.newAPIError("GET", endpoint, resp.StatusCode, respBody)
 	}
 
-	responseData, err := io.ReadAll(resp.Body)
+	responseData, err := c.readBody(endpoint, resp)
 	if err != nil {
-		return nil, fmt.Errorf("ReadAll error: %v", err)
+		return nil, fmt.Errorf("ReadAll error: %w", err)
+	}
+	if c.cache != nil {
+		c.cache.put(endpoint, query, responseData)
+	}
+	if c.validators != nil {
+		c.validators.put(endpoint, query, resp.Header, responseData)
 	}
 	return responseData, nil
 }
 
-// doRequest is a helper function to handle HTTP requests with optional query parameters
-func (c *Client) doRequest(method, endpoint string, body io.Reader, contentType string, opts ...func(*http.Request) error) (*http.Response, error) {
+// doRequestCtx is a helper function to handle HTTP requests with optional query parameters.
+// Unless ctx already has a deadline, the client's default timeout applies until the
+// response body is closed.
+func (c *Client) doRequestCtx(ctx context.Context, method, endpoint string, body io.Reader, contentType string, opts ...func(*http.Request) error) (*http.Response, error) {
+	if err := c.checkReadOnly(endpoint); err != nil {
+		return nil, err
+	}
+	if c.dryRun && dryRunEndpoints[endpoint] {
+		return c.dryRunResponse(method, endpoint, body)
+	}
+	if err := c.breakers.allow(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	// Logins are sent on behalf of queued requests, so they don't wait for
+	// the queue themselves
+	dequeue := func() {}
+	if endpoint != authLoginEndpoint {
+		var err error
+		if dequeue, err = c.queue.acquire(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// Buffer the body so the request can be retried. The transport may read
+	// it until the response body is closed, only then is it released.
+	bodyData, release, err := bufferBody(body)
+	if err != nil {
+		dequeue()
+		return nil, fmt.Errorf("failed to read request body: %v", err)
+	}
+	if err := c.checkAllTorrents(endpoint, contentType, bodyData); err != nil {
+		release()
+		dequeue()
+		return nil, err
+	}
+	callerCtx := ctx
+	ctx, cancel := c.withDefaultTimeout(ctx, endpoint)
+	done := func() {
+		cancel()
+		release()
+		dequeue()
+	}
+
+	start := c.Clock().Now()
+	resp, err := c.doRequestWithReauth(ctx, method, endpoint, bodyData, contentType, opts...)
+	c.breakers.record(callerCtx, endpoint, resp, err)
+	c.auditRequest(start, method, endpoint, contentType, bodyData, resp, err)
+	if invalidated := cacheInvalidations[endpoint]; c.cache != nil && len(invalidated) > 0 {
+		// The request may have been applied even if it failed
+		c.cache.invalidate(invalidated...)
+	}
+	if err != nil {
+		done()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: done}
+	return resp, nil
+}
+
+// doRequestWithReauth sends the request, re-authenticating and retrying on 401 and 403
+// according to the client's ReauthPolicy
+func (c *Client) doRequestWithReauth(ctx context.Context, method, endpoint string, bodyBuffer []byte, contentType string, opts ...func(*http.Request) error) (*http.Response, error) {
 	apiURL, err := url.Parse(c.baseURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse base URL: %v", err)
@@ -517,21 +974,13 @@ func (c *Client) doRequest(method, endpoint string, body io.Reader, contentType
 
 	apiURL.Path = strings.TrimSuffix(apiURL.Path, "/") + endpoint
 
-	// Store body in buffer if it's not nil so we can retry the request
-	var bodyBuffer []byte
-	if body != nil {
-		bodyBuffer, err = io.ReadAll(body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read request body: %v", err)
-		}
-	}
-
+	var sentSID string // the session used by the latest request
 	makeRequest := func() (*http.Request, error) {
 		var bodyReader io.Reader
 		if bodyBuffer != nil {
 			bodyReader = bytes.NewReader(bodyBuffer)
 		}
-		req, err := http.NewRequest(method, apiURL.String(), bodyReader)
+		req, err := http.NewRequestWithContext(ctx, method, apiURL.String(), bodyReader)
 		if err != nil {
 			return nil, fmt.Errorf("NewRequest error: %v", err)
 		}
@@ -539,12 +988,20 @@ func (c *Client) doRequest(method, endpoint string, body io.Reader, contentType
 		if contentType != "" {
 			req.Header.Set("Content-Type", contentType)
 		}
+		if c.userAgent != "" {
+			req.Header.Set("User-Agent", c.userAgent)
+		} else {
+			req.Header.Set("User-Agent", DefaultUserAgent)
+		}
 
-		c.mu.RLock()
-		if c.sid != "" {
-			req.AddCookie(&http.Cookie{Name: "SID", Value: c.sid})
+		if c.basicAuth != nil {
+			req.SetBasicAuth(c.basicAuth.username, c.basicAuth.password)
+		}
+
+		sentSID = c.session()
+		if sentSID != "" {
+			req.AddCookie(&http.Cookie{Name: "SID", Value: sentSID})
 		}
-		c.mu.RUnlock()
 
 		// Apply any optional request modifiers
 		for _, opt := range opts {
@@ -555,23 +1012,39 @@ func (c *Client) doRequest(method, endpoint string, body io.Reader, contentType
 		return req, nil
 	}
 
+	if endpoint != authLoginEndpoint && !c.bypassAuth {
+		c.refreshStaleSession(ctx)
+	}
+
 	// Make initial request
 	req, err := makeRequest()
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.client.Do(req)
+	resp, err := c.send(req)
 	if err != nil {
 		return nil, err
 	}
 
-	// If we get a 403 Forbidden, try to re-authenticate once and retry the request
-	if resp.StatusCode == http.StatusForbidden {
-		resp.Body.Close() // Close the first response
+	// If we get a 403 Forbidden, or a 401 Unauthorized from a reverse proxy,
+	// the session expired: re-authenticate and retry the request as the policy
+	// allows
+	if endpoint == authLoginEndpoint || c.bypassAuth {
+		return resp, nil
+	}
+	policy := c.reauthPolicy()
+	for attempt := 0; sessionRejected(resp.StatusCode) && attempt < policy.Attempts; attempt++ {
+		resp.Body.Close()
 
-		if err := c.AuthLogin(); err != nil {
-			return nil, fmt.Errorf("re-authentication failed: %v", err)
+		if err := sleepCtx(ctx, policy.Backoff<<attempt); err != nil {
+			return nil, err
+		}
+		if err := c.reauthenticate(ctx, sentSID); err != nil {
+			return nil, fmt.Errorf("re-authentication failed: %w", err)
+		}
+		if method == http.MethodPost && !policy.RetryPOST {
+			return nil, fmt.Errorf("%s: %w", endpoint, ErrNotRetried)
 		}
 
 		// Retry the original request with the new SID
@@ -579,13 +1052,43 @@ func (c *Client) doRequest(method, endpoint string, body io.Reader, contentType
 		if err != nil {
 			return nil, err
 		}
-
-		return c.client.Do(req)
+		if resp, err = c.send(req); err != nil {
+			return nil, err
+		}
 	}
 
 	return resp, nil
 }
 
+// withDefaultTimeout applies the client's default timeout for endpoint unless
+// ctx already has a deadline
+func (c *Client) withDefaultTimeout(ctx context.Context, endpoint string) (context.Context, context.CancelFunc) {
+	timeout := c.timeout
+	if c.syncTimeout != nil && strings.HasPrefix(endpoint, "/api/v2/sync/") {
+		timeout = *c.syncTimeout
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// cancelOnClose releases a request context and body once the response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel func()
+	once   sync.Once // releasing the queue slot and buffer twice would corrupt them
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.cancel)
+	return err
+}
+
 // withQuery returns a request modifier that adds query parameters
 func withQuery(query url.Values) func(*http.Request) error {
 	return func(req *http.Request) error {
@@ -594,39 +1097,58 @@ func withQuery(query url.Values) func(*http.Request) error {
 	}
 }
 
-func (c *Client) SyncMainData(rid int) (*MainData, error) {
-	params := url.Values{}
-	params.Set("rid", strconv.Itoa(rid))
+// SyncMainDataCtx retrieves the main data changes since the given response ID
+func (c *Client) SyncMainDataCtx(ctx context.Context, rid int) (*MainData, error) {
+	result, _, err := c.syncMainData(ctx, rid)
+	return result, err
+}
 
-	resp, err := c.doGet("/api/v2/sync/maindata", params)
+// syncMainData retrieves the main data along with the raw response body
+func (c *Client) syncMainData(ctx context.Context, rid int) (*MainData, []byte, error) {
+	resp, err := c.syncMainDataRaw(ctx, rid)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var result MainData
-	err = json.Unmarshal(resp, &result)
+	err = c.decodeJSON("/api/v2/sync/maindata", resp, &result)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return &result, nil
+	return &result, resp, nil
+}
+
+// syncMainDataRaw retrieves the raw main data response, for callers that
+// decode it themselves
+func (c *Client) syncMainDataRaw(ctx context.Context, rid int) ([]byte, error) {
+	params := url.Values{}
+	params.Set("rid", strconv.Itoa(rid))
+	return c.doGetCtx(ctx, "/api/v2/sync/maindata", params)
+}
+
+// SyncTorrentPeersCtx retrieves the peer data changes for a torrent since the given response ID
+func (c *Client) SyncTorrentPeersCtx(ctx context.Context, hash string, rid int) (*TorrentPeers, error) {
+	result, _, err := c.syncTorrentPeers(ctx, hash, rid)
+	return result, err
 }
 
-func (c *Client) SyncTorrentPeers(hash string, rid int) (*TorrentPeers, error) {
+// syncTorrentPeers retrieves the peer data along with the raw response body
+func (c *Client) syncTorrentPeers(ctx context.Context, hash string, rid int) (*TorrentPeers, []byte, error) {
 	params := url.Values{}
 	params.Set("rid", strconv.Itoa(rid))
 	params.Set("hash", hash)
 
-	resp, err := c.doGet("/api/v2/sync/torrentPeers", params)
+	resp, err := c.doGetCtx(ctx, "/api/v2/sync/torrentPeers", params)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var result TorrentPeers
-	err = json.Unmarshal(resp, &result)
+	err = c.decodeJSON("/api/v2/sync/torrentPeers", resp, &result)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return &result, nil
+	return &result, resp, nil
 }
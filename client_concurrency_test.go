@@ -0,0 +1,136 @@
+package qbittorrent
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// newSessionServer simulates a server that rejects requests without a SID it issued
+func newSessionServer(t *testing.T, logins *int32) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	validSIDs := make(map[string]bool)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/auth/login" {
+			n := atomic.AddInt32(logins, 1)
+			mu.Lock()
+			sid := fmt.Sprintf("sid-%d", n)
+			validSIDs[sid] = true
+			http.SetCookie(w, &http.Cookie{Name: "SID", Value: sid})
+			mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		cookie, err := r.Cookie("SID")
+		mu.Lock()
+		valid := err == nil && validSIDs[cookie.Value]
+		mu.Unlock()
+		if !valid {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"name":"torrent1","tags":"tag1"}]`))
+	}))
+}
+
+func TestClient_ConcurrentReauthentication(t *testing.T) {
+	var logins int32
+	mockServer := newSessionServer(t, &logins)
+	defer mockServer.Close()
+
+	client := &Client{
+		username: "user",
+		password: "pass",
+		baseURL:  mockServer.URL,
+		client:   mockServer.Client(),
+		sid:      "expired",
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.TorrentsInfo(); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if logins != 1 {
+		t.Errorf("expected a single login for concurrent 403s, got %d", logins)
+	}
+}
+
+func TestClient_ConcurrentMixedWorkload(t *testing.T) {
+	var logins int32
+	mockServer := newSessionServer(t, &logins)
+	defer mockServer.Close()
+
+	client := &Client{
+		username: "user",
+		password: "pass",
+		baseURL:  mockServer.URL,
+		client:   mockServer.Client(),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if _, err := client.TorrentsGetTags("somehash"); err != nil {
+				t.Errorf("TorrentsGetTags: expected no error, got %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := client.TorrentsAddTags("somehash", "tag2"); err != nil {
+				t.Errorf("TorrentsAddTags: expected no error, got %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			// Explicit logins replace the session used by in-flight requests
+			if err := client.AuthLogin(); err != nil {
+				t.Errorf("AuthLogin: expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestAuthLogin_ForbiddenNotRetried(t *testing.T) {
+	var logins int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&logins, 1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer mockServer.Close()
+
+	client := &Client{
+		username: "user",
+		password: "pass",
+		baseURL:  mockServer.URL,
+		client:   mockServer.Client(),
+	}
+
+	if err := client.AuthLogin(); err == nil {
+		t.Fatalf("expected error, got none")
+	}
+	if logins != 1 {
+		t.Errorf("expected a single login attempt, got %d", logins)
+	}
+}
@@ -28,6 +28,11 @@ func TestTorrentInfo_UnmarshalJSON(t *testing.T) {
 			jsonData: `{"tags": "tag1,tag2,tag3"}`,
 			expected: []string{"tag1", "tag2", "tag3"},
 		},
+		{
+			name:     "Tags separated by comma and space",
+			jsonData: `{"tags": "tag1, tag2, tag3"}`,
+			expected: []string{"tag1", "tag2", "tag3"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1,8 +1,16 @@
 package qbittorrent
 
 import (
+	"context"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"testing"
+
+	"github.com/cehbz/qbittorrent/metainfo"
 )
 
 func TestTorrentsExport(t *testing.T) {
@@ -64,6 +72,102 @@ func TestTorrentsAdd(t *testing.T) {
 	}
 }
 
+func TestTorrentsAdd_Params(t *testing.T) {
+	var form *multipart.Form
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("expected multipart form, got %v", err)
+		}
+		form = r.MultipartForm
+		w.Write([]byte("Ok."))
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	params := &TorrentsAddParams{
+		SavePath:        "/data/tv",
+		Category:        "tv",
+		Tags:            []string{"a", "b"},
+		Trackers:        []string{"udp://backup.example.org:6969", "https://other.example.org/announce"},
+		RewriteAnnounce: metainfo.ReplacePasskey("PASSKEY", "NEWKEY"),
+	}
+	if err := client.TorrentsAddCtx(context.Background(), "test.torrent", []byte(testTorrentFile), params); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for field, expected := range map[string]string{
+		"savepath": "/data/tv", "category": "tv", "tags": "a,b", "skip_checking": "true",
+		"addTrackers": "udp://backup.example.org:6969\nhttps://other.example.org/announce",
+	} {
+		if got := form.Value[field]; len(got) != 1 || got[0] != expected {
+			t.Errorf("expected %s=%s, got %v", field, expected, got)
+		}
+	}
+	file, err := form.File["torrents"][0].Open()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer file.Close()
+	data, _ := io.ReadAll(file)
+	uploaded, err := metainfo.Parse(data)
+	if err != nil {
+		t.Fatalf("expected uploaded torrent to parse, got %v", err)
+	}
+	original, _ := metainfo.Parse([]byte(testTorrentFile))
+	if uploaded.Announce != "http://tracker.example.org/NEWKEY/announce" {
+		t.Errorf("expected rewritten announce URL, got %s", uploaded.Announce)
+	}
+	if uploaded.InfoHash() != original.InfoHash() {
+		t.Errorf("expected infohash to be unchanged")
+	}
+
+	// Rewriting requires a valid .torrent file
+	if err := client.TorrentsAddCtx(context.Background(), "test.torrent", []byte("torrent data"), params); err == nil {
+		t.Errorf("expected error rewriting an invalid torrent, got none")
+	}
+}
+
+func TestTorrentsAddURLs_Params(t *testing.T) {
+	var form url.Values
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("expected multipart form, got %v", err)
+		}
+		form = r.MultipartForm.Value
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	skip := false
+	params := &TorrentsAddParams{
+		Rename:             "Show",
+		SkipChecking:       &skip,
+		FirstLastPiecePrio: true,
+		Forced:             true,
+		AddToTopOfQueue:    true,
+	}
+	if err := client.TorrentsAddURLsCtx(context.Background(), []string{"magnet:?xt=urn:btih:abc"}, params); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := map[string]string{
+		"rename": "Show", "skip_checking": "false", "firstLastPiecePrio": "true",
+		"forced": "true", "forceStart": "true", "addToTopOfQueue": "true",
+	}
+	for field, value := range expected {
+		if got := form[field]; len(got) != 1 || got[0] != value {
+			t.Errorf("expected %s=%s, got %v", field, value, got)
+		}
+	}
+	if got, ok := form["sequentialDownload"]; ok {
+		t.Errorf("expected sequential download to be left to the server, got %v", got)
+	}
+
+	// A name can't be given to several torrents
+	if err := client.TorrentsAddURLsCtx(context.Background(), []string{"magnet:?xt=urn:btih:abc", "magnet:?xt=urn:btih:def"}, params); err == nil {
+		t.Errorf("expected error renaming several torrents, got none")
+	}
+}
+
 func TestTorrentsDelete(t *testing.T) {
 	// Mock successful AuthLogin and TorrentsDelete responses
 	endpointResponses := map[string]mockResponse{
@@ -118,6 +222,31 @@ func TestSetForceStart(t *testing.T) {
 	}
 }
 
+func TestSetForceStartMapCtx(t *testing.T) {
+	var requests []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		requests = append(requests, r.FormValue("hashes")+" "+r.FormValue("value"))
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	err := client.SetForceStartMapCtx(context.Background(), map[InfoHash]bool{"c": true, "a": true, "b": false})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []string{"a|c true", "b false"}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected %v, got %v", expected, requests)
+	}
+
+	requests = nil
+	client.SetForceStartMapCtx(context.Background(), map[InfoHash]bool{"b": false})
+	if !reflect.DeepEqual(requests, []string{"b false"}) {
+		t.Errorf("expected a single request, got %v", requests)
+	}
+}
+
 func TestTorrentsTrackers(t *testing.T) {
 	responseBody := `[{"url":"tracker1","status":1},{"url":"tracker2","status":0}]`
 	// Mock successful AuthLogin and TorrentsTrackers responses
@@ -153,3 +282,35 @@ func TestTorrentsTrackers(t *testing.T) {
 		t.Errorf("Not all expected requests were made")
 	}
 }
+
+func TestTorrentsSetLocationAndRemoveCategories(t *testing.T) {
+	forms := make(map[string]url.Values)
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		forms[r.URL.Path] = r.PostForm
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer mockServer.Close()
+
+	client := &Client{
+		baseURL: mockServer.URL,
+		client:  mockServer.Client(),
+	}
+
+	ctx := context.Background()
+	if err := client.TorrentsSetLocationCtx(ctx, "hash1|hash2", "/data/movies"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := client.TorrentsRemoveCategoriesCtx(ctx, []string{"movies", "tv"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got := forms["/api/v2/torrents/setLocation"]; got.Get("hashes") != "hash1|hash2" || got.Get("location") != "/data/movies" {
+		t.Errorf("unexpected setLocation form: %v", got)
+	}
+	if got := forms["/api/v2/torrents/removeCategories"].Get("categories"); got != "movies\ntv" {
+		t.Errorf("expected newline-separated categories, got %q", got)
+	}
+}
@@ -2,6 +2,7 @@ package qbittorrent
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
 	"net/url"
@@ -27,7 +28,7 @@ func TestDoPostValues(t *testing.T) {
 	data := url.Values{}
 	data.Set("key", "value")
 
-	resp, err := client.doPostValues("/api/test", data)
+	resp, err := client.doPostValuesCtx(context.Background(), "/api/test", data)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -59,7 +60,7 @@ func TestDoPost_Error(t *testing.T) {
 	}
 
 	data := bytes.NewBufferString("test data")
-	_, err = client.doPost("/api/test", data, "text/plain")
+	_, err = client.doPostCtx(context.Background(), "/api/test", data, "text/plain")
 	if err == nil {
 		t.Fatalf("Expected error, got none")
 	}
@@ -86,7 +87,7 @@ func TestDoGet(t *testing.T) {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
-	resp, err := client.doGet("/api/test", nil)
+	resp, err := client.doGetCtx(context.Background(), "/api/test", nil)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -117,7 +118,7 @@ func TestDoGet_Error(t *testing.T) {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
-	_, err = client.doGet("/api/test", nil)
+	_, err = client.doGetCtx(context.Background(), "/api/test", nil)
 	if err == nil {
 		t.Fatalf("Expected error, got none")
 	}
@@ -229,9 +230,9 @@ func TestDoRequest(t *testing.T) {
 				opts = append(opts, withQuery(tt.query))
 			}
 
-			resp, err := client.doRequest(tt.method, tt.endpoint, tt.body, tt.contentType, opts...)
+			resp, err := client.doRequestCtx(context.Background(), tt.method, tt.endpoint, tt.body, tt.contentType, opts...)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("doRequest() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("doRequestCtx() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
@@ -243,7 +244,7 @@ func TestDoRequest(t *testing.T) {
 				}
 
 				if string(body) != tt.wantResponse {
-					t.Errorf("doRequest() response = %v, want %v", string(body), tt.wantResponse)
+					t.Errorf("doRequestCtx() response = %v, want %v", string(body), tt.wantResponse)
 				}
 			}
 
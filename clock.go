@@ -0,0 +1,55 @@
+package qbittorrent
+
+import (
+	"errors"
+	"time"
+)
+
+// Clock is the source of time of the time-based components: the polling
+// loops such as Watcher.Run and SyncState.Run, ban expiry, session age,
+// response caching and circuit breaker cooldowns, health scores, and the
+// timestamps of events, samples and audit records. Replacing it makes them
+// deterministic in tests.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks like a time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// SystemClock is the Clock of the time package
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTicker(d time.Duration) Ticker { return systemTicker{time.NewTicker(d)} }
+
+type systemTicker struct{ *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// WithClock uses clock instead of SystemClock for the client and every
+// component built on it, such as a Watcher, BanManager or HistoryRecorder
+func WithClock(clock Clock) Option {
+	return func(c *Client) error {
+		if clock == nil {
+			return errors.New("nil clock")
+		}
+		c.clock = clock
+		return nil
+	}
+}
+
+// Clock returns the clock of the client, see WithClock
+func (c *Client) Clock() Clock {
+	if c.clock == nil {
+		return SystemClock
+	}
+	return c.clock
+}
@@ -0,0 +1,71 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// stubClock is a Clock whose time is set by the test
+type stubClock struct {
+	now time.Time
+}
+
+func (c *stubClock) Now() time.Time { return c.now }
+
+func (c *stubClock) NewTicker(d time.Duration) Ticker { return SystemClock.NewTicker(d) }
+
+func TestWithClock(t *testing.T) {
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("v5.0.0"))
+	}))
+	defer mockServer.Close()
+
+	clock := &stubClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	client, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithBypassAuth(),
+		WithCache(map[string]time.Duration{"/api/v2/app/version": time.Minute}),
+		WithClock(clock),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if client.Clock() != clock {
+		t.Errorf("expected the injected clock")
+	}
+
+	ctx := context.Background()
+	for range 2 {
+		if _, err := client.AppVersionCtx(ctx); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the version to be cached, got %d requests", requests)
+	}
+
+	// The cache expires with the injected clock, however little real time passed
+	clock.now = clock.now.Add(time.Minute)
+	if _, err := client.AppVersionCtx(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected the cache to expire, got %d requests", requests)
+	}
+}
+
+func TestWithClock_Default(t *testing.T) {
+	client := &Client{}
+	if client.Clock() != SystemClock {
+		t.Errorf("expected SystemClock by default")
+	}
+	if _, err := NewClientWithOptions("", "", "localhost", "8080", WithClock(nil)); err == nil {
+		t.Error("expected an error for a nil clock")
+	}
+}
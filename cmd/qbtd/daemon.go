@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cehbz/qbittorrent"
+	"github.com/cehbz/qbittorrent/config"
+	"github.com/cehbz/qbittorrent/trackerid"
+)
+
+// Daemon runs the configured automation components against one instance
+type Daemon struct {
+	cfg      *config.Config
+	instance config.Instance
+	client   *qbittorrent.Client
+	watcher  *qbittorrent.Watcher
+	logger   *slog.Logger
+}
+
+// NewDaemon wires the components of cfg to the client of instance
+func NewDaemon(cfg *config.Config, instance config.Instance, client *qbittorrent.Client, logger *slog.Logger) *Daemon {
+	d := &Daemon{cfg: cfg, instance: instance, client: client, watcher: qbittorrent.NewWatcher(client), logger: logger}
+
+	d.watcher.OnEvent(func(ctx context.Context, event qbittorrent.Event) error {
+		logger.Info("torrent event", "event", event.Type.String(), "hash", event.Hash, "name", event.Torrent.Name)
+		return nil
+	})
+	if cfg.Exclusion != nil {
+		policy := qbittorrent.NewExclusionPolicy(client, nil, cfg.Exclusion.Regexps()...)
+		d.watcher.OnEvent(policy.HandleEvent)
+	}
+	return d
+}
+
+// Run runs the components until ctx is done or one of them fails
+func (d *Daemon) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	run := func(name string, fn func(context.Context) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// errors after ctx is done come from shutting down
+			if err := fn(ctx); err != nil && ctx.Err() == nil {
+				errs <- fmt.Errorf("%s: %w", name, err)
+				cancel()
+			}
+		}()
+	}
+
+	run("watcher", func(ctx context.Context) error {
+		return d.watcher.Run(ctx, time.Duration(d.instance.PollInterval))
+	})
+	if cfg := d.instance.Webhook; cfg != nil {
+		run("webhook", d.serveWebhook)
+	}
+	if cfg := d.cfg.Janitor; cfg != nil {
+		run("janitor", every(d.client.Clock(), time.Duration(cfg.Interval), d.cleanup))
+	}
+	if cfg := d.cfg.TrackerMonitor; cfg != nil {
+		run("tracker monitor", every(d.client.Clock(), time.Duration(cfg.Interval), d.checkTrackers))
+	}
+	if cfg := d.cfg.Reconciler; cfg != nil {
+		run("reconciler", every(d.client.Clock(), time.Duration(cfg.Interval), d.reconcile))
+	}
+
+	wg.Wait()
+	close(errs)
+	return errors.Join(collect(errs)...)
+}
+
+func collect(errs <-chan error) []error {
+	var all []error
+	for err := range errs {
+		all = append(all, err)
+	}
+	return all
+}
+
+// every returns a function calling fn every interval of clock until ctx is
+// done. Failures are logged by fn and retried at the next interval.
+func every(clock qbittorrent.Clock, interval time.Duration, fn func(context.Context)) func(context.Context) error {
+	return func(ctx context.Context) error {
+		ticker := clock.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			fn(ctx)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C():
+			}
+		}
+	}
+}
+
+// serveWebhook serves the webhook until ctx is done
+func (d *Daemon) serveWebhook(ctx context.Context) error {
+	cfg := d.instance.Webhook
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path, qbittorrent.NewWebhookHandler(d.watcher, cfg.Secret))
+	server := &http.Server{Addr: cfg.Listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+	d.logger.Info("serving webhook", "addr", cfg.Listen, "path", cfg.Path)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return ctx.Err()
+}
+
+// cleanup deletes unused tags and categories
+func (d *Daemon) cleanup(ctx context.Context) {
+	cfg := d.cfg.Janitor
+	if cfg.Tags != nil {
+		deleted, err := d.client.CleanupTagsCtx(ctx, *cfg.Tags)
+		if err != nil {
+			d.logger.Error("cleaning up tags failed", "error", err)
+		} else if len(deleted) > 0 {
+			d.logger.Info("deleted unused tags", "tags", deleted, "dry_run", cfg.Tags.DryRun)
+		}
+	}
+	if cfg.Categories != nil {
+		deleted, err := d.client.CleanupCategoriesCtx(ctx, *cfg.Categories)
+		if err != nil {
+			d.logger.Error("cleaning up categories failed", "error", err)
+		} else if len(deleted) > 0 {
+			d.logger.Info("deleted unused categories", "categories", deleted, "dry_run", cfg.Categories.DryRun)
+		}
+	}
+}
+
+// checkTrackers logs active torrents without a working tracker, with the
+// classified messages of their trackers. qBittorrent only reports a current
+// tracker once one works.
+func (d *Daemon) checkTrackers(ctx context.Context) {
+	torrents, err := d.client.TorrentsInfoCtx(ctx)
+	if err != nil {
+		d.logger.Error("checking trackers failed", "error", err)
+		return
+	}
+	for _, torrent := range torrents {
+		state := qbittorrent.TorrentState(torrent.State)
+		if torrent.Tracker != "" || state.Stopped() || state == qbittorrent.StateMetaDL {
+			continue
+		}
+		trackers, err := d.client.TorrentsTrackersCtx(ctx, string(torrent.Hash))
+		if err != nil {
+			d.logger.Error("checking trackers failed", "hash", torrent.Hash, "error", err)
+			continue
+		}
+		for _, tracker := range trackers {
+			if tracker.Status != qbittorrent.TrackerNotWorking {
+				continue
+			}
+			kind := tracker.MessageKind()
+			level := slog.LevelWarn
+			if kind.Permanent() {
+				level = slog.LevelError
+			}
+			d.logger.Log(ctx, level, "tracker not working", "hash", torrent.Hash, "name", torrent.Name,
+				"tracker", trackerid.Canonical(tracker.URL), "kind", kind.String(), "message", tracker.Msg)
+		}
+	}
+}
+
+// reconcile applies the speed rules
+func (d *Daemon) reconcile(ctx context.Context) {
+	changes, err := d.client.ApplySpeedPolicyCtx(ctx, d.cfg.Reconciler.SpeedRules)
+	if err != nil {
+		d.logger.Error("applying speed rules failed", "error", err)
+		return
+	}
+	for _, change := range changes {
+		d.logger.Info("changed speed limit", "hash", change.Hash, "name", change.Name, "upload", change.Upload, "from", change.Old, "to", change.New)
+	}
+}
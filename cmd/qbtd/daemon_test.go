@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cehbz/qbittorrent"
+	"github.com/cehbz/qbittorrent/config"
+)
+
+func TestDaemon_Run(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/api/v2/sync/maindata":
+			w.Write([]byte(`{"rid":1,"full_update":true,"torrents":{"a":{"name":"one","category":"tv","tags":"hd"}},"tags":["hd","old"],"categories":{"tv":{"name":"tv"}}}`))
+		case "/api/v2/torrents/info":
+			w.Write([]byte(`[{"hash":"a","name":"one","category":"tv","state":"stalledUP"}]`))
+		case "/api/v2/torrents/trackers":
+			w.Write([]byte(`[{"url":"https://tracker.example/announce","status":4,"msg":"Unregistered torrent"}]`))
+		case "/api/v2/torrents/uploadLimit", "/api/v2/torrents/downloadLimit":
+			w.Write([]byte(`{"a":0}`))
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := qbittorrent.NewClientWithOptions("", "", "", "",
+		qbittorrent.WithBaseURL(mockServer.URL),
+		qbittorrent.WithHTTPClient(mockServer.Client()),
+		qbittorrent.WithBypassAuth(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	cfg := &config.Config{
+		Janitor:        &config.Janitor{Interval: config.Duration(time.Hour), Tags: &qbittorrent.CleanupOptions{}},
+		TrackerMonitor: &config.TrackerMonitor{Interval: config.Duration(time.Hour)},
+		Reconciler:     &config.Reconciler{Interval: config.Duration(time.Hour), SpeedRules: []qbittorrent.SpeedRule{{Category: "tv", UploadLimit: 1024}}},
+	}
+	instance := config.Instance{Name: "test", PollInterval: config.Duration(time.Hour)}
+	daemon := NewDaemon(cfg, instance, client, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := daemon.Run(ctx); err != nil {
+		t.Fatalf("expected no error after cancellation, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, path := range []string{"/api/v2/torrents/deleteTags", "/api/v2/torrents/setUploadLimit", "/api/v2/torrents/trackers"} {
+		if requests[path] == 0 {
+			t.Errorf("expected a request to %s, got %v", path, requests)
+		}
+	}
+}
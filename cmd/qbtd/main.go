@@ -0,0 +1,81 @@
+// Command qbtd runs the automation components of the qbittorrent package
+// against qBittorrent instances, configured by a JSON file, or a YAML file
+// with a .yaml or .yml extension, in the format of the config package:
+//
+//	{
+//	  "instances": [
+//	    {"url": "http://localhost:8080", "username": "admin", "password_env": "QBT_PASSWORD",
+//	     "webhook": {"listen": ":9090", "secret": "SECRET"}}
+//	  ],
+//	  "exclusion": {},
+//	  "janitor": {"interval": "1h", "tags": {}, "categories": {"exclude": ["keep"]}},
+//	  "tracker_monitor": {"interval": "10m"},
+//	  "reconciler": {"interval": "5m", "speed_rules": [{"category": "tv", "upload_limit": 1048576}]}
+//	}
+//
+// The watcher always runs and logs torrent events. The webhook receives the
+// calls of qBittorrent's "Run external program" settings, the exclusion policy
+// skips sample and extra files of added torrents, the janitor deletes unused
+// tags and categories, the tracker monitor logs torrents without a working
+// tracker, and the reconciler applies speed limits.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/cehbz/qbittorrent"
+	"github.com/cehbz/qbittorrent/config"
+)
+
+func main() {
+	configPath := flag.String("config", "qbtd.json", "path of the config file, in YAML if it ends in .yaml or .yml")
+	flag.Parse()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	if err := run(*configPath, logger); err != nil {
+		logger.Error("qbtd failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath string, logger *slog.Logger) error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+	daemons := make([]*Daemon, len(cfg.Instances))
+	for i, instance := range cfg.Instances {
+		instanceLogger := logger.With("instance", instance.Name)
+		client, err := instance.NewClient(qbittorrent.WithLogger(instanceLogger))
+		if err != nil {
+			return fmt.Errorf("connecting to %s: %w", instance.Name, err)
+		}
+		daemons[i] = NewDaemon(cfg, instance, client, instanceLogger)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	logger.Info("qbtd started", "instances", len(daemons))
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(daemons))
+	for i, daemon := range daemons {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := daemon.Run(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", cfg.Instances[i].Name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,125 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"hash/maphash"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// WithConditionalRequests remembers the ETag and Last-Modified headers of GET
+// responses and revalidates them with If-None-Match and If-Modified-Since.
+// A 304 Not Modified response is answered with the remembered body. qBittorrent
+// doesn't send validators for API responses itself, but caching reverse
+// proxies in front of it may.
+func WithConditionalRequests() Option {
+	return func(c *Client) error {
+		c.validators = &validatorStore{entries: make(map[string]validated)}
+		return nil
+	}
+}
+
+// validatorStore holds the validators and bodies of GET responses
+type validatorStore struct {
+	mu      sync.Mutex
+	entries map[string]validated // keyed by endpoint and query
+}
+
+type validated struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+func validatorKey(endpoint string, query url.Values) string {
+	return endpoint + "?" + query.Encode()
+}
+
+// get returns the validated response of a request, if any
+func (s *validatorStore) get(endpoint string, query url.Values) (validated, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.entries[validatorKey(endpoint, query)]
+	return v, ok
+}
+
+// put remembers a response if it carries validators
+func (s *validatorStore) put(endpoint string, query url.Values, header http.Header, body []byte) {
+	v := validated{etag: header.Get("ETag"), lastModified: header.Get("Last-Modified"), body: body}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v.etag == "" && v.lastModified == "" {
+		delete(s.entries, validatorKey(endpoint, query))
+		return
+	}
+	s.entries[validatorKey(endpoint, query)] = v
+}
+
+// withValidators sets the conditional headers of a remembered response
+func withValidators(v validated) func(*http.Request) error {
+	return func(req *http.Request) error {
+		if v.etag != "" {
+			req.Header.Set("If-None-Match", v.etag)
+		}
+		if v.lastModified != "" {
+			req.Header.Set("If-Modified-Since", v.lastModified)
+		}
+		return nil
+	}
+}
+
+// ChangePoller fetches GET endpoints and only decodes responses that differ
+// from the previous response to the same request, comparing hashes of the
+// bodies. It works with any server; combined with WithConditionalRequests
+// unchanged responses needn't even be transferred. Each poller keeps its own
+// hashes, so independent watchers each see every change.
+// A ChangePoller is safe for concurrent use.
+type ChangePoller struct {
+	client *Client
+	seed   maphash.Seed
+
+	mu     sync.Mutex
+	hashes map[string]uint64 // keyed by endpoint and query
+}
+
+// NewChangePoller returns a poller for c
+func NewChangePoller(c *Client) *ChangePoller {
+	return &ChangePoller{client: c, seed: maphash.MakeSeed(), hashes: make(map[string]uint64)}
+}
+
+// GetCtx fetches endpoint and, if the response changed since the last call
+// for the same endpoint and query, decodes it into v and returns true. An
+// unchanged response leaves v untouched.
+func (p *ChangePoller) GetCtx(ctx context.Context, endpoint string, query url.Values, v interface{}) (bool, error) {
+	data, err := p.client.doGetCtx(ctx, endpoint, query)
+	if err != nil {
+		return false, err
+	}
+
+	key := validatorKey(endpoint, query)
+	sum := maphash.Bytes(p.seed, data)
+	p.mu.Lock()
+	last, ok := p.hashes[key]
+	p.mu.Unlock()
+	if ok && last == sum {
+		return false, nil
+	}
+
+	if err := p.client.decodeJSON(endpoint, data, v); err != nil {
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+	// Only remember decoded responses, so a failed decode is retried
+	p.mu.Lock()
+	p.hashes[key] = sum
+	p.mu.Unlock()
+	return true, nil
+}
+
+// Forget drops the hashes of all responses, so the next calls decode them
+func (p *ChangePoller) Forget() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.hashes = make(map[string]uint64)
+}
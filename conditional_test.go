@@ -0,0 +1,79 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithConditionalRequests(t *testing.T) {
+	var conditional, full int
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`["a","b"]`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithBypassAuth(),
+		WithConditionalRequests(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		tags, err := client.TorrentsGetAllTagsCtx(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(tags) != 2 {
+			t.Errorf("expected the remembered tags, got %v", tags)
+		}
+	}
+	if full != 1 || conditional != 2 {
+		t.Errorf("expected 1 full and 2 conditional requests, got %d and %d", full, conditional)
+	}
+}
+
+func TestChangePoller(t *testing.T) {
+	body := `[{"hash":"a"}]`
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	poller := NewChangePoller(client)
+	ctx := context.Background()
+
+	var torrents []TorrentInfo
+	changed, err := poller.GetCtx(ctx, "/api/v2/torrents/info", nil, &torrents)
+	if err != nil || !changed || len(torrents) != 1 {
+		t.Fatalf("expected first response to be decoded, got %v, %v, %v", changed, err, torrents)
+	}
+
+	torrents = nil
+	if changed, err := poller.GetCtx(ctx, "/api/v2/torrents/info", nil, &torrents); err != nil || changed || torrents != nil {
+		t.Errorf("expected unchanged response to be skipped, got %v, %v, %v", changed, err, torrents)
+	}
+
+	body = `[{"hash":"a"},{"hash":"b"}]`
+	if changed, err := poller.GetCtx(ctx, "/api/v2/torrents/info", nil, &torrents); err != nil || !changed || len(torrents) != 2 {
+		t.Errorf("expected changed response to be decoded, got %v, %v, %v", changed, err, torrents)
+	}
+
+	poller.Forget()
+	if changed, _ := poller.GetCtx(ctx, "/api/v2/torrents/info", nil, &torrents); !changed {
+		t.Errorf("expected response to be decoded after Forget")
+	}
+}
@@ -0,0 +1,434 @@
+// Package config loads the declarative configuration of the automation
+// components from JSON or YAML: the qBittorrent instances to manage, the policies and
+// rules applied to them, and how often they run. Policies unmarshal into the
+// structs of the qbittorrent package, e.g.
+//
+//	{
+//	  "instances": [
+//	    {"name": "main", "url": "http://localhost:8080", "username": "admin", "password_env": "QBT_PASSWORD",
+//	     "poll_interval": "30s", "webhook": {"listen": ":9090", "secret": "SECRET"}}
+//	  ],
+//	  "exclusion": {"patterns": ["(?i)\\bsample\\b"]},
+//	  "janitor": {"interval": "1h", "tags": {"exclude": ["keep"]}, "categories": {"dry_run": true}},
+//	  "tracker_monitor": {"interval": "10m"},
+//	  "reconciler": {"interval": "5m", "speed_rules": [{"category": "tv", "upload_limit": 1048576}]}
+//	}
+//
+// YAML files have the same structure, see ParseYAML. Components without a
+// section are not run. Errors name the file, line and column and the
+// offending field.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cehbz/qbittorrent"
+)
+
+// DefaultPollInterval is the poll interval of instances that don't set one
+const DefaultPollInterval = 30 * time.Second
+
+// DefaultWebhookPath is the path of webhooks that don't set one
+const DefaultWebhookPath = "/hook"
+
+// Duration is a time.Duration written as a string such as "30s"
+type Duration time.Duration
+
+var durationType = reflect.TypeOf(Duration(0))
+
+// UnmarshalJSON parses durations with time.ParseDuration
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return &json.UnmarshalTypeError{Value: string(data), Type: durationType}
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return &json.UnmarshalTypeError{Value: string(data), Type: durationType}
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON writes durations as strings
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Config is the configuration of the automation components
+type Config struct {
+	Instances      []Instance      `json:"instances"`
+	Exclusion      *Exclusion      `json:"exclusion,omitempty"`
+	Janitor        *Janitor        `json:"janitor,omitempty"`
+	TrackerMonitor *TrackerMonitor `json:"tracker_monitor,omitempty"`
+	Reconciler     *Reconciler     `json:"reconciler,omitempty"`
+}
+
+// Instance is a qBittorrent instance to manage
+type Instance struct {
+	Name         string   `json:"name"` // defaults to the URL
+	URL          string   `json:"url"`
+	Username     string   `json:"username"`
+	Password     string   `json:"password,omitempty"`
+	PasswordEnv  string   `json:"password_env,omitempty"` // environment variable holding the password
+	PollInterval Duration `json:"poll_interval"`
+	Webhook      *Webhook `json:"webhook,omitempty"`
+}
+
+// NewClient connects to the instance
+func (i Instance) NewClient(opts ...qbittorrent.Option) (*qbittorrent.Client, error) {
+	opts = append([]qbittorrent.Option{qbittorrent.WithBaseURL(i.URL)}, opts...)
+	return qbittorrent.NewClientWithOptions(i.Username, i.Password, "", "", opts...)
+}
+
+// Webhook serves a WebhookHandler for the instance
+type Webhook struct {
+	Listen string `json:"listen"` // e.g. ":9090"
+	Path   string `json:"path"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// Exclusion skips sample and extra files of added torrents
+type Exclusion struct {
+	Patterns []string `json:"patterns"` // regular expressions, DefaultExclusionPatterns if empty
+
+	regexps []*regexp.Regexp
+}
+
+// Regexps returns the compiled patterns
+func (e *Exclusion) Regexps() []*regexp.Regexp {
+	return e.regexps
+}
+
+// Janitor deletes unused tags and categories. Tags and categories are only
+// cleaned up if their section is present.
+type Janitor struct {
+	Interval   Duration                    `json:"interval"`
+	Tags       *qbittorrent.CleanupOptions `json:"tags,omitempty"`
+	Categories *qbittorrent.CleanupOptions `json:"categories,omitempty"`
+}
+
+// TrackerMonitor reports torrents without a working tracker
+type TrackerMonitor struct {
+	Interval Duration `json:"interval"`
+}
+
+// Reconciler applies speed limits to torrents
+type Reconciler struct {
+	Interval   Duration                `json:"interval"`
+	SpeedRules []qbittorrent.SpeedRule `json:"speed_rules"`
+}
+
+// Error reports an invalid config. Line and Column are 1-based and zero if
+// the position, or only the column, is unknown; Field is a path such as "instances[0].url".
+type Error struct {
+	File         string
+	Line, Column int
+	Field        string
+	Msg          string
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString("config: ")
+	if e.File != "" {
+		b.WriteString(e.File + ":")
+	}
+	switch {
+	case e.Column > 0:
+		fmt.Fprintf(&b, "%d:%d:", e.Line, e.Column)
+	case e.Line > 0:
+		fmt.Fprintf(&b, "%d:", e.Line)
+	}
+	if b.Len() > len("config: ") {
+		b.WriteString(" ")
+	}
+	if e.Field != "" {
+		b.WriteString(e.Field + ": ")
+	}
+	b.WriteString(e.Msg)
+	return b.String()
+}
+
+// Load reads and validates a config file, in YAML if its extension is .yaml
+// or .yml and in JSON otherwise
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	parse := Parse
+	if isYAML(path) {
+		parse = ParseYAML
+	}
+	cfg, err := parse(data)
+	var cfgErr *Error
+	if errors.As(err, &cfgErr) {
+		cfgErr.File = path
+	}
+	return cfg, err
+}
+
+// Parse decodes and validates a config, filling in defaults. Unknown fields
+// are rejected to catch typos.
+func Parse(data []byte) (*Config, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var cfg Config
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, decodeError(data, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, positioned(data, dec.InputOffset(), &Error{Msg: "unexpected data after the config"})
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, locate(data, err)
+	}
+	return &cfg, nil
+}
+
+// decodeError converts a JSON decoding error into an Error
+func decodeError(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		// the offset is after the invalid character
+		return positioned(data, max(syntaxErr.Offset-1, 0), &Error{Msg: syntaxErr.Error()})
+	case errors.As(err, &typeErr):
+		cfgErr := &Error{Field: indexedField(typeErr.Field), Msg: fmt.Sprintf("cannot use %s as %s", typeErr.Value, typeErr.Type)}
+		if typeErr.Type == durationType {
+			// The decoder knows neither the field nor the offset of errors
+			// of UnmarshalJSON. It stops at the first invalid duration, so
+			// that is the first occurrence of the value.
+			cfgErr.Msg = fmt.Sprintf(`invalid duration %s, use a string such as "30s"`, typeErr.Value)
+			offset := bytes.Index(data, []byte(typeErr.Value))
+			if offset < 0 {
+				return cfgErr
+			}
+			cfgErr.Field = fieldAt(data, int64(offset))
+		}
+		if cfgErr.Field == "" {
+			// the offset is after the value
+			return positioned(data, typeErr.Offset, cfgErr)
+		}
+		return locate(data, cfgErr)
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return positioned(data, int64(len(data)), &Error{Msg: "unexpected end of the config"})
+	}
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &Error{Msg: "unknown field " + field}
+	}
+	return &Error{Msg: err.Error()}
+}
+
+// validate checks the config and fills in defaults. Errors are *Error with
+// the Field set.
+func (c *Config) validate() error {
+	if len(c.Instances) == 0 {
+		return &Error{Field: "instances", Msg: "at least one instance is required"}
+	}
+	names := make(map[string]bool)
+	for i := range c.Instances {
+		instance := &c.Instances[i]
+		field := fmt.Sprintf("instances[%d]", i)
+		if instance.URL == "" {
+			return &Error{Field: field + ".url", Msg: "required"}
+		}
+		if instance.Name == "" {
+			instance.Name = instance.URL
+		}
+		if names[instance.Name] {
+			return &Error{Field: field + ".name", Msg: fmt.Sprintf("duplicate instance %q", instance.Name)}
+		}
+		names[instance.Name] = true
+		if instance.PasswordEnv != "" {
+			password, ok := os.LookupEnv(instance.PasswordEnv)
+			if !ok {
+				return &Error{Field: field + ".password_env", Msg: fmt.Sprintf("environment variable %s is not set", instance.PasswordEnv)}
+			}
+			instance.Password = password
+		}
+		if instance.PollInterval < 0 {
+			return &Error{Field: field + ".poll_interval", Msg: "must be positive"}
+		}
+		if instance.PollInterval == 0 {
+			instance.PollInterval = Duration(DefaultPollInterval)
+		}
+		if webhook := instance.Webhook; webhook != nil {
+			if webhook.Listen == "" {
+				return &Error{Field: field + ".webhook.listen", Msg: "required"}
+			}
+			if webhook.Path == "" {
+				webhook.Path = DefaultWebhookPath
+			}
+			if !strings.HasPrefix(webhook.Path, "/") {
+				return &Error{Field: field + ".webhook.path", Msg: `must start with "/"`}
+			}
+		}
+	}
+
+	if c.Exclusion != nil {
+		for i, pattern := range c.Exclusion.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return &Error{Field: fmt.Sprintf("exclusion.patterns[%d]", i), Msg: err.Error()}
+			}
+			c.Exclusion.regexps = append(c.Exclusion.regexps, re)
+		}
+	}
+	if c.Janitor != nil {
+		if c.Janitor.Interval <= 0 {
+			return &Error{Field: "janitor.interval", Msg: "must be positive"}
+		}
+		if c.Janitor.Tags == nil && c.Janitor.Categories == nil {
+			return &Error{Field: "janitor", Msg: "tags or categories is required"}
+		}
+	}
+	if c.TrackerMonitor != nil && c.TrackerMonitor.Interval <= 0 {
+		return &Error{Field: "tracker_monitor.interval", Msg: "must be positive"}
+	}
+	if c.Reconciler != nil {
+		if c.Reconciler.Interval <= 0 {
+			return &Error{Field: "reconciler.interval", Msg: "must be positive"}
+		}
+		for i, rule := range c.Reconciler.SpeedRules {
+			field := fmt.Sprintf("reconciler.speed_rules[%d]", i)
+			if rule.UploadLimit < 0 {
+				return &Error{Field: field + ".upload_limit", Msg: "must not be negative"}
+			}
+			if rule.DownloadLimit < 0 {
+				return &Error{Field: field + ".download_limit", Msg: "must not be negative"}
+			}
+		}
+	}
+	return nil
+}
+
+// locate sets the position of a validation error to its field, or the
+// closest enclosing field present in data
+func locate(data []byte, err error) error {
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) {
+		return err
+	}
+	offsets := fieldOffsets(data)
+	for field := cfgErr.Field; field != ""; field = parentField(field) {
+		if offset, ok := offsets[field]; ok {
+			return positioned(data, offset, cfgErr)
+		}
+	}
+	return err
+}
+
+// indexedField converts the field paths of the decoder, such as
+// "instances.0.url", to the form of Error, such as "instances[0].url"
+func indexedField(field string) string {
+	return arrayIndex.ReplaceAllString(field, "[$1]")
+}
+
+var arrayIndex = regexp.MustCompile(`\.(\d+)\b`)
+
+// fieldAt returns the field whose value starts at or contains offset
+func fieldAt(data []byte, offset int64) string {
+	var field string
+	start := int64(-1)
+	for f, o := range fieldOffsets(data) {
+		if o <= offset && o > start {
+			field, start = f, o
+		}
+	}
+	return field
+}
+
+// parentField returns the field enclosing field, e.g. "instances[0]" for
+// "instances[0].url" and "instances" for "instances[0]"
+func parentField(field string) string {
+	i := strings.LastIndexAny(field, ".[")
+	if i < 0 {
+		return ""
+	}
+	return field[:i]
+}
+
+// positioned sets the line and column of err to offset in data
+func positioned(data []byte, offset int64, err *Error) *Error {
+	offset = min(offset, int64(len(data)))
+	before := data[:offset]
+	err.Line = bytes.Count(before, []byte("\n")) + 1
+	err.Column = int(offset) - (bytes.LastIndexByte(before, '\n') + 1) + 1
+	return err
+}
+
+// fieldOffsets maps the paths of the fields in a JSON document, such as
+// "instances[0].url", to the offsets at which they start
+func fieldOffsets(data []byte) map[string]int64 {
+	type frame struct {
+		path    string
+		array   bool
+		index   int
+		key     string
+		wantKey bool
+	}
+	offsets := make(map[string]int64)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var stack []*frame
+	for {
+		offset := skipSeparators(data, dec.InputOffset())
+		tok, err := dec.Token()
+		if err != nil {
+			return offsets
+		}
+		var path string
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			switch {
+			case tok == json.Delim('}') || tok == json.Delim(']'):
+				stack = stack[:len(stack)-1]
+				continue
+			case top.array:
+				path = fmt.Sprintf("%s[%d]", top.path, top.index)
+				top.index++
+				offsets[path] = offset
+			case top.wantKey:
+				top.key, _ = tok.(string)
+				top.wantKey = false
+				if top.path != "" {
+					offsets[top.path+"."+top.key] = offset
+				} else {
+					offsets[top.key] = offset
+				}
+				continue
+			default:
+				path = top.key
+				if top.path != "" {
+					path = top.path + "." + top.key
+				}
+				top.wantKey = true
+			}
+		}
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, &frame{path: path, wantKey: true})
+		case json.Delim('['):
+			stack = append(stack, &frame{path: path, array: true})
+		}
+	}
+}
+
+// skipSeparators returns the offset of the next token at or after offset
+func skipSeparators(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
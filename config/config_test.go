@@ -0,0 +1,254 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	t.Setenv("QBT_TEST_PASSWORD", "secret")
+	path := filepath.Join(t.TempDir(), "qbtd.json")
+	config := `{
+		"instances": [
+			{"url": "http://localhost:8080", "username": "admin", "password_env": "QBT_TEST_PASSWORD", "webhook": {"listen": ":9090"}},
+			{"name": "remote", "url": "http://remote:8080", "poll_interval": "1m"}
+		],
+		"exclusion": {"patterns": ["(?i)sample"]},
+		"janitor": {"interval": "1h", "tags": {"exclude": ["keep"]}, "categories": {"dry_run": true}},
+		"reconciler": {"interval": "5m", "speed_rules": [{"category": "tv", "upload_limit": 1024}]}
+	}`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	local, remote := cfg.Instances[0], cfg.Instances[1]
+	if local.Name != "http://localhost:8080" || local.Password != "secret" || time.Duration(local.PollInterval) != DefaultPollInterval || local.Webhook.Path != DefaultWebhookPath {
+		t.Errorf("expected defaults and the password to be filled in, got %+v", local)
+	}
+	if remote.Name != "remote" || time.Duration(remote.PollInterval) != time.Minute || remote.Webhook != nil {
+		t.Errorf("unexpected instance %+v", remote)
+	}
+	if regexps := cfg.Exclusion.Regexps(); len(regexps) != 1 || !regexps[0].MatchString("Sample.mkv") {
+		t.Errorf("expected the compiled pattern, got %v", regexps)
+	}
+	if cfg.Janitor.Tags.Exclude[0] != "keep" || cfg.Janitor.Tags.DryRun || !cfg.Janitor.Categories.DryRun {
+		t.Errorf("unexpected janitor %+v", cfg.Janitor)
+	}
+	if rules := cfg.Reconciler.SpeedRules; len(rules) != 1 || rules[0].Category != "tv" || rules[0].UploadLimit != 1024 {
+		t.Errorf("unexpected speed rules %+v", rules)
+	}
+	if cfg.TrackerMonitor != nil {
+		t.Errorf("expected no tracker monitor, got %+v", cfg.TrackerMonitor)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"instances": []}`), 0o600); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	_, err = Load(path)
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) || cfgErr.File != path || cfgErr.Field != "instances" || cfgErr.Line != 1 || cfgErr.Column != 2 {
+		t.Errorf("expected an error at instances in %s, got %#v", path, err)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		config       string
+		line, column int
+		field        string
+		expected     string
+	}{
+		{
+			config:   "{\n  \"instances\": [\n    {\"url\": \"a\"},\n    {\"username\": \"b\"}\n  ]\n}",
+			line:     4,
+			column:   5,
+			field:    "instances[1].url",
+			expected: "config: 4:5: instances[1].url: required",
+		},
+		{
+			config:   "{\n  \"instances\": [{\"url\": \"a\", \"name\": 3}]\n}",
+			line:     2,
+			column:   30,
+			field:    "instances[0].name",
+			expected: "config: 2:30: instances[0].name: cannot use number as string",
+		},
+		{
+			config:   "{\n  \"instances\": [{\"url\": \"a\", \"poll_interval\": \"3x\"}]\n}",
+			line:     2,
+			column:   30,
+			field:    "instances[0].poll_interval",
+			expected: `config: 2:30: instances[0].poll_interval: invalid duration "3x", use a string such as "30s"`,
+		},
+		{
+			config:   "{\n  \"instances\": [{\"url\": \"a\"}, {\"url\": \"a\"}]\n}",
+			line:     2,
+			column:   31,
+			field:    "instances[1].name",
+			expected: `config: 2:31: instances[1].name: duplicate instance "a"`,
+		},
+		{
+			config:   "{\n  \"instances\": [{\"url\": \"a\"}],\n  \"reconciler\": {\"interval\": \"1m\", \"speed_rules\": [{}, {\"upload_limit\": -5}]}\n}",
+			line:     3,
+			column:   57,
+			field:    "reconciler.speed_rules[1].upload_limit",
+			expected: "config: 3:57: reconciler.speed_rules[1].upload_limit: must not be negative",
+		},
+		{
+			config:   "{\n  \"instances\": [{\"url\": \"a\"}],\n  \"exclusion\": {\"patterns\": [\"(\"]}\n}",
+			line:     3,
+			column:   30,
+			field:    "exclusion.patterns[0]",
+			expected: "config: 3:30: exclusion.patterns[0]: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			config:   "{\n  \"instances\": [{\"url\": \"a\"}],\n  \"janitor\": {\"interval\": \"1h\"}\n}",
+			line:     3,
+			column:   3,
+			field:    "janitor",
+			expected: "config: 3:3: janitor: tags or categories is required",
+		},
+		{
+			config:   "{\n  \"instances\": [{\"url\": \"a\",}]\n}",
+			line:     2,
+			column:   29,
+			expected: "config: 2:29: invalid character '}' looking for beginning of object key string",
+		},
+		{
+			config:   `{"instances": [{"url": "a", "user": "b"}]}`,
+			expected: `config: unknown field "user"`,
+		},
+	}
+	for _, test := range tests {
+		_, err := Parse([]byte(test.config))
+		var cfgErr *Error
+		if !errors.As(err, &cfgErr) {
+			t.Errorf("%s: expected *Error, got %v", test.config, err)
+			continue
+		}
+		if cfgErr.Line != test.line || cfgErr.Column != test.column || cfgErr.Field != test.field || err.Error() != test.expected {
+			t.Errorf("%s: expected %q at %d:%d in %q, got %q at %d:%d in %q", test.config,
+				test.expected, test.line, test.column, test.field, err.Error(), cfgErr.Line, cfgErr.Column, cfgErr.Field)
+		}
+	}
+}
+
+func TestParse_PasswordEnvNotSet(t *testing.T) {
+	_, err := Parse([]byte(`{"instances": [{"url": "a", "password_env": "QBT_TEST_UNSET_PASSWORD"}]}`))
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) || cfgErr.Field != "instances[0].password_env" {
+		t.Errorf("expected an error at instances[0].password_env, got %v", err)
+	}
+}
+
+func TestLoad_YAML(t *testing.T) {
+	t.Setenv("QBT_TEST_PASSWORD", "secret")
+	path := filepath.Join(t.TempDir(), "qbtd.yaml")
+	config := `# managed instances
+instances:
+  - url: http://localhost:8080
+    username: admin
+    password_env: QBT_TEST_PASSWORD
+    webhook:
+      listen: ":9090"
+  - name: remote
+    url: http://remote:8080
+    poll_interval: 1m
+exclusion:
+  patterns: ["(?i)sample"]
+janitor:
+  interval: 1h
+  tags: {exclude: [keep]}
+reconciler:
+  interval: 5m
+  speed_rules:
+    - category: tv
+      upload_limit: 1024
+`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	local, remote := cfg.Instances[0], cfg.Instances[1]
+	if local.Password != "secret" || local.Webhook.Listen != ":9090" || local.Webhook.Path != DefaultWebhookPath {
+		t.Errorf("expected defaults and the password to be filled in, got %+v", local)
+	}
+	if remote.Name != "remote" || time.Duration(remote.PollInterval) != time.Minute {
+		t.Errorf("unexpected instance %+v", remote)
+	}
+	if cfg.Janitor.Tags.Exclude[0] != "keep" || cfg.Janitor.Categories != nil {
+		t.Errorf("unexpected janitor %+v", cfg.Janitor)
+	}
+	if rules := cfg.Reconciler.SpeedRules; len(rules) != 1 || rules[0].Category != "tv" || rules[0].UploadLimit != 1024 {
+		t.Errorf("unexpected speed rules %+v", rules)
+	}
+}
+
+func TestParseYAML_Errors(t *testing.T) {
+	tests := []struct {
+		config       string
+		line, column int
+		field        string
+		expected     string
+	}{
+		{
+			config:   "instances:\n  - url: a\n  - username: b\n",
+			line:     3,
+			column:   5,
+			field:    "instances[1].url",
+			expected: "config: 3:5: instances[1].url: required",
+		},
+		{
+			config:   "instances:\n  - url: a\n    poll_interval: 3x\n",
+			line:     3,
+			column:   5,
+			field:    "instances[0].poll_interval",
+			expected: `config: 3:5: instances[0].poll_interval: invalid duration "3x", use a string such as "30s"`,
+		},
+		{
+			config:   "instances:\n  - url: a\nreconciler:\n  interval: 1m\n  speed_rules:\n    - upload_limit: -5\n",
+			line:     6,
+			column:   7,
+			field:    "reconciler.speed_rules[0].upload_limit",
+			expected: "config: 6:7: reconciler.speed_rules[0].upload_limit: must not be negative",
+		},
+		{
+			config:   "instances:\n  - url: a\njanitor: {}\njanitor: {}\n",
+			line:     4,
+			expected: `config: 4: mapping key "janitor" already defined at line 3`,
+		},
+		{
+			config:   "instances:\n  - url: a\n    user: b\n",
+			expected: `config: unknown field "user"`,
+		},
+		{
+			config:   "instances: []\n",
+			line:     1,
+			column:   1,
+			field:    "instances",
+			expected: "config: 1:1: instances: at least one instance is required",
+		},
+	}
+	for _, test := range tests {
+		_, err := ParseYAML([]byte(test.config))
+		var cfgErr *Error
+		if !errors.As(err, &cfgErr) {
+			t.Errorf("%s: expected *Error, got %v", test.config, err)
+			continue
+		}
+		if cfgErr.Line != test.line || cfgErr.Column != test.column || cfgErr.Field != test.field || err.Error() != test.expected {
+			t.Errorf("%s: expected %q at %d:%d in %q, got %q at %d:%d in %q", test.config,
+				test.expected, test.line, test.column, test.field, err.Error(), cfgErr.Line, cfgErr.Column, cfgErr.Field)
+		}
+	}
+}
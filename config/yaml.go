@@ -0,0 +1,106 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseYAML decodes and validates a config written in YAML, like Parse. The
+// document has the structure of the JSON config, e.g.
+//
+//	instances:
+//	  - url: http://localhost:8080
+//	    username: admin
+//	    password_env: QBT_PASSWORD
+//	janitor:
+//	  interval: 1h
+//	  tags: {}
+func ParseYAML(data []byte) (*Config, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, yamlError(err)
+	}
+	var value interface{}
+	if err := doc.Decode(&value); err != nil {
+		return nil, yamlError(err)
+	}
+	// Mappings with keys other than strings can't be converted
+	converted, err := json.Marshal(value)
+	if err != nil {
+		return nil, &Error{Msg: "keys must be strings"}
+	}
+
+	cfg, err := Parse(converted)
+	var cfgErr *Error
+	if errors.As(err, &cfgErr) {
+		// Positions in the converted document mean nothing to the reader
+		cfgErr.Line, cfgErr.Column = 0, 0
+		if node := yamlField(&doc, cfgErr.Field); node != nil {
+			cfgErr.Line, cfgErr.Column = node.Line, node.Column
+		}
+	}
+	return cfg, err
+}
+
+// yamlLine matches the line of the errors of the YAML decoder
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// yamlError converts a YAML decoding error into an Error
+func yamlError(err error) error {
+	msg := strings.TrimPrefix(err.Error(), "yaml: ")
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		msg = typeErr.Errors[0]
+	}
+	if m := yamlLine.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return &Error{Line: line, Msg: m[2]}
+	}
+	return &Error{Msg: msg}
+}
+
+// yamlPathElem matches the keys and indexes of a field path
+var yamlPathElem = regexp.MustCompile(`[^.\[\]]+|\[\d+\]`)
+
+// yamlField returns the node of field, such as "instances[0].url", or of the
+// closest enclosing field present in doc. Keys are located at the key.
+func yamlField(doc *yaml.Node, field string) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	var found *yaml.Node
+	node := doc.Content[0]
+	for _, elem := range yamlPathElem.FindAllString(field, -1) {
+		var next, at *yaml.Node
+		if index, ok := strings.CutPrefix(elem, "["); ok {
+			i, _ := strconv.Atoi(strings.TrimSuffix(index, "]"))
+			if node.Kind == yaml.SequenceNode && i < len(node.Content) {
+				next, at = node.Content[i], node.Content[i]
+			}
+		} else if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == elem {
+					next, at = node.Content[i+1], node.Content[i]
+					break
+				}
+			}
+		}
+		if next == nil {
+			break
+		}
+		node, found = next, at
+	}
+	return found
+}
+
+// isYAML reports whether path names a YAML file
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
@@ -0,0 +1,173 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ContentState says how much of its content a torrent has
+type ContentState string
+
+const (
+	// ContentComplete torrents have every file
+	ContentComplete ContentState = "complete"
+	// ContentPartialSeed torrents have every wanted file but skip others
+	ContentPartialSeed ContentState = "partialSeed"
+	// ContentIncomplete torrents are missing parts of wanted files
+	ContentIncomplete ContentState = "incomplete"
+)
+
+// ContentReport describes the content of a torrent
+type ContentReport struct {
+	Hash         InfoHash
+	Name         string
+	State        ContentState
+	Availability float64 // distributed copies of the torrent in the swarm, see TorrentInfo
+	Size         int64   // of all files
+	SkippedSize  int64   // of the skipped files
+	Skipped      []TorrentFile
+	Incomplete   []TorrentFile // wanted files that aren't fully downloaded
+}
+
+// SwarmComplete reports whether the swarm has a complete copy, so that skipped
+// files can still be downloaded
+func (r *ContentReport) SwarmComplete() bool {
+	return r.Availability >= 1
+}
+
+// NewContentReport describes the content of torrent from its files
+func NewContentReport(torrent TorrentInfo, files []TorrentFile) ContentReport {
+	report := ContentReport{
+		Hash:         torrent.Hash,
+		Name:         torrent.Name,
+		Availability: torrent.Availability,
+	}
+	for _, file := range files {
+		report.Size += file.Size
+		switch {
+		case file.Priority == FilePrioritySkip:
+			report.Skipped = append(report.Skipped, file)
+			report.SkippedSize += file.Size
+		case file.Progress < 1:
+			report.Incomplete = append(report.Incomplete, file)
+		}
+	}
+
+	switch {
+	case len(report.Incomplete) > 0:
+		report.State = ContentIncomplete
+	case len(report.Skipped) > 0:
+		report.State = ContentPartialSeed
+	default:
+		report.State = ContentComplete
+	}
+	return report
+}
+
+// ContentReportCtx reports on the content of the torrents with the given
+// hashes, or of all torrents without any. It makes a files request per torrent.
+func (c *Client) ContentReportCtx(ctx context.Context, hashes ...string) ([]ContentReport, error) {
+	torrents, err := c.TorrentsInfoCtx(ctx, &TorrentsInfoParams{Hashes: hashes})
+	if err != nil {
+		return nil, fmt.Errorf("ContentReport error: %w", err)
+	}
+
+	reports := make([]ContentReport, 0, len(torrents))
+	for _, torrent := range torrents {
+		files, err := c.TorrentsFilesCtx(ctx, string(torrent.Hash))
+		if err != nil {
+			return nil, fmt.Errorf("ContentReport error: %w", err)
+		}
+		reports = append(reports, NewContentReport(torrent, files))
+	}
+	return reports, nil
+}
+
+// ContentStatus is the progress of the content of a torrent, combining its
+// files, piece states and properties
+type ContentStatus struct {
+	Hash      InfoHash
+	Name      string
+	PieceSize int64
+	Pieces    []PieceState
+	Size      int64 // of all files
+	Completed int64 // bytes of the files in downloaded pieces
+	Files     []FileStatus
+}
+
+// FileStatus is the progress of a file of a torrent. Its Availability is the
+// distributed copies of the file in the swarm.
+type FileStatus struct {
+	TorrentFile
+	Offset      int64 // of the file in the torrent's data
+	Completed   int64 // bytes of the file in downloaded pieces
+	Frontier    int64 // bytes downloaded contiguously from the start, see PieceMap.Frontier
+	Pieces      int   // overlapping the file
+	Downloaded  int   // pieces overlapping the file that are downloaded
+	Downloading int   // pieces overlapping the file that are being downloaded
+}
+
+// Complete reports whether every piece of the file is downloaded
+func (f *FileStatus) Complete() bool {
+	return f.Completed == f.Size
+}
+
+// NewContentStatus computes the progress of the files of a torrent from its
+// piece states, see PieceMap
+func NewContentStatus(properties TorrentsProperties, files []TorrentFile, pieces []PieceState) ContentStatus {
+	status := ContentStatus{
+		Hash:      InfoHash(properties.Hash),
+		Name:      properties.Name,
+		PieceSize: properties.PieceSize,
+		Pieces:    pieces,
+		Files:     make([]FileStatus, len(files)),
+	}
+	for i, file := range files {
+		status.Files[i].TorrentFile = file
+	}
+	sort.SliceStable(status.Files, func(i, j int) bool { return status.Files[i].Index < status.Files[j].Index })
+
+	m := NewPieceMap(files, properties.PieceSize)
+	for i := range status.Files {
+		file := &status.Files[i]
+		file.Offset, _ = m.Offset(file.Index)
+		m.eachPiece(file.Index, pieces, func(start, end int64, state PieceState) bool {
+			file.Pieces++
+			switch state {
+			case PieceDownloaded:
+				file.Downloaded++
+				file.Completed += end - start
+			case PieceDownloading:
+				file.Downloading++
+			}
+			return true
+		})
+		file.Frontier = m.Frontier(file.Index, pieces)
+		status.Size += file.Size
+		status.Completed += file.Completed
+	}
+	return status
+}
+
+// ContentStatusCtx returns the progress of the files of a torrent, see
+// NewContentStatus
+func (c *Client) ContentStatusCtx(ctx context.Context, hash string) (*ContentStatus, error) {
+	properties, err := c.TorrentsPropertiesCtx(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("ContentStatus error: %w", err)
+	}
+	files, err := c.TorrentsFilesCtx(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("ContentStatus error: %w", err)
+	}
+	pieces, err := c.TorrentsPieceStatesCtx(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("ContentStatus error: %w", err)
+	}
+	if properties.Hash == "" {
+		properties.Hash = hash
+	}
+	status := NewContentStatus(*properties, files, pieces)
+	return &status, nil
+}
@@ -0,0 +1,145 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewContentReport(t *testing.T) {
+	torrent := TorrentInfo{Hash: "abc", Name: "show", Availability: 0.8}
+	tests := []struct {
+		name     string
+		files    []TorrentFile
+		expected ContentState
+		skipped  int64
+	}{
+		{
+			name:     "complete",
+			files:    []TorrentFile{{Size: 10, Progress: 1, Priority: FilePriorityNormal}},
+			expected: ContentComplete,
+		},
+		{
+			name: "partial seed",
+			files: []TorrentFile{
+				{Size: 10, Progress: 1, Priority: FilePriorityNormal},
+				{Size: 5, Progress: 0.2, Priority: FilePrioritySkip},
+			},
+			expected: ContentPartialSeed,
+			skipped:  5,
+		},
+		{
+			name: "incomplete",
+			files: []TorrentFile{
+				{Size: 10, Progress: 0.5, Priority: FilePriorityHigh},
+				{Size: 5, Priority: FilePrioritySkip},
+			},
+			expected: ContentIncomplete,
+			skipped:  5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewContentReport(torrent, tt.files)
+			if report.State != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, report.State)
+			}
+			if report.SkippedSize != tt.skipped {
+				t.Errorf("expected %d skipped bytes, got %d", tt.skipped, report.SkippedSize)
+			}
+			if report.SwarmComplete() {
+				t.Errorf("expected the swarm to be incomplete at availability %v", report.Availability)
+			}
+		})
+	}
+}
+
+func TestContentReportCtx(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/torrents/info":
+			w.Write([]byte(`[{"hash":"a","name":"one","availability":2},{"hash":"b","name":"two","availability":1.5}]`))
+		case "/api/v2/torrents/files":
+			if r.URL.Query().Get("hash") == "a" {
+				w.Write([]byte(`[{"index":0,"size":10,"progress":1,"priority":1}]`))
+				return
+			}
+			w.Write([]byte(`[{"index":0,"size":10,"progress":1,"priority":1},{"index":1,"size":4,"progress":0,"priority":0}]`))
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	reports, err := client.ContentReportCtx(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(reports) != 2 || reports[0].State != ContentComplete || reports[1].State != ContentPartialSeed {
+		t.Fatalf("unexpected reports %+v", reports)
+	}
+	if !reports[1].SwarmComplete() || reports[1].Size != 14 {
+		t.Errorf("expected a 14 byte partial seed with a complete swarm, got %+v", reports[1])
+	}
+}
+
+func TestNewContentStatus(t *testing.T) {
+	properties := TorrentsProperties{Hash: "abc", Name: "show", PieceSize: 10}
+	files := []TorrentFile{
+		{Index: 0, Name: "a.mkv", Size: 25, PieceRange: []int{0, 2}},
+		{Index: 1, Name: "b.nfo", Size: 3, PieceRange: []int{2, 2}},
+		// after a pad file aligning it to piece 3
+		{Index: 2, Name: "c.mkv", Size: 12, PieceRange: []int{3, 4}},
+		{Index: 3, Name: "empty", Size: 0, PieceRange: []int{4, 4}},
+	}
+	pieces := []PieceState{PieceDownloaded, PieceDownloading, PieceDownloaded, PieceDownloaded, PieceDownloaded}
+
+	status := NewContentStatus(properties, files, pieces)
+	if status.Hash != "abc" || status.Size != 40 || status.Completed != 30 {
+		t.Errorf("unexpected status %+v", status)
+	}
+	expected := []struct {
+		offset, completed               int64
+		pieces, downloaded, downloading int
+		complete                        bool
+	}{
+		{0, 15, 3, 2, 1, false},
+		{25, 3, 1, 1, 0, true},
+		{30, 12, 2, 2, 0, true},
+		{42, 0, 0, 0, 0, true},
+	}
+	for i, file := range status.Files {
+		e := expected[i]
+		if file.Offset != e.offset || file.Completed != e.completed || file.Pieces != e.pieces ||
+			file.Downloaded != e.downloaded || file.Downloading != e.downloading || file.Complete() != e.complete {
+			t.Errorf("file %s: expected %+v, got %+v", file.Name, e, file)
+		}
+	}
+}
+
+func TestContentStatusCtx(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("hash") != "abc" {
+			t.Errorf("expected hash abc, got %s", r.URL.RawQuery)
+		}
+		switch r.URL.Path {
+		case "/api/v2/torrents/properties":
+			w.Write([]byte(`{"name":"show","piece_size":10}`))
+		case "/api/v2/torrents/files":
+			w.Write([]byte(`[{"index":0,"name":"a.mkv","size":15,"piece_range":[0,1],"availability":0.5}]`))
+		case "/api/v2/torrents/pieceStates":
+			w.Write([]byte(`[2,0]`))
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	status, err := client.ContentStatusCtx(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.Hash != "abc" || status.Completed != 10 || len(status.Files) != 1 || status.Files[0].Availability != 0.5 {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
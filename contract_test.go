@@ -0,0 +1,221 @@
+package qbittorrent_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cehbz/qbittorrent"
+	"github.com/cehbz/qbittorrent/qbtest"
+)
+
+// contractVersions are the qBittorrent versions whose API the client is kept
+// compatible with. Their payloads are qbtest.Recorder captures of real
+// servers in testdata/contract/<version>.json; versions without a capture are
+// skipped. To capture a server, run
+//
+//	QBT_CONTRACT_URL=http://localhost:8080 QBT_CONTRACT_USERNAME=admin \
+//	QBT_CONTRACT_PASSWORD=... go test -run TestContract .
+//
+// against an instance with at least one torrent, and commit the file written
+// for its version.
+var contractVersions = []string{"4.3", "4.6", "5.0", "5.1"}
+
+// contractPath returns the capture file of a qBittorrent version
+func contractPath(version string) string {
+	return filepath.Join("testdata", "contract", version+".json")
+}
+
+// runContract performs the requests covered by the contract tests
+func runContract(ctx context.Context, client *qbittorrent.Client) error {
+	if _, err := client.AppVersionCtx(ctx); err != nil {
+		return err
+	}
+	if _, err := client.SyncMainDataCtx(ctx, 0); err != nil {
+		return err
+	}
+	torrents, err := client.TorrentsInfoCtx(ctx)
+	if err != nil {
+		return err
+	}
+	if len(torrents) > 0 {
+		if _, err := client.TorrentsPropertiesCtx(ctx, string(torrents[0].Hash)); err != nil {
+			return err
+		}
+	}
+	_, err = client.AppPreferencesCtx(ctx)
+	return err
+}
+
+// recordContract captures the contract requests against the server given by
+// the environment and saves them for its version
+func recordContract(t *testing.T, baseURL string) {
+	t.Helper()
+	recorder := &qbtest.Recorder{}
+	client, err := qbittorrent.NewClientWithOptions(os.Getenv("QBT_CONTRACT_USERNAME"), os.Getenv("QBT_CONTRACT_PASSWORD"), "", "",
+		qbittorrent.WithBaseURL(baseURL),
+		qbittorrent.WithHTTPClient(&http.Client{Transport: recorder}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	ctx := context.Background()
+	version, err := client.AppVersionCtx(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := runContract(ctx, client); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		t.Fatalf("unexpected version %q", version)
+	}
+	path := contractPath(parts[0] + "." + parts[1])
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	t.Logf("recorded qBittorrent %s to %s", version, path)
+}
+
+// recordedBody returns the response body recorded for an endpoint
+func recordedBody(t *testing.T, interactions []qbtest.Interaction, endpoint string) interface{} {
+	t.Helper()
+	for _, interaction := range interactions {
+		if interaction.Request.Path != endpoint {
+			continue
+		}
+		var body interface{}
+		if err := json.Unmarshal([]byte(interaction.Response.Body), &body); err != nil {
+			t.Fatalf("failed to decode %s: %v", endpoint, err)
+		}
+		return body
+	}
+	t.Fatalf("no recorded response for %s", endpoint)
+	return nil
+}
+
+// assertFieldsPreserved checks that every field of decoded that is present in
+// the recorded payload holds its value, except for the skipped keys
+func assertFieldsPreserved(t *testing.T, recorded map[string]interface{}, decoded interface{}, skip ...string) {
+	t.Helper()
+	data, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("failed to encode decoded value: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("failed to decode encoded value: %v", err)
+	}
+	for key, value := range fields {
+		expected, ok := recorded[key]
+		if !ok || slices.Contains(skip, key) {
+			continue
+		}
+		if !reflect.DeepEqual(value, expected) {
+			t.Errorf("field %s: expected %v, got %v", key, expected, value)
+		}
+	}
+}
+
+func TestContract(t *testing.T) {
+	if baseURL := os.Getenv("QBT_CONTRACT_URL"); baseURL != "" {
+		recordContract(t, baseURL)
+	}
+
+	for _, version := range contractVersions {
+		t.Run(version, func(t *testing.T) {
+			data, err := os.ReadFile(contractPath(version))
+			if errors.Is(err, fs.ErrNotExist) {
+				t.Skipf("no capture of qBittorrent %s", version)
+			}
+			if err != nil {
+				t.Fatalf("failed to read capture: %v", err)
+			}
+			var interactions []qbtest.Interaction
+			if err := json.Unmarshal(data, &interactions); err != nil {
+				t.Fatalf("failed to decode capture: %v", err)
+			}
+
+			client, err := qbittorrent.NewClientWithOptions("", "", "", "",
+				qbittorrent.WithBaseURL("http://qbittorrent"),
+				qbittorrent.WithHTTPClient(&http.Client{Transport: qbtest.NewReplayer(interactions)}),
+				qbittorrent.WithBypassAuth(),
+			)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			ctx := context.Background()
+
+			t.Run("maindata", func(t *testing.T) {
+				data, err := client.SyncMainDataCtx(ctx, 0)
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				recorded := recordedBody(t, interactions, "/api/v2/sync/maindata").(map[string]interface{})
+				if !data.FullUpdate {
+					t.Errorf("expected a full update, got %+v", data)
+				}
+				torrents, _ := recorded["torrents"].(map[string]interface{})
+				if len(data.Torrents) != len(torrents) {
+					t.Errorf("expected %d torrents, got %d", len(torrents), len(data.Torrents))
+				}
+				for hash, torrent := range data.Torrents {
+					assertFieldsPreserved(t, torrents[hash].(map[string]interface{}), torrent)
+				}
+				if serverState, ok := recorded["server_state"].(map[string]interface{}); ok {
+					assertFieldsPreserved(t, serverState, data.ServerState)
+				}
+			})
+
+			t.Run("torrents_info", func(t *testing.T) {
+				torrents, err := client.TorrentsInfoCtx(ctx)
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				recorded := recordedBody(t, interactions, "/api/v2/torrents/info").([]interface{})
+				if len(torrents) != len(recorded) {
+					t.Fatalf("expected %d torrents, got %d", len(recorded), len(torrents))
+				}
+				for i, torrent := range torrents {
+					assertFieldsPreserved(t, recorded[i].(map[string]interface{}), torrent)
+				}
+
+				if len(torrents) == 0 {
+					return
+				}
+				properties, err := client.TorrentsPropertiesCtx(ctx, string(torrents[0].Hash))
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				assertFieldsPreserved(t, recordedBody(t, interactions, "/api/v2/torrents/properties").(map[string]interface{}), properties)
+			})
+
+			t.Run("preferences", func(t *testing.T) {
+				prefs, err := client.AppPreferencesCtx(ctx)
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				// proxy_type was numeric before 4.6
+				assertFieldsPreserved(t, recordedBody(t, interactions, "/api/v2/app/preferences").(map[string]interface{}), prefs, "proxy_type")
+				switch prefs.ProxyType {
+				case qbittorrent.ProxyNone, qbittorrent.ProxyHTTP, qbittorrent.ProxySOCKS5, qbittorrent.ProxySOCKS4:
+				default:
+					t.Errorf("unexpected proxy type %q", prefs.ProxyType)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package qbittorrent
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// webAPIEndpoint is an endpoint of testdata/webapi.json, the list of
+// qBittorrent WebAPI endpoints the client is checked against. Endpoints and
+// parameters the client deliberately doesn't support are skipped with a reason.
+type webAPIEndpoint struct {
+	Path       string            `json:"path"`
+	Params     []string          `json:"params"`
+	Skip       string            `json:"skip"`
+	SkipParams map[string]string `json:"skip_params"`
+}
+
+// helperDepth is how deep calls are followed to find the parameters of an
+// endpoint, enough for helpers such as postHashesRenamed calling postHashes
+// without reaching the string literals of the request code
+const helperDepth = 2
+
+// implementedEndpoints returns the endpoints called by the functions of the
+// package, with the string literals of those functions and the helpers they
+// call, which include the parameter names sent to the endpoints
+func implementedEndpoints(t *testing.T) map[string]map[string]bool {
+	t.Helper()
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("failed to parse package: %v", err)
+	}
+
+	// string constants such as authLoginEndpoint count as literals
+	consts := make(map[string]string)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.CONST {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					spec := spec.(*ast.ValueSpec)
+					for i, value := range spec.Values {
+						if lit, ok := value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+							consts[spec.Names[i].Name], _ = strconv.Unquote(lit.Value)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	literals := make(map[string][]string)
+	calls := make(map[string][]string)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Body == nil {
+					continue
+				}
+				name := fn.Name.Name
+				ast.Inspect(fn.Body, func(n ast.Node) bool {
+					switch n := n.(type) {
+					case *ast.BasicLit:
+						if s, err := strconv.Unquote(n.Value); err == nil && n.Kind == token.STRING {
+							literals[name] = append(literals[name], s)
+						}
+					case *ast.Ident:
+						if s, ok := consts[n.Name]; ok {
+							literals[name] = append(literals[name], s)
+						}
+					case *ast.CallExpr:
+						switch f := n.Fun.(type) {
+						case *ast.Ident:
+							calls[name] = append(calls[name], f.Name)
+						case *ast.SelectorExpr:
+							calls[name] = append(calls[name], f.Sel.Name)
+						}
+					}
+					return true
+				})
+			}
+		}
+	}
+
+	endpoints := make(map[string]map[string]bool)
+	for name, lits := range literals {
+		for _, lit := range lits {
+			// prefixes such as "/api/v2/sync/" aren't endpoints
+			if !strings.HasPrefix(lit, apiPrefix) || strings.HasSuffix(lit, "/") {
+				continue
+			}
+			params := endpoints[lit]
+			if params == nil {
+				params = make(map[string]bool)
+				endpoints[lit] = params
+			}
+			collectLiterals(name, helperDepth, literals, calls, params)
+		}
+	}
+	return endpoints
+}
+
+func TestEndpointCoverage(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "webapi.json"))
+	if err != nil {
+		t.Fatalf("failed to read endpoint list: %v", err)
+	}
+	var list struct {
+		Endpoints []webAPIEndpoint `json:"endpoints"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		t.Fatalf("failed to decode endpoint list: %v", err)
+	}
+
+	implemented := implementedEndpoints(t)
+	listed := make(map[string]bool)
+	for _, endpoint := range list.Endpoints {
+		listed[endpoint.Path] = true
+		params, ok := implemented[endpoint.Path]
+		if endpoint.Skip != "" {
+			if ok {
+				t.Errorf("%s is implemented but skipped (%s)", endpoint.Path, endpoint.Skip)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("%s is not implemented", endpoint.Path)
+			continue
+		}
+		for _, param := range endpoint.Params {
+			if !params[param] {
+				t.Errorf("%s: parameter %s is not implemented", endpoint.Path, param)
+			}
+		}
+		for param, reason := range endpoint.SkipParams {
+			if params[param] {
+				t.Errorf("%s: parameter %s is implemented but skipped (%s)", endpoint.Path, param, reason)
+			}
+		}
+	}
+	for endpoint := range implemented {
+		if !listed[endpoint] {
+			t.Errorf("%s is implemented but missing from testdata/webapi.json", endpoint)
+		}
+	}
+}
+
+// collectLiterals adds the string literals of fn and of the functions it calls, up to
+// depth calls deep, to params
+func collectLiterals(fn string, depth int, literals, calls map[string][]string, params map[string]bool) {
+	for _, s := range literals[fn] {
+		params[s] = true
+	}
+	if depth == 0 {
+		return
+	}
+	for _, callee := range calls[fn] {
+		collectLiterals(callee, depth-1, literals, calls, params)
+	}
+}
@@ -0,0 +1,64 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+)
+
+// CredentialsProvider supplies the username and password at login time, so
+// secrets can come from a vault or keychain instead of living in the client
+// for the lifetime of the process
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (username, password string, err error)
+}
+
+// CredentialsFunc adapts a function to a CredentialsProvider
+type CredentialsFunc func(ctx context.Context) (username, password string, err error)
+
+// Credentials implements the CredentialsProvider interface
+func (f CredentialsFunc) Credentials(ctx context.Context) (string, string, error) {
+	return f(ctx)
+}
+
+// WithCredentialsProvider asks provider for the credentials at every login,
+// including re-authentication after the session expired. The username and
+// password given to the constructor or to SetCredentials are ignored.
+func WithCredentialsProvider(provider CredentialsProvider) Option {
+	return func(c *Client) error {
+		if provider == nil {
+			return errors.New("nil credentials provider")
+		}
+		c.credentials = provider
+		return nil
+	}
+}
+
+// loginCredentials returns the credentials to log in with
+func (c *Client) loginCredentials(ctx context.Context) (string, string, error) {
+	if c.credentials != nil {
+		return c.credentials.Credentials(ctx)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.username, c.password, nil
+}
+
+// SetCredentials changes the username and password used for logging in, e.g.
+// after changing the WebUI password through the preferences. The current
+// session is kept until it expires or ReLoginCtx is called. A
+// CredentialsProvider takes precedence over them.
+func (c *Client) SetCredentials(username, password string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.username = username
+	c.password = password
+}
+
+// ReLoginCtx logs in again, replacing the current session, e.g. to check
+// credentials given to SetCredentials right away. Unlike the logins after a
+// session expired it doesn't count against the ReauthPolicy budget.
+func (c *Client) ReLoginCtx(ctx context.Context) error {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	return c.AuthLoginCtx(ctx)
+}
@@ -0,0 +1,91 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetCredentials(t *testing.T) {
+	password := "old"
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/auth/login" {
+			r.ParseForm()
+			if r.FormValue("username") != "admin" || r.FormValue("password") != password {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "SID", Value: "sid-" + password})
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClientWithOptions("admin", "old", "", "", WithBaseURL(mockServer.URL), WithHTTPClient(mockServer.Client()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	ctx := context.Background()
+
+	// The password is changed on the server, e.g. through the preferences
+	password = "new"
+	if err := client.ReLoginCtx(ctx); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected the old password to be refused, got %v", err)
+	}
+	if sid := client.session(); sid != "sid-old" {
+		t.Errorf("expected the session to be kept after a failed login, got %s", sid)
+	}
+
+	client.SetCredentials("admin", "new")
+	if err := client.ReLoginCtx(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if sid := client.session(); sid != "sid-new" {
+		t.Errorf("expected a new session, got %s", sid)
+	}
+}
+
+func TestWithCredentialsProvider(t *testing.T) {
+	var logins int32
+	mockServer := newSessionServer(t, &logins)
+	defer mockServer.Close()
+
+	var calls int
+	provider := CredentialsFunc(func(ctx context.Context) (string, string, error) {
+		calls++
+		return "admin", "from-vault", nil
+	})
+	client, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithCredentialsProvider(provider),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if calls != 1 || client.session() != "sid-1" {
+		t.Fatalf("expected a login with the provided credentials, got %d calls and %q", calls, client.session())
+	}
+	if client.username != "" || client.password != "" {
+		t.Errorf("expected the credentials not to be kept")
+	}
+
+	// The session expires, the provider is asked again
+	client.mu.Lock()
+	client.sid = "expired"
+	client.mu.Unlock()
+	if _, err := client.TorrentsInfoCtx(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the provider to be asked at every login, got %d calls", calls)
+	}
+
+	failing := CredentialsFunc(func(ctx context.Context) (string, string, error) {
+		return "", "", errors.New("vault sealed")
+	})
+	if _, err := NewClientWithOptions("", "", "", "", WithBaseURL(mockServer.URL), WithCredentialsProvider(failing)); err == nil {
+		t.Errorf("expected the provider error, got none")
+	}
+}
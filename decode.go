@@ -0,0 +1,163 @@
+package qbittorrent
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UnknownFieldsError is returned in strict decoding mode when a response
+// contains fields that the target type doesn't map
+type UnknownFieldsError struct {
+	Endpoint string
+	Fields   []string
+}
+
+func (e *UnknownFieldsError) Error() string {
+	return fmt.Sprintf("%s: unknown fields in response: %s", e.Endpoint, strings.Join(e.Fields, ", "))
+}
+
+// extraKnownFields lists keys consumed by custom unmarshalers rather than by struct tags
+var extraKnownFields = map[reflect.Type][]string{
+	reflect.TypeOf(TorrentInfo{}): {"tags"},
+}
+
+// decodeJSON decodes a response body from endpoint into v, rejecting unknown
+// fields in strict mode, and passes the time taken to the metrics sink
+func (c *Client) decodeJSON(endpoint string, data []byte, v interface{}) error {
+	defer c.observeDecode(endpoint, c.Clock().Now())
+	if err := c.checkFields(endpoint, data, v); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// checkFields rejects the fields of a response body from endpoint that the
+// type of v doesn't map in strict mode, or reports them to the drift detector
+func (c *Client) checkFields(endpoint string, data []byte, v interface{}) error {
+	if c.strictDecoding {
+		fields, err := UnknownFields(data, v)
+		if err != nil {
+			return err
+		}
+		if len(fields) > 0 {
+			return &UnknownFieldsError{Endpoint: endpoint, Fields: fields}
+		}
+	} else if c.drift != nil {
+		// invalid documents fail below
+		if fields, err := UnknownFields(data, v); err == nil && len(fields) > 0 {
+			c.drift.observe(c, endpoint, fields)
+		}
+	}
+	return nil
+}
+
+// UnknownFields reports the fields of the JSON document data that have no
+// counterpart in the type of v, so structs can be kept up to date with the
+// server. Paths use "*" for map values and "[]" for slice elements, e.g.
+// "torrents.*.popularity".
+func UnknownFields(data []byte, v interface{}) ([]string, error) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{})
+	collectUnknownFields(doc, reflect.TypeOf(v), "", seen)
+
+	fields := make([]string, 0, len(seen))
+	for field := range seen {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// collectUnknownFields walks doc alongside typ and records unmapped object keys
+func collectUnknownFields(doc interface{}, typ reflect.Type, path string, seen map[string]struct{}) {
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := jsonFields(typ)
+		for key, value := range obj {
+			fieldType, known := fields[key]
+			if !known {
+				seen[joinPath(path, key)] = struct{}{}
+				continue
+			}
+			collectUnknownFields(value, fieldType, joinPath(path, key), seen)
+		}
+	case reflect.Map:
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, value := range obj {
+			collectUnknownFields(value, typ.Elem(), joinPath(path, "*"), seen)
+		}
+	case reflect.Slice, reflect.Array:
+		list, ok := doc.([]interface{})
+		if !ok {
+			return
+		}
+		for _, value := range list {
+			collectUnknownFields(value, typ.Elem(), path+"[]", seen)
+		}
+	}
+}
+
+// jsonFields maps the JSON keys of a struct type to their field types,
+// including fields promoted from embedded structs
+func jsonFields(typ reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for _, key := range extraKnownFields[typ] {
+		// Custom unmarshalers handle these keys, don't descend into them
+		fields[key] = nil
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for key, fieldType := range jsonFields(embedded) {
+					fields[key] = fieldType
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
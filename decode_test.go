@@ -0,0 +1,80 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestUnknownFields(t *testing.T) {
+	data := []byte(`{
+		"rid": 1,
+		"new_top_level": true,
+		"server_state": {"alltime_dl": 1, "last_external_address_v4": "203.0.113.7"},
+		"torrents": {
+			"hash1": {"name": "a", "tags": "x, y", "popularity": 0.5},
+			"hash2": {"name": "b", "popularity": 0.1, "root_path": "/data/b"}
+		},
+		"categories": {"movies": {"name": "movies", "savePath": "/data"}}
+	}`)
+
+	fields, err := UnknownFields(data, &MainData{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []string{
+		"new_top_level",
+		"server_state.last_external_address_v4",
+		"torrents.*.popularity",
+		"torrents.*.root_path",
+	}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected %v, got %v", expected, fields)
+	}
+
+	fields, err = UnknownFields([]byte(`[{"url": "http://tracker", "status": 2, "next_announce": 60}]`), &[]TrackerInfo{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(fields, []string{"[].next_announce"}) {
+		t.Errorf("expected [[].next_announce], got %v", fields)
+	}
+}
+
+func TestWithStrictDecoding(t *testing.T) {
+	torrents := []byte(`[{"hash":"abc","name":"a","popularity":0.5,"root_path":"/data/a"}]`)
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(torrents)
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	tolerant := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	if _, err := tolerant.TorrentsInfoCtx(ctx); err != nil {
+		t.Fatalf("expected no error in tolerant mode, got %v", err)
+	}
+
+	strict, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithStrictDecoding(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	_, err = strict.TorrentsInfoCtx(ctx)
+	var unknown *UnknownFieldsError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("expected UnknownFieldsError, got %v", err)
+	}
+	if unknown.Endpoint != "/api/v2/torrents/info" {
+		t.Errorf("expected endpoint /api/v2/torrents/info, got %s", unknown.Endpoint)
+	}
+
+	if expected := []string{"[].popularity", "[].root_path"}; !reflect.DeepEqual(unknown.Fields, expected) {
+		t.Errorf("expected fields %v, got %v", expected, unknown.Fields)
+	}
+}
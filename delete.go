@@ -0,0 +1,85 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrDeleteNotConfirmed is returned by DeleteTorrentsCtx when the
+// confirmation callback declines the deletion
+var ErrDeleteNotConfirmed = errors.New("deletion not confirmed")
+
+// DeletePlan describes the torrents DeleteTorrentsCtx is about to delete
+type DeletePlan struct {
+	Torrents    []TorrentInfo
+	DeleteFiles bool
+	TotalBytes  int64 // downloaded data removed from disk, zero unless DeleteFiles
+}
+
+// Names returns the names of the torrents
+func (p DeletePlan) Names() []string {
+	names := make([]string, len(p.Torrents))
+	for i, torrent := range p.Torrents {
+		names[i] = torrent.Name
+	}
+	return names
+}
+
+// DeleteOptions configures DeleteTorrentsCtx
+type DeleteOptions struct {
+	// Confirm, if set, is called with the resolved torrents before deleting
+	// them, e.g. to ask "are you sure?" or to log what is deleted. Returning
+	// false cancels the deletion with ErrDeleteNotConfirmed.
+	Confirm func(DeletePlan) bool
+}
+
+// DeleteTorrentsCtx deletes the torrents, and their data if deleteFiles is
+// true, and returns what was deleted. The hashes are resolved first; unknown
+// ones are ignored and only the resolved torrents are deleted, so AllTorrents
+// doesn't delete torrents added after the confirmation. Like other
+// destructive calls AllTorrents requires WithAllowAllTorrents.
+func (c *Client) DeleteTorrentsCtx(ctx context.Context, hashes []string, deleteFiles bool, opts DeleteOptions) (*DeletePlan, error) {
+	joined, err := joinHashes(hashes)
+	if err != nil {
+		return nil, fmt.Errorf("DeleteTorrents error: %w", err)
+	}
+	var params *TorrentsInfoParams
+	if joined == AllTorrents {
+		if !c.allowAllTorrents {
+			return nil, fmt.Errorf("DeleteTorrents error: %w", ErrAllTorrentsNotAllowed)
+		}
+	} else {
+		params = &TorrentsInfoParams{Hashes: hashes}
+	}
+	torrents, err := c.TorrentsInfoCtx(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("DeleteTorrents error: %w", err)
+	}
+
+	plan := &DeletePlan{Torrents: torrents, DeleteFiles: deleteFiles}
+	resolved := make([]string, len(torrents))
+	for i, torrent := range torrents {
+		resolved[i] = string(torrent.Hash)
+		if deleteFiles {
+			plan.TotalBytes += torrent.Completed
+		}
+	}
+	if len(torrents) == 0 {
+		return plan, nil
+	}
+	if opts.Confirm != nil && !opts.Confirm(*plan) {
+		return nil, ErrDeleteNotConfirmed
+	}
+
+	data := url.Values{}
+	data.Set("hashes", strings.Join(resolved, "|"))
+	data.Set("deleteFiles", strconv.FormatBool(deleteFiles))
+	if _, err := c.doPostValuesCtx(ctx, "/api/v2/torrents/delete", data); err != nil {
+		return nil, fmt.Errorf("DeleteTorrents error: %w", err)
+	}
+	return plan, nil
+}
@@ -0,0 +1,65 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDeleteTorrentsCtx(t *testing.T) {
+	var deletes []string
+	var query string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/torrents/info":
+			query = r.URL.Query().Get("hashes")
+			w.Write([]byte(`[{"hash":"a","name":"one","completed":100},{"hash":"b","name":"two","completed":50}]`))
+		case "/api/v2/torrents/delete":
+			r.ParseForm()
+			deletes = append(deletes, r.FormValue("hashes")+" "+r.FormValue("deleteFiles"))
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+	ctx := context.Background()
+
+	var confirmed DeletePlan
+	plan, err := client.DeleteTorrentsCtx(ctx, []string{"a", "b", "unknown"}, true, DeleteOptions{
+		Confirm: func(plan DeletePlan) bool {
+			confirmed = plan
+			return true
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if query != "a|b|unknown" || !reflect.DeepEqual(confirmed.Names(), []string{"one", "two"}) || confirmed.TotalBytes != 150 {
+		t.Errorf("expected the resolved torrents to be confirmed, got %+v", confirmed)
+	}
+	if plan.TotalBytes != 150 || !reflect.DeepEqual(deletes, []string{"a|b true"}) {
+		t.Errorf("expected the resolved torrents to be deleted, got %v", deletes)
+	}
+
+	deletes = nil
+	_, err = client.DeleteTorrentsCtx(ctx, []string{"a"}, false, DeleteOptions{Confirm: func(DeletePlan) bool { return false }})
+	if !errors.Is(err, ErrDeleteNotConfirmed) || len(deletes) != 0 {
+		t.Errorf("expected the deletion to be cancelled, got %v and %v", err, deletes)
+	}
+
+	if _, err := client.DeleteTorrentsCtx(ctx, []string{AllTorrents}, false, DeleteOptions{}); !errors.Is(err, ErrAllTorrentsNotAllowed) {
+		t.Errorf("expected ErrAllTorrentsNotAllowed, got %v", err)
+	}
+	client.allowAllTorrents = true
+	query = "unset"
+	plan, err = client.DeleteTorrentsCtx(ctx, []string{AllTorrents}, false, DeleteOptions{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// All torrents are resolved and deleted by hash, not with "all"
+	if query != "" || plan.TotalBytes != 0 || !reflect.DeepEqual(deletes, []string{"a|b false"}) {
+		t.Errorf("expected all torrents to be deleted by hash, got %q and %v", query, deletes)
+	}
+}
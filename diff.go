@@ -0,0 +1,147 @@
+package qbittorrent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cehbz/qbittorrent/metainfo"
+)
+
+// TorrentDiff lists the discrepancies between a local .torrent file and the
+// torrent on the server. Added means only present on the server, removed
+// means only present locally.
+type TorrentDiff struct {
+	LocalHash        string
+	RemoteHash       string
+	TrackersAdded    []string
+	TrackersRemoved  []string
+	FilesAdded       []string
+	FilesRemoved     []string
+	FilesResized     []string // files present on both sides with different sizes
+	PieceLength      [2]int64 // local and remote piece length, if they differ
+	PiecesDifferent  []int    // indexes of pieces with different hashes
+	PieceCountLocal  int
+	PieceCountRemote int
+}
+
+// Equal reports whether no discrepancies were found
+func (d *TorrentDiff) Equal() bool {
+	return d.LocalHash == d.RemoteHash &&
+		len(d.TrackersAdded) == 0 && len(d.TrackersRemoved) == 0 &&
+		len(d.FilesAdded) == 0 && len(d.FilesRemoved) == 0 && len(d.FilesResized) == 0 &&
+		d.PieceLength == [2]int64{} && len(d.PiecesDifferent) == 0 &&
+		d.PieceCountLocal == d.PieceCountRemote
+}
+
+func (d *TorrentDiff) String() string {
+	if d.Equal() {
+		return "no differences"
+	}
+	var lines []string
+	if d.LocalHash != d.RemoteHash {
+		lines = append(lines, fmt.Sprintf("infohash: local %s, remote %s", d.LocalHash, d.RemoteHash))
+	}
+	list := func(label string, items []string) {
+		if len(items) > 0 {
+			lines = append(lines, fmt.Sprintf("%s: %s", label, strings.Join(items, ", ")))
+		}
+	}
+	list("trackers added", d.TrackersAdded)
+	list("trackers removed", d.TrackersRemoved)
+	list("files added", d.FilesAdded)
+	list("files removed", d.FilesRemoved)
+	list("files resized", d.FilesResized)
+	if d.PieceLength != [2]int64{} {
+		lines = append(lines, fmt.Sprintf("piece length: local %d, remote %d", d.PieceLength[0], d.PieceLength[1]))
+	}
+	if d.PieceCountLocal != d.PieceCountRemote {
+		lines = append(lines, fmt.Sprintf("pieces: local %d, remote %d", d.PieceCountLocal, d.PieceCountRemote))
+	}
+	if len(d.PiecesDifferent) > 0 {
+		lines = append(lines, fmt.Sprintf("%d piece hashes differ", len(d.PiecesDifferent)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DiffTorrentCtx compares a local .torrent file with the torrent exported by
+// the server for hash, e.g. to verify a migration or tracker edits
+func (c *Client) DiffTorrentCtx(ctx context.Context, hash string, local *metainfo.MetaInfo) (*TorrentDiff, error) {
+	data, err := c.TorrentsExportCtx(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("DiffTorrent error: %w", err)
+	}
+	remote, err := metainfo.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("DiffTorrent error: %w", err)
+	}
+	return DiffMetaInfo(local, remote), nil
+}
+
+// DiffMetaInfo compares two .torrent files
+func DiffMetaInfo(local, remote *metainfo.MetaInfo) *TorrentDiff {
+	d := &TorrentDiff{
+		LocalHash:        local.InfoHash(),
+		RemoteHash:       remote.InfoHash(),
+		PieceCountLocal:  local.Info.NumPieces(),
+		PieceCountRemote: remote.Info.NumPieces(),
+	}
+	d.TrackersAdded, d.TrackersRemoved = diffStrings(local.Trackers(), remote.Trackers())
+
+	localFiles := fileSizes(local.Info)
+	remoteFiles := fileSizes(remote.Info)
+	var localPaths, remotePaths []string
+	for _, file := range local.Info.FileList() {
+		localPaths = append(localPaths, file.DisplayPath())
+	}
+	for _, file := range remote.Info.FileList() {
+		remotePaths = append(remotePaths, file.DisplayPath())
+	}
+	d.FilesAdded, d.FilesRemoved = diffStrings(localPaths, remotePaths)
+	for _, path := range localPaths {
+		if size, ok := remoteFiles[path]; ok && size != localFiles[path] {
+			d.FilesResized = append(d.FilesResized, path)
+		}
+	}
+
+	if local.Info.PieceLength != remote.Info.PieceLength {
+		d.PieceLength = [2]int64{local.Info.PieceLength, remote.Info.PieceLength}
+	}
+	for i := 0; i < d.PieceCountLocal && i < d.PieceCountRemote; i++ {
+		if !bytes.Equal(local.Info.PieceHash(i), remote.Info.PieceHash(i)) {
+			d.PiecesDifferent = append(d.PiecesDifferent, i)
+		}
+	}
+	return d
+}
+
+// fileSizes maps the file paths of a torrent to their sizes
+func fileSizes(info metainfo.Info) map[string]int64 {
+	sizes := make(map[string]int64)
+	for _, file := range info.FileList() {
+		sizes[file.DisplayPath()] = file.Length
+	}
+	return sizes
+}
+
+// diffStrings returns the strings only in b, and those only in a, in order
+func diffStrings(a, b []string) (added, removed []string) {
+	inA := make(map[string]bool, len(a))
+	for _, s := range a {
+		inA[s] = true
+	}
+	inB := make(map[string]bool, len(b))
+	for _, s := range b {
+		inB[s] = true
+		if !inA[s] {
+			added = append(added, s)
+		}
+	}
+	for _, s := range a {
+		if !inB[s] {
+			removed = append(removed, s)
+		}
+	}
+	return added, removed
+}
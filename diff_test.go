@@ -0,0 +1,67 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cehbz/qbittorrent/metainfo"
+)
+
+func TestDiffTorrent(t *testing.T) {
+	// The server's copy has another tracker, a renamed and a resized file,
+	// and a different piece hash
+	remoteTorrent := "d8:announce42:https://other.example.org/PASSKEY/announce" +
+		"4:infod5:filesld6:lengthi10e4:pathl5:a.mkveed6:lengthi6e4:pathl5:c.nfoeee" +
+		"4:name4:Show12:piece lengthi16384e6:pieces20:bbbbbbbbbbbbbbbbbbbb7:privatei1eee"
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.FormValue("hash") {
+		case "same":
+			w.Write([]byte(testTorrentFile))
+		case "other":
+			w.Write([]byte(remoteTorrent))
+		}
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	local, err := metainfo.Parse([]byte(testTorrentFile))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	diff, err := client.DiffTorrentCtx(context.Background(), "same", local)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !diff.Equal() {
+		t.Errorf("expected no differences, got %s", diff)
+	}
+
+	diff, err = client.DiffTorrentCtx(context.Background(), "other", local)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if diff.Equal() {
+		t.Fatalf("expected differences, got none")
+	}
+	lines := strings.Split(diff.String(), "\n")
+	expected := []string{
+		"trackers added: https://other.example.org/PASSKEY/announce",
+		"trackers removed: http://tracker.example.org/PASSKEY/announce",
+		"files added: Show/c.nfo",
+		"files removed: Show/b.nfo",
+		"1 piece hashes differ",
+	}
+	// The first line reports the infohash mismatch
+	if len(lines) != len(expected)+1 || !strings.HasPrefix(lines[0], "infohash: ") {
+		t.Fatalf("expected %d differences, got %q", len(expected)+1, lines)
+	}
+	for i := range expected {
+		if lines[i+1] != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], lines[i+1])
+		}
+	}
+}
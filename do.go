@@ -0,0 +1,95 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// apiPrefix is the path all WebUI API endpoints start with
+const apiPrefix = "/api/v2/"
+
+// apiEndpoint returns the full path of an endpoint given with or without the
+// API prefix, e.g. "torrents/info"
+func apiEndpoint(endpoint string) string {
+	if strings.HasPrefix(endpoint, apiPrefix) {
+		return endpoint
+	}
+	return apiPrefix + strings.TrimPrefix(endpoint, "/")
+}
+
+// Do calls any endpoint of the WebUI API and returns the response body, for
+// endpoints this package doesn't wrap yet. The endpoint may omit the
+// "/api/v2/" prefix, e.g. "torrents/info". params are sent as the query of GET
+// requests and as the form of other requests. Login, retries, timeouts, the
+// cache, circuit breakers and the audit sink apply as to the typed methods;
+// statuses other than 200 are returned as *APIError.
+//
+// Read-only clients refuse endpoints not known to be read-only, and dry-run
+// clients log instead of sending requests to them, as their effect is unknown.
+func (c *Client) Do(ctx context.Context, method, endpoint string, params url.Values) ([]byte, error) {
+	if method == http.MethodGet {
+		return c.doGetCtx(ctx, apiEndpoint(endpoint), params)
+	}
+	return c.DoBody(ctx, method, endpoint, strings.NewReader(params.Encode()), "application/x-www-form-urlencoded")
+}
+
+// DoBody is Do with a request body of the given content type, e.g. a
+// multipart form uploading files
+func (c *Client) DoBody(ctx context.Context, method, endpoint string, body io.Reader, contentType string) ([]byte, error) {
+	endpoint = apiEndpoint(endpoint)
+	if err := c.checkReadOnly(endpoint); err != nil {
+		return nil, err
+	}
+
+	var resp *http.Response
+	var err error
+	if c.dryRun && !readOnlyEndpoints[endpoint] {
+		resp, err = c.dryRunResponse(method, endpoint, body)
+	} else {
+		resp, err = c.doRequestCtx(ctx, method, endpoint, body, contentType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := c.readBody(endpoint, resp)
+	if err != nil {
+		return nil, fmt.Errorf("ReadAll error: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.newAPIError(method, endpoint, resp.StatusCode, respBody)
+	}
+	return respBody, nil
+}
+
+// DoGetJSON calls a GET endpoint with Do and decodes the JSON response into a
+// T, e.g.
+//
+//	torrents, err := qbittorrent.DoGetJSON[[]qbittorrent.TorrentInfo](ctx, client, "torrents/info", nil)
+//
+// Strict decoding applies if the client was created with WithStrictDecoding.
+func DoGetJSON[T any](ctx context.Context, c *Client, endpoint string, query url.Values) (T, error) {
+	return doJSON[T](ctx, c, http.MethodGet, endpoint, query)
+}
+
+// DoPostJSON calls a POST endpoint with Do and decodes the JSON response into
+// a T, like DoGetJSON
+func DoPostJSON[T any](ctx context.Context, c *Client, endpoint string, params url.Values) (T, error) {
+	return doJSON[T](ctx, c, http.MethodPost, endpoint, params)
+}
+
+func doJSON[T any](ctx context.Context, c *Client, method, endpoint string, params url.Values) (T, error) {
+	var v T
+	data, err := c.Do(ctx, method, endpoint, params)
+	if err != nil {
+		return v, err
+	}
+	if err := c.decodeJSON(apiEndpoint(endpoint), data, &v); err != nil {
+		return v, fmt.Errorf("decoding %s: %w", apiEndpoint(endpoint), err)
+	}
+	return v, nil
+}
@@ -0,0 +1,147 @@
+package qbittorrent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestDo(t *testing.T) {
+	var records []AuditRecord
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/torrents/info":
+			if r.Method != http.MethodGet || r.URL.Query().Get("filter") != "completed" {
+				t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			}
+			w.Write([]byte(`[]`))
+		case "/api/v2/torrents/setShareLimits":
+			r.ParseForm()
+			if r.Method != http.MethodPost || r.PostForm.Get("hashes") != "abc" || r.PostForm.Get("ratioLimit") != "2" {
+				t.Errorf("unexpected request %s %v", r.Method, r.PostForm)
+			}
+			w.Write([]byte(`Ok.`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true,
+		audit: AuditFunc(func(record AuditRecord) { records = append(records, record) })}
+
+	ctx := context.Background()
+	body, err := client.Do(ctx, http.MethodGet, "torrents/info", url.Values{"filter": {"completed"}})
+	if err != nil || string(body) != `[]` {
+		t.Errorf("expected [], got %q, %v", body, err)
+	}
+	body, err = client.Do(ctx, http.MethodPost, "/api/v2/torrents/setShareLimits", url.Values{"hashes": {"abc"}, "ratioLimit": {"2"}})
+	if err != nil || string(body) != `Ok.` {
+		t.Errorf("expected Ok., got %q, %v", body, err)
+	}
+	if len(records) != 1 || records[0].Endpoint != "/api/v2/torrents/setShareLimits" || records[0].Params.Get("ratioLimit") != "2" {
+		t.Errorf("expected the POST to be audited, got %+v", records)
+	}
+
+	_, err = client.Do(ctx, http.MethodPost, "/torrents/brandNew", nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Endpoint != "/api/v2/torrents/brandNew" || !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for /api/v2/torrents/brandNew, got %v", err)
+	}
+}
+
+func TestDoBody(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("torrents")
+		if err != nil {
+			t.Errorf("expected a file, got %v", err)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		if r.URL.Path != "/api/v2/torrents/add" || header.Filename != "a.torrent" || string(data) != "data" {
+			t.Errorf("unexpected upload %s %s %q", r.URL.Path, header.Filename, data)
+		}
+		w.Write([]byte(`Ok.`))
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, _ := writer.CreateFormFile("torrents", "a.torrent")
+	part.Write([]byte("data"))
+	writer.Close()
+	if _, err := client.DoBody(context.Background(), http.MethodPost, "torrents/add", &form, writer.FormDataContentType()); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestDo_ReadOnlyAndDryRun(t *testing.T) {
+	var requests []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	readOnly := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true, readOnly: true}
+	if _, err := readOnly.Do(ctx, http.MethodPost, "torrents/brandNew", nil); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+	if _, err := readOnly.Do(ctx, http.MethodGet, "torrents/info", nil); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	dryRun := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true, dryRun: true}
+	if body, err := dryRun.Do(ctx, http.MethodPost, "torrents/brandNew", url.Values{"hashes": {"abc"}}); err != nil || string(body) != "Ok." {
+		t.Errorf("expected a faked response, got %q, %v", body, err)
+	}
+	if len(requests) != 1 || requests[0] != "/api/v2/torrents/info" {
+		t.Errorf("expected only the read-only request to be sent, got %v", requests)
+	}
+}
+
+func TestDoJSON(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/torrents/info":
+			w.Write([]byte(`[{"hash":"abc","name":"one","brand_new":1}]`))
+		case "/api/v2/torrents/brandNew":
+			r.ParseForm()
+			w.Write([]byte(`{"hash":"` + r.PostForm.Get("hash") + `","count":3}`))
+		case "/api/v2/app/version":
+			w.Write([]byte(`v5.0.0`))
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+	ctx := context.Background()
+
+	torrents, err := DoGetJSON[[]TorrentInfo](ctx, client, "torrents/info", nil)
+	if err != nil || len(torrents) != 1 || torrents[0].Hash != "abc" {
+		t.Errorf("expected torrent abc, got %+v, %v", torrents, err)
+	}
+	result, err := DoPostJSON[struct {
+		Hash  string `json:"hash"`
+		Count int    `json:"count"`
+	}](ctx, client, "torrents/brandNew", url.Values{"hash": {"abc"}})
+	if err != nil || result.Hash != "abc" || result.Count != 3 {
+		t.Errorf("expected abc and 3, got %+v, %v", result, err)
+	}
+	if _, err := DoGetJSON[map[string]any](ctx, client, "app/version", nil); err == nil {
+		t.Error("expected an error for a non-JSON response")
+	}
+
+	client.strictDecoding = true
+	_, err = DoGetJSON[[]TorrentInfo](ctx, client, "torrents/info", nil)
+	var unknown *UnknownFieldsError
+	if !errors.As(err, &unknown) || unknown.Fields[0] != "[].brand_new" {
+		t.Errorf("expected the unknown field brand_new, got %v", err)
+	}
+}
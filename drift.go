@@ -0,0 +1,74 @@
+package qbittorrent
+
+import (
+	"sort"
+	"sync"
+)
+
+// schemaDrift collects the unknown fields of responses, see
+// WithSchemaDriftReporting
+type schemaDrift struct {
+	report func(endpoint string, fields []string) // nil to log
+
+	mu     sync.Mutex
+	fields map[string]map[string]bool // by endpoint
+}
+
+// WithSchemaDriftReporting collects the fields of responses that the decoded
+// types don't map and passes each field to report the first time it is seen,
+// so new server fields are noticed in real deployments. A nil report logs a
+// warning instead. Unlike WithStrictDecoding, responses are still decoded;
+// finding the fields costs a second pass over every JSON response.
+func WithSchemaDriftReporting(report func(endpoint string, fields []string)) Option {
+	return func(c *Client) error {
+		c.drift = &schemaDrift{report: report, fields: make(map[string]map[string]bool)}
+		return nil
+	}
+}
+
+// observe records the unknown fields of a response and reports the new ones
+func (d *schemaDrift) observe(c *Client, endpoint string, fields []string) {
+	d.mu.Lock()
+	seen := d.fields[endpoint]
+	if seen == nil {
+		seen = make(map[string]bool)
+		d.fields[endpoint] = seen
+	}
+	var added []string
+	for _, field := range fields {
+		if !seen[field] {
+			seen[field] = true
+			added = append(added, field)
+		}
+	}
+	d.mu.Unlock()
+
+	if len(added) == 0 {
+		return
+	}
+	if d.report != nil {
+		d.report(endpoint, added)
+		return
+	}
+	c.log().Warn("unknown fields in response", "endpoint", endpoint, "fields", added)
+}
+
+// SchemaDrift returns the unknown response fields seen so far by endpoint,
+// sorted, or nil unless the client was created with WithSchemaDriftReporting
+func (c *Client) SchemaDrift() map[string][]string {
+	if c.drift == nil {
+		return nil
+	}
+	c.drift.mu.Lock()
+	defer c.drift.mu.Unlock()
+	drift := make(map[string][]string, len(c.drift.fields))
+	for endpoint, seen := range c.drift.fields {
+		fields := make([]string, 0, len(seen))
+		for field := range seen {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		drift[endpoint] = fields
+	}
+	return drift
+}
@@ -0,0 +1,90 @@
+package qbittorrent
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithSchemaDriftReporting(t *testing.T) {
+	responses := []string{
+		`[{"hash":"abc","name":"one","popularity":0.5}]`,
+		`[{"hash":"abc","name":"one","popularity":0.5,"has_metadata":true}]`,
+	}
+	calls := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(responses[min(calls, len(responses)-1)]))
+		calls++
+	}))
+	defer mockServer.Close()
+
+	type report struct {
+		endpoint string
+		fields   []string
+	}
+	var reports []report
+	client, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithBypassAuth(),
+		WithSchemaDriftReporting(func(endpoint string, fields []string) {
+			reports = append(reports, report{endpoint, fields})
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		torrents, err := client.TorrentsInfoCtx(context.Background())
+		if err != nil || len(torrents) != 1 || torrents[0].Name != "one" {
+			t.Fatalf("expected the torrent to be decoded, got %+v, %v", torrents, err)
+		}
+	}
+
+	expected := []report{
+		{"/api/v2/torrents/info", []string{"[].popularity"}},
+		{"/api/v2/torrents/info", []string{"[].has_metadata"}},
+	}
+	if !reflect.DeepEqual(reports, expected) {
+		t.Errorf("expected each field to be reported once, got %+v", reports)
+	}
+	drift := client.SchemaDrift()
+	if fields := drift["/api/v2/torrents/info"]; !reflect.DeepEqual(fields, []string{"[].has_metadata", "[].popularity"}) {
+		t.Errorf("expected the collected fields, got %v", drift)
+	}
+}
+
+func TestWithSchemaDriftReporting_Log(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"hash":"abc","popularity":0.5}]`))
+	}))
+	defer mockServer.Close()
+
+	var logs bytes.Buffer
+	client, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithBypassAuth(),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		WithSchemaDriftReporting(nil),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.TorrentsInfoCtx(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(logs.String(), "unknown fields in response") || !strings.Contains(logs.String(), "[].popularity") {
+		t.Errorf("expected a warning naming the field, got %q", logs.String())
+	}
+
+	if (&Client{}).SchemaDrift() != nil {
+		t.Error("expected no drift without reporting")
+	}
+}
@@ -0,0 +1,52 @@
+package qbittorrent
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// dryRunEndpoints are the destructive endpoints skipped in dry-run mode
+var dryRunEndpoints = map[string]bool{
+	"/api/v2/torrents/delete":           true,
+	"/api/v2/torrents/removeCategories": true,
+	"/api/v2/torrents/deleteTags":       true,
+	"/api/v2/torrents/setLocation":      true,
+	"/api/v2/app/shutdown":              true,
+}
+
+// log returns the configured logger, or the default one, redacting the URLs
+// in messages and attributes unless the client was created with
+// WithoutRedaction
+func (c *Client) log() *slog.Logger {
+	logger := c.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if c.noRedaction {
+		return logger
+	}
+	return slog.New(redactingHandler{logger.Handler()})
+}
+
+// dryRunResponse logs the request that would have been sent and fakes a successful response
+func (c *Client) dryRunResponse(method, endpoint string, body io.Reader) (*http.Response, error) {
+	var params string
+	if body != nil {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %v", err)
+		}
+		params = c.redactForm(string(data))
+	}
+	c.log().Info("dry run: request not sent", "method", method, "endpoint", endpoint, "params", params)
+
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader("Ok.")),
+	}, nil
+}
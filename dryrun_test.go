@@ -0,0 +1,61 @@
+package qbittorrent
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithDryRun(t *testing.T) {
+	var requests []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+
+	var logs bytes.Buffer
+	client, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		WithDryRun(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ctx := context.Background()
+	calls := map[string]func() error{
+		"delete":           func() error { return client.TorrentsDeleteCtx(ctx, "somehash") },
+		"removeCategories": func() error { return client.TorrentsRemoveCategoriesCtx(ctx, []string{"movies"}) },
+		"deleteTags":       func() error { return client.TorrentsDeleteTagsCtx(ctx, "tag1") },
+		"setLocation":      func() error { return client.TorrentsSetLocationCtx(ctx, "somehash", "/data") },
+		"shutdown":         func() error { return client.AppShutdownCtx(ctx) },
+	}
+	for name, call := range calls {
+		if err := call(); err != nil {
+			t.Errorf("%s: expected no error, got %v", name, err)
+		}
+	}
+	if len(requests) != 0 {
+		t.Errorf("expected no requests in dry-run mode, got %v", requests)
+	}
+	if !strings.Contains(logs.String(), "endpoint=/api/v2/torrents/delete") ||
+		!strings.Contains(logs.String(), "hashes=somehash") {
+		t.Errorf("expected dry-run log of delete request, got %q", logs.String())
+	}
+
+	// Non-destructive calls still reach the server
+	if _, err := client.TorrentsInfoCtx(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(requests) != 1 || requests[0] != "/api/v2/torrents/info" {
+		t.Errorf("expected info request to be sent, got %v", requests)
+	}
+}
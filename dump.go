@@ -0,0 +1,106 @@
+package qbittorrent
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithDebugDump writes every HTTP request and response to w, for
+// troubleshooting responses such as 400 or 409 that only some server versions
+// return. Cookies, Authorization headers, passwords and other secret form
+// fields and JSON members, and, unless WithoutRedaction is given, the secrets
+// of URLs are redacted. Binary bodies such as uploaded or exported .torrent
+// files are omitted.
+func WithDebugDump(w io.Writer) Option {
+	return func(c *Client) error {
+		c.dump = &debugDump{w: w, now: time.Now}
+		return nil
+	}
+}
+
+// redacted replaces secrets in dumps
+const redacted = "[REDACTED]"
+
+// sensitiveHeader matches header lines that carry credentials
+var sensitiveHeader = regexp.MustCompile(`(?im)^((?:cookie|set-cookie|authorization|proxy-authorization):)[^\r\n]*`)
+
+// debugDump serializes dumps of concurrent requests to a writer
+type debugDump struct {
+	mu  sync.Mutex
+	w   io.Writer
+	now func() time.Time
+}
+
+// send sends req, dumping it and its response if WithDebugDump is set
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.dump == nil {
+		return c.client.Do(req)
+	}
+	c.dump.request(req, c.redactForm)
+	resp, err := c.client.Do(req)
+	c.dump.response(req, resp, err, c.redactForm)
+	return resp, err
+}
+
+func (d *debugDump) request(req *http.Request, redact func(string) string) {
+	dump, err := httputil.DumpRequestOut(req, dumpableBody(req.Header.Get("Content-Type")))
+	if err != nil {
+		d.write("request", req, []byte(fmt.Sprintf("failed to dump request: %v\n", err)), redact)
+		return
+	}
+	d.write("request", req, redactDumpBody(dump, req.Header.Get("Content-Type")), redact)
+}
+
+func (d *debugDump) response(req *http.Request, resp *http.Response, err error, redact func(string) string) {
+	if err != nil {
+		d.write("response", req, []byte(fmt.Sprintf("request failed: %v\n", err)), redact)
+		return
+	}
+	dump, err := httputil.DumpResponse(resp, dumpableBody(resp.Header.Get("Content-Type")))
+	if err != nil {
+		dump = []byte(fmt.Sprintf("failed to dump response: %v\n", err))
+	}
+	d.write("response", req, redactDumpBody(dump, resp.Header.Get("Content-Type")), redact)
+}
+
+// write writes a dump with sensitive headers and, unless disabled, the
+// secrets of URLs redacted
+func (d *debugDump) write(kind string, req *http.Request, dump []byte, redact func(string) string) {
+	dump = sensitiveHeader.ReplaceAll(dump, []byte("$1 "+redacted))
+	dump = []byte(redact(string(dump)))
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	fmt.Fprintf(d.w, "--- %s %s %s %s\n", kind, d.now().Format(time.RFC3339Nano), req.Method, req.URL.Path)
+	d.w.Write(bytes.TrimRight(dump, "\r\n"))
+	io.WriteString(d.w, "\n\n")
+}
+
+// redactDumpBody redacts the secrets of the form or JSON body of a dump, see
+// redactSecrets
+func redactDumpBody(dump []byte, contentType string) []byte {
+	head, body, found := bytes.Cut(dump, []byte("\r\n\r\n"))
+	if !found {
+		return dump
+	}
+	return []byte(string(head) + "\r\n\r\n" + redactSecrets(contentType, string(body)))
+}
+
+// dumpableBody reports whether bodies of contentType are text worth dumping
+func dumpableBody(contentType string) bool {
+	switch {
+	case contentType == "",
+		strings.HasPrefix(contentType, "text/"),
+		strings.HasPrefix(contentType, "application/json"),
+		strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,113 @@
+package qbittorrent
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithDebugDump(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/auth/login" {
+			http.SetCookie(w, &http.Cookie{Name: "SID", Value: "secret-sid"})
+			w.Write([]byte("Ok."))
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte("Torrent queueing must be enabled"))
+	}))
+	defer mockServer.Close()
+
+	var dump bytes.Buffer
+	client, err := NewClientWithOptions("user", "hunter2", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithBasicAuth("proxyuser", "proxypass"),
+		WithDebugDump(&dump),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := client.TorrentsAddTags("abc", "tag"); err == nil {
+		t.Fatalf("expected error, got none")
+	}
+	if err := client.TorrentsAdd("a.torrent", []byte("binary torrent data")); err == nil {
+		t.Fatalf("expected error, got none")
+	}
+
+	out := dump.String()
+	for _, secret := range []string{"hunter2", "secret-sid", "cHJveHl1c2VyOnByb3h5cGFzcw=="} {
+		if strings.Contains(out, secret) {
+			t.Errorf("expected %q to be redacted, got\n%s", secret, out)
+		}
+	}
+	for _, want := range []string{
+		"--- request",
+		"POST /api/v2/auth/login",
+		"password=[REDACTED]&username=user",
+		"Set-Cookie: [REDACTED]",
+		"Cookie: [REDACTED]",
+		"hashes=abc&tags=tag",
+		"409 Conflict",
+		"Torrent queueing must be enabled",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected dump to contain %q, got\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "binary torrent data") {
+		t.Errorf("expected multipart bodies to be omitted, got\n%s", out)
+	}
+}
+
+func TestWithDebugDump_Preferences(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/app/preferences" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"proxy_password":"proxy-secret","listen_port":51413}`))
+		}
+	}))
+	defer mockServer.Close()
+
+	var dump bytes.Buffer
+	clock := &stubClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	client, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithBypassAuth(),
+		WithDebugDump(&dump),
+		WithClock(clock),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	ctx := context.Background()
+	prefs := map[string]interface{}{"web_ui_password": "ui-secret", "proxy_password": "proxy-secret", "dht": true}
+	if err := client.AppSetPreferencesCtx(ctx, prefs); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.AppPreferencesCtx(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	out := dump.String()
+	for _, secret := range []string{"ui-secret", "proxy-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("expected %q to be redacted, got\n%s", secret, out)
+		}
+	}
+	for _, want := range []string{
+		"--- request 2024-01-01T00:00:00Z POST /api/v2/app/setPreferences",
+		"%22dht%22%3Atrue",
+		`"listen_port":51413`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected dump to contain %q, got\n%s", want, out)
+		}
+	}
+}
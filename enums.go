@@ -0,0 +1,118 @@
+package qbittorrent
+
+import "strconv"
+
+// FilePriority is the download priority of a file of a torrent
+type FilePriority int
+
+// File priorities understood by torrents/filePrio
+const (
+	FilePrioritySkip    FilePriority = 0 // the file is not downloaded
+	FilePriorityNormal  FilePriority = 1
+	FilePriorityHigh    FilePriority = 6
+	FilePriorityMaximum FilePriority = 7
+)
+
+func (p FilePriority) String() string {
+	switch p {
+	case FilePrioritySkip:
+		return "skip"
+	case FilePriorityNormal:
+		return "normal"
+	case FilePriorityHigh:
+		return "high"
+	case FilePriorityMaximum:
+		return "maximum"
+	default:
+		return "priority " + strconv.Itoa(int(p))
+	}
+}
+
+// PieceState is the download state of a piece of a torrent
+type PieceState int
+
+// Piece states reported by torrents/pieceStates
+const (
+	PieceMissing     PieceState = 0
+	PieceDownloading PieceState = 1
+	PieceDownloaded  PieceState = 2
+)
+
+func (s PieceState) String() string {
+	switch s {
+	case PieceMissing:
+		return "missing"
+	case PieceDownloading:
+		return "downloading"
+	case PieceDownloaded:
+		return "downloaded"
+	default:
+		return "unknown"
+	}
+}
+
+// TrackerStatus is the status of a tracker of a torrent
+type TrackerStatus int
+
+// Tracker statuses reported by torrents/trackers. DHT, PeX and LSD are listed
+// as disabled trackers.
+const (
+	TrackerDisabled     TrackerStatus = 0
+	TrackerNotContacted TrackerStatus = 1
+	TrackerWorking      TrackerStatus = 2
+	TrackerUpdating     TrackerStatus = 3
+	TrackerNotWorking   TrackerStatus = 4
+)
+
+func (s TrackerStatus) String() string {
+	switch s {
+	case TrackerDisabled:
+		return "disabled"
+	case TrackerNotContacted:
+		return "not contacted"
+	case TrackerWorking:
+		return "working"
+	case TrackerUpdating:
+		return "updating"
+	case TrackerNotWorking:
+		return "not working"
+	default:
+		return "unknown"
+	}
+}
+
+// Disabled reports whether the tracker is disabled, or is DHT, PeX or LSD
+func (s TrackerStatus) Disabled() bool {
+	return s == TrackerDisabled
+}
+
+// NotContacted reports whether the tracker hasn't been contacted yet
+func (s TrackerStatus) NotContacted() bool {
+	return s == TrackerNotContacted
+}
+
+// Working reports whether the last announce succeeded, including while the
+// tracker is being updated
+func (s TrackerStatus) Working() bool {
+	return s == TrackerWorking || s == TrackerUpdating
+}
+
+// ConnectionStatus is the connection status of qBittorrent in ServerState
+type ConnectionStatus string
+
+// Connection statuses reported in the server state
+const (
+	ConnectionConnected    ConnectionStatus = "connected"
+	ConnectionFirewalled   ConnectionStatus = "firewalled"
+	ConnectionDisconnected ConnectionStatus = "disconnected"
+)
+
+func (s ConnectionStatus) String() string {
+	return string(s)
+}
+
+// Connected reports whether qBittorrent is connected, whether or not it is
+// reachable from outside
+func (s ConnectionStatus) Connected() bool {
+	return s == ConnectionConnected || s == ConnectionFirewalled
+}
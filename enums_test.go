@@ -0,0 +1,47 @@
+package qbittorrent
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEnums_Decode(t *testing.T) {
+	var trackers []TrackerInfo
+	if err := json.Unmarshal([]byte(`[{"url":"** [DHT] **","status":0},{"url":"udp://tracker","status":3}]`), &trackers); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !trackers[0].Status.Disabled() || !trackers[1].Status.Working() || trackers[1].Status.String() != "updating" {
+		t.Errorf("unexpected tracker statuses %v and %v", trackers[0].Status, trackers[1].Status)
+	}
+
+	var state ServerState
+	if err := json.Unmarshal([]byte(`{"connection_status":"firewalled"}`), &state); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if state.ConnectionStatus != ConnectionFirewalled || !state.ConnectionStatus.Connected() {
+		t.Errorf("unexpected connection status %v", state.ConnectionStatus)
+	}
+
+	var files []TorrentFile
+	if err := json.Unmarshal([]byte(`[{"name":"a","priority":0},{"name":"b","priority":6}]`), &files); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if files[0].Priority != FilePrioritySkip || files[1].Priority.String() != "high" {
+		t.Errorf("unexpected file priorities %v and %v", files[0].Priority, files[1].Priority)
+	}
+}
+
+func TestTrackerStatus_String(t *testing.T) {
+	tests := map[TrackerStatus]string{
+		TrackerDisabled:     "disabled",
+		TrackerNotContacted: "not contacted",
+		TrackerWorking:      "working",
+		TrackerNotWorking:   "not working",
+		TrackerStatus(9):    "unknown",
+	}
+	for status, expected := range tests {
+		if status.String() != expected {
+			t.Errorf("expected %q, got %q", expected, status.String())
+		}
+	}
+}
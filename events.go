@@ -0,0 +1,157 @@
+package qbittorrent
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EventType identifies what happened to a torrent
+type EventType int
+
+const (
+	// TorrentAdded is emitted for torrents that appear after the first poll
+	TorrentAdded EventType = iota
+	// TorrentRemoved is emitted for torrents that disappear
+	TorrentRemoved
+	// TorrentCompleted is emitted when a torrent finishes downloading
+	TorrentCompleted
+	// TorrentStateChanged is emitted when the state of a torrent changes
+	TorrentStateChanged
+)
+
+func (t EventType) String() string {
+	switch t {
+	case TorrentAdded:
+		return "added"
+	case TorrentRemoved:
+		return "removed"
+	case TorrentCompleted:
+		return "completed"
+	case TorrentStateChanged:
+		return "state changed"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes a change to a torrent observed by a Watcher
+type Event struct {
+	Type    EventType
+	At      time.Time
+	Hash    InfoHash
+	Torrent TorrentInfo // the torrent after the change, or before it was removed
+	// Previous is the torrent before the change, for completed and state changed events
+	Previous TorrentInfo
+}
+
+// EventHandler handles an event. Errors are logged and don't stop the Watcher.
+type EventHandler func(ctx context.Context, event Event) error
+
+// Watcher polls the sync endpoint and turns the changes into events. The first
+// poll establishes the baseline, so torrents that already exist are not
+// reported as added. A Watcher is safe for concurrent use.
+type Watcher struct {
+	client *Client
+	state  *SyncState
+
+	mu       sync.Mutex
+	handlers []EventHandler
+	torrents map[string]TorrentInfo // nil until the first poll
+}
+
+// NewWatcher returns a watcher for the torrents of c
+func NewWatcher(c *Client) *Watcher {
+	return &Watcher{client: c, state: NewSyncState()}
+}
+
+// OnEvent registers a handler called for every event, in registration order
+func (w *Watcher) OnEvent(handler EventHandler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, handler)
+}
+
+// Poll fetches the changes since the last poll and dispatches their events
+func (w *Watcher) Poll(ctx context.Context) error {
+	if _, err := w.state.UpdateDiff(ctx, w.client); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	previous := w.torrents
+	w.torrents = w.state.Torrents()
+	var events []Event
+	if previous != nil {
+		events = diffTorrents(w.client.Clock().Now(), previous, w.torrents)
+	}
+	w.mu.Unlock()
+
+	w.Dispatch(ctx, events...)
+	return nil
+}
+
+// Dispatch passes events from other sources, such as a WebhookHandler, to
+// the registered handlers
+func (w *Watcher) Dispatch(ctx context.Context, events ...Event) {
+	w.mu.Lock()
+	handlers := append([]EventHandler{}, w.handlers...)
+	w.mu.Unlock()
+
+	for _, event := range events {
+		for _, handler := range handlers {
+			if err := handler(ctx, event); err != nil {
+				w.client.log().Error("event handler failed", "event", event.Type.String(), "hash", event.Hash, "error", err)
+			}
+		}
+	}
+}
+
+// Run polls every interval until ctx is done
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) error {
+	ticker := w.client.Clock().NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Poll(ctx); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		}
+	}
+}
+
+// diffTorrents returns the events turning previous into current, ordered by hash
+func diffTorrents(at time.Time, previous, current map[string]TorrentInfo) []Event {
+	var events []Event
+	for hash, torrent := range current {
+		old, ok := previous[hash]
+		if !ok {
+			events = append(events, Event{Type: TorrentAdded, At: at, Hash: InfoHash(hash), Torrent: torrent})
+			continue
+		}
+		if old.Progress < 1 && torrent.Progress >= 1 {
+			events = append(events, Event{Type: TorrentCompleted, At: at, Hash: InfoHash(hash), Torrent: torrent, Previous: old})
+		}
+		if old.State != torrent.State {
+			events = append(events, Event{Type: TorrentStateChanged, At: at, Hash: InfoHash(hash), Torrent: torrent, Previous: old})
+		}
+	}
+	for hash, torrent := range previous {
+		if _, ok := current[hash]; !ok {
+			events = append(events, Event{Type: TorrentRemoved, At: at, Hash: InfoHash(hash), Torrent: torrent})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Hash != events[j].Hash {
+			return events[i].Hash < events[j].Hash
+		}
+		return events[i].Type < events[j].Type
+	})
+	return events
+}
@@ -0,0 +1,71 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// newMainDataServer serves the given maindata responses in order, repeating
+// the last one with an empty update
+func newMainDataServer(t *testing.T, responses []string) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	next := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/sync/maindata" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if next >= len(responses) {
+			w.Write([]byte(`{"rid":` + strconv.Itoa(next) + `}`))
+			return
+		}
+		w.Write([]byte(responses[next]))
+		next++
+	}))
+}
+
+func TestWatcher(t *testing.T) {
+	mockServer := newMainDataServer(t, []string{
+		`{"rid":1,"full_update":true,"torrents":{"hash1":{"name":"a","state":"downloading","progress":0.5}}}`,
+		`{"rid":2,"torrents":{"hash1":{"state":"uploading","progress":1},"hash2":{"name":"b","state":"metaDL"}}}`,
+		`{"rid":3,"torrents_removed":["hash1"]}`,
+	})
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	watcher := NewWatcher(client)
+	var events []string
+	watcher.OnEvent(func(ctx context.Context, event Event) error {
+		events = append(events, string(event.Hash)+" "+event.Type.String())
+		return nil
+	})
+	watcher.OnEvent(func(ctx context.Context, event Event) error {
+		return errors.New("handler errors don't stop the watcher")
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := watcher.Poll(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	expected := []string{"hash1 completed", "hash1 state changed", "hash2 added", "hash1 removed"}
+	if len(events) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("expected event %q, got %q", expected[i], events[i])
+		}
+	}
+}
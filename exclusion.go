@@ -0,0 +1,99 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// DefaultExclusionPatterns match samples, proofs and NFO files
+var DefaultExclusionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bsample\b`),
+	regexp.MustCompile(`(?i)\bproof\b`),
+	regexp.MustCompile(`(?i)\.nfo$`),
+}
+
+// ExclusionRecord is the audit record of files skipped by an ExclusionPolicy
+type ExclusionRecord struct {
+	At           time.Time
+	Hash         InfoHash
+	Name         string
+	Files        []string // paths of the skipped files
+	SkippedBytes int64
+}
+
+// ExclusionPolicy stops downloading the files of newly added torrents whose
+// path matches one of its patterns, e.g. samples and extras. Register
+// HandleEvent with a Watcher to apply it automatically.
+type ExclusionPolicy struct {
+	client   *Client
+	patterns []*regexp.Regexp
+	audit    func(ExclusionRecord)
+}
+
+// NewExclusionPolicy returns a policy skipping files matching patterns, or
+// DefaultExclusionPatterns if none are given. Every torrent with skipped files
+// is logged to the client's logger and passed to audit, if not nil.
+func NewExclusionPolicy(c *Client, audit func(ExclusionRecord), patterns ...*regexp.Regexp) *ExclusionPolicy {
+	if len(patterns) == 0 {
+		patterns = DefaultExclusionPatterns
+	}
+	return &ExclusionPolicy{client: c, patterns: patterns, audit: audit}
+}
+
+// HandleEvent applies the policy to torrents when they are added
+func (p *ExclusionPolicy) HandleEvent(ctx context.Context, event Event) error {
+	if event.Type != TorrentAdded {
+		return nil
+	}
+	_, err := p.Apply(ctx, event.Hash, event.Torrent.Name)
+	return err
+}
+
+// Apply skips the matching files of the torrent and returns what was skipped.
+// Files that are already skipped are not reported again.
+func (p *ExclusionPolicy) Apply(ctx context.Context, hash InfoHash, name string) (*ExclusionRecord, error) {
+	files, err := p.client.TorrentsFilesCtx(ctx, string(hash))
+	if err != nil {
+		return nil, fmt.Errorf("exclusion policy error: %w", err)
+	}
+
+	record := &ExclusionRecord{At: p.client.Clock().Now(), Hash: hash, Name: name}
+	var skipped []int
+	for _, file := range files {
+		if file.Priority == FilePrioritySkip || !p.matches(file.Name) {
+			continue
+		}
+		skipped = append(skipped, file.Index)
+		record.Files = append(record.Files, file.Name)
+		record.SkippedBytes += file.Size
+	}
+	if len(skipped) == 0 {
+		return record, nil
+	}
+	// Never skip every file, which would leave nothing to download
+	if len(skipped) == len(files) {
+		p.client.log().Warn("exclusion policy matched every file, skipping nothing", "hash", hash, "name", name)
+		return &ExclusionRecord{At: record.At, Hash: hash, Name: name}, nil
+	}
+
+	if err := p.client.TorrentsFilePrioCtx(ctx, string(hash), skipped, FilePrioritySkip); err != nil {
+		return nil, fmt.Errorf("exclusion policy error: %w", err)
+	}
+	p.client.log().Info("excluded files", "hash", hash, "name", name, "files", len(record.Files), "skipped_bytes", record.SkippedBytes)
+	if p.audit != nil {
+		p.audit(*record)
+	}
+	return record, nil
+}
+
+// matches reports whether a file path matches one of the patterns
+func (p *ExclusionPolicy) matches(name string) bool {
+	for _, pattern := range p.patterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
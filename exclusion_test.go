@@ -0,0 +1,61 @@
+package qbittorrent
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExclusionPolicy(t *testing.T) {
+	var prioCalls []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/sync/maindata":
+			if r.URL.Query().Get("rid") == "0" {
+				w.Write([]byte(`{"rid":1,"full_update":true,"torrents":{}}`))
+				return
+			}
+			w.Write([]byte(`{"rid":2,"torrents":{"abc":{"name":"Movie"}}}`))
+		case "/api/v2/torrents/files":
+			w.Write([]byte(`[
+				{"index":0,"name":"Movie/movie.mkv","size":1000,"priority":1},
+				{"index":1,"name":"Movie/Sample/movie-sample.mkv","size":50,"priority":1},
+				{"index":2,"name":"Movie/Proof/proof.jpg","size":5,"priority":1},
+				{"index":3,"name":"Movie/movie.nfo","size":1,"priority":1},
+				{"index":4,"name":"Movie/sampler.txt","size":1,"priority":1}
+			]`))
+		case "/api/v2/torrents/filePrio":
+			prioCalls = append(prioCalls, r.FormValue("priority")+":"+r.FormValue("id"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	var records []ExclusionRecord
+	policy := NewExclusionPolicy(client, func(record ExclusionRecord) {
+		records = append(records, record)
+	})
+	watcher := NewWatcher(client)
+	watcher.OnEvent(policy.HandleEvent)
+
+	for i := 0; i < 2; i++ {
+		if err := watcher.Poll(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if len(prioCalls) != 1 || prioCalls[0] != "0:1|2|3" {
+		t.Errorf("expected files 1, 2 and 3 to be skipped, got %v", prioCalls)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 audit record, got %d", len(records))
+	}
+	if records[0].Hash != "abc" || records[0].Name != "Movie" || records[0].SkippedBytes != 56 || len(records[0].Files) != 3 {
+		t.Errorf("expected audit record of 3 files and 56 bytes, got %+v", records[0])
+	}
+}
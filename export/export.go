@@ -0,0 +1,253 @@
+// Package export writes torrent listings as CSV, newline-delimited JSON or a
+// text table, for the command line and reporting scripts. Columns are chosen
+// by name; CSV and NDJSON hold raw values, tables human-readable ones.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cehbz/qbittorrent"
+)
+
+// Column is a column of a torrent listing
+type Column struct {
+	Name   string                                      // header, the field name of the WebUI API
+	Value  func(t qbittorrent.TorrentInfo) interface{} // raw value for CSV and NDJSON
+	Format func(t qbittorrent.TorrentInfo) string      // human-readable value for tables
+}
+
+// DefaultColumns are written when no columns are selected
+var DefaultColumns = []string{"name", "state", "size", "progress", "ratio", "eta"}
+
+// Columns are the columns that can be selected, by name
+var Columns = builtinColumns()
+
+func builtinColumns() map[string]Column {
+	columns := make(map[string]Column)
+	for _, column := range []Column{
+		stringColumn("hash", func(t qbittorrent.TorrentInfo) string { return string(t.Hash) }),
+		stringColumn("name", func(t qbittorrent.TorrentInfo) string { return t.Name }),
+		stringColumn("state", func(t qbittorrent.TorrentInfo) string { return t.State }),
+		stringColumn("category", func(t qbittorrent.TorrentInfo) string { return t.Category }),
+		{
+			Name:   "tags",
+			Value:  func(t qbittorrent.TorrentInfo) interface{} { return t.Tags },
+			Format: func(t qbittorrent.TorrentInfo) string { return strings.Join(t.Tags, ", ") },
+		},
+		stringColumn("save_path", func(t qbittorrent.TorrentInfo) string { return t.SavePath }),
+		stringColumn("tracker", func(t qbittorrent.TorrentInfo) string { return t.Tracker }),
+		bytesColumn("size", func(t qbittorrent.TorrentInfo) int64 { return t.Size }),
+		bytesColumn("downloaded", func(t qbittorrent.TorrentInfo) int64 { return t.Downloaded }),
+		bytesColumn("uploaded", func(t qbittorrent.TorrentInfo) int64 { return t.Uploaded }),
+		bytesColumn("amount_left", func(t qbittorrent.TorrentInfo) int64 { return t.AmountLeft }),
+		speedColumn("dlspeed", func(t qbittorrent.TorrentInfo) int64 { return t.DLSpeed }),
+		speedColumn("upspeed", func(t qbittorrent.TorrentInfo) int64 { return t.UpSpeed }),
+		{
+			Name:   "progress",
+			Value:  func(t qbittorrent.TorrentInfo) interface{} { return t.Progress },
+			Format: func(t qbittorrent.TorrentInfo) string { return formatPercent(t.Progress) },
+		},
+		{
+			Name:   "ratio",
+			Value:  func(t qbittorrent.TorrentInfo) interface{} { return t.Ratio },
+			Format: func(t qbittorrent.TorrentInfo) string { return qbittorrent.FormatRatio(t.Ratio) },
+		},
+		etaColumn("eta", func(t qbittorrent.TorrentInfo) int64 { return t.ETA }),
+		durationColumn("seeding_time", func(t qbittorrent.TorrentInfo) int64 { return t.SeedingTime }),
+		durationColumn("time_active", func(t qbittorrent.TorrentInfo) int64 { return t.TimeActive }),
+		timeColumn("added_on", func(t qbittorrent.TorrentInfo) int64 { return t.AddedOn }),
+		timeColumn("completion_on", func(t qbittorrent.TorrentInfo) int64 { return t.CompletionOn }),
+		intColumn("num_seeds", func(t qbittorrent.TorrentInfo) int64 { return t.NumSeeds }),
+		intColumn("num_leechs", func(t qbittorrent.TorrentInfo) int64 { return t.NumLeechs }),
+	} {
+		columns[column.Name] = column
+	}
+	return columns
+}
+
+// CSV writes the torrents with a header row
+func CSV(w io.Writer, torrents []qbittorrent.TorrentInfo, columns ...string) error {
+	cols, err := lookup(columns)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	header := make([]string, len(cols))
+	for i, col := range cols {
+		header[i] = col.Name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, t := range torrents {
+		record := make([]string, len(cols))
+		for i, col := range cols {
+			record[i] = csvValue(col.Value(t))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// NDJSON writes one JSON object per torrent and line
+func NDJSON(w io.Writer, torrents []qbittorrent.TorrentInfo, columns ...string) error {
+	cols, err := lookup(columns)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for _, t := range torrents {
+		// Marshal by hand to keep the columns in the selected order
+		var b strings.Builder
+		b.WriteByte('{')
+		for i, col := range cols {
+			key, _ := json.Marshal(col.Name)
+			value, err := json.Marshal(col.Value(t))
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.Write(key)
+			b.WriteByte(':')
+			b.Write(value)
+		}
+		b.WriteByte('}')
+		if err := enc.Encode(json.RawMessage(b.String())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Table writes the torrents as an aligned text table with a header row
+func Table(w io.Writer, torrents []qbittorrent.TorrentInfo, columns ...string) error {
+	cols, err := lookup(columns)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, len(cols))
+	for i, col := range cols {
+		header[i] = strings.ToUpper(col.Name)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, t := range torrents {
+		row := make([]string, len(cols))
+		for i, col := range cols {
+			// Tabs would break the alignment
+			row[i] = strings.ReplaceAll(col.Format(t), "\t", " ")
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// lookup returns the named columns, or the default ones if none are named
+func lookup(names []string) ([]Column, error) {
+	if len(names) == 0 {
+		names = DefaultColumns
+	}
+	cols := make([]Column, len(names))
+	for i, name := range names {
+		col, ok := Columns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		cols[i] = col
+	}
+	return cols, nil
+}
+
+// csvValue formats a raw value for a CSV field
+func csvValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func stringColumn(name string, value func(qbittorrent.TorrentInfo) string) Column {
+	return Column{
+		Name:   name,
+		Value:  func(t qbittorrent.TorrentInfo) interface{} { return value(t) },
+		Format: value,
+	}
+}
+
+func intColumn(name string, value func(qbittorrent.TorrentInfo) int64) Column {
+	return Column{
+		Name:   name,
+		Value:  func(t qbittorrent.TorrentInfo) interface{} { return value(t) },
+		Format: func(t qbittorrent.TorrentInfo) string { return strconv.FormatInt(value(t), 10) },
+	}
+}
+
+func bytesColumn(name string, value func(qbittorrent.TorrentInfo) int64) Column {
+	return Column{
+		Name:   name,
+		Value:  func(t qbittorrent.TorrentInfo) interface{} { return value(t) },
+		Format: func(t qbittorrent.TorrentInfo) string { return qbittorrent.FormatBytes(value(t)) },
+	}
+}
+
+func speedColumn(name string, value func(qbittorrent.TorrentInfo) int64) Column {
+	return Column{
+		Name:   name,
+		Value:  func(t qbittorrent.TorrentInfo) interface{} { return value(t) },
+		Format: func(t qbittorrent.TorrentInfo) string { return qbittorrent.FormatSpeed(value(t)) },
+	}
+}
+
+func etaColumn(name string, value func(qbittorrent.TorrentInfo) int64) Column {
+	return Column{
+		Name:   name,
+		Value:  func(t qbittorrent.TorrentInfo) interface{} { return value(t) },
+		Format: func(t qbittorrent.TorrentInfo) string { return qbittorrent.FormatETA(value(t)) },
+	}
+}
+
+func durationColumn(name string, value func(qbittorrent.TorrentInfo) int64) Column {
+	return Column{
+		Name:   name,
+		Value:  func(t qbittorrent.TorrentInfo) interface{} { return value(t) },
+		Format: func(t qbittorrent.TorrentInfo) string { return qbittorrent.FormatDuration(value(t)) },
+	}
+}
+
+// timeColumn is a column of Unix timestamps, exported in RFC 3339
+func timeColumn(name string, value func(qbittorrent.TorrentInfo) int64) Column {
+	format := func(t qbittorrent.TorrentInfo) string {
+		if value(t) <= 0 {
+			return ""
+		}
+		return time.Unix(value(t), 0).UTC().Format(time.RFC3339)
+	}
+	return Column{
+		Name:   name,
+		Value:  func(t qbittorrent.TorrentInfo) interface{} { return format(t) },
+		Format: format,
+	}
+}
+
+func formatPercent(progress float64) string {
+	return strconv.FormatFloat(math.Floor(progress*1000)/10, 'f', 1, 64) + "%"
+}
@@ -0,0 +1,68 @@
+package export_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cehbz/qbittorrent"
+	"github.com/cehbz/qbittorrent/export"
+)
+
+var torrents = []qbittorrent.TorrentInfo{
+	{Hash: "aaa", Name: "Show, S01", State: "uploading", Size: 1536 * 1024 * 1024, Progress: 1, Ratio: 1.234, ETA: 8640000, Tags: []string{"hd", "tv"}},
+	{Hash: "bbb", Name: "Movie", State: "downloading", Size: 700, Progress: 0.4567, Ratio: 0, ETA: 3725},
+}
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.CSV(&buf, torrents, "hash", "name", "size", "tags"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := "hash,name,size,tags\naaa,\"Show, S01\",1610612736,\"hd,tv\"\nbbb,Movie,700,\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.NDJSON(&buf, torrents, "name", "progress"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := `{"name":"Show, S01","progress":1}` + "\n" + `{"name":"Movie","progress":0.4567}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.Table(&buf, torrents); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header and 2 rows, got %q", buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "NAME") {
+		t.Errorf("expected header, got %q", lines[0])
+	}
+	for _, want := range []string{"1.50 GiB", "100.0%", "1.23", "∞"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("expected %q in %q", want, lines[1])
+		}
+	}
+	for _, want := range []string{"700 B", "45.6%", "1h 2m"} {
+		if !strings.Contains(lines[2], want) {
+			t.Errorf("expected %q in %q", want, lines[2])
+		}
+	}
+}
+
+func TestUnknownColumn(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.CSV(&buf, torrents, "nope"); err == nil {
+		t.Errorf("expected error for unknown column")
+	}
+}
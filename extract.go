@@ -0,0 +1,66 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ContentFile is a file of a torrent with its location on the server
+type ContentFile struct {
+	TorrentFile
+	Path string // absolute, with the separators of the server
+}
+
+// ContentFiles returns the files of torrent with their paths on the server.
+// File names are relative to the directory of the content path, which is the
+// torrent's file or root folder, unless the torrent was added without a root
+// folder: its content path is then its save path, or its download path while
+// incomplete, and the names are relative to it.
+func ContentFiles(torrent TorrentInfo, files []TorrentFile) []ContentFile {
+	dir := normalizePath(torrent.ContentPath)
+	if !samePath(dir, torrent.SavePath) && (torrent.DownloadPath == "" || !samePath(dir, torrent.DownloadPath)) {
+		dir = path.Dir(dir)
+	}
+
+	contentFiles := make([]ContentFile, len(files))
+	for i, file := range files {
+		p := path.Join(dir, file.Name)
+		if strings.Contains(torrent.ContentPath, `\`) {
+			p = strings.ReplaceAll(p, "/", `\`)
+		}
+		contentFiles[i] = ContentFile{TorrentFile: file, Path: p}
+	}
+	return contentFiles
+}
+
+// CompletedFilesCtx returns the downloaded files of a torrent matching one of
+// the patterns, or all of them without patterns, so they can be processed,
+// e.g. unpacked or imported, before the rest of the torrent completes.
+// Patterns are matched like by SelectFilesCtx. Paths are on the server, which
+// may differ from where its storage is mounted locally.
+func (c *Client) CompletedFilesCtx(ctx context.Context, hash string, patterns ...string) ([]ContentFile, error) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	torrent, err := c.GetTorrentCtx(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("CompletedFiles error: %w", err)
+	}
+	files, err := c.TorrentsFilesCtx(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("CompletedFiles error: %w", err)
+	}
+
+	var completed []ContentFile
+	for _, file := range ContentFiles(*torrent, files) {
+		if file.Progress < 1 || (len(patterns) > 0 && !matchFile(file.Name, patterns)) {
+			continue
+		}
+		completed = append(completed, file)
+	}
+	return completed, nil
+}
@@ -0,0 +1,114 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestContentFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		torrent  TorrentInfo
+		files    []string
+		expected []string
+	}{
+		{
+			name:     "single file",
+			torrent:  TorrentInfo{SavePath: "/data/tv", ContentPath: "/data/tv/show.mkv"},
+			files:    []string{"show.mkv"},
+			expected: []string{"/data/tv/show.mkv"},
+		},
+		{
+			name:     "root folder",
+			torrent:  TorrentInfo{SavePath: "/data/tv/", ContentPath: "/data/tv/Show"},
+			files:    []string{"Show/a.rar", "Show/Sample/s.mkv"},
+			expected: []string{"/data/tv/Show/a.rar", "/data/tv/Show/Sample/s.mkv"},
+		},
+		{
+			name:     "no root folder",
+			torrent:  TorrentInfo{SavePath: "/data/tv", ContentPath: "/data/tv"},
+			files:    []string{"a.rar", "Sample/s.mkv"},
+			expected: []string{"/data/tv/a.rar", "/data/tv/Sample/s.mkv"},
+		},
+		{
+			name:     "no root folder while incomplete",
+			torrent:  TorrentInfo{SavePath: "/data/tv", DownloadPath: "/incomplete", ContentPath: "/incomplete"},
+			files:    []string{"a.rar"},
+			expected: []string{"/incomplete/a.rar"},
+		},
+		{
+			name:     "root folder while incomplete",
+			torrent:  TorrentInfo{SavePath: "/data/tv", DownloadPath: "/incomplete", ContentPath: "/incomplete/Show"},
+			files:    []string{"Show/a.rar"},
+			expected: []string{"/incomplete/Show/a.rar"},
+		},
+		{
+			name:     "windows",
+			torrent:  TorrentInfo{SavePath: `D:\TV`, ContentPath: `D:\TV\Show`},
+			files:    []string{"Show/a.rar"},
+			expected: []string{`D:\TV\Show\a.rar`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := make([]TorrentFile, len(tt.files))
+			for i, name := range tt.files {
+				files[i] = TorrentFile{Index: i, Name: name}
+			}
+			var paths []string
+			for _, file := range ContentFiles(tt.torrent, files) {
+				paths = append(paths, file.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, paths)
+			}
+		})
+	}
+}
+
+func TestCompletedFilesCtx(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/torrents/info":
+			if r.URL.Query().Get("hashes") != "abc" {
+				w.Write([]byte(`[]`))
+				return
+			}
+			w.Write([]byte(`[{"hash":"abc","save_path":"/data","content_path":"/data/Show"}]`))
+		case "/api/v2/torrents/files":
+			w.Write([]byte(`[
+				{"index":0,"name":"Show/show.r00","progress":1},
+				{"index":1,"name":"Show/show.r01","progress":0.5},
+				{"index":2,"name":"Show/show.rar","progress":1},
+				{"index":3,"name":"Show/show.nfo","progress":1}
+			]`))
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	files, err := client.CompletedFilesCtx(context.Background(), "abc", "*.r[0-9][0-9]", "*.rar")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	expected := []string{"/data/Show/show.r00", "/data/Show/show.rar"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+
+	if _, err := client.CompletedFilesCtx(context.Background(), "def"); !errors.Is(err, ErrTorrentNotFound) {
+		t.Errorf("expected ErrTorrentNotFound, got %v", err)
+	}
+	if _, err := client.CompletedFilesCtx(context.Background(), "abc", "["); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
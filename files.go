@@ -0,0 +1,168 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// TorrentFile represents a file of a torrent from /api/v2/torrents/files
+type TorrentFile struct {
+	Index        int          `json:"index"`
+	Name         string       `json:"name"` // path relative to the torrent's content
+	Size         int64        `json:"size"`
+	Progress     float64      `json:"progress"`
+	Priority     FilePriority `json:"priority"`
+	IsSeed       bool         `json:"is_seed"`
+	PieceRange   []int        `json:"piece_range"`
+	Availability float64      `json:"availability"`
+}
+
+// TorrentsFilesCtx retrieves the files of a torrent
+func (c *Client) TorrentsFilesCtx(ctx context.Context, hash string) ([]TorrentFile, error) {
+	params := url.Values{}
+	params.Set("hash", hash)
+
+	respData, err := c.doGetCtx(ctx, "/api/v2/torrents/files", params)
+	if err != nil {
+		return nil, fmt.Errorf("TorrentsFiles error: %w", err)
+	}
+
+	var files []TorrentFile
+	if err := c.decodeJSON("/api/v2/torrents/files", respData, &files); err != nil {
+		return nil, fmt.Errorf("failed to decode files response: %w", err)
+	}
+	// Servers before qBittorrent 4.2 don't report the index, it's the position
+	for i := range files {
+		if files[i].Index == 0 {
+			files[i].Index = i
+		}
+	}
+
+	return files, nil
+}
+
+// TorrentsPieceStatesCtx retrieves the states of the pieces of a torrent
+func (c *Client) TorrentsPieceStatesCtx(ctx context.Context, hash string) ([]PieceState, error) {
+	params := url.Values{}
+	params.Set("hash", hash)
+
+	respData, err := c.doGetCtx(ctx, "/api/v2/torrents/pieceStates", params)
+	if err != nil {
+		return nil, fmt.Errorf("TorrentsPieceStates error: %w", err)
+	}
+
+	var states []PieceState
+	if err := c.decodeJSON("/api/v2/torrents/pieceStates", respData, &states); err != nil {
+		return nil, fmt.Errorf("failed to decode piece states response: %w", err)
+	}
+	return states, nil
+}
+
+// TorrentsFilePrioCtx sets the priority of the files of a torrent given by index
+func (c *Client) TorrentsFilePrioCtx(ctx context.Context, hash string, indexes []int, priority FilePriority) error {
+	ids := make([]string, len(indexes))
+	for i, index := range indexes {
+		ids[i] = strconv.Itoa(index)
+	}
+	data := url.Values{}
+	data.Set("hash", hash)
+	data.Set("id", strings.Join(ids, "|"))
+	data.Set("priority", strconv.Itoa(int(priority)))
+
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/torrents/filePrio", data)
+	if err != nil {
+		return fmt.Errorf("TorrentsFilePrio error: %w", err)
+	}
+	return nil
+}
+
+// SelectFilesCtx downloads only the files of a torrent matching one of the
+// include patterns and none of the exclude patterns; an empty include list
+// matches every file. Patterns use path.Match syntax and are matched against
+// both the file's path and its base name, so "*.mkv" matches in any directory.
+// Selected files keep their priority if they were already being downloaded.
+func (c *Client) SelectFilesCtx(ctx context.Context, hash string, include, exclude []string) error {
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return c.applyFileSelection(ctx, hash, func(file TorrentFile) bool {
+		if len(include) > 0 && !matchFile(file.Name, include) {
+			return false
+		}
+		return !matchFile(file.Name, exclude)
+	})
+}
+
+// SkipByExtensionCtx stops downloading the files of a torrent with one of the
+// given extensions, e.g. ".nfo" or "txt", compared case-insensitively.
+// Other files are left as they are.
+func (c *Client) SkipByExtensionCtx(ctx context.Context, hash string, extensions []string) error {
+	skip := make(map[string]bool)
+	for _, ext := range extensions {
+		skip["."+strings.ToLower(strings.TrimPrefix(ext, "."))] = true
+	}
+	files, err := c.TorrentsFilesCtx(ctx, hash)
+	if err != nil {
+		return err
+	}
+	var skipped []int
+	for _, file := range files {
+		if file.Priority != FilePrioritySkip && skip[strings.ToLower(path.Ext(file.Name))] {
+			skipped = append(skipped, file.Index)
+		}
+	}
+	if len(skipped) == 0 {
+		return nil
+	}
+	return c.TorrentsFilePrioCtx(ctx, hash, skipped, FilePrioritySkip)
+}
+
+// applyFileSelection downloads the files for which selected returns true and
+// skips the others, only sending the priorities that change
+func (c *Client) applyFileSelection(ctx context.Context, hash string, selected func(TorrentFile) bool) error {
+	files, err := c.TorrentsFilesCtx(ctx, hash)
+	if err != nil {
+		return err
+	}
+
+	var enable, skip []int
+	for _, file := range files {
+		switch want := selected(file); {
+		case want && file.Priority == FilePrioritySkip:
+			enable = append(enable, file.Index)
+		case !want && file.Priority != FilePrioritySkip:
+			skip = append(skip, file.Index)
+		}
+	}
+
+	if len(enable) > 0 {
+		if err := c.TorrentsFilePrioCtx(ctx, hash, enable, FilePriorityNormal); err != nil {
+			return err
+		}
+	}
+	if len(skip) > 0 {
+		if err := c.TorrentsFilePrioCtx(ctx, hash, skip, FilePrioritySkip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchFile reports whether name or its base name matches one of the patterns
+func matchFile(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(name)); ok {
+			return true
+		}
+	}
+	return false
+}
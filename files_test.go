@@ -0,0 +1,80 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func newFilesServer(t *testing.T, files string, prioCalls *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/torrents/files":
+			if r.URL.Query().Get("hash") != "abc" {
+				t.Errorf("expected hash abc, got %s", r.URL.Query().Get("hash"))
+			}
+			w.Write([]byte(files))
+		case "/api/v2/torrents/filePrio":
+			*prioCalls = append(*prioCalls, r.FormValue("priority")+":"+r.FormValue("id"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+const testFiles = `[
+	{"index":0,"name":"Show/S01E01.mkv","size":100,"priority":1},
+	{"index":1,"name":"Show/S01E02.mkv","size":100,"priority":0},
+	{"index":2,"name":"Show/Sample/sample.mkv","size":10,"priority":1},
+	{"index":3,"name":"Show/info.NFO","size":1,"priority":1},
+	{"index":4,"name":"Show/cover.jpg","size":1,"priority":6}
+]`
+
+func TestSelectFiles(t *testing.T) {
+	var prioCalls []string
+	mockServer := newFilesServer(t, testFiles, &prioCalls)
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	err := client.SelectFilesCtx(context.Background(), "abc", []string{"*.mkv", "*.jpg"}, []string{"Show/Sample/*"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sort.Strings(prioCalls)
+	expected := []string{"0:2|3", "1:1"}
+	if strings.Join(prioCalls, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected priority calls %v, got %v", expected, prioCalls)
+	}
+
+	if err := client.SelectFilesCtx(context.Background(), "abc", []string{"[invalid"}, nil); err == nil {
+		t.Errorf("expected error for invalid pattern, got none")
+	}
+}
+
+func TestSkipByExtension(t *testing.T) {
+	var prioCalls []string
+	mockServer := newFilesServer(t, testFiles, &prioCalls)
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	if err := client.SkipByExtensionCtx(context.Background(), "abc", []string{"nfo", ".JPG", ".txt"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(prioCalls) != 1 || prioCalls[0] != "0:3|4" {
+		t.Errorf("expected files 3 and 4 to be skipped, got %v", prioCalls)
+	}
+
+	// Nothing to change, no request
+	prioCalls = nil
+	if err := client.SkipByExtensionCtx(context.Background(), "abc", []string{".iso"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(prioCalls) != 0 {
+		t.Errorf("expected no priority calls, got %v", prioCalls)
+	}
+}
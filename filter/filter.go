@@ -0,0 +1,503 @@
+// Package filter compiles simple query expressions into predicates over
+// torrents, e.g.
+//
+//	tracker contains redacted && ratio < 1.0 && added_on > 30d
+//
+// An expression combines comparisons of a torrent field with a value using
+// &&, || and !, grouped with parentheses. Fields are named like in the WebUI
+// API, see Fields. The operators are ==, !=, <, <=, >, >=, contains, which is
+// case-insensitive, and matches, which takes a regular expression. Values are
+// bare words or quoted strings; sizes and speeds accept units such as 1.5GiB
+// or 500MB, durations units such as 30d, 12h, 15m or 45s.
+//
+// Timestamp fields such as added_on compare the time elapsed since then, so
+// "added_on > 30d" matches torrents added more than 30 days ago; torrents for
+// which the timestamp isn't set never match. Boolean fields can be used on
+// their own, e.g. "private && !force_start", and the tags field matches
+// torrents having the given tag.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cehbz/qbittorrent"
+)
+
+// Predicate reports whether a torrent matches
+type Predicate func(t qbittorrent.TorrentInfo) bool
+
+// kind is the type of a field, which determines how values are parsed
+type kind int
+
+const (
+	kindString kind = iota
+	kindNumber
+	kindSize     // bytes or bytes per second, values may have units
+	kindDuration // seconds, values may have units
+	kindTime     // Unix timestamp, compared by the time elapsed since
+	kindBool
+	kindTags
+)
+
+// field reads a value of a torrent
+type field struct {
+	kind   kind
+	str    func(t qbittorrent.TorrentInfo) string
+	num    func(t qbittorrent.TorrentInfo) float64
+	flag   func(t qbittorrent.TorrentInfo) bool
+	values func(t qbittorrent.TorrentInfo) []string
+}
+
+func stringField(f func(t qbittorrent.TorrentInfo) string) field {
+	return field{kind: kindString, str: f}
+}
+
+func numberField(k kind, f func(t qbittorrent.TorrentInfo) float64) field {
+	return field{kind: k, num: f}
+}
+
+func boolField(f func(t qbittorrent.TorrentInfo) bool) field {
+	return field{kind: kindBool, flag: f}
+}
+
+// Fields are the names of the fields that can be queried
+var Fields []string
+
+var fields = map[string]field{
+	"name":         stringField(func(t qbittorrent.TorrentInfo) string { return t.Name }),
+	"hash":         stringField(func(t qbittorrent.TorrentInfo) string { return string(t.Hash) }),
+	"state":        stringField(func(t qbittorrent.TorrentInfo) string { return t.State }),
+	"category":     stringField(func(t qbittorrent.TorrentInfo) string { return t.Category }),
+	"tracker":      stringField(func(t qbittorrent.TorrentInfo) string { return t.Tracker }),
+	"save_path":    stringField(func(t qbittorrent.TorrentInfo) string { return t.SavePath }),
+	"content_path": stringField(func(t qbittorrent.TorrentInfo) string { return t.ContentPath }),
+	"tags":         {kind: kindTags, values: func(t qbittorrent.TorrentInfo) []string { return t.Tags }},
+
+	"ratio":          numberField(kindNumber, func(t qbittorrent.TorrentInfo) float64 { return t.Ratio }),
+	"progress":       numberField(kindNumber, func(t qbittorrent.TorrentInfo) float64 { return t.Progress }),
+	"availability":   numberField(kindNumber, func(t qbittorrent.TorrentInfo) float64 { return t.Availability }),
+	"priority":       numberField(kindNumber, func(t qbittorrent.TorrentInfo) float64 { return float64(t.Priority) }),
+	"num_seeds":      numberField(kindNumber, func(t qbittorrent.TorrentInfo) float64 { return float64(t.NumSeeds) }),
+	"num_leechs":     numberField(kindNumber, func(t qbittorrent.TorrentInfo) float64 { return float64(t.NumLeechs) }),
+	"num_complete":   numberField(kindNumber, func(t qbittorrent.TorrentInfo) float64 { return float64(t.NumComplete) }),
+	"num_incomplete": numberField(kindNumber, func(t qbittorrent.TorrentInfo) float64 { return float64(t.NumIncomplete) }),
+
+	"size":        numberField(kindSize, func(t qbittorrent.TorrentInfo) float64 { return float64(t.Size) }),
+	"total_size":  numberField(kindSize, func(t qbittorrent.TorrentInfo) float64 { return float64(t.TotalSize) }),
+	"downloaded":  numberField(kindSize, func(t qbittorrent.TorrentInfo) float64 { return float64(t.Downloaded) }),
+	"uploaded":    numberField(kindSize, func(t qbittorrent.TorrentInfo) float64 { return float64(t.Uploaded) }),
+	"amount_left": numberField(kindSize, func(t qbittorrent.TorrentInfo) float64 { return float64(t.AmountLeft) }),
+	"dlspeed":     numberField(kindSize, func(t qbittorrent.TorrentInfo) float64 { return float64(t.DLSpeed) }),
+	"upspeed":     numberField(kindSize, func(t qbittorrent.TorrentInfo) float64 { return float64(t.UpSpeed) }),
+
+	"eta":          numberField(kindDuration, func(t qbittorrent.TorrentInfo) float64 { return float64(t.ETA) }),
+	"seeding_time": numberField(kindDuration, func(t qbittorrent.TorrentInfo) float64 { return float64(t.SeedingTime) }),
+	"time_active":  numberField(kindDuration, func(t qbittorrent.TorrentInfo) float64 { return float64(t.TimeActive) }),
+
+	"added_on":      numberField(kindTime, func(t qbittorrent.TorrentInfo) float64 { return float64(t.AddedOn) }),
+	"completion_on": numberField(kindTime, func(t qbittorrent.TorrentInfo) float64 { return float64(t.CompletionOn) }),
+	"last_activity": numberField(kindTime, func(t qbittorrent.TorrentInfo) float64 { return float64(t.LastActivity) }),
+	"seen_complete": numberField(kindTime, func(t qbittorrent.TorrentInfo) float64 { return float64(t.SeenComplete) }),
+
+	"private":        boolField(func(t qbittorrent.TorrentInfo) bool { return t.IsPrivate }),
+	"auto_tmm":       boolField(func(t qbittorrent.TorrentInfo) bool { return t.AutoTMM }),
+	"force_start":    boolField(func(t qbittorrent.TorrentInfo) bool { return t.ForceStart }),
+	"seq_dl":         boolField(func(t qbittorrent.TorrentInfo) bool { return t.SequentialDownload }),
+	"f_l_piece_prio": boolField(func(t qbittorrent.TorrentInfo) bool { return t.FirstLastPiecePrio }),
+	"super_seeding":  boolField(func(t qbittorrent.TorrentInfo) bool { return t.SuperSeeding }),
+}
+
+func init() {
+	for name := range fields {
+		Fields = append(Fields, name)
+	}
+	sort.Strings(Fields)
+}
+
+// Compile compiles an expression into a predicate. An empty expression
+// matches all torrents.
+func Compile(expr string) (Predicate, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return func(qbittorrent.TorrentInfo) bool { return true }, nil
+	}
+	p := &parser{tokens: tokens}
+	pred, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, p.errorf("unexpected %q", p.peek().text)
+	}
+	return pred, nil
+}
+
+// MustCompile is like Compile but panics if the expression is invalid
+func MustCompile(expr string) Predicate {
+	pred, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return pred
+}
+
+// Apply returns the torrents matching pred
+func Apply(torrents []qbittorrent.TorrentInfo, pred Predicate) []qbittorrent.TorrentInfo {
+	return qbittorrent.Filter(torrents, pred)
+}
+
+// SyntaxError reports an invalid expression
+type SyntaxError struct {
+	Pos int // byte offset in the expression
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("filter: %s at position %d", e.Msg, e.Pos)
+}
+
+// token is a lexical token of an expression
+type token struct {
+	text   string
+	pos    int
+	quoted bool // a quoted string, never an operator
+}
+
+// lex splits an expression into tokens
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, &SyntaxError{Pos: i, Msg: "unterminated string"}
+			}
+			tokens = append(tokens, token{text: expr[i+1 : i+1+end], pos: i, quoted: true})
+			i += end + 2
+		case strings.HasPrefix(expr[i:], "&&"), strings.HasPrefix(expr[i:], "||"),
+			strings.HasPrefix(expr[i:], "=="), strings.HasPrefix(expr[i:], "!="),
+			strings.HasPrefix(expr[i:], "<="), strings.HasPrefix(expr[i:], ">="):
+			tokens = append(tokens, token{text: expr[i : i+2], pos: i})
+			i += 2
+		case strings.IndexByte("()!<>", c) >= 0:
+			tokens = append(tokens, token{text: expr[i : i+1], pos: i})
+			i++
+		default:
+			start := i
+			for i < len(expr) && isWordChar(rune(expr[i])) {
+				i++
+			}
+			if i == start {
+				return nil, &SyntaxError{Pos: i, Msg: fmt.Sprintf("unexpected %q", expr[i])}
+			}
+			tokens = append(tokens, token{text: expr[start:i], pos: start})
+		}
+	}
+	return tokens, nil
+}
+
+// isWordChar reports whether r may be part of a bare word
+func isWordChar(r rune) bool {
+	return r > unicode.MaxASCII || unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-:/+@%*", r)
+}
+
+// parser is a recursive descent parser over tokens
+type parser struct {
+	tokens []token
+	next   int
+}
+
+func (p *parser) done() bool {
+	return p.next >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.tokens[p.next]
+}
+
+// accept consumes the next token if it is the operator op
+func (p *parser) accept(op string) bool {
+	if p.done() || p.tokens[p.next].quoted || p.tokens[p.next].text != op {
+		return false
+	}
+	p.next++
+	return true
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	pos := 0
+	if !p.done() {
+		pos = p.peek().pos
+	} else if len(p.tokens) > 0 {
+		last := p.tokens[len(p.tokens)-1]
+		pos = last.pos + len(last.text)
+	}
+	return &SyntaxError{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseOr() (Predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(t qbittorrent.TorrentInfo) bool { return l(t) || right(t) }
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Predicate, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(t qbittorrent.TorrentInfo) bool { return l(t) && right(t) }
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Predicate, error) {
+	if p.accept("!") {
+		pred, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(t qbittorrent.TorrentInfo) bool { return !pred(t) }, nil
+	}
+	if p.accept("(") {
+		pred, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.errorf("expected )")
+		}
+		return pred, nil
+	}
+	return p.parseComparison()
+}
+
+// operators are the comparison operators
+var operators = map[string]bool{
+	"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+	"contains": true, "matches": true,
+}
+
+func (p *parser) parseComparison() (Predicate, error) {
+	if p.done() {
+		return nil, p.errorf("expected field")
+	}
+	name := p.peek()
+	f, ok := fields[name.text]
+	if !ok || name.quoted {
+		return nil, p.errorf("unknown field %q", name.text)
+	}
+	p.next++
+
+	op := p.peek()
+	if p.done() || op.quoted || !operators[op.text] {
+		if f.kind == kindBool {
+			return f.flag, nil
+		}
+		return nil, p.errorf("expected operator after %s", name.text)
+	}
+	p.next++
+	if p.done() {
+		return nil, p.errorf("expected value after %s", op.text)
+	}
+	value := p.peek()
+	p.next++
+
+	pred, err := compare(f, op.text, value.text)
+	if err != nil {
+		return nil, &SyntaxError{Pos: value.pos, Msg: fmt.Sprintf("%s %s: %v", name.text, op.text, err)}
+	}
+	return pred, nil
+}
+
+// compare returns a predicate comparing field f with value
+func compare(f field, op, value string) (Predicate, error) {
+	switch f.kind {
+	case kindString:
+		return compareString(f.str, op, value)
+	case kindTags:
+		return compareTags(f.values, op, value)
+	case kindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "==":
+			return func(t qbittorrent.TorrentInfo) bool { return f.flag(t) == b }, nil
+		case "!=":
+			return func(t qbittorrent.TorrentInfo) bool { return f.flag(t) != b }, nil
+		}
+		return nil, fmt.Errorf("unsupported operator for a boolean")
+	}
+
+	var (
+		n   float64
+		err error
+	)
+	switch f.kind {
+	case kindSize:
+		n, err = parseSize(value)
+	case kindDuration, kindTime:
+		n, err = parseDuration(value)
+	default:
+		n, err = strconv.ParseFloat(value, 64)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cmp, err := compareNumbers(op)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.kind == kindTime {
+		return func(t qbittorrent.TorrentInfo) bool {
+			ts := f.num(t)
+			if ts <= 0 {
+				return false
+			}
+			return cmp(float64(time.Now().Unix())-ts, n)
+		}, nil
+	}
+	return func(t qbittorrent.TorrentInfo) bool { return cmp(f.num(t), n) }, nil
+}
+
+func compareString(get func(qbittorrent.TorrentInfo) string, op, value string) (Predicate, error) {
+	switch op {
+	case "==":
+		return func(t qbittorrent.TorrentInfo) bool { return get(t) == value }, nil
+	case "!=":
+		return func(t qbittorrent.TorrentInfo) bool { return get(t) != value }, nil
+	case "contains":
+		lower := strings.ToLower(value)
+		return func(t qbittorrent.TorrentInfo) bool { return strings.Contains(strings.ToLower(get(t)), lower) }, nil
+	case "matches":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(t qbittorrent.TorrentInfo) bool { return re.MatchString(get(t)) }, nil
+	}
+	return nil, fmt.Errorf("unsupported operator for a string")
+}
+
+func compareTags(get func(qbittorrent.TorrentInfo) []string, op, value string) (Predicate, error) {
+	has := func(t qbittorrent.TorrentInfo) bool {
+		for _, tag := range get(t) {
+			if tag == value {
+				return true
+			}
+		}
+		return false
+	}
+	switch op {
+	case "contains", "==":
+		return has, nil
+	case "!=":
+		return func(t qbittorrent.TorrentInfo) bool { return !has(t) }, nil
+	}
+	return nil, fmt.Errorf("unsupported operator for tags")
+}
+
+func compareNumbers(op string) (func(a, b float64) bool, error) {
+	switch op {
+	case "==":
+		return func(a, b float64) bool { return a == b }, nil
+	case "!=":
+		return func(a, b float64) bool { return a != b }, nil
+	case "<":
+		return func(a, b float64) bool { return a < b }, nil
+	case "<=":
+		return func(a, b float64) bool { return a <= b }, nil
+	case ">":
+		return func(a, b float64) bool { return a > b }, nil
+	case ">=":
+		return func(a, b float64) bool { return a >= b }, nil
+	}
+	return nil, fmt.Errorf("unsupported operator for a number")
+}
+
+// sizeUnits are the multipliers of size units, matched case-insensitively
+var sizeUnits = []struct {
+	suffix string
+	factor float64
+}{
+	{"kib", 1 << 10}, {"mib", 1 << 20}, {"gib", 1 << 30}, {"tib", 1 << 40},
+	{"kb", 1e3}, {"mb", 1e6}, {"gb", 1e9}, {"tb", 1e12},
+	{"k", 1 << 10}, {"m", 1 << 20}, {"g", 1 << 30}, {"t", 1 << 40},
+	{"b", 1},
+}
+
+// parseSize parses a number of bytes with an optional unit
+func parseSize(s string) (float64, error) {
+	lower := strings.ToLower(strings.TrimSuffix(s, "/s"))
+	for _, unit := range sizeUnits {
+		if n, ok := strings.CutSuffix(lower, unit.suffix); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid size %q", s)
+			}
+			return v * unit.factor, nil
+		}
+	}
+	v, err := strconv.ParseFloat(lower, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return v, nil
+}
+
+// durationUnits are the seconds of duration units
+var durationUnits = map[byte]float64{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 7 * 86400, 'y': 365 * 86400}
+
+// parseDuration parses a number of seconds with an optional unit
+func parseDuration(s string) (float64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	factor, ok := durationUnits[s[len(s)-1]]
+	n := s
+	if ok {
+		n = s[:len(s)-1]
+	} else {
+		factor = 1
+	}
+	v, err := strconv.ParseFloat(n, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return v * factor, nil
+}
@@ -0,0 +1,83 @@
+package filter_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cehbz/qbittorrent"
+	"github.com/cehbz/qbittorrent/filter"
+)
+
+func TestCompile(t *testing.T) {
+	now := time.Now().Unix()
+	old := qbittorrent.TorrentInfo{
+		Name: "Show S01", Tracker: "https://flacsfor.me/REDACTED/announce", Ratio: 0.5,
+		AddedOn: now - 40*86400, Size: 2 << 30, Tags: []string{"hd", "tv"}, IsPrivate: true, State: "stalledUP",
+	}
+	fresh := qbittorrent.TorrentInfo{
+		Name: "Movie", Tracker: "udp://open.tracker:1337", Ratio: 2,
+		AddedOn: now - 3600, Size: 700 << 20, SeedingTime: 7200, State: "uploading",
+	}
+
+	tests := []struct {
+		expr  string
+		old   bool
+		fresh bool
+	}{
+		{"", true, true},
+		{"tracker contains redacted && ratio < 1.0 && added_on > 30d", true, false},
+		{"added_on < 1d", false, true},
+		{"completion_on > 1d", false, false},
+		{"size >= 1GiB", true, false},
+		{"size < 1GB && size > 500MB", false, true},
+		{"tags contains hd", true, false},
+		{"tags != hd", false, true},
+		{"private && !force_start", true, false},
+		{"private == false", false, true},
+		{`name == "Show S01" || seeding_time >= 2h`, true, true},
+		{"!(state == uploading || state == stalledUP)", false, false},
+		{"name matches '^Mov'", false, true},
+		{"ratio >= 1 || ratio < 1 && private", true, true},
+	}
+	for _, tt := range tests {
+		pred, err := filter.Compile(tt.expr)
+		if err != nil {
+			t.Errorf("Compile(%q): expected no error, got %v", tt.expr, err)
+			continue
+		}
+		if got := pred(old); got != tt.old {
+			t.Errorf("Compile(%q) on old torrent: expected %v, got %v", tt.expr, tt.old, got)
+		}
+		if got := pred(fresh); got != tt.fresh {
+			t.Errorf("Compile(%q) on fresh torrent: expected %v, got %v", tt.expr, tt.fresh, got)
+		}
+	}
+
+	if matched := filter.Apply([]qbittorrent.TorrentInfo{old, fresh}, filter.MustCompile("ratio > 1")); len(matched) != 1 || matched[0].Name != "Movie" {
+		t.Errorf("unexpected matches %+v", matched)
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	for _, expr := range []string{
+		"nope == 1",
+		"ratio",
+		"ratio <",
+		"ratio < abc",
+		"size > 1XB",
+		"added_on > soon",
+		"(ratio < 1",
+		"ratio < 1 ratio",
+		`name == "unterminated`,
+		"name < x",
+		"name matches '('",
+		"ratio < 1 & private",
+	} {
+		_, err := filter.Compile(expr)
+		var syntaxErr *filter.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Compile(%q): expected syntax error, got %v", expr, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package qbittorrent
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// MaxETA is the ETA qBittorrent reports for torrents that won't complete, 100 days
+const MaxETA = 8640000
+
+// sizeUnits are the units of FormatBytes
+var sizeUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// FormatBytes formats a size like the WebUI does, in binary units truncated
+// rather than rounded to a precision depending on the unit, e.g. "700 B",
+// "1.4 MiB" or "1.50 GiB". Negative sizes are unknown.
+func FormatBytes(n int64) string {
+	if n < 0 {
+		return "Unknown"
+	}
+	if n < 1024 {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+	v := float64(n)
+	unit := 0
+	for v >= 1024 && unit < len(sizeUnits)-1 {
+		v /= 1024
+		unit++
+	}
+	return formatFloor(v, unitPrecision(unit)) + " " + sizeUnits[unit]
+}
+
+// FormatSpeed formats a rate in bytes per second like the WebUI does, e.g. "1.4 MiB/s"
+func FormatSpeed(bytesPerSecond int64) string {
+	return FormatBytes(bytesPerSecond) + "/s"
+}
+
+// FormatETA formats an ETA in seconds like the WebUI does, see FormatDuration.
+// Negative values and MaxETA or more are shown as "∞".
+func FormatETA(seconds int64) string {
+	if seconds >= MaxETA {
+		return "∞"
+	}
+	return FormatDuration(seconds)
+}
+
+// FormatDuration formats a number of seconds like the WebUI formats durations
+// such as the seeding time, with the two most significant units, e.g. "1h 2m"
+// or "3d 4h". Negative values are shown as "∞".
+func FormatDuration(seconds int64) string {
+	switch {
+	case seconds < 0:
+		return "∞"
+	case seconds == 0:
+		return "0"
+	case seconds < 60:
+		return "< 1m"
+	}
+	minutes := seconds / 60
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	hours := minutes / 60
+	if hours < 24 {
+		return fmt.Sprintf("%dh %dm", hours, minutes%60)
+	}
+	days := hours / 24
+	if days < 365 {
+		return fmt.Sprintf("%dd %dh", days, hours%24)
+	}
+	return fmt.Sprintf("%dy %dd", days/365, days%365)
+}
+
+// FormatRatio formats a share ratio like the WebUI does, truncated to two
+// decimals. qBittorrent reports -1 for an infinite ratio, and like the WebUI
+// ratios above 9999 are shown as "∞" too.
+func FormatRatio(ratio float64) string {
+	if ratio < 0 || ratio > 9999 {
+		return "∞"
+	}
+	return formatFloor(ratio, 2)
+}
+
+// unitPrecision returns the decimals shown for a unit of sizeUnits
+func unitPrecision(unit int) int {
+	switch {
+	case unit == 0:
+		return 0
+	case unit <= 2:
+		return 1
+	case unit == 3:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// formatFloor formats v truncated to the given number of decimals, so that
+// e.g. a 99.99% complete size isn't shown as complete
+func formatFloor(v float64, decimals int) string {
+	offset := math.Pow(10, float64(decimals))
+	return strconv.FormatFloat(math.Floor(v*offset)/offset, 'f', decimals, 64)
+}
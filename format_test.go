@@ -0,0 +1,69 @@
+package qbittorrent
+
+import "testing"
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n        int64
+		expected string
+	}{
+		{-1, "Unknown"},
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536*1024 - 1, "1.4 MiB"},
+		{1536 * 1024 * 1024, "1.50 GiB"},
+		{2*1024*1024*1024*1024 - 1, "1.999 TiB"},
+	}
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n); got != tt.expected {
+			t.Errorf("FormatBytes(%d): expected %q, got %q", tt.n, tt.expected, got)
+		}
+	}
+	if got := FormatSpeed(2048); got != "2.0 KiB/s" {
+		t.Errorf("expected 2.0 KiB/s, got %q", got)
+	}
+}
+
+func TestFormatETA(t *testing.T) {
+	tests := []struct {
+		seconds  int64
+		expected string
+	}{
+		{-1, "∞"},
+		{MaxETA, "∞"},
+		{0, "0"},
+		{59, "< 1m"},
+		{60 * 59, "59m"},
+		{3725, "1h 2m"},
+		{3*86400 + 4*3600 + 59, "3d 4h"},
+	}
+	for _, tt := range tests {
+		if got := FormatETA(tt.seconds); got != tt.expected {
+			t.Errorf("FormatETA(%d): expected %q, got %q", tt.seconds, tt.expected, got)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	if got := FormatDuration(400 * 86400); got != "1y 35d" {
+		t.Errorf("expected 1y 35d, got %q", got)
+	}
+}
+
+func TestFormatRatio(t *testing.T) {
+	tests := []struct {
+		ratio    float64
+		expected string
+	}{
+		{-1, "∞"},
+		{10000, "∞"},
+		{0, "0.00"},
+		{1.239, "1.23"},
+	}
+	for _, tt := range tests {
+		if got := FormatRatio(tt.ratio); got != tt.expected {
+			t.Errorf("FormatRatio(%v): expected %q, got %q", tt.ratio, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package qbittorrent
+
+import (
+	"sync"
+	"time"
+)
+
+// FreeSpaceAlertKind identifies the condition a FreeSpaceAlert reports
+type FreeSpaceAlertKind int
+
+const (
+	// FreeSpaceLow means free space dropped below the absolute threshold
+	FreeSpaceLow FreeSpaceAlertKind = iota
+	// FreeSpaceFillingUp means the projected time until the disk is full
+	// dropped below the threshold
+	FreeSpaceFillingUp
+)
+
+func (k FreeSpaceAlertKind) String() string {
+	switch k {
+	case FreeSpaceLow:
+		return "free space low"
+	case FreeSpaceFillingUp:
+		return "disk filling up"
+	default:
+		return "unknown"
+	}
+}
+
+// FreeSpaceAlert is emitted when a free-space condition starts or stops holding
+type FreeSpaceAlert struct {
+	Kind       FreeSpaceAlertKind
+	Cleared    bool // the condition no longer holds
+	At         time.Time
+	FreeSpace  int64
+	FillRate   float64       // bytes per second, positive while the disk fills up
+	TimeToFull time.Duration // zero unless the disk is filling up
+}
+
+// FreeSpaceThresholds configures a FreeSpaceWatcher. Zero values disable a check.
+type FreeSpaceThresholds struct {
+	MinFree       int64         // alert when free space drops below this many bytes
+	MinTimeToFull time.Duration // alert when the disk is projected to fill up sooner
+	Window        time.Duration // period the fill rate is computed over, 1 hour by default
+}
+
+// FreeSpaceWatcher tracks ServerState.FreeSpaceOnDisk across polls, computes
+// the fill rate, and calls OnAlert whenever a threshold is crossed in either
+// direction. A FreeSpaceWatcher is safe for concurrent use.
+type FreeSpaceWatcher struct {
+	thresholds FreeSpaceThresholds
+	onAlert    func(FreeSpaceAlert)
+
+	mu      sync.Mutex
+	samples []freeSpaceSample
+	active  map[FreeSpaceAlertKind]bool
+}
+
+type freeSpaceSample struct {
+	at   time.Time
+	free int64
+}
+
+// NewFreeSpaceWatcher returns a watcher calling onAlert when thresholds are crossed
+func NewFreeSpaceWatcher(thresholds FreeSpaceThresholds, onAlert func(FreeSpaceAlert)) *FreeSpaceWatcher {
+	if thresholds.Window <= 0 {
+		thresholds.Window = time.Hour
+	}
+	return &FreeSpaceWatcher{
+		thresholds: thresholds,
+		onAlert:    onAlert,
+		active:     make(map[FreeSpaceAlertKind]bool),
+	}
+}
+
+// Observe records the free space of a server state sampled at the given time
+func (w *FreeSpaceWatcher) Observe(at time.Time, state ServerState) {
+	w.mu.Lock()
+	if n := len(w.samples); n > 0 && at.Before(w.samples[n-1].at) {
+		w.mu.Unlock()
+		return
+	}
+	w.samples = append(w.samples, freeSpaceSample{at: at, free: state.FreeSpaceOnDisk})
+	cutoff := at.Add(-w.thresholds.Window)
+	drop := 0
+	for drop+1 < len(w.samples) && !w.samples[drop+1].at.After(cutoff) {
+		drop++
+	}
+	w.samples = w.samples[drop:]
+
+	fillRate := w.fillRate()
+	timeToFull, filling := projectTimeToFull(state.FreeSpaceOnDisk, fillRate)
+
+	var alerts []FreeSpaceAlert
+	check := func(kind FreeSpaceAlertKind, enabled, holds bool) {
+		if !enabled || holds == w.active[kind] {
+			return
+		}
+		w.active[kind] = holds
+		alert := FreeSpaceAlert{
+			Kind:      kind,
+			Cleared:   !holds,
+			At:        at,
+			FreeSpace: state.FreeSpaceOnDisk,
+			FillRate:  fillRate,
+		}
+		if filling {
+			alert.TimeToFull = timeToFull
+		}
+		alerts = append(alerts, alert)
+	}
+	check(FreeSpaceLow, w.thresholds.MinFree > 0, state.FreeSpaceOnDisk < w.thresholds.MinFree)
+	check(FreeSpaceFillingUp, w.thresholds.MinTimeToFull > 0, filling && timeToFull < w.thresholds.MinTimeToFull)
+	w.mu.Unlock()
+
+	// Call back without holding the lock so handlers may query the watcher
+	if w.onAlert != nil {
+		for _, alert := range alerts {
+			w.onAlert(alert)
+		}
+	}
+}
+
+// fillRate returns the bytes per second consumed over the window
+func (w *FreeSpaceWatcher) fillRate() float64 {
+	if len(w.samples) < 2 {
+		return 0
+	}
+	first, last := w.samples[0], w.samples[len(w.samples)-1]
+	return rate(first.free-last.free, last.at.Sub(first.at))
+}
+
+// projectTimeToFull returns how long until free space runs out at fillRate
+func projectTimeToFull(free int64, fillRate float64) (time.Duration, bool) {
+	if fillRate <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(free) / fillRate * float64(time.Second)), true
+}
+
+// FillRate returns the bytes per second consumed over the window, negative
+// while space is being freed
+func (w *FreeSpaceWatcher) FillRate() float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.fillRate()
+}
+
+// TimeToFull returns the projected time until the disk is full, and false
+// if the disk isn't filling up
+func (w *FreeSpaceWatcher) TimeToFull() (time.Duration, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.samples) == 0 {
+		return 0, false
+	}
+	return projectTimeToFull(w.samples[len(w.samples)-1].free, w.fillRate())
+}
@@ -0,0 +1,67 @@
+package qbittorrent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFreeSpaceWatcher(t *testing.T) {
+	var alerts []FreeSpaceAlert
+	watcher := NewFreeSpaceWatcher(FreeSpaceThresholds{
+		MinFree:       100 << 30,
+		MinTimeToFull: 24 * time.Hour,
+		Window:        time.Hour,
+	}, func(alert FreeSpaceAlert) {
+		alerts = append(alerts, alert)
+	})
+
+	start := time.Unix(1700000000, 0)
+	// 1 TiB free, filling at 10 GiB per hour: full in ~102 hours, no alert
+	for m := 0; m <= 60; m += 10 {
+		watcher.Observe(start.Add(time.Duration(m)*time.Minute), ServerState{FreeSpaceOnDisk: 1<<40 - int64(m)*(10<<30)/60})
+	}
+	if len(alerts) != 0 {
+		t.Fatalf("expected no alerts, got %+v", alerts)
+	}
+	if rate := watcher.FillRate(); rate < 2.98e6 || rate > 2.99e6 {
+		t.Errorf("expected fill rate of 10 GiB/h, got %f B/s", rate)
+	}
+
+	// A burst of 2 GiB per minute projects the disk to be full within a day
+	at := start.Add(time.Hour)
+	free := int64(1<<40 - 10<<30)
+	for m := 1; m <= 30; m++ {
+		free -= 2 << 30
+		watcher.Observe(at.Add(time.Duration(m)*time.Minute), ServerState{FreeSpaceOnDisk: free})
+	}
+	if len(alerts) != 1 || alerts[0].Kind != FreeSpaceFillingUp || alerts[0].Cleared {
+		t.Fatalf("expected a single filling up alert, got %+v", alerts)
+	}
+	if ttf, ok := watcher.TimeToFull(); !ok || ttf > 24*time.Hour {
+		t.Errorf("expected time to full below a day, got %v", ttf)
+	}
+
+	// Dropping below the absolute threshold alerts once, and clears after cleanup
+	at = at.Add(30 * time.Minute)
+	watcher.Observe(at.Add(time.Minute), ServerState{FreeSpaceOnDisk: 50 << 30})
+	watcher.Observe(at.Add(2*time.Minute), ServerState{FreeSpaceOnDisk: 40 << 30})
+	watcher.Observe(at.Add(90*time.Minute), ServerState{FreeSpaceOnDisk: 500 << 30})
+
+	var kinds []string
+	for _, alert := range alerts[1:] {
+		state := "raised"
+		if alert.Cleared {
+			state = "cleared"
+		}
+		kinds = append(kinds, alert.Kind.String()+" "+state)
+	}
+	expected := []string{"free space low raised", "free space low cleared", "disk filling up cleared"}
+	if len(kinds) != len(expected) {
+		t.Fatalf("expected alerts %v, got %v", expected, kinds)
+	}
+	for i := range expected {
+		if kinds[i] != expected[i] {
+			t.Errorf("expected alert %q, got %q", expected[i], kinds[i])
+		}
+	}
+}
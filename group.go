@@ -0,0 +1,83 @@
+package qbittorrent
+
+// TorrentGroup is a group of torrents with their aggregates
+type TorrentGroup struct {
+	Torrents   []TorrentInfo
+	Count      int
+	Size       int64 // size of the selected files
+	TotalSize  int64
+	Downloaded int64
+	Uploaded   int64
+	DLSpeed    int64
+	UpSpeed    int64
+}
+
+// Ratio returns the ratio of the group, uploaded over downloaded, or 0 if
+// nothing was downloaded
+func (g TorrentGroup) Ratio() float64 {
+	if g.Downloaded == 0 {
+		return 0
+	}
+	return float64(g.Uploaded) / float64(g.Downloaded)
+}
+
+// add adds a torrent to the group
+func (g *TorrentGroup) add(t TorrentInfo) {
+	g.Torrents = append(g.Torrents, t)
+	g.Count++
+	g.Size += t.Size
+	g.TotalSize += t.TotalSize
+	g.Downloaded += t.Downloaded
+	g.Uploaded += t.Uploaded
+	g.DLSpeed += t.DLSpeed
+	g.UpSpeed += t.UpSpeed
+}
+
+// GroupBy groups torrents by the keys returned for them, keeping their order
+// within each group. A torrent with several keys is counted in each of their
+// groups, and one without keys in none.
+func GroupBy[K comparable](torrents []TorrentInfo, keys func(t TorrentInfo) []K) map[K]TorrentGroup {
+	groups := make(map[K]TorrentGroup)
+	for _, t := range torrents {
+		for _, key := range keys(t) {
+			group := groups[key]
+			group.add(t)
+			groups[key] = group
+		}
+	}
+	return groups
+}
+
+// GroupByCategory groups torrents by category, with uncategorized torrents
+// under ""
+func GroupByCategory(torrents []TorrentInfo) map[string]TorrentGroup {
+	return GroupBy(torrents, func(t TorrentInfo) []string { return []string{t.Category} })
+}
+
+// GroupByTag groups torrents by tag, with untagged torrents under "". A
+// torrent with several tags is counted in each of their groups, so the
+// aggregates of the groups don't add up to those of all torrents.
+func GroupByTag(torrents []TorrentInfo) map[string]TorrentGroup {
+	return GroupBy(torrents, func(t TorrentInfo) []string {
+		if len(t.Tags) == 0 {
+			return []string{""}
+		}
+		return t.Tags
+	})
+}
+
+// GroupByTracker groups torrents by the RegistrableDomain of their current
+// tracker, with torrents without a working tracker under ""
+func GroupByTracker(torrents []TorrentInfo) map[string]TorrentGroup {
+	return GroupBy(torrents, func(t TorrentInfo) []string {
+		if t.Tracker == "" {
+			return []string{""}
+		}
+		return []string{RegistrableDomain(t.Tracker)}
+	})
+}
+
+// GroupByState groups torrents by state
+func GroupByState(torrents []TorrentInfo) map[TorrentState]TorrentGroup {
+	return GroupBy(torrents, func(t TorrentInfo) []TorrentState { return []TorrentState{TorrentState(t.State)} })
+}
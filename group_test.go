@@ -0,0 +1,71 @@
+package qbittorrent
+
+import (
+	"reflect"
+	"testing"
+)
+
+var groupTorrents = []TorrentInfo{
+	{Hash: "a", Category: "tv", Tags: []string{"hd", "keep"}, Tracker: "https://tracker.example.org/announce", State: "uploading", Size: 100, Downloaded: 100, Uploaded: 300, UpSpeed: 10},
+	{Hash: "b", Category: "tv", Tags: []string{"hd"}, Tracker: "https://announce.example.org/announce", State: "stalledUP", Size: 200, Downloaded: 200, Uploaded: 100},
+	{Hash: "c", Category: "", Tracker: "", State: "downloading", Size: 50, Downloaded: 10, DLSpeed: 5},
+}
+
+func groupHashes(group TorrentGroup) []InfoHash {
+	var hashes []InfoHash
+	for _, t := range group.Torrents {
+		hashes = append(hashes, t.Hash)
+	}
+	return hashes
+}
+
+func TestGroupByCategory(t *testing.T) {
+	groups := GroupByCategory(groupTorrents)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(groups))
+	}
+	tv := groups["tv"]
+	if tv.Count != 2 || tv.Size != 300 || tv.Downloaded != 300 || tv.Uploaded != 400 || tv.UpSpeed != 10 {
+		t.Errorf("unexpected tv group %+v", tv)
+	}
+	if !reflect.DeepEqual(groupHashes(tv), []InfoHash{"a", "b"}) {
+		t.Errorf("expected the torrents in order, got %v", groupHashes(tv))
+	}
+	if ratio := tv.Ratio(); ratio < 1.33 || ratio > 1.34 {
+		t.Errorf("expected a ratio of 4/3, got %f", ratio)
+	}
+	if uncategorized := groups[""]; uncategorized.Count != 1 || uncategorized.DLSpeed != 5 {
+		t.Errorf("unexpected uncategorized group %+v", uncategorized)
+	}
+}
+
+func TestGroupByTag(t *testing.T) {
+	groups := GroupByTag(groupTorrents)
+	counts := map[string]int{}
+	for tag, group := range groups {
+		counts[tag] = group.Count
+	}
+	if !reflect.DeepEqual(counts, map[string]int{"hd": 2, "keep": 1, "": 1}) {
+		t.Errorf("unexpected tag groups %v", counts)
+	}
+}
+
+func TestGroupByTracker(t *testing.T) {
+	groups := GroupByTracker(groupTorrents)
+	if !reflect.DeepEqual(groupHashes(groups["example.org"]), []InfoHash{"a", "b"}) {
+		t.Errorf("expected the trackers of a domain together, got %v", groupHashes(groups["example.org"]))
+	}
+	if !reflect.DeepEqual(groupHashes(groups[""]), []InfoHash{"c"}) {
+		t.Errorf("expected torrents without a tracker under \"\", got %v", groupHashes(groups[""]))
+	}
+}
+
+func TestGroupByState(t *testing.T) {
+	groups := GroupByState(groupTorrents)
+	if len(groups) != 3 || groups[StateUploading].Count != 1 || groups[StateDownloading].Size != 50 {
+		t.Errorf("unexpected state groups %+v", groups)
+	}
+	if ratio := (TorrentGroup{}).Ratio(); ratio != 0 {
+		t.Errorf("expected a zero ratio without downloads, got %f", ratio)
+	}
+}
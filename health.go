@@ -0,0 +1,133 @@
+package qbittorrent
+
+import (
+	"math"
+	"time"
+)
+
+// StallLimit is how long a download may go without activity before its stall
+// score drops to zero
+const StallLimit = 7 * 24 * time.Hour
+
+// HealthScore rates a torrent between 0 (unhealthy) and 1 (healthy). The
+// components are scored on the same scale, so dashboards can show why a
+// torrent ranks low.
+type HealthScore struct {
+	Score            float64       // weighted mean of the components
+	SeedAvailability float64       // complete copies available to finish the download
+	TrackerStatus    float64       // whether a tracker is working
+	Stall            float64       // how recently a download made progress
+	RatioProgress    float64       // share ratio relative to the ratio goal
+	Stalled          time.Duration // time since the last activity, zero if active
+}
+
+// health score weights, summing to 1
+const (
+	seedAvailabilityWeight = 0.35
+	trackerStatusWeight    = 0.25
+	stallWeight            = 0.25
+	ratioProgressWeight    = 0.15
+)
+
+// Health scores a torrent from its info, trackers and, optionally, properties.
+// Scores are comparable across torrents, so they can be used to rank them.
+func Health(torrent TorrentInfo, trackers []TrackerInfo, properties *TorrentsProperties) HealthScore {
+	return healthAt(SystemClock.Now(), torrent, trackers, properties)
+}
+
+// Health scores a torrent like the Health function, as of the time of the
+// client's clock
+func (c *Client) Health(torrent TorrentInfo, trackers []TrackerInfo, properties *TorrentsProperties) HealthScore {
+	return healthAt(c.Clock().Now(), torrent, trackers, properties)
+}
+
+// healthAt scores a torrent as of the given time
+func healthAt(now time.Time, torrent TorrentInfo, trackers []TrackerInfo, properties *TorrentsProperties) HealthScore {
+	h := HealthScore{
+		SeedAvailability: seedAvailability(torrent, properties),
+		TrackerStatus:    trackerStatus(trackers),
+		Stall:            1,
+		RatioProgress:    ratioProgress(torrent, properties),
+	}
+
+	if torrent.LastActivity > 0 {
+		if idle := now.Sub(time.Unix(torrent.LastActivity, 0)); idle > 0 {
+			h.Stalled = idle
+		}
+	}
+	// Seeding torrents are expected to be idle when nobody is leeching
+	if torrent.Progress < 1 {
+		h.Stall = clamp01(1 - float64(h.Stalled)/float64(StallLimit))
+	}
+
+	h.Score = seedAvailabilityWeight*h.SeedAvailability +
+		trackerStatusWeight*h.TrackerStatus +
+		stallWeight*h.Stall +
+		ratioProgressWeight*h.RatioProgress
+	return h
+}
+
+// seedAvailability scores whether the swarm holds enough pieces to complete
+func seedAvailability(torrent TorrentInfo, properties *TorrentsProperties) float64 {
+	if torrent.Progress >= 1 {
+		return 1
+	}
+	// Availability is the number of distributed copies, -1 while unknown
+	if torrent.Availability >= 0 {
+		return clamp01(torrent.Availability)
+	}
+	seeds := torrent.NumComplete
+	if properties != nil && int64(properties.SeedsTotal) > seeds {
+		seeds = int64(properties.SeedsTotal)
+	}
+	if seeds > 0 {
+		return 1
+	}
+	return 0
+}
+
+// trackerStatus scores the best tracker status. DHT, PeX and LSD are reported
+// as disabled trackers and don't count.
+func trackerStatus(trackers []TrackerInfo) float64 {
+	best := -1.0
+	for _, tracker := range trackers {
+		var score float64
+		switch {
+		case tracker.Status.Disabled():
+			continue
+		case tracker.Status.Working():
+			score = 1
+		case tracker.Status.NotContacted():
+			score = 0.5
+		default:
+			score = 0
+		}
+		best = math.Max(best, score)
+	}
+	if best < 0 {
+		// Without trackers the torrent relies on DHT, which may or may not work
+		return 0.5
+	}
+	return best
+}
+
+// ratioProgress scores the share ratio against the torrent's ratio limit, or
+// its global maximum ratio, or 1
+func ratioProgress(torrent TorrentInfo, properties *TorrentsProperties) float64 {
+	goal := 1.0
+	// Negative limits mean the global limit applies, or none
+	if torrent.RatioLimit > 0 {
+		goal = torrent.RatioLimit
+	} else if torrent.MaxRatio > 0 {
+		goal = torrent.MaxRatio
+	}
+	ratio := torrent.Ratio
+	if properties != nil && properties.ShareRatio > ratio {
+		ratio = properties.ShareRatio
+	}
+	return clamp01(ratio / goal)
+}
+
+func clamp01(v float64) float64 {
+	return math.Min(math.Max(v, 0), 1)
+}
@@ -0,0 +1,66 @@
+package qbittorrent
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHealth(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	working := []TrackerInfo{
+		{URL: "** [DHT] **", Status: TrackerDisabled},
+		{URL: "https://tracker.example.org/announce", Status: TrackerWorking},
+	}
+
+	tests := []struct {
+		name       string
+		torrent    TorrentInfo
+		trackers   []TrackerInfo
+		properties *TorrentsProperties
+		expected   HealthScore
+	}{
+		{
+			name:     "seeding past its ratio goal",
+			torrent:  TorrentInfo{Progress: 1, Ratio: 2.5, RatioLimit: 2, LastActivity: now.Add(-48 * time.Hour).Unix()},
+			trackers: working,
+			expected: HealthScore{Score: 1, SeedAvailability: 1, TrackerStatus: 1, Stall: 1, RatioProgress: 1, Stalled: 48 * time.Hour},
+		},
+		{
+			name:     "stalled download without seeds",
+			torrent:  TorrentInfo{Progress: 0.4, Availability: 0.4, RatioLimit: -2, LastActivity: now.Add(-StallLimit).Unix()},
+			trackers: []TrackerInfo{{Status: TrackerNotWorking}},
+			expected: HealthScore{Score: 0.35 * 0.4, SeedAvailability: 0.4, Stalled: StallLimit},
+		},
+		{
+			name:       "unknown availability falls back to seeds",
+			torrent:    TorrentInfo{Progress: 0.5, Availability: -1, LastActivity: now.Unix()},
+			properties: &TorrentsProperties{SeedsTotal: 3, ShareRatio: 0.5},
+			expected:   HealthScore{Score: 0.35 + 0.25*0.5 + 0.25 + 0.15*0.5, SeedAvailability: 1, TrackerStatus: 0.5, Stall: 1, RatioProgress: 0.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := healthAt(now, tt.torrent, tt.trackers, tt.properties)
+			if math.Abs(h.Score-tt.expected.Score) > 1e-9 {
+				t.Errorf("expected score %f, got %f", tt.expected.Score, h.Score)
+			}
+			h.Score = tt.expected.Score
+			if h != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, h)
+			}
+		})
+	}
+}
+
+func TestClient_Health(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	client := &Client{clock: &stubClock{now: now}}
+	torrent := TorrentInfo{Progress: 1, LastActivity: now.Add(-time.Hour).Unix()}
+
+	h := client.Health(torrent, nil, nil)
+	if h.Stalled != time.Hour {
+		t.Errorf("expected the stall as of the client's clock, got %v", h.Stalled)
+	}
+}
@@ -0,0 +1,180 @@
+package qbittorrent
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TorrentSample is a point-in-time measurement of a torrent's activity
+type TorrentSample struct {
+	Time       time.Time
+	Hash       InfoHash
+	DLSpeed    int64
+	UpSpeed    int64
+	Downloaded int64
+	Uploaded   int64
+	Ratio      float64
+	Progress   float64
+	NumSeeds   int64
+	NumLeechs  int64
+	State      string
+	Tracker    string // the current tracker, empty if none works
+}
+
+// newTorrentSample samples a torrent at the given time
+func newTorrentSample(at time.Time, torrent TorrentInfo) TorrentSample {
+	return TorrentSample{
+		Time:       at,
+		Hash:       torrent.Hash,
+		DLSpeed:    torrent.DLSpeed,
+		UpSpeed:    torrent.UpSpeed,
+		Downloaded: torrent.Downloaded,
+		Uploaded:   torrent.Uploaded,
+		Ratio:      torrent.Ratio,
+		Progress:   torrent.Progress,
+		NumSeeds:   torrent.NumSeeds,
+		NumLeechs:  torrent.NumLeechs,
+		State:      torrent.State,
+		Tracker:    torrent.Tracker,
+	}
+}
+
+// HistorySink stores torrent samples. Each call to Record receives one sample
+// for every torrent present at that time.
+type HistorySink interface {
+	Record(samples []TorrentSample) error
+}
+
+// HistorySinkFunc adapts a function to the HistorySink interface
+type HistorySinkFunc func(samples []TorrentSample) error
+
+// Record calls f(samples)
+func (f HistorySinkFunc) Record(samples []TorrentSample) error {
+	return f(samples)
+}
+
+// RingBuffer is an in-memory HistorySink keeping the most recent samples of
+// each torrent. Torrents missing from a batch are forgotten.
+// A RingBuffer is safe for concurrent use.
+type RingBuffer struct {
+	mu       sync.RWMutex
+	capacity int
+	samples  map[InfoHash]*ring
+}
+
+// ring is a fixed-size circular buffer of samples
+type ring struct {
+	samples []TorrentSample
+	next    int
+	full    bool
+}
+
+// NewRingBuffer returns a RingBuffer keeping up to capacity samples per torrent
+func NewRingBuffer(capacity int) *RingBuffer {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &RingBuffer{
+		capacity: capacity,
+		samples:  make(map[InfoHash]*ring),
+	}
+}
+
+// Record implements the HistorySink interface
+func (b *RingBuffer) Record(samples []TorrentSample) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	present := make(map[InfoHash]struct{}, len(samples))
+	for _, sample := range samples {
+		present[sample.Hash] = struct{}{}
+		r, ok := b.samples[sample.Hash]
+		if !ok {
+			r = &ring{samples: make([]TorrentSample, b.capacity)}
+			b.samples[sample.Hash] = r
+		}
+		r.samples[r.next] = sample
+		r.next = (r.next + 1) % b.capacity
+		if r.next == 0 {
+			r.full = true
+		}
+	}
+	for hash := range b.samples {
+		if _, ok := present[hash]; !ok {
+			delete(b.samples, hash)
+		}
+	}
+	return nil
+}
+
+// Samples returns the stored samples of a torrent, oldest first
+func (b *RingBuffer) Samples(hash InfoHash) []TorrentSample {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	r, ok := b.samples[hash]
+	if !ok {
+		return nil
+	}
+	if !r.full {
+		return append([]TorrentSample(nil), r.samples[:r.next]...)
+	}
+	return append(append([]TorrentSample(nil), r.samples[r.next:]...), r.samples[:r.next]...)
+}
+
+// Hashes returns the torrents with stored samples
+func (b *RingBuffer) Hashes() []InfoHash {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	hashes := make([]InfoHash, 0, len(b.samples))
+	for hash := range b.samples {
+		hashes = append(hashes, hash)
+	}
+	return hashes
+}
+
+// HistoryRecorder samples the activity of every torrent over time
+type HistoryRecorder struct {
+	sink HistorySink
+}
+
+// NewHistoryRecorder returns a recorder storing samples in sink
+func NewHistoryRecorder(sink HistorySink) *HistoryRecorder {
+	return &HistoryRecorder{sink: sink}
+}
+
+// Observe records a sample of every torrent at the given time
+func (r *HistoryRecorder) Observe(at time.Time, torrents map[string]TorrentInfo) error {
+	samples := make([]TorrentSample, 0, len(torrents))
+	for hash, torrent := range torrents {
+		if torrent.Hash == "" {
+			torrent.Hash = InfoHash(hash)
+		}
+		samples = append(samples, newTorrentSample(at, torrent))
+	}
+	return r.sink.Record(samples)
+}
+
+// Run polls the sync endpoint every interval and records the torrents until
+// ctx is done or an error occurs
+func (r *HistoryRecorder) Run(ctx context.Context, c *Client, interval time.Duration) error {
+	state := NewSyncState()
+	ticker := c.Clock().NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := state.Update(ctx, c); err != nil {
+			return err
+		}
+		if err := r.Observe(c.Clock().Now(), state.Torrents()); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRingBuffer(t *testing.T) {
+	buffer := NewRingBuffer(3)
+	start := time.Unix(1700000000, 0)
+
+	for i := 0; i < 5; i++ {
+		samples := []TorrentSample{{Time: start.Add(time.Duration(i) * time.Minute), Hash: "hash1", UpSpeed: int64(i)}}
+		if i < 2 {
+			samples = append(samples, TorrentSample{Hash: "hash2"})
+		}
+		if err := buffer.Record(samples); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	samples := buffer.Samples("hash1")
+	if len(samples) != 3 {
+		t.Fatalf("expected 3 samples, got %d", len(samples))
+	}
+	for i, sample := range samples {
+		if sample.UpSpeed != int64(i+2) {
+			t.Errorf("expected sample %d to have speed %d, got %d", i, i+2, sample.UpSpeed)
+		}
+	}
+	if samples := buffer.Samples("hash2"); samples != nil {
+		t.Errorf("expected removed torrent to be forgotten, got %v", samples)
+	}
+	if hashes := buffer.Hashes(); len(hashes) != 1 || hashes[0] != "hash1" {
+		t.Errorf("expected hashes [hash1], got %v", hashes)
+	}
+}
+
+func TestHistoryRecorder_Run(t *testing.T) {
+	var polls int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&polls, 1) {
+		case 1:
+			w.Write([]byte(`{"rid":1,"full_update":true,"torrents":{"hash1":{"name":"a","upspeed":100,"ratio":0.5,"num_leechs":3}}}`))
+		default:
+			w.Write([]byte(`{"rid":2,"torrents":{"hash1":{"upspeed":300}}}`))
+		}
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	buffer := NewRingBuffer(10)
+	recorder := NewHistoryRecorder(buffer)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- recorder.Run(ctx, client, time.Millisecond) }()
+	for len(buffer.Samples("hash1")) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	samples := buffer.Samples("hash1")
+	if samples[0].UpSpeed != 100 || samples[1].UpSpeed != 300 {
+		t.Errorf("expected speeds 100 then 300, got %d and %d", samples[0].UpSpeed, samples[1].UpSpeed)
+	}
+	if samples[1].Ratio != 0.5 || samples[1].NumLeechs != 3 {
+		t.Errorf("expected unchanged fields to carry over, got %+v", samples[1])
+	}
+}
+
+func TestHistorySinkFunc(t *testing.T) {
+	var recorded []TorrentSample
+	recorder := NewHistoryRecorder(HistorySinkFunc(func(samples []TorrentSample) error {
+		recorded = append(recorded, samples...)
+		return nil
+	}))
+
+	at := time.Unix(1700000000, 0)
+	if err := recorder.Observe(at, map[string]TorrentInfo{"hash1": {DLSpeed: 42}}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(recorded) != 1 || recorded[0].Hash != "hash1" || recorded[0].DLSpeed != 42 || !recorded[0].Time.Equal(at) {
+		t.Errorf("unexpected samples %+v", recorded)
+	}
+}
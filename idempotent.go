@@ -0,0 +1,121 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cehbz/qbittorrent/metainfo"
+)
+
+// AddStore records when torrents were added, keyed by infohash, or by URL for
+// URLs whose infohash isn't known before the server fetches them
+type AddStore interface {
+	// LastAdded returns when key was recorded, if it was
+	LastAdded(key string) (time.Time, bool, error)
+	// RecordAdded records that key was added at the given time
+	RecordAdded(key string, at time.Time) error
+}
+
+// MemoryAddStore is an in-memory AddStore. Entries older than its TTL are
+// pruned as new ones are recorded. A MemoryAddStore is safe for concurrent use.
+type MemoryAddStore struct {
+	ttl time.Duration
+
+	mu    sync.Mutex
+	added map[string]time.Time
+}
+
+// NewMemoryAddStore returns a store forgetting entries after ttl
+func NewMemoryAddStore(ttl time.Duration) *MemoryAddStore {
+	return &MemoryAddStore{ttl: ttl, added: make(map[string]time.Time)}
+}
+
+// LastAdded implements the AddStore interface
+func (s *MemoryAddStore) LastAdded(key string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	at, ok := s.added[key]
+	return at, ok, nil
+}
+
+// RecordAdded implements the AddStore interface
+func (s *MemoryAddStore) RecordAdded(key string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, t := range s.added {
+		if at.Sub(t) >= s.ttl {
+			delete(s.added, k)
+		}
+	}
+	s.added[key] = at
+	return nil
+}
+
+// IdempotentAdder adds torrents, silently ignoring those it added within its
+// TTL, so automation that retries or re-sends adds doesn't produce duplicates
+// or duplicate warnings. An IdempotentAdder is safe for concurrent use.
+type IdempotentAdder struct {
+	client *Client
+	store  AddStore
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu sync.Mutex // serializes adds, so concurrent repeats add once
+}
+
+// NewIdempotentAdder returns an adder for c recording adds in store. A nil
+// store keeps them in memory.
+func NewIdempotentAdder(c *Client, store AddStore, ttl time.Duration) *IdempotentAdder {
+	if store == nil {
+		store = NewMemoryAddStore(ttl)
+	}
+	return &IdempotentAdder{client: c, store: store, ttl: ttl, now: c.Clock().Now}
+}
+
+// AddIdempotentCtx adds a torrent file unless a torrent with the same
+// infohash was added within the TTL, and reports whether it was added
+func (a *IdempotentAdder) AddIdempotentCtx(ctx context.Context, torrentFile string, fileData []byte, params ...*TorrentsAddParams) (bool, error) {
+	m, err := metainfo.Parse(fileData)
+	if err != nil {
+		return false, fmt.Errorf("AddIdempotent error: %w", err)
+	}
+	return a.add(m.InfoHash(), func() error {
+		return a.client.TorrentsAddCtx(ctx, torrentFile, fileData, params...)
+	})
+}
+
+// AddURLIdempotentCtx adds a torrent by URL or magnet link unless it was
+// added within the TTL, and reports whether it was added. Magnet links are
+// keyed by infohash, other URLs by the URL itself.
+func (a *IdempotentAdder) AddURLIdempotentCtx(ctx context.Context, link string, params ...*TorrentsAddParams) (bool, error) {
+	key := magnetInfoHash(link)
+	if key == "" {
+		key = link
+	}
+	return a.add(key, func() error {
+		return a.client.TorrentsAddURLsCtx(ctx, []string{link}, params...)
+	})
+}
+
+// add calls addFn unless key was recorded within the TTL and records it
+// after a successful add
+func (a *IdempotentAdder) add(key string, addFn func() error) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	last, ok, err := a.store.LastAdded(key)
+	if err != nil {
+		return false, err
+	}
+	if ok && now.Sub(last) < a.ttl {
+		a.client.log().Debug("skipping repeated add", "key", key, "added", last)
+		return false, nil
+	}
+	if err := addFn(); err != nil {
+		return false, err
+	}
+	return true, a.store.RecordAdded(key, now)
+}
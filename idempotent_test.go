@@ -0,0 +1,71 @@
+package qbittorrent
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIdempotentAdder(t *testing.T) {
+	var mu sync.Mutex
+	adds := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		adds++
+		mu.Unlock()
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	adder := NewIdempotentAdder(client, nil, time.Hour)
+	adder.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	added, err := adder.AddIdempotentCtx(ctx, "show.torrent", []byte(testTorrentFile))
+	if err != nil || !added {
+		t.Fatalf("expected first add, got %v, %v", added, err)
+	}
+	added, err = adder.AddIdempotentCtx(ctx, "renamed.torrent", []byte(testTorrentFile))
+	if err != nil || added {
+		t.Errorf("expected repeat to be skipped, got %v, %v", added, err)
+	}
+
+	magnet := "magnet:?xt=urn:btih:ABCDEF&dn=show"
+	if added, _ := adder.AddURLIdempotentCtx(ctx, magnet); !added {
+		t.Errorf("expected magnet to be added")
+	}
+	if added, _ := adder.AddURLIdempotentCtx(ctx, "magnet:?xt=urn:btih:abcdef"); added {
+		t.Errorf("expected magnet with the same infohash to be skipped")
+	}
+
+	now = now.Add(time.Hour)
+	if added, _ := adder.AddIdempotentCtx(ctx, "show.torrent", []byte(testTorrentFile)); !added {
+		t.Errorf("expected add after the TTL")
+	}
+	if adds != 3 {
+		t.Errorf("expected 3 add requests, got %d", adds)
+	}
+
+	if _, err := adder.AddIdempotentCtx(ctx, "broken.torrent", []byte("nope")); err == nil {
+		t.Errorf("expected error for invalid torrent")
+	}
+}
+
+func TestMemoryAddStore_Prunes(t *testing.T) {
+	store := NewMemoryAddStore(time.Minute)
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.RecordAdded("a", at)
+	store.RecordAdded("b", at.Add(time.Minute))
+	if _, ok, _ := store.LastAdded("a"); ok {
+		t.Errorf("expected expired entry to be pruned")
+	}
+	if last, ok, _ := store.LastAdded("b"); !ok || !last.Equal(at.Add(time.Minute)) {
+		t.Errorf("expected b to be recorded, got %v, %v", last, ok)
+	}
+}
@@ -0,0 +1,55 @@
+package importer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ReadBTBackup reads a qBittorrent BT_backup directory, e.g. to recover the
+// torrents of an instance whose configuration was lost. Each .torrent file is
+// paired with the .fastresume file of the same hash, which holds the save
+// path, category, tags and stopped state. Torrents without a .torrent file,
+// such as magnet links still fetching metadata, are skipped.
+func ReadBTBackup(dir string) ([]Entry, error) {
+	torrentFiles, err := filepath.Glob(filepath.Join(dir, "*.torrent"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(torrentFiles)
+
+	var entries []Entry
+	for _, torrentFile := range torrentFiles {
+		hash := strings.TrimSuffix(filepath.Base(torrentFile), ".torrent")
+		data, err := os.ReadFile(torrentFile)
+		if err != nil {
+			return nil, err
+		}
+		entry := Entry{Source: torrentFile, TorrentFile: data}
+
+		resume, err := readBencodeDict(filepath.Join(dir, hash+".fastresume"))
+		if errors.Is(err, os.ErrNotExist) {
+			entries = append(entries, entry)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		entry.Name, _ = resume["qBt-name"].(string)
+		entry.Category, _ = resume["qBt-category"].(string)
+		entry.Tags = stringList(resume["qBt-tags"])
+		// qBittorrent keeps its own save path, libtorrent's may be a temporary path
+		if entry.SavePath, _ = resume["qBt-savePath"].(string); entry.SavePath == "" {
+			entry.SavePath, _ = resume["save_path"].(string)
+		}
+		for _, key := range []string{"qBt-stopped", "qBt-paused", "paused"} {
+			if stopped, ok := resume[key].(int64); ok {
+				entry.Paused = stopped != 0
+				break
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,88 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cehbz/qbittorrent/bencode"
+)
+
+// ReadDeluge reads the torrents of a Deluge state directory, e.g.
+// ~/.config/deluge/state. The save path and paused state come from the
+// libtorrent resume data in torrents.fastresume and labels from the Label
+// plugin's label.conf in the parent directory. torrents.state, a Python
+// pickle, is not read.
+func ReadDeluge(stateDir string) ([]Entry, error) {
+	torrentFiles, err := filepath.Glob(filepath.Join(stateDir, "*.torrent"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(torrentFiles)
+
+	resume, err := readBencodeDict(filepath.Join(stateDir, "torrents.fastresume"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	labels, err := readDelugeLabels(filepath.Join(filepath.Dir(stateDir), "label.conf"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, torrentFile := range torrentFiles {
+		hash := strings.TrimSuffix(filepath.Base(torrentFile), ".torrent")
+		data, err := os.ReadFile(torrentFile)
+		if err != nil {
+			return nil, err
+		}
+		entry := Entry{Source: torrentFile, TorrentFile: data}
+		if label := labels[hash]; label != "" {
+			entry.Labels = []string{label}
+		}
+
+		// The resume data of each torrent is itself bencoded
+		if encoded, ok := resume[hash].(string); ok {
+			v, err := bencode.Decode([]byte(encoded))
+			if err != nil {
+				return nil, fmt.Errorf("resume data of %s: %w", hash, err)
+			}
+			if fastresume, ok := v.(map[string]interface{}); ok {
+				entry.SavePath, _ = fastresume["save_path"].(string)
+				paused, _ := fastresume["paused"].(int64)
+				entry.Paused = paused != 0
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// readDelugeLabels reads the torrent labels from a Deluge label.conf, which
+// holds a version header object followed by the configuration object
+func readDelugeLabels(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	var header, config struct {
+		TorrentLabels map[string]string `json:"torrent_labels"`
+	}
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := dec.Decode(&config); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config.TorrentLabels, nil
+}
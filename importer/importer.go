@@ -0,0 +1,102 @@
+// Package importer migrates torrents from other BitTorrent clients, or from
+// the BT_backup directory of a qBittorrent instance, to qBittorrent. Readers
+// extract the .torrent files, save paths and labels of a client's state, and
+// Import re-adds them without rechecking the data.
+package importer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cehbz/qbittorrent"
+	"github.com/cehbz/qbittorrent/metainfo"
+)
+
+// Entry is a torrent read from another client's state
+type Entry struct {
+	Source      string // file the torrent was read from
+	Name        string
+	TorrentFile []byte
+	SavePath    string
+	Labels      []string // labels of the source client, mapped by Options.Categories
+	Category    string   // category, for sources that have them
+	Tags        []string
+	Paused      bool
+}
+
+// Options configures Import
+type Options struct {
+	// Categories maps labels to categories. The first mapped label becomes the
+	// category, unless the entry has one; other labels are added as tags.
+	Categories map[string]string
+	Tags       []string            // added to every torrent
+	MapPath    func(string) string // translates save paths, e.g. between mounts
+	DryRun     bool                // only compute what would be added
+}
+
+// Result is the outcome of importing an entry
+type Result struct {
+	Entry  Entry
+	Hash   string
+	Params qbittorrent.TorrentsAddParams
+	Err    error
+}
+
+// Import adds the entries to qBittorrent, continuing after failures, which
+// are reported in the results. The data is not rechecked, so save paths must
+// point to the existing data.
+func Import(ctx context.Context, c *qbittorrent.Client, entries []Entry, opts Options) []Result {
+	results := make([]Result, 0, len(entries))
+	for _, entry := range entries {
+		result := Result{Entry: entry, Params: addParams(entry, opts)}
+		m, err := metainfo.Parse(entry.TorrentFile)
+		if err != nil {
+			result.Err = fmt.Errorf("%s: %w", entry.Source, err)
+			results = append(results, result)
+			continue
+		}
+		result.Hash = m.InfoHash()
+		// Keep names given in the source client
+		if entry.Name != "" && entry.Name != m.Info.Name {
+			result.Params.Rename = entry.Name
+		}
+		if !opts.DryRun {
+			params := result.Params
+			if err := c.TorrentsAddCtx(ctx, m.Info.Name+".torrent", entry.TorrentFile, &params); err != nil {
+				result.Err = fmt.Errorf("%s: %w", entry.Source, err)
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// addParams maps an entry to the parameters it is added with
+func addParams(entry Entry, opts Options) qbittorrent.TorrentsAddParams {
+	params := qbittorrent.TorrentsAddParams{
+		SavePath: entry.SavePath,
+		Category: entry.Category,
+		Paused:   entry.Paused,
+	}
+	if opts.MapPath != nil && params.SavePath != "" {
+		params.SavePath = opts.MapPath(params.SavePath)
+	}
+
+	tags := make(map[string]bool)
+	for _, label := range entry.Labels {
+		if category, ok := opts.Categories[label]; ok && params.Category == "" {
+			params.Category = category
+			continue
+		}
+		tags[label] = true
+	}
+	for _, tag := range append(append([]string{}, entry.Tags...), opts.Tags...) {
+		tags[tag] = true
+	}
+	for tag := range tags {
+		params.Tags = append(params.Tags, tag)
+	}
+	sort.Strings(params.Tags)
+	return params
+}
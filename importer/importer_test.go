@@ -0,0 +1,177 @@
+package importer_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cehbz/qbittorrent"
+	"github.com/cehbz/qbittorrent/bencode"
+	"github.com/cehbz/qbittorrent/importer"
+)
+
+// torrentFile encodes a minimal single-file torrent
+func torrentFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := bencode.Encode(map[string]interface{}{
+		"announce": "http://tracker.example.org/announce",
+		"info": map[string]interface{}{
+			"name":         name,
+			"length":       int64(10),
+			"piece length": int64(16384),
+			"pieces":       strings.Repeat("x", 20),
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return data
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func encode(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := bencode.Encode(v)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return data
+}
+
+func TestReadTransmission(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "torrents", "aaaa.torrent"), torrentFile(t, "a"))
+	writeFile(t, filepath.Join(dir, "resume", "aaaa.resume"), encode(t, map[string]interface{}{
+		"destination": "/downloads/tv",
+		"name":        "a",
+		"paused":      int64(1),
+		"labels":      []interface{}{"tv", "hd"},
+	}))
+	writeFile(t, filepath.Join(dir, "torrents", "bbbb.torrent"), torrentFile(t, "b"))
+
+	entries, err := importer.ReadTransmission(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	a := entries[0]
+	if a.Name != "a" || a.SavePath != "/downloads/tv" || !a.Paused || !reflect.DeepEqual(a.Labels, []string{"tv", "hd"}) {
+		t.Errorf("unexpected entry %+v", a)
+	}
+	if entries[1].SavePath != "" || len(entries[1].TorrentFile) == 0 {
+		t.Errorf("expected entry without resume data, got %+v", entries[1])
+	}
+}
+
+func TestReadDeluge(t *testing.T) {
+	dir := t.TempDir()
+	stateDir := filepath.Join(dir, "state")
+	writeFile(t, filepath.Join(stateDir, "aaaa.torrent"), torrentFile(t, "a"))
+	resume := encode(t, map[string]interface{}{"save_path": "/downloads/movies", "paused": int64(0)})
+	writeFile(t, filepath.Join(stateDir, "torrents.fastresume"), encode(t, map[string]interface{}{"aaaa": string(resume)}))
+	writeFile(t, filepath.Join(dir, "label.conf"), []byte(`{"file": 1, "format": 1}{"labels": {"movies": {}}, "torrent_labels": {"aaaa": "movies"}}`))
+
+	entries, err := importer.ReadDeluge(stateDir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(entries) != 1 || entries[0].SavePath != "/downloads/movies" || entries[0].Paused || !reflect.DeepEqual(entries[0].Labels, []string{"movies"}) {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
+
+func TestImport(t *testing.T) {
+	var mu sync.Mutex
+	var added []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		mu.Lock()
+		added = append(added, strings.Join([]string{r.FormValue("savepath"), r.FormValue("category"), r.FormValue("tags"), r.FormValue("paused"), r.FormValue("skip_checking"), r.FormValue("rename")}, " "))
+		mu.Unlock()
+	}))
+	defer mockServer.Close()
+
+	client, err := qbittorrent.NewClientWithOptions("", "", "", "",
+		qbittorrent.WithBaseURL(mockServer.URL),
+		qbittorrent.WithHTTPClient(mockServer.Client()),
+		qbittorrent.WithBypassAuth(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	entries := []importer.Entry{
+		{Source: "a", Name: "A show", TorrentFile: torrentFile(t, "a"), SavePath: "/downloads/tv", Labels: []string{"hd", "tv"}, Paused: true},
+		{Source: "broken", TorrentFile: []byte("not a torrent")},
+	}
+	opts := importer.Options{
+		Categories: map[string]string{"tv": "TV"},
+		Tags:       []string{"migrated"},
+		MapPath:    func(p string) string { return strings.Replace(p, "/downloads", "/data", 1) },
+	}
+
+	results := importer.Import(context.Background(), client, entries, importer.Options{DryRun: true, Categories: opts.Categories})
+	if len(added) != 0 || results[0].Params.Category != "TV" {
+		t.Errorf("expected dry run to compute params without adding, got %v and %+v", added, results[0].Params)
+	}
+
+	results = importer.Import(context.Background(), client, entries, opts)
+	if len(results) != 2 || results[0].Err != nil || results[1].Err == nil {
+		t.Fatalf("expected the second entry to fail, got %+v", results)
+	}
+	if len(results[0].Hash) != 40 {
+		t.Errorf("expected infohash, got %q", results[0].Hash)
+	}
+	if len(added) != 1 || added[0] != "/data/tv TV hd,migrated true true A show" {
+		t.Errorf("unexpected add requests %v", added)
+	}
+}
+
+func TestReadBTBackup(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "aaaa.torrent"), torrentFile(t, "a"))
+	writeFile(t, filepath.Join(dir, "aaaa.fastresume"), encode(t, map[string]interface{}{
+		"save_path":    "/tmp/incomplete",
+		"qBt-savePath": "/data/tv",
+		"qBt-category": "tv",
+		"qBt-tags":     []interface{}{"hd", "kids"},
+		"qBt-name":     "Renamed",
+		"paused":       int64(1),
+	}))
+	writeFile(t, filepath.Join(dir, "bbbb.torrent"), torrentFile(t, "b"))
+	writeFile(t, filepath.Join(dir, "bbbb.fastresume"), encode(t, map[string]interface{}{"save_path": "/data/movies"}))
+	// Metadata was never received, there's nothing to add
+	writeFile(t, filepath.Join(dir, "cccc.fastresume"), encode(t, map[string]interface{}{"save_path": "/data"}))
+
+	entries, err := importer.ReadBTBackup(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	a := entries[0]
+	if a.Name != "Renamed" || a.SavePath != "/data/tv" || a.Category != "tv" || !a.Paused || !reflect.DeepEqual(a.Tags, []string{"hd", "kids"}) {
+		t.Errorf("unexpected entry %+v", a)
+	}
+	if b := entries[1]; b.SavePath != "/data/movies" || b.Paused {
+		t.Errorf("unexpected entry %+v", b)
+	}
+}
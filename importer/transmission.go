@@ -0,0 +1,79 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cehbz/qbittorrent/bencode"
+)
+
+// ReadTransmission reads the torrents of a Transmission configuration
+// directory, e.g. ~/.config/transmission-daemon. Each .torrent file in its
+// torrents directory is paired with the .resume file of the same name in its
+// resume directory, which holds the save path, labels and paused state.
+func ReadTransmission(configDir string) ([]Entry, error) {
+	torrentFiles, err := filepath.Glob(filepath.Join(configDir, "torrents", "*.torrent"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(torrentFiles)
+
+	var entries []Entry
+	for _, torrentFile := range torrentFiles {
+		stem := strings.TrimSuffix(filepath.Base(torrentFile), ".torrent")
+		data, err := os.ReadFile(torrentFile)
+		if err != nil {
+			return nil, err
+		}
+		entry := Entry{Source: torrentFile, TorrentFile: data}
+
+		resume, err := readBencodeDict(filepath.Join(configDir, "resume", stem+".resume"))
+		if errors.Is(err, os.ErrNotExist) {
+			// Without resume data the torrent can still be added to the default path
+			entries = append(entries, entry)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		entry.Name, _ = resume["name"].(string)
+		entry.SavePath, _ = resume["destination"].(string)
+		paused, _ := resume["paused"].(int64)
+		entry.Paused = paused != 0
+		entry.Labels = stringList(resume["labels"])
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// readBencodeDict reads a file holding a bencoded dictionary
+func readBencodeDict(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	v, err := bencode.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	dict, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: not a dictionary", path)
+	}
+	return dict, nil
+}
+
+// stringList returns the strings of a decoded bencode list
+func stringList(v interface{}) []string {
+	list, _ := v.([]interface{})
+	var strs []string
+	for _, item := range list {
+		if s, ok := item.(string); ok && s != "" {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
@@ -0,0 +1,179 @@
+// Command gendeprecated generates the deprecated methods of Client that call
+// their Ctx counterparts with context.Background(), so the wrappers can't
+// drift from the methods they wrap. Run it with go generate from the package
+// directory:
+//
+//	gendeprecated -o deprecated.go AuthLogin TorrentsInfo ...
+//
+// Every named method gets the parameters and results of its Ctx method without
+// the leading context, and the first paragraph of its documentation.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	output := flag.String("o", "deprecated.go", "file to write, relative to the package directory")
+	flag.Parse()
+
+	src, err := generate(".", *output, flag.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate returns the source of the wrappers of names for the package in
+// dir, ignoring the output file, which may be stale
+func generate(dir, output string, names []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != filepath.Base(output)
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+
+	var pkgName string
+	methods := make(map[string]*ast.FuncDecl)
+	for name, pkg := range pkgs {
+		pkgName = name
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				if fn, ok := decl.(*ast.FuncDecl); ok && isClientMethod(fn) {
+					methods[fn.Name.Name] = fn
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gendeprecated; DO NOT EDIT.\n\npackage %s\n\nimport \"context\"\n", pkgName)
+	for _, name := range names {
+		fn, ok := methods[name+"Ctx"]
+		if !ok {
+			return nil, fmt.Errorf("no method %sCtx", name)
+		}
+		if err := writeWrapper(&buf, fset, name, fn); err != nil {
+			return nil, err
+		}
+	}
+	return format.Source(buf.Bytes())
+}
+
+// isClientMethod reports whether fn is a method on *Client
+func isClientMethod(fn *ast.FuncDecl) bool {
+	if fn.Recv == nil || len(fn.Recv.List) != 1 {
+		return false
+	}
+	star, ok := fn.Recv.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := star.X.(*ast.Ident)
+	return ok && ident.Name == "Client"
+}
+
+// writeWrapper writes the deprecated wrapper name of the Ctx method fn
+func writeWrapper(buf *bytes.Buffer, fset *token.FileSet, name string, fn *ast.FuncDecl) error {
+	params := fn.Type.Params.List
+	if len(params) == 0 || expr(fset, params[0].Type) != "context.Context" {
+		return fmt.Errorf("%s: the first parameter must be a context.Context", fn.Name.Name)
+	}
+	rest := append([]*ast.Field{}, params...)
+	if len(rest[0].Names) > 1 {
+		first := *rest[0]
+		first.Names = first.Names[1:]
+		rest[0] = &first
+	} else {
+		rest = rest[1:]
+	}
+
+	var decls, args []string
+	for _, field := range rest {
+		typ := expr(fset, field.Type)
+		var names []string
+		for _, ident := range field.Names {
+			names = append(names, ident.Name)
+			arg := ident.Name
+			if strings.HasPrefix(typ, "...") {
+				arg += "..."
+			}
+			args = append(args, arg)
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("%s: parameters must be named", fn.Name.Name)
+		}
+		decls = append(decls, strings.Join(names, ", ")+" "+typ)
+	}
+	var results []string
+	named := false
+	if fn.Type.Results != nil {
+		for _, field := range fn.Type.Results.List {
+			typ := expr(fset, field.Type)
+			if len(field.Names) == 0 {
+				results = append(results, typ)
+				continue
+			}
+			named = true
+			var names []string
+			for _, ident := range field.Names {
+				names = append(names, ident.Name)
+			}
+			results = append(results, strings.Join(names, ", ")+" "+typ)
+		}
+	}
+	result := ""
+	switch {
+	case len(results) == 1 && !named:
+		result = " " + results[0]
+	case len(results) > 0:
+		result = " (" + strings.Join(results, ", ") + ")"
+	}
+
+	fmt.Fprintf(buf, "\n%s//\n// Deprecated: use %s\n", doc(fn, name), fn.Name.Name)
+	fmt.Fprintf(buf, "func (c *Client) %s(%s)%s {\n", name, strings.Join(decls, ", "), result)
+	fmt.Fprintf(buf, "\treturn c.%s(%s)\n}\n", fn.Name.Name, strings.Join(append([]string{"context.Background()"}, args...), ", "))
+	return nil
+}
+
+// doc returns the first paragraph of the documentation of fn, as a comment
+// about name
+func doc(fn *ast.FuncDecl, name string) string {
+	text := fn.Doc.Text()
+	paragraph, _, _ := strings.Cut(strings.TrimSpace(text), "\n\n")
+	if paragraph == "" {
+		paragraph = fn.Name.Name + " calls the API"
+	}
+	paragraph = name + strings.TrimPrefix(paragraph, fn.Name.Name)
+	var b strings.Builder
+	for _, line := range strings.Split(paragraph, "\n") {
+		b.WriteString("// " + line + "\n")
+	}
+	return b.String()
+}
+
+// expr formats an expression as source
+func expr(fset *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, node); err != nil {
+		return fmt.Sprintf("/* %v */", err)
+	}
+	return buf.String()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"testing"
+)
+
+func TestGeneratedFileIsCurrent(t *testing.T) {
+	current, err := os.ReadFile("../../deprecated.go")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), "deprecated.go", current, 0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var names []string
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			names = append(names, fn.Name.Name)
+		}
+	}
+
+	src, err := generate("../..", "deprecated.go", names)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !bytes.Equal(src, current) {
+		t.Errorf("deprecated.go is stale, run go generate")
+	}
+}
+
+func TestGenerateUnknownMethod(t *testing.T) {
+	if _, err := generate("../..", "deprecated.go", []string{"NoSuchMethod"}); err == nil {
+		t.Errorf("expected an error for a method without a Ctx counterpart")
+	}
+}
@@ -0,0 +1,115 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ErrIPFilterDisabled is returned when reloading the IP filter while it is disabled
+var ErrIPFilterDisabled = errors.New("IP filter is disabled")
+
+// BannedIPsCtx returns the addresses in the banned_IPs preference
+func (c *Client) BannedIPsCtx(ctx context.Context) ([]string, error) {
+	prefs, err := c.AppPreferencesCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return splitBannedIPs(prefs.BannedIPs), nil
+}
+
+// BanIPsCtx adds addresses to the banned IPs. Unlike TransferBanPeersCtx,
+// which bans peers for the current session, the banned IPs are persisted.
+func (c *Client) BanIPsCtx(ctx context.Context, ips ...string) error {
+	for _, ip := range ips {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("BanIPs error: invalid IP address %q", ip)
+		}
+	}
+	banned, err := c.BannedIPsCtx(ctx)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, ip := range banned {
+		seen[ip] = true
+	}
+	updated := banned
+	for _, ip := range ips {
+		if !seen[ip] {
+			seen[ip] = true
+			updated = append(updated, ip)
+		}
+	}
+	if len(updated) == len(banned) {
+		return nil
+	}
+	return c.setBannedIPs(ctx, updated)
+}
+
+// UnbanIPsCtx removes addresses from the banned IPs
+func (c *Client) UnbanIPsCtx(ctx context.Context, ips ...string) error {
+	banned, err := c.BannedIPsCtx(ctx)
+	if err != nil {
+		return err
+	}
+	lifted := make(map[string]bool)
+	for _, ip := range ips {
+		lifted[ip] = true
+	}
+	var kept []string
+	for _, ip := range banned {
+		if !lifted[ip] {
+			kept = append(kept, ip)
+		}
+	}
+	if len(kept) == len(banned) {
+		return nil
+	}
+	return c.setBannedIPs(ctx, kept)
+}
+
+// setBannedIPs replaces the banned IPs
+func (c *Client) setBannedIPs(ctx context.Context, ips []string) error {
+	return c.AppSetPreferencesCtx(ctx, map[string]interface{}{
+		"banned_IPs": strings.Join(ips, "\n"),
+	})
+}
+
+// SetIPFilterPathCtx sets the IP filter file (.dat, .p2p or .p2b) and enables
+// the filter. Whether trackers are filtered too is left unchanged.
+func (c *Client) SetIPFilterPathCtx(ctx context.Context, path string) error {
+	if path == "" {
+		return errors.New("SetIPFilterPath error: empty path")
+	}
+	return c.AppSetPreferencesCtx(ctx, map[string]interface{}{
+		"ip_filter_path":    path,
+		"ip_filter_enabled": true,
+	})
+}
+
+// SetIPFilterEnabledCtx enables or disables the IP filter
+func (c *Client) SetIPFilterEnabledCtx(ctx context.Context, enabled bool) error {
+	return c.AppSetPreferencesCtx(ctx, map[string]interface{}{
+		"ip_filter_enabled": enabled,
+	})
+}
+
+// ReloadIPFilterCtx makes qBittorrent reload the IP filter file, e.g. after a
+// blocklist update. There is no endpoint for it, so the filter is disabled and
+// enabled again, which reloads it.
+func (c *Client) ReloadIPFilterCtx(ctx context.Context) error {
+	prefs, err := c.AppPreferencesCtx(ctx)
+	if err != nil {
+		return err
+	}
+	if !prefs.IPFilterEnabled {
+		return fmt.Errorf("ReloadIPFilter error: %w", ErrIPFilterDisabled)
+	}
+	if err := c.SetIPFilterEnabledCtx(ctx, false); err != nil {
+		return err
+	}
+	return c.SetIPFilterEnabledCtx(ctx, true)
+}
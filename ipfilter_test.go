@@ -0,0 +1,98 @@
+package qbittorrent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// newPreferencesServer serves preferences from prefs and applies updates to
+// them, recording each update
+func newPreferencesServer(t *testing.T, prefs map[string]interface{}, updates *[]map[string]interface{}) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/api/v2/app/preferences":
+			json.NewEncoder(w).Encode(prefs)
+		case "/api/v2/app/setPreferences":
+			r.ParseForm()
+			var update map[string]interface{}
+			if err := json.Unmarshal([]byte(r.FormValue("json")), &update); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			for key, value := range update {
+				prefs[key] = value
+			}
+			*updates = append(*updates, update)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestBanIPs(t *testing.T) {
+	var updates []map[string]interface{}
+	mockServer := newPreferencesServer(t, map[string]interface{}{"banned_IPs": "1.2.3.4\n5.6.7.8"}, &updates)
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+	ctx := context.Background()
+
+	if err := client.BanIPsCtx(ctx, "5.6.7.8", "::1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := client.UnbanIPsCtx(ctx, "1.2.3.4", "9.9.9.9"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	banned, err := client.BannedIPsCtx(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(banned, []string{"5.6.7.8", "::1"}) {
+		t.Errorf("unexpected banned IPs %v", banned)
+	}
+
+	// Nothing changes, nothing is sent
+	client.BanIPsCtx(ctx, "::1")
+	client.UnbanIPsCtx(ctx, "1.2.3.4")
+	if len(updates) != 2 {
+		t.Errorf("expected 2 updates, got %v", updates)
+	}
+	if err := client.BanIPsCtx(ctx, "not an ip"); err == nil {
+		t.Errorf("expected error for an invalid address, got none")
+	}
+}
+
+func TestReloadIPFilterCtx(t *testing.T) {
+	var updates []map[string]interface{}
+	mockServer := newPreferencesServer(t, map[string]interface{}{"ip_filter_enabled": false}, &updates)
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+	ctx := context.Background()
+
+	if err := client.ReloadIPFilterCtx(ctx); !errors.Is(err, ErrIPFilterDisabled) {
+		t.Fatalf("expected ErrIPFilterDisabled, got %v", err)
+	}
+	if err := client.SetIPFilterPathCtx(ctx, "/config/blocklist.p2p"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := client.ReloadIPFilterCtx(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []map[string]interface{}{
+		{"ip_filter_path": "/config/blocklist.p2p", "ip_filter_enabled": true},
+		{"ip_filter_enabled": false},
+		{"ip_filter_enabled": true},
+	}
+	if !reflect.DeepEqual(updates, expected) {
+		t.Errorf("expected %v, got %v", expected, updates)
+	}
+}
@@ -0,0 +1,60 @@
+package qbittorrent
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultMaxResponseSize is the default limit of response bodies, far above
+// the sync responses of instances with tens of thousands of torrents
+const DefaultMaxResponseSize = 256 << 20
+
+// ErrResponseTooLarge is returned for response bodies above the limit set by
+// WithMaxResponseSize
+var ErrResponseTooLarge = errors.New("response too large")
+
+// WithMaxResponseSize limits the size of response bodies read into memory, so
+// a base URL pointing at a file server by mistake can't exhaust the memory of
+// the process. Larger responses fail with ErrResponseTooLarge. Zero disables
+// the limit; the default is DefaultMaxResponseSize.
+func WithMaxResponseSize(size int64) Option {
+	return func(c *Client) error {
+		if size < 0 {
+			return fmt.Errorf("invalid max response size %d", size)
+		}
+		c.maxResponseSize = size
+		return nil
+	}
+}
+
+// readBody reads the body of a response to endpoint, up to the max response
+// size, and passes its size to the metrics sink. Bodies announcing a larger
+// Content-Length are refused unread.
+func (c *Client) readBody(endpoint string, resp *http.Response) ([]byte, error) {
+	data, err := c.readLimited(endpoint, resp)
+	if err == nil && c.metrics != nil {
+		c.metrics.ObserveResponseSize(endpoint, len(data))
+	}
+	return data, err
+}
+
+// readLimited reads the body of a response up to the max response size
+func (c *Client) readLimited(endpoint string, resp *http.Response) ([]byte, error) {
+	limit := c.maxResponseSize
+	if limit == 0 {
+		return io.ReadAll(resp.Body)
+	}
+	if resp.ContentLength > limit {
+		return nil, fmt.Errorf("%s: %w: %d bytes, the limit is %d", endpoint, ErrResponseTooLarge, resp.ContentLength, limit)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s: %w: more than %d bytes", endpoint, ErrResponseTooLarge, limit)
+	}
+	return data, nil
+}
@@ -0,0 +1,69 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxResponseSize(t *testing.T) {
+	body := strings.Repeat("x", 100)
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/app/webapiVersion" {
+			// chunked, without a Content-Length
+			w.(http.Flusher).Flush()
+		}
+		w.Write([]byte(body))
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true, maxResponseSize: 100}
+	version, err := client.AppVersionCtx(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if version != body {
+		t.Errorf("expected the full body, got %d bytes", len(version))
+	}
+
+	client.maxResponseSize = 99
+	if _, err := client.AppVersionCtx(context.Background()); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected ErrResponseTooLarge, got %v", err)
+	}
+	if _, err := client.AppWebAPIVersionCtx(context.Background()); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected ErrResponseTooLarge without a Content-Length, got %v", err)
+	}
+	if _, err := client.Do(context.Background(), http.MethodPost, "app/version", nil); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected ErrResponseTooLarge from Do, got %v", err)
+	}
+
+	client.maxResponseSize = 0
+	if _, err := client.AppVersionCtx(context.Background()); err != nil {
+		t.Errorf("expected no limit, got %v", err)
+	}
+}
+
+func TestWithMaxResponseSize(t *testing.T) {
+	client, err := NewClientWithOptions("", "", "localhost", "8080")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if client.maxResponseSize != DefaultMaxResponseSize {
+		t.Errorf("expected the default limit, got %d", client.maxResponseSize)
+	}
+
+	client, err = NewClientWithOptions("", "", "localhost", "8080", WithMaxResponseSize(1<<20))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if client.maxResponseSize != 1<<20 {
+		t.Errorf("expected a limit of 1 MiB, got %d", client.maxResponseSize)
+	}
+
+	if _, err := NewClientWithOptions("", "", "localhost", "8080", WithMaxResponseSize(-1)); err == nil {
+		t.Error("expected an error for a negative limit")
+	}
+}
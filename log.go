@@ -0,0 +1,44 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// LogType is the severity of a LogEntry
+type LogType int
+
+const (
+	LogNormal   LogType = 1
+	LogInfo     LogType = 2
+	LogWarning  LogType = 4
+	LogCritical LogType = 8
+)
+
+// LogEntry is a message from the main log of qBittorrent
+type LogEntry struct {
+	ID        int64   `json:"id"`
+	Message   string  `json:"message"`
+	Timestamp int64   `json:"timestamp"`
+	Type      LogType `json:"type"`
+}
+
+// LogMainCtx returns the main log entries after lastKnownID, or all of them
+// for -1
+func (c *Client) LogMainCtx(ctx context.Context, lastKnownID int64) ([]LogEntry, error) {
+	query := url.Values{}
+	query.Set("last_known_id", strconv.FormatInt(lastKnownID, 10))
+
+	respData, err := c.doGetCtx(ctx, "/api/v2/log/main", query)
+	if err != nil {
+		return nil, fmt.Errorf("LogMain error: %w", err)
+	}
+
+	var entries []LogEntry
+	if err := c.decodeJSON("/api/v2/log/main", respData, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode LogMain response: %w", err)
+	}
+	return entries, nil
+}
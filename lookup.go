@@ -0,0 +1,44 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// GetTorrentCtx returns the torrent with the given hash, or an error matching
+// ErrTorrentNotFound if the server doesn't know it
+func (c *Client) GetTorrentCtx(ctx context.Context, hash string) (*TorrentInfo, error) {
+	torrents, err := c.TorrentsInfoCtx(ctx, &TorrentsInfoParams{Hashes: []string{hash}})
+	if err != nil {
+		return nil, fmt.Errorf("GetTorrent error: %w", err)
+	}
+	if len(torrents) == 0 {
+		return nil, fmt.Errorf("GetTorrent error: %s: %w", hash, ErrTorrentNotFound)
+	}
+	return &torrents[0], nil
+}
+
+// FindTorrentsByNameCtx returns the torrents whose name matches pattern,
+// ignoring case. Patterns use path.Match syntax, e.g. "*.S01E0?.*", and are
+// matched by the client since the server can't filter by name; params, if
+// given, narrow the torrents fetched, e.g. to a category.
+func (c *Client) FindTorrentsByNameCtx(ctx context.Context, pattern string, params ...*TorrentsInfoParams) ([]TorrentInfo, error) {
+	pattern = strings.ToLower(pattern)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("FindTorrentsByName error: invalid pattern %q: %w", pattern, err)
+	}
+	torrents, err := c.TorrentsInfoCtx(ctx, params...)
+	if err != nil {
+		return nil, fmt.Errorf("FindTorrentsByName error: %w", err)
+	}
+
+	var matched []TorrentInfo
+	for _, torrent := range torrents {
+		if ok, _ := path.Match(pattern, strings.ToLower(torrent.Name)); ok {
+			matched = append(matched, torrent)
+		}
+	}
+	return matched, nil
+}
@@ -0,0 +1,69 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+)
+
+func TestGetTorrentCtx(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("hashes") == "abc" {
+			w.Write([]byte(`[{"hash":"abc","name":"Ubuntu"}]`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+	torrent, err := client.GetTorrentCtx(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if torrent.Hash != "abc" || torrent.Name != "Ubuntu" {
+		t.Errorf("unexpected torrent %+v", torrent)
+	}
+
+	if _, err := client.GetTorrentCtx(context.Background(), "def"); !errors.Is(err, ErrTorrentNotFound) {
+		t.Errorf("expected ErrTorrentNotFound, got %v", err)
+	}
+}
+
+func TestFindTorrentsByNameCtx(t *testing.T) {
+	var category string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		category = r.URL.Query().Get("category")
+		w.Write([]byte(`[
+			{"hash":"a","name":"Show.S01E01.1080p"},
+			{"hash":"b","name":"show.s01e02.720p"},
+			{"hash":"c","name":"Show.S02E01.1080p"},
+			{"hash":"d","name":"Other"}
+		]`))
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+	torrents, err := client.FindTorrentsByNameCtx(context.Background(), "SHOW.S01E0?.*", &TorrentsInfoParams{Category: "tv"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(torrents) != 2 || torrents[0].Hash != "a" || torrents[1].Hash != "b" {
+		t.Errorf("expected the first season regardless of case, got %+v", torrents)
+	}
+	if category != "tv" {
+		t.Errorf("expected the params to be sent, got category %q", category)
+	}
+
+	torrents, err = client.FindTorrentsByNameCtx(context.Background(), "nothing*")
+	if err != nil || len(torrents) != 0 {
+		t.Errorf("expected no torrents, got %+v, %v", torrents, err)
+	}
+
+	if _, err := client.FindTorrentsByNameCtx(context.Background(), "[a-"); !errors.Is(err, path.ErrBadPattern) {
+		t.Errorf("expected ErrBadPattern, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package qbittorrent
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MainDataDiff describes what a /api/v2/sync/maindata response changed in a
+// SyncState. Partial responses only carry changed fields, so unlike MainData
+// the diff holds complete torrents and tells added and updated ones apart.
+type MainDataDiff struct {
+	Rid  int
+	Full bool // the response was a full update
+
+	TorrentsAdded   []TorrentInfo
+	TorrentsUpdated []TorrentUpdate
+	TorrentsRemoved []TorrentInfo // as they were before removal
+
+	CategoriesAdded   []string
+	CategoriesUpdated []string
+	CategoriesRemoved []string
+
+	TagsAdded   []string
+	TagsRemoved []string
+
+	TrackersAdded   []string
+	TrackersUpdated []string // the torrents using the tracker changed
+	TrackersRemoved []string
+}
+
+// TorrentUpdate is a torrent changed by a sync response
+type TorrentUpdate struct {
+	Torrent  TorrentInfo
+	Previous TorrentInfo
+	Fields   []string // JSON names of the changed fields, sorted
+}
+
+// Empty reports whether nothing changed, apart from the server state
+func (d MainDataDiff) Empty() bool {
+	return len(d.TorrentsAdded) == 0 && len(d.TorrentsUpdated) == 0 && len(d.TorrentsRemoved) == 0 &&
+		len(d.CategoriesAdded) == 0 && len(d.CategoriesUpdated) == 0 && len(d.CategoriesRemoved) == 0 &&
+		len(d.TagsAdded) == 0 && len(d.TagsRemoved) == 0 &&
+		len(d.TrackersAdded) == 0 && len(d.TrackersUpdated) == 0 && len(d.TrackersRemoved) == 0
+}
+
+// sort orders torrents by hash and names alphabetically
+func (d *MainDataDiff) sort() {
+	byHash := func(torrents []TorrentInfo) {
+		sort.Slice(torrents, func(i, j int) bool { return torrents[i].Hash < torrents[j].Hash })
+	}
+	byHash(d.TorrentsAdded)
+	byHash(d.TorrentsRemoved)
+	sort.Slice(d.TorrentsUpdated, func(i, j int) bool { return d.TorrentsUpdated[i].Torrent.Hash < d.TorrentsUpdated[j].Torrent.Hash })
+	for _, names := range [][]string{
+		d.CategoriesAdded, d.CategoriesUpdated, d.CategoriesRemoved,
+		d.TagsAdded, d.TagsRemoved,
+		d.TrackersAdded, d.TrackersUpdated, d.TrackersRemoved,
+	} {
+		sort.Strings(names)
+	}
+}
+
+// torrentFields are the JSON names of the fields of TorrentInfo by index
+var torrentFields = func() []string {
+	typ := reflect.TypeOf(TorrentInfo{})
+	names := make([]string, typ.NumField())
+	for i := range names {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		names[i] = name
+	}
+	return names
+}()
+
+// changedFields returns the sorted JSON names of the fields that differ
+// between two states of a torrent
+func changedFields(previous, current TorrentInfo) []string {
+	var changed []string
+	prev, cur := reflect.ValueOf(previous), reflect.ValueOf(current)
+	for i, name := range torrentFields {
+		if name == "-" {
+			continue
+		}
+		if !prev.Field(i).Equal(cur.Field(i)) {
+			changed = append(changed, name)
+		}
+	}
+	if !slices.Equal(previous.Tags, current.Tags) {
+		changed = append(changed, "tags")
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,71 @@
+package qbittorrent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSyncState_ApplyDiff(t *testing.T) {
+	state := NewSyncState()
+	diff, err := state.ApplyDiff([]byte(`{"rid":1,"full_update":true,
+		"torrents":{"hash1":{"name":"a","state":"downloading"},"hash2":{"name":"b"}},
+		"categories":{"tv":{"name":"tv","savePath":"/tv"}},
+		"tags":["x"],
+		"trackers":{"http://tracker":["hash1"]}}`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !diff.Full || len(diff.TorrentsAdded) != 2 || !reflect.DeepEqual(diff.CategoriesAdded, []string{"tv"}) || !reflect.DeepEqual(diff.TagsAdded, []string{"x"}) || !reflect.DeepEqual(diff.TrackersAdded, []string{"http://tracker"}) {
+		t.Errorf("expected everything to be added, got %+v", diff)
+	}
+
+	diff, err = state.ApplyDiff([]byte(`{"rid":2,
+		"torrents":{"hash1":{"state":"uploading","name":"a"},"hash3":{"name":"c"}},
+		"torrents_removed":["hash2"],
+		"categories":{"tv":{"savePath":"/shows"}},
+		"tags":["y"],
+		"tags_removed":["x"],
+		"trackers":{"http://tracker":["hash1","hash3"]}}`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if diff.Full {
+		t.Errorf("expected partial update")
+	}
+	if len(diff.TorrentsAdded) != 1 || diff.TorrentsAdded[0].Hash != "hash3" {
+		t.Errorf("expected hash3 to be added, got %+v", diff.TorrentsAdded)
+	}
+	if len(diff.TorrentsUpdated) != 1 {
+		t.Fatalf("expected 1 updated torrent, got %+v", diff.TorrentsUpdated)
+	}
+	update := diff.TorrentsUpdated[0]
+	if update.Torrent.State != "uploading" || update.Previous.State != "downloading" || !reflect.DeepEqual(update.Fields, []string{"state"}) {
+		t.Errorf("unexpected update %+v", update)
+	}
+	if len(diff.TorrentsRemoved) != 1 || diff.TorrentsRemoved[0].Name != "b" {
+		t.Errorf("expected hash2 to be removed with its last state, got %+v", diff.TorrentsRemoved)
+	}
+	if !reflect.DeepEqual(diff.CategoriesUpdated, []string{"tv"}) || !reflect.DeepEqual(diff.TagsAdded, []string{"y"}) || !reflect.DeepEqual(diff.TagsRemoved, []string{"x"}) || !reflect.DeepEqual(diff.TrackersUpdated, []string{"http://tracker"}) {
+		t.Errorf("unexpected diff %+v", diff)
+	}
+
+	// A forced full update only reports what actually changed
+	diff, err = state.ApplyDiff([]byte(`{"rid":1,"full_update":true,
+		"torrents":{"hash1":{"name":"a","state":"uploading"}},
+		"categories":{"tv":{"name":"tv","savePath":"/shows"}},
+		"tags":["y"],
+		"trackers":{"http://tracker":["hash1","hash3"]}}`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(diff.TorrentsRemoved) != 1 || diff.TorrentsRemoved[0].Hash != "hash3" {
+		t.Errorf("expected hash3 to be removed, got %+v", diff.TorrentsRemoved)
+	}
+	diff.TorrentsRemoved = nil
+	if !diff.Empty() {
+		t.Errorf("expected no other changes, got %+v", diff)
+	}
+	if len(state.Torrents()) != 1 {
+		t.Errorf("expected 1 torrent, got %d", len(state.Torrents()))
+	}
+}
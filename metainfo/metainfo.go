@@ -0,0 +1,270 @@
+// Package metainfo reads and writes .torrent files (BitTorrent v1 metainfo).
+// The info dictionary is kept as it was encoded, so rewriting trackers or
+// other top-level fields never changes the infohash.
+package metainfo
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cehbz/qbittorrent/bencode"
+)
+
+// MetaInfo is a parsed .torrent file
+type MetaInfo struct {
+	Announce     string
+	AnnounceList [][]string // tiers of tracker URLs
+	Comment      string
+	CreatedBy    string
+	CreationDate int64 // Unix time
+	Info         Info
+
+	infoBytes bencode.Raw            // the info dictionary as encoded
+	fields    map[string]interface{} // all top-level fields, for writing back
+}
+
+// Info is the info dictionary of a .torrent file
+type Info struct {
+	Name        string
+	PieceLength int64
+	Pieces      []byte // concatenated SHA-1 hashes of the pieces
+	Length      int64  // size of a single-file torrent
+	Files       []File // files of a multi-file torrent
+	Private     bool
+}
+
+// File is a file of a multi-file torrent
+type File struct {
+	Length int64
+	Path   []string // path components relative to the torrent's folder
+}
+
+// Parse parses the contents of a .torrent file
+func Parse(data []byte) (*MetaInfo, error) {
+	v, err := bencode.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("metainfo: not a dictionary")
+	}
+	// Keep the encoded info dictionary to preserve it byte for byte
+	rawFields, err := bencode.DecodeRawDict(data)
+	if err != nil {
+		return nil, err
+	}
+	infoBytes, ok := rawFields["info"]
+	if !ok {
+		return nil, errors.New("metainfo: missing info dictionary")
+	}
+	fields["info"] = infoBytes
+
+	m := &MetaInfo{fields: fields, infoBytes: infoBytes}
+	m.Announce, _ = fields["announce"].(string)
+	m.Comment, _ = fields["comment"].(string)
+	m.CreatedBy, _ = fields["created by"].(string)
+	m.CreationDate, _ = fields["creation date"].(int64)
+	if tiers, ok := fields["announce-list"].([]interface{}); ok {
+		for _, tier := range tiers {
+			urls, _ := tier.([]interface{})
+			var list []string
+			for _, u := range urls {
+				if s, ok := u.(string); ok {
+					list = append(list, s)
+				}
+			}
+			if len(list) > 0 {
+				m.AnnounceList = append(m.AnnounceList, list)
+			}
+		}
+	}
+
+	info, err := bencode.Decode(m.infoBytes)
+	if err != nil {
+		return nil, err
+	}
+	if m.Info, err = parseInfo(info); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func parseInfo(v interface{}) (Info, error) {
+	dict, ok := v.(map[string]interface{})
+	if !ok {
+		return Info{}, errors.New("metainfo: info is not a dictionary")
+	}
+	var info Info
+	info.Name, _ = dict["name"].(string)
+	info.PieceLength, _ = dict["piece length"].(int64)
+	pieces, _ := dict["pieces"].(string)
+	info.Pieces = []byte(pieces)
+	info.Length, _ = dict["length"].(int64)
+	private, _ := dict["private"].(int64)
+	info.Private = private == 1
+
+	if files, ok := dict["files"].([]interface{}); ok {
+		for i, f := range files {
+			file, ok := f.(map[string]interface{})
+			if !ok {
+				return Info{}, fmt.Errorf("metainfo: file %d is not a dictionary", i)
+			}
+			length, _ := file["length"].(int64)
+			components, _ := file["path"].([]interface{})
+			var path []string
+			for _, c := range components {
+				if s, ok := c.(string); ok {
+					path = append(path, s)
+				}
+			}
+			info.Files = append(info.Files, File{Length: length, Path: path})
+		}
+	}
+	if info.Name == "" || info.PieceLength <= 0 || len(info.Pieces)%sha1.Size != 0 {
+		return Info{}, errors.New("metainfo: invalid info dictionary")
+	}
+	return info, nil
+}
+
+// Bytes encodes the metainfo, including changes made to its top-level fields
+func (m *MetaInfo) Bytes() ([]byte, error) {
+	fields := make(map[string]interface{}, len(m.fields))
+	for key, v := range m.fields {
+		fields[key] = v
+	}
+	setString := func(key, value string) {
+		if value == "" {
+			delete(fields, key)
+		} else {
+			fields[key] = value
+		}
+	}
+	setString("announce", m.Announce)
+	setString("comment", m.Comment)
+	setString("created by", m.CreatedBy)
+	if m.CreationDate != 0 {
+		fields["creation date"] = m.CreationDate
+	} else {
+		delete(fields, "creation date")
+	}
+	if len(m.AnnounceList) > 0 {
+		tiers := make([]interface{}, len(m.AnnounceList))
+		for i, tier := range m.AnnounceList {
+			urls := make([]interface{}, len(tier))
+			for j, u := range tier {
+				urls[j] = u
+			}
+			tiers[i] = urls
+		}
+		fields["announce-list"] = tiers
+	} else {
+		delete(fields, "announce-list")
+	}
+	fields["info"] = m.infoBytes
+
+	return bencode.Encode(fields)
+}
+
+// InfoHash returns the hex encoded SHA-1 hash of the info dictionary
+func (m *MetaInfo) InfoHash() string {
+	sum := sha1.Sum(m.infoBytes)
+	return hex.EncodeToString(sum[:])
+}
+
+// Trackers returns the tracker URLs of all tiers, without duplicates. The
+// announce URL is only used by clients when there is no announce list.
+func (m *MetaInfo) Trackers() []string {
+	seen := make(map[string]bool)
+	var trackers []string
+	add := func(u string) {
+		if u != "" && !seen[u] {
+			seen[u] = true
+			trackers = append(trackers, u)
+		}
+	}
+	for _, tier := range m.AnnounceList {
+		for _, u := range tier {
+			add(u)
+		}
+	}
+	add(m.Announce)
+	return trackers
+}
+
+// RewriteAnnounce replaces every tracker URL with the result of rewrite
+func (m *MetaInfo) RewriteAnnounce(rewrite func(string) string) {
+	if m.Announce != "" {
+		m.Announce = rewrite(m.Announce)
+	}
+	for _, tier := range m.AnnounceList {
+		for i, u := range tier {
+			tier[i] = rewrite(u)
+		}
+	}
+}
+
+// NumPieces returns the number of pieces
+func (i Info) NumPieces() int {
+	return len(i.Pieces) / sha1.Size
+}
+
+// PieceHash returns the SHA-1 hash of piece n
+func (i Info) PieceHash(n int) []byte {
+	return i.Pieces[n*sha1.Size : (n+1)*sha1.Size]
+}
+
+// TotalLength returns the size of the torrent's content
+func (i Info) TotalLength() int64 {
+	if len(i.Files) == 0 {
+		return i.Length
+	}
+	var total int64
+	for _, file := range i.Files {
+		total += file.Length
+	}
+	return total
+}
+
+// FileList returns the files as laid out on disk, with paths starting with
+// the torrent's name, the way qBittorrent reports them. A single-file torrent
+// has one file named after the torrent.
+func (i Info) FileList() []File {
+	if len(i.Files) == 0 {
+		return []File{{Length: i.Length, Path: []string{i.Name}}}
+	}
+	files := make([]File, len(i.Files))
+	for n, file := range i.Files {
+		files[n] = File{Length: file.Length, Path: append([]string{i.Name}, file.Path...)}
+	}
+	return files
+}
+
+// DisplayPath returns the path of the file joined with "/"
+func (f File) DisplayPath() string {
+	return strings.Join(f.Path, "/")
+}
+
+// ReplacePasskey returns a rewrite function for RewriteAnnounce replacing the
+// passkey oldKey with newKey in tracker URLs. An empty oldKey leaves the URLs
+// unchanged.
+func ReplacePasskey(oldKey, newKey string) func(string) string {
+	return func(u string) string {
+		if oldKey == "" {
+			return u
+		}
+		return strings.ReplaceAll(u, oldKey, newKey)
+	}
+}
+
+// UpgradeHTTPS is a rewrite function for RewriteAnnounce switching http
+// tracker URLs to https
+func UpgradeHTTPS(u string) string {
+	if strings.HasPrefix(u, "http://") {
+		return "https://" + strings.TrimPrefix(u, "http://")
+	}
+	return u
+}
@@ -0,0 +1,105 @@
+package metainfo_test
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/cehbz/qbittorrent/metainfo"
+)
+
+// The info dictionary deliberately has unsorted keys: re-encoding it would
+// change the infohash
+const testInfo = "d4:name4:Show12:piece lengthi16384e6:pieces40:" +
+	"aaaaaaaaaaaaaaaaaaaabbbbbbbbbbbbbbbbbbbb" +
+	"5:filesld6:lengthi10e4:pathl3:S015:a.mkveed6:lengthi20e4:pathl5:b.nfoeee" +
+	"7:privatei1ee"
+
+const testTorrent = "d8:announce37:http://tracker.example.org/OLDKEY/ann" +
+	"13:announce-listll37:http://tracker.example.org/OLDKEY/annel27:udp://backup.example.net:80ee" +
+	"7:comment4:test" +
+	"4:info" + testInfo +
+	"12:x-extra-data5:kept!e"
+
+func TestParse(t *testing.T) {
+	m, err := metainfo.Parse([]byte(testTorrent))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sum := sha1.Sum([]byte(testInfo))
+	if m.InfoHash() != hex.EncodeToString(sum[:]) {
+		t.Errorf("expected infohash of the encoded info dictionary, got %s", m.InfoHash())
+	}
+	if m.Info.Name != "Show" || !m.Info.Private || m.Info.NumPieces() != 2 || m.Info.TotalLength() != 30 {
+		t.Errorf("unexpected info %+v", m.Info)
+	}
+	if string(m.Info.PieceHash(1)) != strings.Repeat("b", 20) {
+		t.Errorf("unexpected hash of piece 1: %q", m.Info.PieceHash(1))
+	}
+	var paths []string
+	for _, file := range m.Info.FileList() {
+		paths = append(paths, file.DisplayPath())
+	}
+	if strings.Join(paths, ",") != "Show/S01/a.mkv,Show/b.nfo" {
+		t.Errorf("unexpected files %v", paths)
+	}
+	if trackers := m.Trackers(); len(trackers) != 2 {
+		t.Errorf("expected 2 trackers, got %v", trackers)
+	}
+}
+
+func TestRewriteAnnounce(t *testing.T) {
+	m, err := metainfo.Parse([]byte(testTorrent))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	hash := m.InfoHash()
+
+	m.RewriteAnnounce(metainfo.ReplacePasskey("OLDKEY", "NEWKEY"))
+	m.RewriteAnnounce(metainfo.UpgradeHTTPS)
+	data, err := m.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	rewritten, err := metainfo.Parse(data)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rewritten.InfoHash() != hash {
+		t.Errorf("expected infohash %s to be unchanged, got %s", hash, rewritten.InfoHash())
+	}
+	expected := []string{"https://tracker.example.org/NEWKEY/ann", "udp://backup.example.net:80"}
+	if trackers := rewritten.Trackers(); strings.Join(trackers, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected trackers %v, got %v", expected, trackers)
+	}
+	if !strings.Contains(string(data), "12:x-extra-data5:kept!") {
+		t.Errorf("expected unknown fields to be kept, got %q", data)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []string{"", "i42e", "d4:infod4:name1:xee", "d8:announce3:urle", "d4:info" + testInfo}
+	for _, data := range tests {
+		if _, err := metainfo.Parse([]byte(data)); err == nil {
+			t.Errorf("expected error parsing %q, got none", data)
+		}
+	}
+}
+
+func TestReplacePasskey(t *testing.T) {
+	tests := []struct {
+		oldKey, newKey, url, expected string
+	}{
+		{"OLDKEY", "NEWKEY", "https://tracker.example.org/OLDKEY/announce", "https://tracker.example.org/NEWKEY/announce"},
+		{"OLDKEY", "NEWKEY", "https://other.example.org/announce", "https://other.example.org/announce"},
+		{"", "NEWKEY", "https://tracker.example.org/announce", "https://tracker.example.org/announce"},
+	}
+	for _, tt := range tests {
+		if got := metainfo.ReplacePasskey(tt.oldKey, tt.newKey)(tt.url); got != tt.expected {
+			t.Errorf("replacing %q: expected %s, got %s", tt.oldKey, tt.expected, got)
+		}
+	}
+}
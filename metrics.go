@@ -0,0 +1,107 @@
+package qbittorrent
+
+import (
+	"sync"
+	"time"
+)
+
+// MetricsSink receives measurements of the responses of each endpoint. Its
+// methods are called synchronously from concurrent requests, so they must be
+// quick and safe for concurrent use.
+type MetricsSink interface {
+	// ObserveResponseSize is called with the size of every response body read
+	ObserveResponseSize(endpoint string, bytes int)
+	// ObserveDecode is called with the time taken to decode a JSON response
+	ObserveDecode(endpoint string, d time.Duration)
+}
+
+// WithMetrics passes the size of every response and the time taken to decode
+// it to sink, so operators can tell when payloads such as sync/maindata grow
+// pathological and tune their filters. EndpointMetrics aggregates them in
+// memory; other sinks can export them to a monitoring system.
+func WithMetrics(sink MetricsSink) Option {
+	return func(c *Client) error {
+		c.metrics = sink
+		return nil
+	}
+}
+
+// EndpointStats are the aggregated measurements of an endpoint
+type EndpointStats struct {
+	Responses     int   // responses read
+	Bytes         int64 // total size of the responses
+	LastBytes     int   // size of the latest response
+	MaxBytes      int   // size of the largest response
+	Decodes       int   // JSON responses decoded
+	DecodeTime    time.Duration
+	MaxDecodeTime time.Duration
+}
+
+// AvgBytes returns the average size of the responses
+func (s EndpointStats) AvgBytes() int64 {
+	if s.Responses == 0 {
+		return 0
+	}
+	return s.Bytes / int64(s.Responses)
+}
+
+// AvgDecodeTime returns the average time taken to decode a response
+func (s EndpointStats) AvgDecodeTime() time.Duration {
+	if s.Decodes == 0 {
+		return 0
+	}
+	return s.DecodeTime / time.Duration(s.Decodes)
+}
+
+// EndpointMetrics is a MetricsSink aggregating the measurements per endpoint
+type EndpointMetrics struct {
+	mu    sync.Mutex
+	stats map[string]EndpointStats
+}
+
+// NewEndpointMetrics returns an empty EndpointMetrics
+func NewEndpointMetrics() *EndpointMetrics {
+	return &EndpointMetrics{stats: make(map[string]EndpointStats)}
+}
+
+// ObserveResponseSize implements the MetricsSink interface
+func (m *EndpointMetrics) ObserveResponseSize(endpoint string, bytes int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.stats[endpoint]
+	s.Responses++
+	s.Bytes += int64(bytes)
+	s.LastBytes = bytes
+	s.MaxBytes = max(s.MaxBytes, bytes)
+	m.stats[endpoint] = s
+}
+
+// ObserveDecode implements the MetricsSink interface
+func (m *EndpointMetrics) ObserveDecode(endpoint string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.stats[endpoint]
+	s.Decodes++
+	s.DecodeTime += d
+	s.MaxDecodeTime = max(s.MaxDecodeTime, d)
+	m.stats[endpoint] = s
+}
+
+// Snapshot returns the stats of the endpoints measured so far
+func (m *EndpointMetrics) Snapshot() map[string]EndpointStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := make(map[string]EndpointStats, len(m.stats))
+	for endpoint, s := range m.stats {
+		stats[endpoint] = s
+	}
+	return stats
+}
+
+// observeDecode passes the time since start decoding a response from
+// endpoint to the metrics sink, if any
+func (c *Client) observeDecode(endpoint string, start time.Time) {
+	if c.metrics != nil {
+		c.metrics.ObserveDecode(endpoint, c.Clock().Now().Sub(start))
+	}
+}
@@ -0,0 +1,77 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithMetrics(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/torrents/info":
+			w.Write([]byte(`[{"hash":"abc","name":"one"},{"hash":"def","name":"two"}]`))
+		case "/api/v2/app/version":
+			w.Write([]byte("v5.1.0"))
+		case "/api/v2/sync/maindata":
+			w.Write([]byte(`{"rid":1,"full_update":true}`))
+		}
+	}))
+	defer mockServer.Close()
+
+	metrics := NewEndpointMetrics()
+	// Decode times are measured with the clock of the client, which stands still
+	clock := &stubClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true, metrics: metrics, clock: clock}
+	for range 2 {
+		if _, err := client.TorrentsInfoCtx(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if _, err := client.AppVersionCtx(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := NewSyncState().Update(context.Background(), client); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	stats := metrics.Snapshot()
+	info := stats["/api/v2/torrents/info"]
+	if info.Responses != 2 || info.Bytes != 2*57 || info.LastBytes != 57 || info.MaxBytes != 57 || info.AvgBytes() != 57 {
+		t.Errorf("unexpected torrents/info sizes: %+v", info)
+	}
+	if info.Decodes != 2 || info.DecodeTime != 0 {
+		t.Errorf("unexpected torrents/info decode times: %+v", info)
+	}
+	if maindata := stats["/api/v2/sync/maindata"]; maindata.Decodes != 1 || maindata.DecodeTime != 0 {
+		t.Errorf("unexpected sync/maindata decode times: %+v", maindata)
+	}
+	version := stats["/api/v2/app/version"]
+	if version.Responses != 1 || version.Bytes != 6 || version.Decodes != 0 {
+		t.Errorf("unexpected app/version stats: %+v", version)
+	}
+}
+
+func TestEndpointMetrics(t *testing.T) {
+	metrics := NewEndpointMetrics()
+	metrics.ObserveResponseSize("/api/v2/sync/maindata", 100)
+	metrics.ObserveResponseSize("/api/v2/sync/maindata", 300)
+	metrics.ObserveResponseSize("/api/v2/sync/maindata", 200)
+	metrics.ObserveDecode("/api/v2/sync/maindata", time.Millisecond)
+	metrics.ObserveDecode("/api/v2/sync/maindata", 3*time.Millisecond)
+
+	stats := metrics.Snapshot()["/api/v2/sync/maindata"]
+	if stats.Responses != 3 || stats.Bytes != 600 || stats.LastBytes != 200 || stats.MaxBytes != 300 || stats.AvgBytes() != 200 {
+		t.Errorf("unexpected sizes: %+v", stats)
+	}
+	if stats.Decodes != 2 || stats.DecodeTime != 4*time.Millisecond || stats.MaxDecodeTime != 3*time.Millisecond || stats.AvgDecodeTime() != 2*time.Millisecond {
+		t.Errorf("unexpected decode times: %+v", stats)
+	}
+
+	var empty EndpointStats
+	if empty.AvgBytes() != 0 || empty.AvgDecodeTime() != 0 {
+		t.Errorf("expected zero averages without measurements")
+	}
+}
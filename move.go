@@ -0,0 +1,127 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrTorrentNotFound is returned when the server doesn't know the torrent
+	ErrTorrentNotFound = errors.New("torrent not found")
+	// ErrMoveFailed is wrapped by every MoveError
+	ErrMoveFailed = errors.New("move failed")
+	// ErrDiskFull is wrapped by a MoveError when the destination ran out of space
+	ErrDiskFull = errors.New("disk full")
+	// ErrPermissionDenied is wrapped by a MoveError when the destination isn't writable
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// MoveError describes a failed move of the data of a torrent
+type MoveError struct {
+	Hash        InfoHash
+	Name        string
+	Destination string
+	Reason      string // from the log of qBittorrent, or the state of the torrent
+}
+
+func (e *MoveError) Error() string {
+	if e.Destination == "" {
+		return fmt.Sprintf("moving %s failed: %s", e.Name, e.Reason)
+	}
+	return fmt.Sprintf("moving %s to %s failed: %s", e.Name, e.Destination, e.Reason)
+}
+
+// Unwrap returns ErrMoveFailed, along with ErrDiskFull or ErrPermissionDenied
+// when the reason says so
+func (e *MoveError) Unwrap() []error {
+	errs := []error{ErrMoveFailed}
+	reason := strings.ToLower(e.Reason)
+	switch {
+	case strings.Contains(reason, "no space left"), strings.Contains(reason, "not enough space"), strings.Contains(reason, "disk full"):
+		errs = append(errs, ErrDiskFull)
+	case strings.Contains(reason, "permission denied"), strings.Contains(reason, "access is denied"):
+		errs = append(errs, ErrPermissionDenied)
+	}
+	return errs
+}
+
+// moveFailedMessage matches the log message of a failed move since qBittorrent 4.4
+var moveFailedMessage = regexp.MustCompile(`^Failed to move torrent\. Torrent: "(.*)"\. Source: ".*"\. Destination: "(.*)"\. Reason: "(.*)"$`)
+
+// moveWaitInterval is how often WaitForMoveCtx checks on the torrent
+var moveWaitInterval = time.Second
+
+// WaitForMoveCtx waits until the torrent is no longer moving its data, as it
+// does after TorrentsSetLocationCtx or a category change under automatic
+// torrent management, and returns the torrent afterwards. A failed move is
+// reported as a *MoveError; qBittorrent only logs the reason, so failures
+// logged before WaitForMoveCtx is called aren't seen. Call it right after
+// starting the move.
+func (c *Client) WaitForMoveCtx(ctx context.Context, hash string) (*TorrentInfo, error) {
+	entries, err := c.LogMainCtx(ctx, -1)
+	if err != nil {
+		return nil, fmt.Errorf("WaitForMove error: %w", err)
+	}
+	lastID := int64(-1)
+	if len(entries) > 0 {
+		lastID = entries[len(entries)-1].ID
+	}
+
+	torrent, err := c.pollTorrent(ctx, hash, moveWaitInterval, func(torrent TorrentInfo) bool {
+		return TorrentState(torrent.State) != StateMoving
+	})
+	if err != nil {
+		return nil, fmt.Errorf("WaitForMove error: %w", err)
+	}
+	return c.moveResult(ctx, *torrent, lastID)
+}
+
+// pollTorrent polls the torrent every interval until done returns true for it
+func (c *Client) pollTorrent(ctx context.Context, hash string, interval time.Duration, done func(TorrentInfo) bool) (*TorrentInfo, error) {
+	ticker := c.Clock().NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		torrents, err := c.TorrentsInfoCtx(ctx, &TorrentsInfoParams{Hashes: []string{hash}})
+		if err != nil {
+			return nil, err
+		}
+		if len(torrents) == 0 {
+			return nil, fmt.Errorf("%s: %w", hash, ErrTorrentNotFound)
+		}
+		if done(torrents[0]) {
+			return &torrents[0], nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C():
+		}
+	}
+}
+
+// moveResult returns the torrent, or a *MoveError when a failed move of it was
+// logged after lastID or it was left without its files
+func (c *Client) moveResult(ctx context.Context, torrent TorrentInfo, lastID int64) (*TorrentInfo, error) {
+	entries, err := c.LogMainCtx(ctx, lastID)
+	if err != nil {
+		return nil, fmt.Errorf("WaitForMove error: %w", err)
+	}
+	for _, entry := range entries {
+		m := moveFailedMessage.FindStringSubmatch(entry.Message)
+		if m != nil && m[1] == torrent.Name {
+			return &torrent, &MoveError{Hash: torrent.Hash, Name: torrent.Name, Destination: m[2], Reason: m[3]}
+		}
+	}
+
+	switch state := TorrentState(torrent.State); state {
+	case StateError, StateMissingFiles:
+		return &torrent, &MoveError{Hash: torrent.Hash, Name: torrent.Name, Reason: "torrent is in state " + string(state)}
+	}
+	return &torrent, nil
+}
@@ -0,0 +1,103 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWaitForMoveCtx(t *testing.T) {
+	moveWaitInterval = time.Millisecond
+	defer func() { moveWaitInterval = time.Second }()
+
+	tests := []struct {
+		name     string
+		log      string // entries after the wait started
+		state    string // state once the move is over
+		expected []error
+	}{
+		{
+			name:  "moved",
+			log:   `[{"id":8,"message":"Moved torrent. Torrent: \"linux\"","timestamp":1,"type":1}]`,
+			state: "stalledUP",
+		},
+		{
+			name:     "disk full",
+			log:      `[{"id":8,"message":"Failed to move torrent. Torrent: \"linux\". Source: \"/a\". Destination: \"/b\". Reason: \"No space left on device\"","timestamp":1,"type":8}]`,
+			state:    "stalledUP",
+			expected: []error{ErrMoveFailed, ErrDiskFull},
+		},
+		{
+			name:     "permission denied",
+			log:      `[{"id":8,"message":"Failed to move torrent. Torrent: \"linux\". Source: \"/a\". Destination: \"/b\". Reason: \"Permission denied\"","timestamp":1,"type":8}]`,
+			state:    "stalledUP",
+			expected: []error{ErrMoveFailed, ErrPermissionDenied},
+		},
+		{
+			name:     "other torrent failed",
+			log:      `[{"id":8,"message":"Failed to move torrent. Torrent: \"bsd\". Source: \"/a\". Destination: \"/b\". Reason: \"Permission denied\"","timestamp":1,"type":8}]`,
+			state:    "missingFiles",
+			expected: []error{ErrMoveFailed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			polls := 0
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v2/log/main":
+					if r.URL.Query().Get("last_known_id") == "-1" {
+						w.Write([]byte(`[{"id":7,"message":"Failed to move torrent. Torrent: \"linux\". Source: \"/a\". Destination: \"/c\". Reason: \"old\"","timestamp":1,"type":8}]`))
+						return
+					}
+					if id := r.URL.Query().Get("last_known_id"); id != "7" {
+						t.Errorf("expected entries after 7, got %s", id)
+					}
+					w.Write([]byte(tt.log))
+				case "/api/v2/torrents/info":
+					polls++
+					state := "moving"
+					if polls > 2 {
+						state = tt.state
+					}
+					w.Write([]byte(`[{"hash":"abc","name":"linux","state":"` + state + `"}]`))
+				}
+			}))
+			defer mockServer.Close()
+			client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+			torrent, err := client.WaitForMoveCtx(context.Background(), "abc")
+			if torrent == nil || torrent.State != tt.state {
+				t.Fatalf("expected the torrent in state %s, got %+v", tt.state, torrent)
+			}
+			if tt.expected == nil && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			for _, expected := range tt.expected {
+				if !errors.Is(err, expected) {
+					t.Errorf("expected %v, got %v", expected, err)
+				}
+			}
+			var moveErr *MoveError
+			if tt.expected != nil && !errors.As(err, &moveErr) {
+				t.Errorf("expected a MoveError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWaitForMoveCtx_NotFound(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	if _, err := client.WaitForMoveCtx(context.Background(), "abc"); !errors.Is(err, ErrTorrentNotFound) {
+		t.Errorf("expected ErrTorrentNotFound, got %v", err)
+	}
+}
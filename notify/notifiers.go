@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Webhook posts messages as JSON to a URL:
+//
+//	{"kind": "completed", "at": "2024-01-02T15:04:05Z", "title": "...", "text": "...", "hash": "...", "name": "..."}
+//
+// hash and name are omitted for DiskLow.
+type Webhook struct {
+	URL    string
+	Header http.Header  // added to the requests, e.g. for authorization
+	Client *http.Client // http.DefaultClient if nil
+}
+
+type webhookPayload struct {
+	Kind  string    `json:"kind"`
+	At    time.Time `json:"at"`
+	Title string    `json:"title"`
+	Text  string    `json:"text"`
+	Hash  string    `json:"hash,omitempty"`
+	Name  string    `json:"name,omitempty"`
+}
+
+// Notify implements the Notifier interface
+func (w *Webhook) Notify(ctx context.Context, msg Message) error {
+	payload := webhookPayload{Kind: msg.Kind.String(), At: msg.At, Title: msg.Title, Text: msg.Text}
+	if msg.Torrent != nil {
+		payload.Hash = string(msg.Torrent.Hash)
+		payload.Name = msg.Torrent.Name
+	}
+	if err := postJSON(ctx, w.Client, w.URL, w.Header, payload); err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return nil
+}
+
+// Discord posts messages to a Discord channel webhook
+type Discord struct {
+	WebhookURL string
+	Username   string       // overrides the name of the webhook, if set
+	Client     *http.Client // http.DefaultClient if nil
+}
+
+// Notify implements the Notifier interface
+func (d *Discord) Notify(ctx context.Context, msg Message) error {
+	payload := struct {
+		Content  string `json:"content"`
+		Username string `json:"username,omitempty"`
+	}{Content: "**" + msg.Title + "**\n" + msg.Text, Username: d.Username}
+	if err := postJSON(ctx, d.Client, d.WebhookURL, nil, payload); err != nil {
+		return fmt.Errorf("discord: %w", err)
+	}
+	return nil
+}
+
+// DefaultTelegramAPI is the URL of the Telegram Bot API
+const DefaultTelegramAPI = "https://api.telegram.org"
+
+// Telegram sends messages to a chat through a Telegram bot
+type Telegram struct {
+	Token  string // of the bot
+	ChatID string
+	APIURL string       // DefaultTelegramAPI if empty
+	Client *http.Client // http.DefaultClient if nil
+}
+
+// Notify implements the Notifier interface
+func (t *Telegram) Notify(ctx context.Context, msg Message) error {
+	apiURL := t.APIURL
+	if apiURL == "" {
+		apiURL = DefaultTelegramAPI
+	}
+	payload := struct {
+		ChatID string `json:"chat_id"`
+		Text   string `json:"text"`
+	}{ChatID: t.ChatID, Text: msg.Title + "\n" + msg.Text}
+	if err := postJSON(ctx, t.Client, strings.TrimSuffix(apiURL, "/")+"/bot"+t.Token+"/sendMessage", nil, payload); err != nil {
+		// the URL holds the token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram: %w", err)
+	}
+	return nil
+}
+
+// sendMail sends email, replaced in tests
+var sendMail = smtp.SendMail
+
+// Email sends messages by SMTP. Contexts are not supported by net/smtp, so
+// Notify only checks ctx before sending.
+type Email struct {
+	Addr string    // host:port of the SMTP server
+	Auth smtp.Auth // nil to send without authentication
+	From string
+	To   []string
+}
+
+// Notify implements the Notifier interface
+func (e *Email) Notify(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", e.From)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Title))
+	fmt.Fprintf(&body, "Date: %s\r\n", msg.At.Format(time.RFC1123Z))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	body.WriteString(strings.ReplaceAll(msg.Text, "\n", "\r\n"))
+	body.WriteString("\r\n")
+	if err := sendMail(e.Addr, e.Auth, e.From, e.To, body.Bytes()); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	return nil
+}
+
+// postJSON posts v as JSON and fails unless the response status is 2xx
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, v any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
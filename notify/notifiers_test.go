@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cehbz/qbittorrent"
+)
+
+var testMessage = Message{
+	Kind:    Completed,
+	At:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	Title:   "Completed: Show",
+	Text:    "Show finished",
+	Torrent: &qbittorrent.TorrentInfo{Hash: "abc", Name: "Show"},
+}
+
+// captureServer records the path, headers and JSON body of the last request
+func captureServer(t *testing.T, status int) (*httptest.Server, *http.Request, map[string]any) {
+	t.Helper()
+	req := &http.Request{}
+	body := make(map[string]any)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*req = *r
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("expected a JSON body, got %s", data)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte("rejected"))
+	}))
+	t.Cleanup(server.Close)
+	return server, req, body
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	server, req, body := captureServer(t, http.StatusNoContent)
+	webhook := &Webhook{URL: server.URL + "/notify", Header: http.Header{"Authorization": {"Bearer token"}}, Client: server.Client()}
+	if err := webhook.Notify(context.Background(), testMessage); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if req.URL.Path != "/notify" || req.Header.Get("Authorization") != "Bearer token" || req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected request %s %v", req.URL.Path, req.Header)
+	}
+	if body["kind"] != "completed" || body["at"] != "2024-01-02T03:04:05Z" || body["title"] != "Completed: Show" || body["text"] != "Show finished" || body["hash"] != "abc" || body["name"] != "Show" {
+		t.Errorf("unexpected payload %v", body)
+	}
+}
+
+func TestDiscord_Notify(t *testing.T) {
+	server, _, body := captureServer(t, http.StatusNoContent)
+	discord := &Discord{WebhookURL: server.URL, Username: "qbt", Client: server.Client()}
+	if err := discord.Notify(context.Background(), testMessage); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if body["content"] != "**Completed: Show**\nShow finished" || body["username"] != "qbt" {
+		t.Errorf("unexpected payload %v", body)
+	}
+}
+
+func TestTelegram_Notify(t *testing.T) {
+	server, req, body := captureServer(t, http.StatusOK)
+	telegram := &Telegram{Token: "123:SECRET", ChatID: "42", APIURL: server.URL, Client: server.Client()}
+	if err := telegram.Notify(context.Background(), testMessage); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if req.URL.Path != "/bot123:SECRET/sendMessage" || body["chat_id"] != "42" || body["text"] != "Completed: Show\nShow finished" {
+		t.Errorf("unexpected request %s %v", req.URL.Path, body)
+	}
+
+	server.Close()
+	err := telegram.Notify(context.Background(), testMessage)
+	if err == nil || strings.Contains(err.Error(), "SECRET") {
+		t.Errorf("expected an error without the token, got %v", err)
+	}
+}
+
+func TestNotify_ErrorStatus(t *testing.T) {
+	server, _, _ := captureServer(t, http.StatusBadRequest)
+	err := (&Discord{WebhookURL: server.URL, Client: server.Client()}).Notify(context.Background(), testMessage)
+	if err == nil || !strings.Contains(err.Error(), "400 Bad Request: rejected") {
+		t.Errorf("expected the status and body in the error, got %v", err)
+	}
+}
+
+func TestEmail_Notify(t *testing.T) {
+	var addr, from string
+	var to []string
+	var mail []byte
+	sendMail = func(a string, auth smtp.Auth, f string, t []string, msg []byte) error {
+		addr, from, to, mail = a, f, t, msg
+		return nil
+	}
+	defer func() { sendMail = smtp.SendMail }()
+
+	email := &Email{Addr: "mail.example:587", From: "qbt@example.org", To: []string{"a@example.org", "b@example.org"}}
+	msg := testMessage
+	msg.Title = "Completed: Über"
+	msg.Text = "line one\nline two"
+	if err := email.Notify(context.Background(), msg); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if addr != "mail.example:587" || from != "qbt@example.org" || len(to) != 2 {
+		t.Errorf("unexpected envelope %s %s %v", addr, from, to)
+	}
+	for _, expected := range []string{
+		"To: a@example.org, b@example.org\r\n",
+		"Subject: =?utf-8?q?Completed:_=C3=9Cber?=\r\n",
+		"Date: Tue, 02 Jan 2024 03:04:05 +0000\r\n",
+		"\r\n\r\nline one\r\nline two\r\n",
+	} {
+		if !strings.Contains(string(mail), expected) {
+			t.Errorf("expected %q in the mail, got %q", expected, mail)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := email.Notify(ctx, msg); err == nil {
+		t.Error("expected an error for a canceled context")
+	}
+}
@@ -0,0 +1,289 @@
+// Package notify sends notifications about torrent events and disk alerts to
+// chat services, email and generic webhooks. A Dispatcher subscribes to a
+// Watcher and a FreeSpaceWatcher, renders messages from templates and passes
+// them to its notifiers:
+//
+//	dispatcher := notify.NewDispatcher(client, &notify.Discord{WebhookURL: url})
+//	watcher.OnEvent(dispatcher.HandleEvent)
+//	freeSpace := qbittorrent.NewFreeSpaceWatcher(thresholds, dispatcher.AlertHandler(ctx, logger))
+//
+// Only the standard library is used; the package is separate so the core
+// package doesn't depend on net/smtp and the services' APIs.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/cehbz/qbittorrent"
+)
+
+// Kind is what a notification is about
+type Kind int
+
+const (
+	// Completed is sent when a torrent finishes downloading
+	Completed Kind = iota
+	// Errored is sent when a torrent enters the error or missing files state
+	Errored
+	// Unregistered is sent when a tracker no longer knows a torrent
+	Unregistered
+	// DiskLow is sent when free space is low or the disk fills up quickly
+	DiskLow
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Completed:
+		return "completed"
+	case Errored:
+		return "errored"
+	case Unregistered:
+		return "unregistered"
+	case DiskLow:
+		return "disk low"
+	default:
+		return "unknown"
+	}
+}
+
+// Message is a rendered notification
+type Message struct {
+	Kind  Kind
+	At    time.Time
+	Title string
+	Text  string
+	// Torrent is the torrent the message is about, nil for DiskLow
+	Torrent *qbittorrent.TorrentInfo
+	// Alert is the alert of DiskLow messages
+	Alert *qbittorrent.FreeSpaceAlert
+}
+
+// Notifier delivers messages
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// NotifierFunc adapts a function to a Notifier
+type NotifierFunc func(ctx context.Context, msg Message) error
+
+// Notify implements the Notifier interface
+func (f NotifierFunc) Notify(ctx context.Context, msg Message) error {
+	return f(ctx, msg)
+}
+
+// TemplateData is passed to the templates of a message. Torrent is the zero
+// value for DiskLow, Alert for the other kinds.
+type TemplateData struct {
+	Kind    Kind
+	At      time.Time
+	Torrent qbittorrent.TorrentInfo
+	Tracker string // the message of the tracker, for Unregistered
+	Alert   qbittorrent.FreeSpaceAlert
+}
+
+// Template renders the title and text of a kind of message
+type Template struct {
+	Title *template.Template
+	Text  *template.Template
+}
+
+// Funcs are available to templates and format values as in the WebUI: bytes
+// sizes, speed rates, seconds durations in seconds such as SeedingTime,
+// duration a time.Duration, and ratio ratios
+var Funcs = template.FuncMap{
+	"bytes":   qbittorrent.FormatBytes,
+	"speed":   qbittorrent.FormatSpeed,
+	"seconds": qbittorrent.FormatDuration,
+	"duration": func(d time.Duration) string {
+		return qbittorrent.FormatDuration(int64(d / time.Second))
+	},
+	"ratio": qbittorrent.FormatRatio,
+}
+
+// ParseTemplate parses the title and text of a Template
+func ParseTemplate(title, text string) (Template, error) {
+	titleTmpl, err := template.New("title").Funcs(Funcs).Parse(title)
+	if err != nil {
+		return Template{}, err
+	}
+	textTmpl, err := template.New("text").Funcs(Funcs).Parse(text)
+	if err != nil {
+		return Template{}, err
+	}
+	return Template{Title: titleTmpl, Text: textTmpl}, nil
+}
+
+func mustParseTemplate(title, text string) Template {
+	tmpl, err := ParseTemplate(title, text)
+	if err != nil {
+		panic(err)
+	}
+	return tmpl
+}
+
+// DefaultTemplates are used for the kinds a Dispatcher has no template for
+var DefaultTemplates = map[Kind]Template{
+	Completed: mustParseTemplate(
+		"Completed: {{.Torrent.Name}}",
+		"{{.Torrent.Name}} ({{bytes .Torrent.Size}}) finished downloading to {{.Torrent.SavePath}}"),
+	Errored: mustParseTemplate(
+		"Error: {{.Torrent.Name}}",
+		"{{.Torrent.Name}} stopped with state {{.Torrent.State}}"),
+	Unregistered: mustParseTemplate(
+		"Unregistered: {{.Torrent.Name}}",
+		"The tracker no longer knows {{.Torrent.Name}}: {{.Tracker}}"),
+	DiskLow: mustParseTemplate(
+		"Disk space low",
+		"{{.Alert.Kind}}: {{bytes .Alert.FreeSpace}} free{{if .Alert.TimeToFull}}, full in {{duration .Alert.TimeToFull}}{{end}}"),
+}
+
+// Dispatcher turns events and alerts into messages for its notifiers. A
+// Dispatcher is safe for concurrent use.
+type Dispatcher struct {
+	client    *qbittorrent.Client
+	notifiers []Notifier
+
+	mu           sync.Mutex
+	templates    map[Kind]Template
+	unregistered map[qbittorrent.InfoHash]bool // notified, until the torrent works again
+}
+
+// NewDispatcher returns a dispatcher sending to notifiers. The client looks up
+// the trackers of torrents that lost their working tracker, to detect
+// unregistered torrents; if nil, Unregistered is never sent.
+func NewDispatcher(client *qbittorrent.Client, notifiers ...Notifier) *Dispatcher {
+	return &Dispatcher{
+		client:       client,
+		notifiers:    notifiers,
+		templates:    make(map[Kind]Template),
+		unregistered: make(map[qbittorrent.InfoHash]bool),
+	}
+}
+
+// SetTemplate replaces the template of a kind of message
+func (d *Dispatcher) SetTemplate(kind Kind, tmpl Template) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.templates[kind] = tmpl
+}
+
+// HandleEvent is an EventHandler sending Completed, Errored and Unregistered
+// messages
+func (d *Dispatcher) HandleEvent(ctx context.Context, event qbittorrent.Event) error {
+	data := TemplateData{At: event.At, Torrent: event.Torrent}
+	switch event.Type {
+	case qbittorrent.TorrentCompleted:
+		data.Kind = Completed
+	case qbittorrent.TorrentStateChanged:
+		state := qbittorrent.TorrentState(event.Torrent.State)
+		if state == qbittorrent.StateError || state == qbittorrent.StateMissingFiles {
+			data.Kind = Errored
+			break
+		}
+		msg, err := d.checkUnregistered(ctx, event.Torrent)
+		if err != nil || msg == "" {
+			return err
+		}
+		data.Kind = Unregistered
+		data.Tracker = msg
+	default:
+		return nil
+	}
+	return d.send(ctx, data)
+}
+
+// checkUnregistered returns the message of the tracker that doesn't know the
+// torrent, if it has no working tracker and wasn't reported yet
+func (d *Dispatcher) checkUnregistered(ctx context.Context, torrent qbittorrent.TorrentInfo) (string, error) {
+	if d.client == nil || qbittorrent.TorrentState(torrent.State).Stopped() {
+		return "", nil
+	}
+	d.mu.Lock()
+	reported := d.unregistered[torrent.Hash]
+	if torrent.Tracker != "" {
+		delete(d.unregistered, torrent.Hash)
+	}
+	d.mu.Unlock()
+	if torrent.Tracker != "" || reported {
+		return "", nil
+	}
+
+	trackers, err := d.client.TorrentsTrackersCtx(ctx, string(torrent.Hash))
+	if err != nil {
+		return "", fmt.Errorf("checking trackers of %s: %w", torrent.Hash, err)
+	}
+	for _, tracker := range trackers {
+		if tracker.Status == qbittorrent.TrackerNotWorking && tracker.MessageKind() == qbittorrent.TrackerMessageUnregistered {
+			d.mu.Lock()
+			d.unregistered[torrent.Hash] = true
+			d.mu.Unlock()
+			return tracker.Msg, nil
+		}
+	}
+	return "", nil
+}
+
+// AlertHandler returns a function for NewFreeSpaceWatcher sending DiskLow
+// messages when an alert starts. Delivery errors are logged to logger.
+func (d *Dispatcher) AlertHandler(ctx context.Context, logger *slog.Logger) func(qbittorrent.FreeSpaceAlert) {
+	return func(alert qbittorrent.FreeSpaceAlert) {
+		if err := d.HandleAlert(ctx, alert); err != nil {
+			logger.Error("sending disk alert failed", "error", err)
+		}
+	}
+}
+
+// HandleAlert sends a DiskLow message for an alert, unless it cleared
+func (d *Dispatcher) HandleAlert(ctx context.Context, alert qbittorrent.FreeSpaceAlert) error {
+	if alert.Cleared {
+		return nil
+	}
+	return d.send(ctx, TemplateData{Kind: DiskLow, At: alert.At, Alert: alert})
+}
+
+// send renders a message and passes it to every notifier
+func (d *Dispatcher) send(ctx context.Context, data TemplateData) error {
+	msg, err := d.render(data)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, notifier := range d.notifiers {
+		if err := notifier.Notify(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// render executes the template of the kind of data
+func (d *Dispatcher) render(data TemplateData) (Message, error) {
+	d.mu.Lock()
+	tmpl, ok := d.templates[data.Kind]
+	d.mu.Unlock()
+	if !ok {
+		tmpl = DefaultTemplates[data.Kind]
+	}
+
+	var title, text bytes.Buffer
+	if err := tmpl.Title.Execute(&title, data); err != nil {
+		return Message{}, fmt.Errorf("rendering %s title: %w", data.Kind, err)
+	}
+	if err := tmpl.Text.Execute(&text, data); err != nil {
+		return Message{}, fmt.Errorf("rendering %s text: %w", data.Kind, err)
+	}
+	msg := Message{Kind: data.Kind, At: data.At, Title: title.String(), Text: text.String()}
+	if data.Kind == DiskLow {
+		msg.Alert = &data.Alert
+	} else {
+		msg.Torrent = &data.Torrent
+	}
+	return msg, nil
+}
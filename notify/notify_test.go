@@ -0,0 +1,140 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cehbz/qbittorrent"
+)
+
+// recorder is a Notifier recording the messages
+type recorder struct {
+	messages []Message
+	err      error
+}
+
+func (r *recorder) Notify(ctx context.Context, msg Message) error {
+	r.messages = append(r.messages, msg)
+	return r.err
+}
+
+func TestDispatcher_HandleEvent(t *testing.T) {
+	trackerRequests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/torrents/trackers" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		trackerRequests++
+		w.Write([]byte(`[{"url":"** [DHT] **","status":0},{"url":"https://tracker.example/announce","status":4,"msg":"Unregistered torrent"}]`))
+	}))
+	defer mockServer.Close()
+	client, err := qbittorrent.NewClientWithOptions("", "", "", "",
+		qbittorrent.WithBaseURL(mockServer.URL),
+		qbittorrent.WithHTTPClient(mockServer.Client()),
+		qbittorrent.WithBypassAuth(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	rec := &recorder{}
+	dispatcher := NewDispatcher(client, rec)
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	torrent := qbittorrent.TorrentInfo{Hash: "abc", Name: "Show", Size: 2048, SavePath: "/data", State: "uploading", Tracker: "https://tracker.example/announce"}
+	events := []qbittorrent.Event{
+		{Type: qbittorrent.TorrentAdded, At: at, Hash: "abc", Torrent: torrent},
+		{Type: qbittorrent.TorrentCompleted, At: at, Hash: "abc", Torrent: torrent},
+		{Type: qbittorrent.TorrentStateChanged, At: at, Hash: "abc", Torrent: torrent},
+		{Type: qbittorrent.TorrentStateChanged, At: at, Hash: "abc", Torrent: withState(torrent, "missingFiles")},
+		{Type: qbittorrent.TorrentStateChanged, At: at, Hash: "abc", Torrent: withState(torrent, "stalledUP")},
+		{Type: qbittorrent.TorrentStateChanged, At: at, Hash: "abc", Torrent: withState(torrent, "queuedUP")},
+		{Type: qbittorrent.TorrentStateChanged, At: at, Hash: "abc", Torrent: withState(torrent, "uploading")},
+		{Type: qbittorrent.TorrentStateChanged, At: at, Hash: "abc", Torrent: withState(torrent, "stoppedUP")},
+		{Type: qbittorrent.TorrentStateChanged, At: at, Hash: "abc", Torrent: withState(torrent, "stalledUP")},
+	}
+	for _, event := range events {
+		if err := dispatcher.HandleEvent(context.Background(), event); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	expected := []struct {
+		kind        Kind
+		title, text string
+	}{
+		{Completed, "Completed: Show", "Show (2.0 KiB) finished downloading to /data"},
+		{Errored, "Error: Show", "Show stopped with state missingFiles"},
+		{Unregistered, "Unregistered: Show", "The tracker no longer knows Show: Unregistered torrent"},
+		{Unregistered, "Unregistered: Show", "The tracker no longer knows Show: Unregistered torrent"},
+	}
+	if len(rec.messages) != len(expected) {
+		t.Fatalf("expected %d messages, got %+v", len(expected), rec.messages)
+	}
+	for i, msg := range rec.messages {
+		if msg.Kind != expected[i].kind || msg.Title != expected[i].title || msg.Text != expected[i].text || msg.Torrent.Hash != "abc" || !msg.At.Equal(at) {
+			t.Errorf("expected %+v, got %+v", expected[i], msg)
+		}
+	}
+	// reported again only after the tracker worked, stopped torrents aren't checked
+	if trackerRequests != 2 {
+		t.Errorf("expected 2 tracker requests, got %d", trackerRequests)
+	}
+}
+
+func withState(torrent qbittorrent.TorrentInfo, state string) qbittorrent.TorrentInfo {
+	torrent.State = state
+	if state != "uploading" {
+		torrent.Tracker = ""
+	}
+	return torrent
+}
+
+func TestDispatcher_HandleAlert(t *testing.T) {
+	rec := &recorder{}
+	dispatcher := NewDispatcher(nil, rec)
+	alert := qbittorrent.FreeSpaceAlert{Kind: qbittorrent.FreeSpaceFillingUp, FreeSpace: 1 << 30, TimeToFull: 90 * time.Minute}
+	if err := dispatcher.HandleAlert(context.Background(), alert); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	alert.Cleared = true
+	if err := dispatcher.HandleAlert(context.Background(), alert); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(rec.messages) != 1 {
+		t.Fatalf("expected 1 message, got %+v", rec.messages)
+	}
+	msg := rec.messages[0]
+	if msg.Kind != DiskLow || msg.Text != "disk filling up: 1.00 GiB free, full in 1h 30m" || msg.Alert == nil || msg.Torrent != nil {
+		t.Errorf("unexpected message %+v", msg)
+	}
+}
+
+func TestDispatcher_Templates(t *testing.T) {
+	tmpl, err := ParseTemplate("{{.Kind}}", "{{.Torrent.Name}} seeded {{seconds .Torrent.SeedingTime}} at {{ratio .Torrent.Ratio}}")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	failing := &recorder{err: errors.New("unavailable")}
+	rec := &recorder{}
+	dispatcher := NewDispatcher(nil, failing, rec)
+	dispatcher.SetTemplate(Completed, tmpl)
+
+	torrent := qbittorrent.TorrentInfo{Name: "Show", SeedingTime: 3600, Ratio: 1.5}
+	err = dispatcher.HandleEvent(context.Background(), qbittorrent.Event{Type: qbittorrent.TorrentCompleted, Torrent: torrent})
+	if err == nil || !strings.Contains(err.Error(), "unavailable") {
+		t.Errorf("expected the error of the failing notifier, got %v", err)
+	}
+	if len(rec.messages) != 1 || rec.messages[0].Title != "completed" || rec.messages[0].Text != "Show seeded 1h 0m at 1.50" {
+		t.Errorf("expected the message despite the failing notifier, got %+v", rec.messages)
+	}
+
+	if _, err := ParseTemplate("{{.Kind", ""); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+}
@@ -0,0 +1,245 @@
+package qbittorrent
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds each request unless overridden by WithTimeout or a
+// context deadline
+const DefaultTimeout = 30 * time.Second
+
+// Option configures a Client created by NewClientWithOptions
+type Option func(*Client) error
+
+// WithHTTPClient uses the given http.Client for all requests.
+// If httpClient is nil, http.DefaultClient is used.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) error {
+		c.client = httpClient
+		return nil
+	}
+}
+
+// WithTimeout sets the default timeout for each request, including reading the
+// response body. Zero disables the timeout. A deadline on the context passed to
+// a Ctx method takes precedence, so a single call can be given more or less time.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout < 0 {
+			return fmt.Errorf("invalid timeout %v", timeout)
+		}
+		c.timeout = timeout
+		return nil
+	}
+}
+
+// WithSyncTimeout sets the timeout of requests to the sync endpoints,
+// replacing the default timeout for them. Zero disables it. Sync responses
+// can be large on instances with many torrents, and loops polling them
+// shouldn't fail on a single slow response, see SyncState.Run.
+func WithSyncTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout < 0 {
+			return fmt.Errorf("invalid sync timeout %v", timeout)
+		}
+		c.syncTimeout = &timeout
+		return nil
+	}
+}
+
+// WithBaseURL overrides the base URL built from addr and port, e.g. to reach
+// a WebUI served over HTTPS or below a path prefix by a reverse proxy.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) error {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return fmt.Errorf("invalid base URL: %v", err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid base URL %q: scheme and host are required", baseURL)
+		}
+		c.baseURL = baseURL
+		c.customBaseURL = true
+		return nil
+	}
+}
+
+// WithBasicAuth sends HTTP Basic Auth credentials with every request, for
+// reverse proxies that protect the WebUI. The qBittorrent login is unaffected.
+func WithBasicAuth(username, password string) Option {
+	return func(c *Client) error {
+		c.basicAuth = &basicAuth{username: username, password: password}
+		return nil
+	}
+}
+
+// WithBypassAuth is for servers configured to bypass authentication for
+// localhost or whitelisted IPs. The client never calls AuthLogin, neither on
+// creation nor to recover from a 403 response.
+func WithBypassAuth() Option {
+	return func(c *Client) error {
+		c.bypassAuth = true
+		return nil
+	}
+}
+
+// WithTLSConfig connects over HTTPS using a copy of cfg. It replaces any TLS
+// settings made by earlier options.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Client) error {
+		if cfg == nil {
+			return errors.New("nil TLS config")
+		}
+		c.transportConfig().tls = cfg.Clone()
+		return nil
+	}
+}
+
+// WithRootCAs connects over HTTPS and verifies the server certificate against
+// pool instead of the system roots, e.g. for a self-signed WebUI certificate.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(c *Client) error {
+		c.transportConfig().tlsConfig().RootCAs = pool
+		return nil
+	}
+}
+
+// WithClientCertificate connects over HTTPS and presents cert to the server.
+func WithClientCertificate(cert tls.Certificate) Option {
+	return func(c *Client) error {
+		cfg := c.transportConfig().tlsConfig()
+		cfg.Certificates = append(cfg.Certificates, cert)
+		return nil
+	}
+}
+
+// WithInsecureSkipVerify connects over HTTPS without verifying the server
+// certificate. Prefer WithRootCAs where possible.
+func WithInsecureSkipVerify() Option {
+	return func(c *Client) error {
+		c.transportConfig().tlsConfig().InsecureSkipVerify = true
+		return nil
+	}
+}
+
+// WithProxy sends all requests through the proxy at proxyURL. The http, https,
+// socks5 and socks5h schemes are supported, e.g. "socks5://127.0.0.1:1080" for
+// an SSH-forwarded SOCKS proxy.
+func WithProxy(proxyURL string) Option {
+	return func(c *Client) error {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL: %v", err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("invalid proxy URL %q: host is required", proxyURL)
+		}
+		c.transportConfig().proxy = u
+		return nil
+	}
+}
+
+// WithLogger sets the logger used for diagnostics such as dry-run requests.
+// By default slog.Default() is used.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) error {
+		c.logger = logger
+		return nil
+	}
+}
+
+// WithDryRun makes destructive calls (deleting torrents, tags or categories,
+// moving data and shutting down the application) log the request they would
+// have sent and report success without contacting the server.
+func WithDryRun() Option {
+	return func(c *Client) error {
+		c.dryRun = true
+		return nil
+	}
+}
+
+// WithReadOnly restricts the client to endpoints that only read state, such as
+// torrent info and sync data. Any other call fails with ErrReadOnly without
+// contacting the server, so dashboards can be given a client that provably
+// cannot modify the instance.
+func WithReadOnly() Option {
+	return func(c *Client) error {
+		c.readOnly = true
+		return nil
+	}
+}
+
+// WithStrictDecoding rejects responses containing fields that the decoded
+// types don't map, failing with an *UnknownFieldsError. It is meant for CI runs
+// that detect schema drift between qBittorrent versions; by default unknown
+// fields are ignored.
+func WithStrictDecoding() Option {
+	return func(c *Client) error {
+		c.strictDecoding = true
+		return nil
+	}
+}
+
+// basicAuth holds credentials for HTTP Basic Auth
+type basicAuth struct {
+	username string
+	password string
+}
+
+// transportOptions collects options that require the client to build its own transport
+type transportOptions struct {
+	tls   *tls.Config
+	proxy *url.URL
+}
+
+// transportConfig returns the transport options, creating them if needed
+func (c *Client) transportConfig() *transportOptions {
+	if c.transport == nil {
+		c.transport = &transportOptions{}
+	}
+	return c.transport
+}
+
+// tlsConfig returns the TLS config, creating it if needed
+func (t *transportOptions) tlsConfig() *tls.Config {
+	if t.tls == nil {
+		t.tls = &tls.Config{}
+	}
+	return t.tls
+}
+
+// buildHTTPClient creates an http.Client honoring the transport options
+func (c *Client) buildHTTPClient() (*http.Client, error) {
+	if c.client != nil {
+		return nil, errors.New("transport options cannot be combined with WithHTTPClient")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.transport.tls != nil {
+		transport.TLSClientConfig = c.transport.tls
+		// TLS options imply HTTPS, upgrade the default base URL accordingly
+		if strings.HasPrefix(c.baseURL, "http://") {
+			if c.customBaseURL {
+				return nil, fmt.Errorf("TLS options require an https base URL, got %q", c.baseURL)
+			}
+			c.baseURL = "https://" + strings.TrimPrefix(c.baseURL, "http://")
+		}
+	}
+	if c.transport.proxy != nil {
+		transport.Proxy = http.ProxyURL(c.transport.proxy)
+	}
+	return &http.Client{Transport: transport}, nil
+}
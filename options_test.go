@@ -0,0 +1,292 @@
+package qbittorrent
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithBasicAuth(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "proxyuser" || pass != "proxypass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/api/v2/auth/login" {
+			http.SetCookie(w, &http.Cookie{Name: "SID", Value: "sid"})
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClientWithOptions("user", "pass", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithBasicAuth("proxyuser", "proxypass"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if client.sid != "sid" {
+		t.Errorf("expected SID to be set, got %q", client.sid)
+	}
+
+	if _, err := client.TorrentsInfo(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestWithBypassAuth(t *testing.T) {
+	var logins int
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/auth/login":
+			logins++
+			w.WriteHeader(http.StatusOK)
+		case "/api/v2/torrents/info":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`[{"name":"torrent1"}]`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClientWithOptions("user", "pass", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithBypassAuth(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	torrents, err := client.TorrentsInfo()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(torrents) != 1 {
+		t.Errorf("expected 1 torrent, got %d", len(torrents))
+	}
+
+	// A 403 must be reported rather than triggering a login
+	if _, err := client.TorrentsTrackers("somehash"); err == nil {
+		t.Errorf("expected error on 403, got none")
+	}
+	if logins != 0 {
+		t.Errorf("expected no login requests, got %d", logins)
+	}
+}
+
+func TestWithBaseURL_Invalid(t *testing.T) {
+	_, err := NewClientWithOptions("", "", "", "", WithBaseURL("localhost:8080"))
+	if err == nil || !strings.Contains(err.Error(), "scheme and host") {
+		t.Errorf("expected invalid base URL error, got %v", err)
+	}
+}
+
+func newTLSTestServer(t *testing.T, clientAuth tls.ClientAuthType) *httptest.Server {
+	t.Helper()
+	mockServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[]`))
+	}))
+	mockServer.TLS = &tls.Config{ClientAuth: clientAuth}
+	mockServer.StartTLS()
+	return mockServer
+}
+
+func TestWithRootCAs(t *testing.T) {
+	mockServer := newTLSTestServer(t, tls.NoClientCert)
+	defer mockServer.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(mockServer.Certificate())
+
+	// The default base URL is upgraded to HTTPS
+	addr := strings.TrimPrefix(mockServer.URL, "https://")
+	host, port, _ := strings.Cut(addr, ":")
+	client, err := NewClientWithOptions("", "", host, port, WithRootCAs(pool))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if client.baseURL != mockServer.URL {
+		t.Errorf("expected base URL %s, got %s", mockServer.URL, client.baseURL)
+	}
+	if _, err := client.TorrentsInfo(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Without the CA the certificate is rejected
+	client, err = NewClientWithOptions("", "", host, port, WithTLSConfig(&tls.Config{}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.TorrentsInfo(); err == nil {
+		t.Errorf("expected certificate verification error, got none")
+	}
+}
+
+func TestWithTLSConfig_Invalid(t *testing.T) {
+	if _, err := NewClientWithOptions("", "", "localhost", "8080", WithTLSConfig(nil)); err == nil {
+		t.Error("expected an error for a nil TLS config")
+	}
+	// An explicit http base URL is not rewritten
+	if _, err := NewClientWithOptions("", "", "", "", WithBaseURL("http://localhost:8080"), WithInsecureSkipVerify()); err == nil {
+		t.Error("expected an error for TLS options with an http base URL")
+	}
+}
+
+func TestWithInsecureSkipVerify(t *testing.T) {
+	mockServer := newTLSTestServer(t, tls.NoClientCert)
+	defer mockServer.Close()
+
+	client, err := NewClientWithOptions("", "", "", "", WithBaseURL(mockServer.URL), WithInsecureSkipVerify())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.TorrentsInfo(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestWithClientCertificate(t *testing.T) {
+	mockServer := newTLSTestServer(t, tls.RequireAnyClientCert)
+	defer mockServer.Close()
+
+	client, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithInsecureSkipVerify(),
+		WithClientCertificate(mockServer.TLS.Certificates[0]),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.TorrentsInfo(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestTransportOptions_WithHTTPClient(t *testing.T) {
+	_, err := NewClientWithOptions("", "", "localhost", "8080", WithHTTPClient(&http.Client{}), WithInsecureSkipVerify())
+	if err == nil {
+		t.Errorf("expected error combining transport options with WithHTTPClient, got none")
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute target URL
+		proxied = r.URL.String()
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[]`))
+	}))
+	defer proxy.Close()
+
+	client, err := NewClientWithOptions("", "", "qbittorrent.internal", "8080", WithProxy(proxy.URL))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.TorrentsInfo(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if proxied != "http://qbittorrent.internal:8080/api/v2/torrents/info" {
+		t.Errorf("expected request through proxy, got %q", proxied)
+	}
+}
+
+func TestWithProxy_Invalid(t *testing.T) {
+	tests := []string{"ftp://proxy:21", "socks5://", "://bad"}
+	for _, proxyURL := range tests {
+		if _, err := NewClientWithOptions("", "", "localhost", "8080", WithProxy(proxyURL)); err == nil {
+			t.Errorf("expected error for proxy URL %q, got none", proxyURL)
+		}
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithTimeout(20*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err = client.TorrentsInfoCtx(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	// A context deadline overrides the default timeout for a single call
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := client.TorrentsInfoCtx(ctx); err != nil {
+		t.Errorf("expected no error with per-call deadline, got %v", err)
+	}
+}
+
+func TestDefaultTimeout(t *testing.T) {
+	client, err := NewClientWithOptions("", "", "localhost", "8080")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if client.timeout != DefaultTimeout {
+		t.Errorf("expected default timeout %v, got %v", DefaultTimeout, client.timeout)
+	}
+
+	if _, err := NewClientWithOptions("", "", "localhost", "8080", WithTimeout(-time.Second)); err == nil {
+		t.Errorf("expected error for negative timeout, got none")
+	}
+}
+
+func TestWithSyncTimeout(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		if r.URL.Path == "/api/v2/sync/maindata" {
+			w.Write([]byte(`{"rid":1}`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithBypassAuth(),
+		WithTimeout(20*time.Millisecond),
+		WithSyncTimeout(0),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := client.SyncMainDataCtx(context.Background(), 0); err != nil {
+		t.Errorf("expected sync without timeout to succeed, got %v", err)
+	}
+	if _, err := client.TorrentsInfoCtx(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the default timeout for other endpoints, got %v", err)
+	}
+
+	if _, err := NewClientWithOptions("", "", "localhost", "8080", WithSyncTimeout(-time.Second)); err == nil {
+		t.Errorf("expected error for negative sync timeout, got none")
+	}
+}
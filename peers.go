@@ -0,0 +1,164 @@
+package qbittorrent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultPeerSyncConcurrency bounds the peer requests of SyncAllTorrentPeersCtx
+// made at the same time
+const DefaultPeerSyncConcurrency = 4
+
+// ConnectedPeer is a peer connected for a torrent
+type ConnectedPeer struct {
+	Hash InfoHash
+	Key  string // "ip:port"
+	TorrentPeer
+}
+
+// PeerTables maintains the peer tables of all active torrents of an instance
+// from the incremental responses of /api/v2/sync/torrentPeers, for monitoring
+// connections across the instance. A PeerTables is safe for concurrent use.
+type PeerTables struct {
+	rids        *RIDTracker
+	concurrency int
+
+	mu    sync.RWMutex
+	peers map[string]map[string]TorrentPeer
+}
+
+// NewPeerTables returns empty peer tables. The response IDs are kept in rids,
+// which may be shared with other consumers of the sync endpoints; if nil, an
+// in-memory tracker is used. concurrency bounds the requests made at the same
+// time, DefaultPeerSyncConcurrency if not positive.
+func NewPeerTables(rids *RIDTracker, concurrency int) *PeerTables {
+	if rids == nil {
+		rids, _ = NewRIDTracker("")
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultPeerSyncConcurrency
+	}
+	return &PeerTables{
+		rids:        rids,
+		concurrency: concurrency,
+		peers:       make(map[string]map[string]TorrentPeer),
+	}
+}
+
+// SyncAllTorrentPeersCtx updates the peer tables of all active torrents and
+// drops those of torrents that are no longer active. Failures for single
+// torrents don't stop the others and are returned joined.
+func (t *PeerTables) SyncAllTorrentPeersCtx(ctx context.Context, c *Client) error {
+	torrents, err := c.TorrentsInfoCtx(ctx, &TorrentsInfoParams{Filter: "active"})
+	if err != nil {
+		return err
+	}
+	active := make(map[string]bool, len(torrents))
+	for _, torrent := range torrents {
+		active[string(torrent.Hash)] = true
+	}
+
+	var errs []error
+	t.mu.Lock()
+	for hash := range t.peers {
+		if !active[hash] {
+			delete(t.peers, hash)
+		}
+	}
+	t.mu.Unlock()
+	for _, hash := range t.rids.PeerHashes() {
+		if !active[hash] {
+			errs = append(errs, t.rids.ForgetPeers(hash))
+		}
+	}
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		sem = make(chan struct{}, t.concurrency)
+	)
+	for hash := range active {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(hash string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := t.syncTorrent(ctx, c, hash); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("torrent %s: %w", hash, err))
+				mu.Unlock()
+			}
+		}(hash)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// syncTorrent applies the peer changes of a torrent
+func (t *PeerTables) syncTorrent(ctx context.Context, c *Client, hash string) error {
+	_, raw, info, err := t.rids.syncTorrentPeers(ctx, c, hash)
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Peers        map[string]json.RawMessage `json:"peers"`
+		PeersRemoved []string                   `json:"peers_removed"`
+	}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	peers, ok := t.peers[hash]
+	if !ok || info.Full {
+		peers = make(map[string]TorrentPeer)
+		t.peers[hash] = peers
+	}
+	for key, fields := range resp.Peers {
+		// Unmarshaling only overwrites the fields present, merging partial updates
+		peer := peers[key]
+		if err := json.Unmarshal(fields, &peer); err != nil {
+			return fmt.Errorf("failed to decode peer %s: %w", key, err)
+		}
+		peers[key] = peer
+	}
+	for _, key := range resp.PeersRemoved {
+		delete(peers, key)
+	}
+	return nil
+}
+
+// TorrentPeers returns a copy of the peer table of a torrent keyed by "ip:port"
+func (t *PeerTables) TorrentPeers(hash string) map[string]TorrentPeer {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	peers := make(map[string]TorrentPeer, len(t.peers[hash]))
+	for key, peer := range t.peers[hash] {
+		peers[key] = peer
+	}
+	return peers
+}
+
+// Peers returns the peers of all torrents, ordered by torrent and peer
+func (t *PeerTables) Peers() []ConnectedPeer {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var peers []ConnectedPeer
+	for hash, table := range t.peers {
+		for key, peer := range table {
+			peers = append(peers, ConnectedPeer{Hash: InfoHash(hash), Key: key, TorrentPeer: peer})
+		}
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		if peers[i].Hash != peers[j].Hash {
+			return peers[i].Hash < peers[j].Hash
+		}
+		return peers[i].Key < peers[j].Key
+	})
+	return peers
+}
@@ -0,0 +1,76 @@
+package qbittorrent
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestPeerTables_SyncAllTorrentPeers(t *testing.T) {
+	var mu sync.Mutex
+	active := `[{"hash":"hash1"},{"hash":"hash2"}]`
+	responses := map[string]string{
+		"hash1 0": `{"rid":1,"full_update":true,"peers":{"1.2.3.4:6881":{"ip":"1.2.3.4","port":6881,"client":"qBittorrent","dl_speed":10}}}`,
+		"hash1 1": `{"rid":2,"peers":{"1.2.3.4:6881":{"dl_speed":20},"5.6.7.8:51413":{"ip":"5.6.7.8","port":51413}}}`,
+		"hash2 0": `{"rid":1,"full_update":true,"peers":{"9.9.9.9:1":{"ip":"9.9.9.9","port":1}}}`,
+		"hash1 2": `{"rid":3,"peers_removed":["5.6.7.8:51413"]}`,
+	}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/api/v2/torrents/info":
+			if r.URL.Query().Get("filter") != "active" {
+				t.Errorf("expected active filter, got %q", r.URL.Query().Get("filter"))
+			}
+			w.Write([]byte(active))
+		case "/api/v2/sync/torrentPeers":
+			key := r.URL.Query().Get("hash") + " " + r.URL.Query().Get("rid")
+			resp, ok := responses[key]
+			if !ok {
+				t.Errorf("unexpected peers request %q", key)
+			}
+			w.Write([]byte(resp))
+		}
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	tables := NewPeerTables(nil, 2)
+	ctx := context.Background()
+
+	if err := tables.SyncAllTorrentPeersCtx(ctx, client); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if peers := tables.Peers(); len(peers) != 2 || peers[0].Hash != "hash1" || peers[1].Key != "9.9.9.9:1" {
+		t.Errorf("unexpected peers %+v", peers)
+	}
+
+	mu.Lock()
+	active = `[{"hash":"hash1"}]`
+	mu.Unlock()
+	if err := tables.SyncAllTorrentPeersCtx(ctx, client); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	peers := tables.TorrentPeers("hash1")
+	if len(peers) != 2 || peers["1.2.3.4:6881"].DLSpeed != 20 || peers["1.2.3.4:6881"].Client != "qBittorrent" {
+		t.Errorf("expected merged peers of hash1, got %+v", peers)
+	}
+	if len(tables.TorrentPeers("hash2")) != 0 {
+		t.Errorf("expected peers of inactive torrent to be dropped")
+	}
+	if hashes := tables.rids.PeerHashes(); len(hashes) != 1 {
+		t.Errorf("expected response ID of hash2 to be forgotten, got %v", hashes)
+	}
+
+	if err := tables.SyncAllTorrentPeersCtx(ctx, client); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if peers := tables.Peers(); len(peers) != 1 || peers[0].IP != "1.2.3.4" {
+		t.Errorf("expected removed peer to be gone, got %+v", peers)
+	}
+}
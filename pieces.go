@@ -0,0 +1,132 @@
+package qbittorrent
+
+import "sort"
+
+// PieceMap maps the pieces of a torrent to the byte ranges of its files, e.g.
+// for progress bars of files being streamed or extracted before the torrent
+// completes. Offsets are the sums of the preceding file sizes, moved to the
+// first piece of a file's piece range if that is later, since the server
+// doesn't list the pad files aligning files to pieces.
+type PieceMap struct {
+	PieceSize int64
+	files     []pieceMapFile // by offset
+	byIndex   map[int]int    // positions in files by file index
+}
+
+// pieceMapFile is the byte range of a file in the torrent's data
+type pieceMapFile struct {
+	index  int
+	offset int64
+	size   int64
+}
+
+// NewPieceMap maps the pieces of pieceSize bytes, see
+// TorrentsProperties.PieceSize, to files as returned by TorrentsFilesCtx
+func NewPieceMap(files []TorrentFile, pieceSize int64) *PieceMap {
+	sorted := append([]TorrentFile(nil), files...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Index < sorted[j].Index })
+
+	m := &PieceMap{PieceSize: pieceSize, files: make([]pieceMapFile, len(sorted)), byIndex: make(map[int]int, len(sorted))}
+	var offset int64
+	for i, file := range sorted {
+		if len(file.PieceRange) == 2 && pieceSize > 0 {
+			offset = max(offset, int64(file.PieceRange[0])*pieceSize)
+		}
+		m.files[i] = pieceMapFile{index: file.Index, offset: offset, size: file.Size}
+		m.byIndex[file.Index] = i
+		offset += file.Size
+	}
+	return m
+}
+
+// Offset returns the offset of the file with the given index in the torrent's
+// data, and false for unknown files
+func (m *PieceMap) Offset(index int) (int64, bool) {
+	file, ok := m.file(index)
+	return file.offset, ok
+}
+
+// Pieces returns the first and last piece holding data of the file with the
+// given index, and false for unknown and empty files
+func (m *PieceMap) Pieces(index int) (first, last int, ok bool) {
+	file, ok := m.file(index)
+	if !ok || file.size == 0 || m.PieceSize <= 0 {
+		return 0, 0, false
+	}
+	return int(file.offset / m.PieceSize), int((file.offset + file.size - 1) / m.PieceSize), true
+}
+
+// Files returns the indexes of the files holding data in piece, in the order
+// of their data
+func (m *PieceMap) Files(piece int) []int {
+	if m.PieceSize <= 0 || piece < 0 {
+		return nil
+	}
+	start, end := int64(piece)*m.PieceSize, int64(piece+1)*m.PieceSize
+	first := sort.Search(len(m.files), func(i int) bool { return m.files[i].offset+m.files[i].size > start })
+	var indexes []int
+	for _, file := range m.files[first:] {
+		if file.offset >= end {
+			break
+		}
+		if file.size > 0 {
+			indexes = append(indexes, file.index)
+		}
+	}
+	return indexes
+}
+
+// Completed returns the bytes of the file with the given index in the
+// downloaded pieces of states, see TorrentsPieceStatesCtx
+func (m *PieceMap) Completed(index int, states []PieceState) int64 {
+	var completed int64
+	m.eachPiece(index, states, func(start, end int64, state PieceState) bool {
+		if state == PieceDownloaded {
+			completed += end - start
+		}
+		return true
+	})
+	return completed
+}
+
+// Frontier returns the bytes of the file with the given index that are
+// downloaded contiguously from its start, i.e. how far it can be played or
+// read
+func (m *PieceMap) Frontier(index int, states []PieceState) int64 {
+	var frontier int64
+	m.eachPiece(index, states, func(start, end int64, state PieceState) bool {
+		if state != PieceDownloaded {
+			return false
+		}
+		frontier += end - start
+		return true
+	})
+	return frontier
+}
+
+// eachPiece calls fn with the byte range of the file with the given index in
+// each of its pieces and the piece's state, until fn returns false. Pieces
+// beyond states are not visited.
+func (m *PieceMap) eachPiece(index int, states []PieceState, fn func(start, end int64, state PieceState) bool) {
+	first, last, ok := m.Pieces(index)
+	if !ok {
+		return
+	}
+	file, _ := m.file(index)
+	for piece := first; piece <= last && piece < len(states); piece++ {
+		start := max(int64(piece)*m.PieceSize, file.offset)
+		end := min(int64(piece+1)*m.PieceSize, file.offset+file.size)
+		if !fn(start, end, states[piece]) {
+			return
+		}
+	}
+}
+
+// file returns the file with the given index
+func (m *PieceMap) file(index int) (pieceMapFile, bool) {
+	i, ok := m.byIndex[index]
+	if !ok {
+		return pieceMapFile{}, false
+	}
+	return m.files[i], true
+}
@@ -0,0 +1,86 @@
+package qbittorrent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testPieceMap() *PieceMap {
+	return NewPieceMap([]TorrentFile{
+		// out of order to check that files are sorted by index
+		{Index: 1, Name: "b.nfo", Size: 3, PieceRange: []int{2, 2}},
+		{Index: 0, Name: "a.mkv", Size: 25, PieceRange: []int{0, 2}},
+		// after a pad file aligning it to piece 3
+		{Index: 2, Name: "c.mkv", Size: 12, PieceRange: []int{3, 4}},
+		{Index: 3, Name: "empty", Size: 0, PieceRange: []int{4, 4}},
+	}, 10)
+}
+
+func TestPieceMap_Pieces(t *testing.T) {
+	m := testPieceMap()
+	tests := []struct {
+		index       int
+		offset      int64
+		first, last int
+		ok          bool
+	}{
+		{0, 0, 0, 2, true},
+		{1, 25, 2, 2, true},
+		{2, 30, 3, 4, true},
+		{3, 42, 0, 0, false},
+	}
+	for _, tt := range tests {
+		offset, _ := m.Offset(tt.index)
+		first, last, ok := m.Pieces(tt.index)
+		if offset != tt.offset || first != tt.first || last != tt.last || ok != tt.ok {
+			t.Errorf("file %d: expected offset %d, pieces %d-%d %v, got %d, %d-%d %v", tt.index, tt.offset, tt.first, tt.last, tt.ok, offset, first, last, ok)
+		}
+	}
+	if _, ok := m.Offset(9); ok {
+		t.Error("expected an unknown file not to be found")
+	}
+}
+
+func TestPieceMap_Files(t *testing.T) {
+	m := testPieceMap()
+	tests := map[int][]int{
+		0:  {0},
+		2:  {0, 1},
+		3:  {2},
+		4:  {2},
+		5:  nil,
+		-1: nil,
+	}
+	for piece, expected := range tests {
+		if files := m.Files(piece); !reflect.DeepEqual(files, expected) {
+			t.Errorf("piece %d: expected files %v, got %v", piece, expected, files)
+		}
+	}
+}
+
+func TestPieceMap_Frontier(t *testing.T) {
+	m := testPieceMap()
+	states := []PieceState{PieceDownloaded, PieceDownloading, PieceDownloaded, PieceDownloaded, PieceMissing}
+	tests := []struct {
+		index               int
+		frontier, completed int64
+	}{
+		{0, 10, 15},
+		{1, 3, 3},
+		{2, 10, 10},
+		{3, 0, 0},
+	}
+	for _, tt := range tests {
+		if frontier := m.Frontier(tt.index, states); frontier != tt.frontier {
+			t.Errorf("file %d: expected frontier %d, got %d", tt.index, tt.frontier, frontier)
+		}
+		if completed := m.Completed(tt.index, states); completed != tt.completed {
+			t.Errorf("file %d: expected %d completed, got %d", tt.index, tt.completed, completed)
+		}
+	}
+
+	// pieces beyond the states, e.g. of a torrent without metadata, are missing
+	if frontier := m.Frontier(2, states[:3]); frontier != 0 {
+		t.Errorf("expected no frontier without states, got %d", frontier)
+	}
+}
@@ -0,0 +1,183 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TransferInfo is the global transfer state from /api/v2/transfer/info
+type TransferInfo struct {
+	DLInfoSpeed      int64            `json:"dl_info_speed"`
+	DLInfoData       int64            `json:"dl_info_data"`
+	UpInfoSpeed      int64            `json:"up_info_speed"`
+	UpInfoData       int64            `json:"up_info_data"`
+	DLRateLimit      int64            `json:"dl_rate_limit"`
+	UpRateLimit      int64            `json:"up_rate_limit"`
+	DHTNodes         int              `json:"dht_nodes"`
+	ConnectionStatus ConnectionStatus `json:"connection_status"`
+}
+
+// TransferInfoCtx retrieves the global transfer state
+func (c *Client) TransferInfoCtx(ctx context.Context) (*TransferInfo, error) {
+	respData, err := c.doGetCtx(ctx, "/api/v2/transfer/info", nil)
+	if err != nil {
+		return nil, fmt.Errorf("TransferInfo error: %w", err)
+	}
+
+	var info TransferInfo
+	if err := c.decodeJSON("/api/v2/transfer/info", respData, &info); err != nil {
+		return nil, fmt.Errorf("failed to decode transfer info response: %w", err)
+	}
+	return &info, nil
+}
+
+// GetListenPortCtx returns the port qBittorrent listens on for incoming connections
+func (c *Client) GetListenPortCtx(ctx context.Context) (int, error) {
+	prefs, err := c.AppPreferencesCtx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return prefs.ListenPort, nil
+}
+
+// SetListenPortCtx sets the port qBittorrent listens on and disables random
+// ports, which would replace it on the next start
+func (c *Client) SetListenPortCtx(ctx context.Context, port int) error {
+	if !validPort(port) {
+		return fmt.Errorf("SetListenPort error: port %d out of range", port)
+	}
+	return c.AppSetPreferencesCtx(ctx, map[string]interface{}{
+		"listen_port": port,
+		"random_port": false,
+	})
+}
+
+// ErrPortNotConnectable is returned by PortSync when qBittorrent doesn't
+// report being connected on the new port before the verification timeout
+var ErrPortNotConnectable = errors.New("listen port not connectable")
+
+// DefaultPortVerifyTimeout is how long PortSync waits for qBittorrent to
+// report being connected after changing the port
+const DefaultPortVerifyTimeout = 2 * time.Minute
+
+// PortSync keeps the listen port of qBittorrent in line with a port assigned
+// elsewhere, such as the forwarded port of a VPN. The port is only changed
+// when it differs, and the change is verified by waiting for the connection
+// status of the transfer info to become connected, which requires incoming
+// connections on the port. A PortSync is safe for concurrent use.
+type PortSync struct {
+	client *Client
+	// VerifyTimeout bounds the wait for the connected status; zero skips verification
+	VerifyTimeout time.Duration
+
+	verifyInterval time.Duration
+	mu             sync.Mutex
+	port           int // the port last seen or set, zero until known
+}
+
+// NewPortSync returns a PortSync for c
+func NewPortSync(c *Client) *PortSync {
+	return &PortSync{client: c, VerifyTimeout: DefaultPortVerifyTimeout, verifyInterval: time.Second}
+}
+
+// SetPortCtx sets the listen port to port unless it already is, and reports
+// whether it was changed. If the port was changed but qBittorrent doesn't
+// become connected, the error wraps ErrPortNotConnectable.
+func (s *PortSync) SetPortCtx(ctx context.Context, port int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.port == 0 {
+		current, err := s.client.GetListenPortCtx(ctx)
+		if err != nil {
+			return false, err
+		}
+		s.port = current
+	}
+	if s.port == port {
+		return false, nil
+	}
+
+	if err := s.client.SetListenPortCtx(ctx, port); err != nil {
+		// The port is unknown again, re-read it on the next call
+		s.port = 0
+		return false, err
+	}
+	s.client.log().Info("changed listen port", "from", s.port, "to", port)
+	s.port = port
+	return true, s.verify(ctx, port)
+}
+
+// verify waits for qBittorrent to report being connected
+func (s *PortSync) verify(ctx context.Context, port int) error {
+	if s.VerifyTimeout <= 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.VerifyTimeout)
+	defer cancel()
+	ticker := s.client.Clock().NewTicker(s.verifyInterval)
+	defer ticker.Stop()
+
+	var status ConnectionStatus
+	for {
+		info, err := s.client.TransferInfoCtx(ctx)
+		if err == nil {
+			if status = info.ConnectionStatus; status == ConnectionConnected {
+				return nil
+			}
+		} else if ctx.Err() == nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("port %d: %w, connection status %q", port, ErrPortNotConnectable, status)
+		case <-ticker.C():
+		}
+	}
+}
+
+// Run reads the port from source every interval and applies it until ctx is
+// done. Failures are logged and retried on the next tick.
+func (s *PortSync) Run(ctx context.Context, interval time.Duration, source func(context.Context) (int, error)) error {
+	ticker := s.client.Clock().NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		port, err := source(ctx)
+		if err == nil {
+			_, err = s.SetPortCtx(ctx, port)
+		}
+		if err != nil && ctx.Err() == nil {
+			s.client.log().Warn("failed to sync listen port", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		}
+	}
+}
+
+// PortFromFile returns a port source for PortSync.Run reading the port from
+// a file, such as the forwarded_port file written by VPN clients
+func PortFromFile(path string) func(context.Context) (int, error) {
+	return func(context.Context) (int, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return 0, err
+		}
+		port, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil || !validPort(port) {
+			return 0, fmt.Errorf("%s: invalid port %q", path, strings.TrimSpace(string(data)))
+		}
+		return port, nil
+	}
+}
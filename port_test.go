@@ -0,0 +1,94 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newPortServer simulates a server that becomes connected once the port is
+// set to connectablePort
+func newPortServer(t *testing.T, port, connectablePort int, sets *[]string) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/api/v2/app/preferences":
+			w.Write([]byte(`{"listen_port":` + strconv.Itoa(port) + `}`))
+		case "/api/v2/app/setPreferences":
+			r.ParseForm()
+			*sets = append(*sets, r.FormValue("json"))
+			port = 0
+			if r.FormValue("json") == `{"listen_port":`+strconv.Itoa(connectablePort)+`,"random_port":false}` {
+				port = connectablePort
+			}
+		case "/api/v2/transfer/info":
+			status := "firewalled"
+			if port == connectablePort {
+				status = "connected"
+			}
+			w.Write([]byte(`{"connection_status":"` + status + `"}`))
+		}
+	}))
+}
+
+func TestPortSync(t *testing.T) {
+	var sets []string
+	mockServer := newPortServer(t, 6881, 51413, &sets)
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+	ps := NewPortSync(client)
+	ps.verifyInterval = time.Millisecond
+
+	changed, err := ps.SetPortCtx(context.Background(), 6881)
+	if err != nil || changed {
+		t.Fatalf("expected the current port to be left alone, got %v and %v", changed, err)
+	}
+	changed, err = ps.SetPortCtx(context.Background(), 51413)
+	if err != nil || !changed {
+		t.Fatalf("expected the port to change, got %v and %v", changed, err)
+	}
+	if changed, _ := ps.SetPortCtx(context.Background(), 51413); changed || len(sets) != 1 {
+		t.Errorf("expected a single update, got %v", sets)
+	}
+
+	ps.VerifyTimeout = 20 * time.Millisecond
+	changed, err = ps.SetPortCtx(context.Background(), 40000)
+	if !changed || !errors.Is(err, ErrPortNotConnectable) {
+		t.Errorf("expected ErrPortNotConnectable, got %v and %v", changed, err)
+	}
+}
+
+func TestPortFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "forwarded_port")
+	source := PortFromFile(path)
+	if _, err := source(context.Background()); err == nil {
+		t.Errorf("expected error for a missing file, got none")
+	}
+
+	os.WriteFile(path, []byte("51413\n"), 0o644)
+	if port, err := source(context.Background()); err != nil || port != 51413 {
+		t.Errorf("expected 51413, got %d and %v", port, err)
+	}
+	os.WriteFile(path, []byte("99999"), 0o644)
+	if _, err := source(context.Background()); err == nil {
+		t.Errorf("expected error for an invalid port, got none")
+	}
+}
+
+func TestSetListenPortCtx_Invalid(t *testing.T) {
+	client := &Client{baseURL: "http://localhost", bypassAuth: true}
+	if err := client.SetListenPortCtx(context.Background(), 0); err == nil {
+		t.Errorf("expected error, got none")
+	}
+}
@@ -0,0 +1,312 @@
+package qbittorrent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Preferences holds the application preferences from /api/v2/app/preferences.
+// Only commonly used settings are mapped. They are grouped by the tabs of the
+// options dialog; the groups are embedded, so the JSON document stays flat and
+// fields can be accessed directly, e.g. prefs.ListenPort.
+type Preferences struct {
+	Locale string `json:"locale"`
+	DownloadsPreferences
+	ConnectionPreferences
+	ProxyPreferences
+	SpeedPreferences
+	SchedulerPreferences
+	BitTorrentPreferences
+	WebUIPreferences
+	RSSPreferences
+}
+
+// DownloadsPreferences are where and how torrents are saved
+type DownloadsPreferences struct {
+	SavePath        string `json:"save_path"`
+	TempPathEnabled bool   `json:"temp_path_enabled"`
+	TempPath        string `json:"temp_path"`
+	AutoTMMEnabled  bool   `json:"auto_tmm_enabled"`
+}
+
+// ConnectionPreferences are the listening port and IP filtering
+type ConnectionPreferences struct {
+	ListenPort       int    `json:"listen_port"`
+	UPnP             bool   `json:"upnp"`
+	RandomPort       bool   `json:"random_port"`
+	IPFilterEnabled  bool   `json:"ip_filter_enabled"`
+	IPFilterPath     string `json:"ip_filter_path"`
+	IPFilterTrackers bool   `json:"ip_filter_trackers"`
+	BannedIPs        string `json:"banned_IPs"`
+}
+
+// ProxyPreferences are the proxy qBittorrent connects through
+type ProxyPreferences struct {
+	ProxyType        ProxyType `json:"proxy_type"`
+	ProxyIP          string    `json:"proxy_ip"`
+	ProxyPort        int       `json:"proxy_port"`
+	ProxyAuthEnabled bool      `json:"proxy_auth_enabled"`
+	ProxyUsername    string    `json:"proxy_username"`
+	ProxyPassword    string    `json:"proxy_password"`
+}
+
+// SpeedPreferences are the global and alternative rate limits in bytes/s,
+// zero for unlimited
+type SpeedPreferences struct {
+	DLLimit    int64 `json:"dl_limit"`
+	UpLimit    int64 `json:"up_limit"`
+	AltDLLimit int64 `json:"alt_dl_limit"`
+	AltUpLimit int64 `json:"alt_up_limit"`
+}
+
+// SchedulerPreferences are when the alternative rate limits apply
+type SchedulerPreferences struct {
+	SchedulerEnabled bool `json:"scheduler_enabled"`
+	ScheduleFromHour int  `json:"schedule_from_hour"`
+	ScheduleFromMin  int  `json:"schedule_from_min"`
+	ScheduleToHour   int  `json:"schedule_to_hour"`
+	ScheduleToMin    int  `json:"schedule_to_min"`
+	SchedulerDays    int  `json:"scheduler_days"`
+}
+
+// BitTorrentPreferences are the protocol, queueing and seeding limit settings
+type BitTorrentPreferences struct {
+	DHT                   bool    `json:"dht"`
+	PeX                   bool    `json:"pex"`
+	LSD                   bool    `json:"lsd"`
+	Encryption            int     `json:"encryption"`
+	AnonymousMode         bool    `json:"anonymous_mode"`
+	AddTrackersEnabled    bool    `json:"add_trackers_enabled"`
+	AddTrackers           string  `json:"add_trackers"`
+	QueueingEnabled       bool    `json:"queueing_enabled"`
+	MaxActiveDownloads    int     `json:"max_active_downloads"`
+	MaxActiveUploads      int     `json:"max_active_uploads"`
+	MaxActiveTorrents     int     `json:"max_active_torrents"`
+	MaxRatioEnabled       bool    `json:"max_ratio_enabled"`
+	MaxRatio              float64 `json:"max_ratio"`
+	MaxRatioAct           int     `json:"max_ratio_act"`
+	MaxSeedingTimeEnabled bool    `json:"max_seeding_time_enabled"`
+	MaxSeedingTime        int     `json:"max_seeding_time"`
+}
+
+// WebUIPreferences are the WebUI's address and authentication
+type WebUIPreferences struct {
+	WebUIAddress                     string `json:"web_ui_address"`
+	WebUIPort                        int    `json:"web_ui_port"`
+	WebUIUsername                    string `json:"web_ui_username"`
+	UseHTTPS                         bool   `json:"use_https"`
+	BypassLocalAuth                  bool   `json:"bypass_local_auth"`
+	BypassAuthSubnetWhitelistEnabled bool   `json:"bypass_auth_subnet_whitelist_enabled"`
+	BypassAuthSubnetWhitelist        string `json:"bypass_auth_subnet_whitelist"`
+}
+
+// RSSPreferences are the RSS reader settings
+type RSSPreferences struct {
+	RSSRefreshInterval        int  `json:"rss_refresh_interval"` // minutes
+	RSSProcessingEnabled      bool `json:"rss_processing_enabled"`
+	RSSAutoDownloadingEnabled bool `json:"rss_auto_downloading_enabled"`
+}
+
+// Validate reports invalid settings and combinations, such as ports out of
+// range or a schedule ending before it starts, so they can be caught before
+// they are sent to the server
+func (p *Preferences) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(!p.TempPathEnabled || p.TempPath != "", "temp_path is required when temp_path_enabled is set")
+
+	// Zero lets qBittorrent pick a port
+	check(p.ListenPort >= 0 && p.ListenPort <= 65535, "listen_port %d out of range", p.ListenPort)
+	check(!p.IPFilterEnabled || p.IPFilterPath != "", "ip_filter_path is required when ip_filter_enabled is set")
+
+	if p.ProxyType != "" && p.ProxyType != ProxyNone {
+		check(p.ProxyIP != "", "proxy_ip is required for a %s proxy", p.ProxyType)
+		check(validPort(p.ProxyPort), "proxy_port %d out of range", p.ProxyPort)
+		check(!p.ProxyAuthEnabled || p.ProxyUsername != "", "proxy_username is required when proxy_auth_enabled is set")
+	}
+
+	for name, limit := range map[string]int64{"dl_limit": p.DLLimit, "up_limit": p.UpLimit, "alt_dl_limit": p.AltDLLimit, "alt_up_limit": p.AltUpLimit} {
+		check(limit >= 0, "%s %d is negative", name, limit)
+	}
+
+	if p.SchedulerEnabled {
+		// Schedules ending before they start run past midnight
+		check(timeOfDay(p.ScheduleFromHour, p.ScheduleFromMin), "schedule start %02d:%02d is not a time of day", p.ScheduleFromHour, p.ScheduleFromMin)
+		check(timeOfDay(p.ScheduleToHour, p.ScheduleToMin), "schedule end %02d:%02d is not a time of day", p.ScheduleToHour, p.ScheduleToMin)
+		check(p.SchedulerDays >= 0 && p.SchedulerDays <= 9, "scheduler_days %d out of range", p.SchedulerDays)
+	}
+
+	check(p.Encryption >= 0 && p.Encryption <= 2, "encryption %d out of range", p.Encryption)
+	if p.QueueingEnabled {
+		// -1 means unlimited
+		check(p.MaxActiveDownloads >= -1, "max_active_downloads %d out of range", p.MaxActiveDownloads)
+		check(p.MaxActiveUploads >= -1, "max_active_uploads %d out of range", p.MaxActiveUploads)
+		check(p.MaxActiveTorrents >= -1, "max_active_torrents %d out of range", p.MaxActiveTorrents)
+	}
+	check(!p.MaxRatioEnabled || p.MaxRatio >= 0, "max_ratio %g is negative", p.MaxRatio)
+	check(!p.MaxSeedingTimeEnabled || p.MaxSeedingTime >= 0, "max_seeding_time %d is negative", p.MaxSeedingTime)
+
+	check(validPort(p.WebUIPort), "web_ui_port %d out of range", p.WebUIPort)
+	if p.BypassAuthSubnetWhitelistEnabled {
+		for _, subnet := range strings.FieldsFunc(p.BypassAuthSubnetWhitelist, func(r rune) bool { return r == ',' || r == '\n' }) {
+			_, _, err := net.ParseCIDR(strings.TrimSpace(subnet))
+			check(err == nil, "bypass_auth_subnet_whitelist: invalid subnet %q", subnet)
+		}
+	}
+
+	check(!p.RSSProcessingEnabled || p.RSSRefreshInterval > 0, "rss_refresh_interval %d must be positive", p.RSSRefreshInterval)
+
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+		return fmt.Errorf("invalid preferences: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validPort reports whether port is a valid TCP or UDP port
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+// timeOfDay reports whether hour and min are a time of day
+func timeOfDay(hour, min int) bool {
+	return hour >= 0 && hour <= 23 && min >= 0 && min <= 59
+}
+
+// ProxyType is the kind of proxy qBittorrent connects through
+type ProxyType string
+
+const (
+	ProxyNone   ProxyType = "None"
+	ProxyHTTP   ProxyType = "HTTP"
+	ProxySOCKS5 ProxyType = "SOCKS5"
+	ProxySOCKS4 ProxyType = "SOCKS4"
+)
+
+// legacyProxyTypes maps the numeric proxy types used before qBittorrent 4.6
+var legacyProxyTypes = map[int]ProxyType{
+	-1: ProxyNone,
+	0:  ProxyNone,
+	1:  ProxyHTTP,
+	2:  ProxySOCKS5,
+	3:  ProxyHTTP,
+	4:  ProxySOCKS5,
+	5:  ProxySOCKS4,
+}
+
+// UnmarshalJSON accepts both the numeric and the string proxy type encodings
+func (p *ProxyType) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*p = ProxyType(name)
+		return nil
+	}
+	var legacy int
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return fmt.Errorf("invalid proxy type %s", data)
+	}
+	proxyType, ok := legacyProxyTypes[legacy]
+	if !ok {
+		return fmt.Errorf("unknown proxy type %d", legacy)
+	}
+	*p = proxyType
+	return nil
+}
+
+// AppPreferencesCtx retrieves the application preferences
+func (c *Client) AppPreferencesCtx(ctx context.Context) (*Preferences, error) {
+	respData, err := c.doGetCtx(ctx, "/api/v2/app/preferences", nil)
+	if err != nil {
+		return nil, fmt.Errorf("AppPreferences error: %w", err)
+	}
+
+	var prefs Preferences
+	if err := c.decodeJSON("/api/v2/app/preferences", respData, &prefs); err != nil {
+		return nil, fmt.Errorf("failed to decode preferences response: %w", err)
+	}
+
+	return &prefs, nil
+}
+
+// AppSetPreferencesCtx changes the given preferences, keyed by their JSON
+// names. Preferences not present in prefs are left unchanged.
+func (c *Client) AppSetPreferencesCtx(ctx context.Context, prefs map[string]interface{}) error {
+	encoded, err := json.Marshal(prefs)
+	if err != nil {
+		return fmt.Errorf("AppSetPreferences error: %w", err)
+	}
+	data := url.Values{}
+	data.Set("json", string(encoded))
+
+	if _, err := c.doPostValuesCtx(ctx, "/api/v2/app/setPreferences", data); err != nil {
+		return fmt.Errorf("AppSetPreferences error: %w", err)
+	}
+	return nil
+}
+
+// AppUpdatePreferencesCtx retrieves the preferences, applies update, validates
+// the result and sends only the settings that changed. Invalid preferences are
+// not sent.
+func (c *Client) AppUpdatePreferencesCtx(ctx context.Context, update func(*Preferences)) error {
+	current, err := c.AppPreferencesCtx(ctx)
+	if err != nil {
+		return err
+	}
+	updated := *current
+	update(&updated)
+	if err := updated.Validate(); err != nil {
+		return err
+	}
+
+	changes, err := preferenceChanges(current, &updated)
+	if err != nil {
+		return fmt.Errorf("AppUpdatePreferences error: %w", err)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return c.AppSetPreferencesCtx(ctx, changes)
+}
+
+// preferenceChanges returns the settings of updated that differ from
+// current, keyed by their JSON names
+func preferenceChanges(current, updated *Preferences) (map[string]interface{}, error) {
+	before, err := preferenceMap(current)
+	if err != nil {
+		return nil, err
+	}
+	after, err := preferenceMap(updated)
+	if err != nil {
+		return nil, err
+	}
+	changes := make(map[string]interface{})
+	for key, value := range after {
+		if !reflect.DeepEqual(before[key], value) {
+			changes[key] = value
+		}
+	}
+	return changes, nil
+}
+
+// preferenceMap returns the JSON document of prefs as a map
+func preferenceMap(prefs *Preferences) (map[string]interface{}, error) {
+	data, err := json.Marshal(prefs)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
@@ -0,0 +1,135 @@
+package qbittorrent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func validPreferences() Preferences {
+	var prefs Preferences
+	prefs.ListenPort = 6881
+	prefs.WebUIPort = 8080
+	prefs.Encryption = 1
+	prefs.ProxyType = ProxyNone
+	return prefs
+}
+
+func TestPreferences_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Preferences)
+		errs   []string
+	}{
+		{name: "valid", modify: func(*Preferences) {}},
+		{
+			name:   "ports out of range",
+			modify: func(p *Preferences) { p.ListenPort = 70000; p.WebUIPort = 0 },
+			errs:   []string{"listen_port 70000 out of range", "web_ui_port 0 out of range"},
+		},
+		{
+			name: "schedule past midnight",
+			modify: func(p *Preferences) {
+				p.SchedulerEnabled = true
+				p.ScheduleFromHour, p.ScheduleToHour, p.ScheduleToMin = 22, 6, 30
+			},
+		},
+		{
+			name: "schedule outside a day",
+			modify: func(p *Preferences) {
+				p.SchedulerEnabled = true
+				p.ScheduleFromHour, p.ScheduleToHour, p.ScheduleToMin = 24, 6, 60
+			},
+			errs: []string{"schedule end 06:60 is not a time of day", "schedule start 24:00 is not a time of day"},
+		},
+		{
+			name:   "disabled schedule isn't checked",
+			modify: func(p *Preferences) { p.ScheduleFromHour, p.ScheduleToHour = 25, 6 },
+		},
+		{
+			name: "proxy without address",
+			modify: func(p *Preferences) {
+				p.ProxyType = ProxySOCKS5
+				p.ProxyAuthEnabled = true
+			},
+			errs: []string{"proxy_ip is required", "proxy_port 0 out of range", "proxy_username is required"},
+		},
+		{
+			name: "invalid whitelist and limits",
+			modify: func(p *Preferences) {
+				p.BypassAuthSubnetWhitelistEnabled = true
+				p.BypassAuthSubnetWhitelist = "192.168.0.0/24, 10.0.0.1"
+				p.AltDLLimit = -1
+			},
+			errs: []string{`invalid subnet " 10.0.0.1"`, "alt_dl_limit -1 is negative"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefs := validPreferences()
+			tt.modify(&prefs)
+			err := prefs.Validate()
+			if len(tt.errs) == 0 {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors %v, got none", tt.errs)
+			}
+			for _, want := range tt.errs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error to contain %q, got %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPreferences_FlatJSON(t *testing.T) {
+	var prefs Preferences
+	if err := json.Unmarshal([]byte(`{"listen_port":6881,"proxy_ip":"10.0.0.1","web_ui_port":8080,"scheduler_days":2}`), &prefs); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if prefs.ListenPort != 6881 || prefs.ProxyPreferences.ProxyIP != "10.0.0.1" || prefs.WebUIPort != 8080 || prefs.SchedulerPreferences.SchedulerDays != 2 {
+		t.Errorf("unexpected preferences %+v", prefs)
+	}
+}
+
+func TestAppUpdatePreferencesCtx(t *testing.T) {
+	var sent []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/app/preferences":
+			w.Write([]byte(`{"listen_port":6881,"web_ui_port":8080,"encryption":0,"proxy_type":"None","dl_limit":0}`))
+		case "/api/v2/app/setPreferences":
+			r.ParseForm()
+			sent = append(sent, r.FormValue("json"))
+		}
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	err := client.AppUpdatePreferencesCtx(context.Background(), func(p *Preferences) {
+		p.ListenPort = 51413
+		p.DLLimit = 1 << 20
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(sent, []string{`{"dl_limit":1048576,"listen_port":51413}`}) {
+		t.Errorf("expected only the changes to be sent, got %v", sent)
+	}
+
+	err = client.AppUpdatePreferencesCtx(context.Background(), func(p *Preferences) { p.ListenPort = -1 })
+	if err == nil || len(sent) != 1 {
+		t.Errorf("expected invalid preferences not to be sent, got %v and %v", err, sent)
+	}
+}
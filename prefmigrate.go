@@ -0,0 +1,226 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PreferenceMigration describes a preference that changed its key or its
+// encoding in a qBittorrent release
+type PreferenceMigration struct {
+	Since string // first version using New, e.g. "v5.0.0"
+	Old   string // key before Since
+	New   string // key since Since, the same as Old if only the encoding changed
+
+	// Upgrade and Downgrade convert a value between the encodings. They are
+	// given values in either encoding and return values in the other one
+	// unchanged. prefs is the document being migrated, for settings that
+	// change together. Nil keeps the value.
+	Upgrade   func(value interface{}, prefs map[string]interface{}) interface{}
+	Downgrade func(value interface{}, prefs map[string]interface{}) interface{}
+}
+
+// PreferenceMigrations are the preference changes known to MigratePreferences
+var PreferenceMigrations = []PreferenceMigration{
+	{
+		Since:     "v4.3.2",
+		Old:       "create_subfolder_enabled",
+		New:       "torrent_content_layout",
+		Upgrade:   upgradeContentLayout,
+		Downgrade: downgradeContentLayout,
+	},
+	{
+		Since:     "v4.6.0",
+		Old:       "proxy_type",
+		New:       "proxy_type",
+		Upgrade:   upgradeProxyType,
+		Downgrade: downgradeProxyType,
+	},
+	{
+		Since: "v5.0.0",
+		Old:   "start_paused_enabled",
+		New:   "add_stopped_enabled",
+	},
+}
+
+// MigratePreferences returns a copy of prefs, keyed by their JSON names, with
+// the keys and values of PreferenceMigrations converted to the ones of the
+// given qBittorrent version, e.g. "v4.6.4", so a preference document written
+// for one major version can be applied to another. Given both the old and the
+// new key, the one of the version wins.
+func MigratePreferences(prefs map[string]interface{}, version string) (map[string]interface{}, error) {
+	target, err := parseAppVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	migrated := make(map[string]interface{}, len(prefs))
+	for key, value := range prefs {
+		migrated[key] = value
+	}
+
+	for _, migration := range PreferenceMigrations {
+		since, err := parseAppVersion(migration.Since)
+		if err != nil {
+			return nil, err
+		}
+		from, to, convert := migration.Old, migration.New, migration.Upgrade
+		if compareAppVersions(target, since) < 0 {
+			from, to, convert = migration.New, migration.Old, migration.Downgrade
+		}
+
+		value, ok := migrated[from]
+		if !ok {
+			continue
+		}
+		if from != to {
+			delete(migrated, from)
+			if _, ok := migrated[to]; ok {
+				continue
+			}
+		}
+		if convert != nil {
+			value = convert(value, migrated)
+		}
+		migrated[to] = value
+	}
+	return migrated, nil
+}
+
+// AppApplyPreferencesCtx changes the given preferences like
+// AppSetPreferencesCtx after migrating them to the version of the server, see
+// MigratePreferences
+func (c *Client) AppApplyPreferencesCtx(ctx context.Context, prefs map[string]interface{}) error {
+	version, err := c.AppVersionCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("AppApplyPreferences error: %w", err)
+	}
+	migrated, err := MigratePreferences(prefs, version)
+	if err != nil {
+		return fmt.Errorf("AppApplyPreferences error: %w", err)
+	}
+	return c.AppSetPreferencesCtx(ctx, migrated)
+}
+
+// appVersion is a parsed qBittorrent version
+type appVersion [3]int
+
+// parseAppVersion parses versions like "v4.6.4", "4.3.2" and "v5.0.0beta1".
+// Suffixes are ignored, so pre-releases count as their release.
+func parseAppVersion(version string) (appVersion, error) {
+	var parsed appVersion
+	parts := strings.SplitN(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".", 3)
+	for i, part := range parts {
+		digits := strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' })
+		if digits < 0 {
+			digits = len(part)
+		}
+		n, err := strconv.Atoi(part[:digits])
+		if err != nil || (digits < len(part) && i < len(parts)-1) {
+			return parsed, fmt.Errorf("invalid version %q", version)
+		}
+		parsed[i] = n
+	}
+	return parsed, nil
+}
+
+// compareAppVersions returns -1, 0 or 1 as a is older than, the same as, or
+// newer than b
+func compareAppVersions(a, b appVersion) int {
+	for i := range a {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// upgradeContentLayout converts create_subfolder_enabled to
+// torrent_content_layout
+func upgradeContentLayout(value interface{}, _ map[string]interface{}) interface{} {
+	if enabled, ok := value.(bool); ok {
+		if enabled {
+			return "Original"
+		}
+		return "NoSubfolder"
+	}
+	return value
+}
+
+// downgradeContentLayout converts torrent_content_layout to
+// create_subfolder_enabled. "Subfolder" has no equivalent and is approximated
+// by creating subfolders.
+func downgradeContentLayout(value interface{}, _ map[string]interface{}) interface{} {
+	if layout, ok := value.(string); ok {
+		return layout != "NoSubfolder"
+	}
+	return value
+}
+
+// upgradeProxyType converts a numeric proxy type to its name. The types with
+// authentication enable proxy_auth_enabled unless it is given.
+func upgradeProxyType(value interface{}, prefs map[string]interface{}) interface{} {
+	legacy, ok := jsonInt(value)
+	if !ok {
+		return value
+	}
+	proxyType, ok := legacyProxyTypes[legacy]
+	if !ok {
+		return value
+	}
+	if _, ok := prefs["proxy_auth_enabled"]; !ok && (legacy == 3 || legacy == 4) {
+		prefs["proxy_auth_enabled"] = true
+	}
+	return string(proxyType)
+}
+
+// downgradeProxyType converts a proxy type name to its number, the one with
+// authentication if proxy_auth_enabled is set
+func downgradeProxyType(value interface{}, prefs map[string]interface{}) interface{} {
+	auth, _ := prefs["proxy_auth_enabled"].(bool)
+	var proxyType ProxyType
+	switch v := value.(type) {
+	case string:
+		proxyType = ProxyType(v)
+	case ProxyType:
+		proxyType = v
+	default:
+		return value
+	}
+	switch proxyType {
+	case ProxyNone:
+		return -1
+	case ProxyHTTP:
+		if auth {
+			return 3
+		}
+		return 1
+	case ProxySOCKS5:
+		if auth {
+			return 4
+		}
+		return 2
+	case ProxySOCKS4:
+		return 5
+	}
+	return value
+}
+
+// jsonInt returns a number of a decoded or literal JSON document as an int
+func jsonInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), true
+		}
+	}
+	return 0, false
+}
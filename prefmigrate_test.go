@@ -0,0 +1,154 @@
+package qbittorrent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMigratePreferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		prefs    map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name:     "renamed key upgraded",
+			version:  "v5.0.1",
+			prefs:    map[string]interface{}{"start_paused_enabled": true, "dht": false},
+			expected: map[string]interface{}{"add_stopped_enabled": true, "dht": false},
+		},
+		{
+			name:     "renamed key downgraded",
+			version:  "v4.6.7",
+			prefs:    map[string]interface{}{"add_stopped_enabled": true},
+			expected: map[string]interface{}{"start_paused_enabled": true},
+		},
+		{
+			name:     "current key wins",
+			version:  "v5.0.0",
+			prefs:    map[string]interface{}{"start_paused_enabled": true, "add_stopped_enabled": false},
+			expected: map[string]interface{}{"add_stopped_enabled": false},
+		},
+		{
+			name:     "pre-release counts as its release",
+			version:  "v5.0.0beta1",
+			prefs:    map[string]interface{}{"start_paused_enabled": true},
+			expected: map[string]interface{}{"add_stopped_enabled": true},
+		},
+		{
+			name:     "numeric proxy type upgraded",
+			version:  "v4.6.0",
+			prefs:    map[string]interface{}{"proxy_type": float64(2)},
+			expected: map[string]interface{}{"proxy_type": "SOCKS5"},
+		},
+		{
+			name:     "proxy type downgraded",
+			version:  "v4.5.5",
+			prefs:    map[string]interface{}{"proxy_type": "HTTP"},
+			expected: map[string]interface{}{"proxy_type": 1},
+		},
+		{
+			name:     "proxy type with authentication upgraded",
+			version:  "v5.0.0",
+			prefs:    map[string]interface{}{"proxy_type": float64(4), "proxy_username": "user"},
+			expected: map[string]interface{}{"proxy_type": "SOCKS5", "proxy_auth_enabled": true, "proxy_username": "user"},
+		},
+		{
+			name:     "proxy authentication given",
+			version:  "v5.0.0",
+			prefs:    map[string]interface{}{"proxy_type": 3, "proxy_auth_enabled": false},
+			expected: map[string]interface{}{"proxy_type": "HTTP", "proxy_auth_enabled": false},
+		},
+		{
+			name:     "proxy type with authentication downgraded",
+			version:  "v4.5.5",
+			prefs:    map[string]interface{}{"proxy_type": "SOCKS5", "proxy_auth_enabled": true},
+			expected: map[string]interface{}{"proxy_type": 4, "proxy_auth_enabled": true},
+		},
+		{
+			name:     "HTTP proxy with authentication downgraded",
+			version:  "v4.5.5",
+			prefs:    map[string]interface{}{"proxy_type": ProxyHTTP, "proxy_auth_enabled": true},
+			expected: map[string]interface{}{"proxy_type": 3, "proxy_auth_enabled": true},
+		},
+		{
+			name:     "proxy type kept",
+			version:  "v4.5.5",
+			prefs:    map[string]interface{}{"proxy_type": 5},
+			expected: map[string]interface{}{"proxy_type": 5},
+		},
+		{
+			name:     "content layout upgraded",
+			version:  "v4.4.0",
+			prefs:    map[string]interface{}{"create_subfolder_enabled": false},
+			expected: map[string]interface{}{"torrent_content_layout": "NoSubfolder"},
+		},
+		{
+			name:     "content layout downgraded",
+			version:  "v4.3.1",
+			prefs:    map[string]interface{}{"torrent_content_layout": "Original"},
+			expected: map[string]interface{}{"create_subfolder_enabled": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrated, err := MigratePreferences(tt.prefs, tt.version)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(migrated, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, migrated)
+			}
+		})
+	}
+}
+
+func TestMigratePreferences_DoesNotModify(t *testing.T) {
+	prefs := map[string]interface{}{"start_paused_enabled": true}
+	if _, err := MigratePreferences(prefs, "v5.1.0"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(prefs, map[string]interface{}{"start_paused_enabled": true}) {
+		t.Errorf("expected the preferences to be unchanged, got %v", prefs)
+	}
+}
+
+func TestMigratePreferences_InvalidVersion(t *testing.T) {
+	for _, version := range []string{"", "unknown", "v4.x.1"} {
+		if _, err := MigratePreferences(nil, version); err == nil {
+			t.Errorf("expected an error for version %q", version)
+		}
+	}
+}
+
+func TestAppApplyPreferencesCtx(t *testing.T) {
+	var sent map[string]interface{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/app/version":
+			w.Write([]byte("v4.5.5\n"))
+		case "/api/v2/app/setPreferences":
+			r.ParseForm()
+			json.Unmarshal([]byte(r.FormValue("json")), &sent)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	err := client.AppApplyPreferencesCtx(context.Background(), map[string]interface{}{"add_stopped_enabled": true, "proxy_type": "None"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := map[string]interface{}{"start_paused_enabled": true, "proxy_type": float64(-1)}
+	if !reflect.DeepEqual(sent, expected) {
+		t.Errorf("expected %v, got %v", expected, sent)
+	}
+}
@@ -0,0 +1,87 @@
+package qbtest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cehbz/qbittorrent"
+)
+
+// FakeClock is a qbittorrent.Clock whose time only moves when Advance is
+// called, so tests of time-based components such as a Watcher or BanManager
+// don't depend on the wall clock. It is safe for concurrent use.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+// NewFakeClock returns a FakeClock starting at now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now implements the qbittorrent.Clock interface
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker implements the qbittorrent.Clock interface. Like time.NewTicker
+// it panics if d is not positive.
+func (c *FakeClock) NewTicker(d time.Duration) qbittorrent.Ticker {
+	if d <= 0 {
+		panic("qbtest: non-positive interval for NewTicker")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{clock: c, interval: d, next: c.now.Add(d), ch: make(chan time.Time, 1)}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Tickers returns the number of tickers that haven't been stopped, e.g. to
+// wait until a polling loop has started before advancing the clock
+func (c *FakeClock) Tickers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.tickers)
+}
+
+// Advance moves the clock forward by d and delivers the ticks that fell due.
+// Like a time.Ticker, a ticker whose receiver is behind drops ticks.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for _, t := range c.tickers {
+		for !t.next.After(c.now) {
+			select {
+			case t.ch <- t.next:
+			default:
+			}
+			t.next = t.next.Add(t.interval)
+		}
+	}
+}
+
+type fakeTicker struct {
+	clock    *FakeClock
+	interval time.Duration
+	next     time.Time // guarded by clock.mu
+	ch       chan time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.ch }
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	for i, other := range t.clock.tickers {
+		if other == t {
+			t.clock.tickers = append(t.clock.tickers[:i], t.clock.tickers[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package qbtest_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cehbz/qbittorrent"
+	"github.com/cehbz/qbittorrent/qbtest"
+)
+
+func TestFakeClockTicker(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := qbtest.NewFakeClock(start)
+	ticker := clock.NewTicker(time.Minute)
+
+	clock.Advance(59 * time.Second)
+	select {
+	case <-ticker.C():
+		t.Fatal("expected no tick before the interval")
+	default:
+	}
+
+	clock.Advance(3 * time.Minute)
+	if tick := <-ticker.C(); !tick.Equal(start.Add(time.Minute)) {
+		t.Errorf("expected the first tick at %v, got %v", start.Add(time.Minute), tick)
+	}
+	select {
+	case <-ticker.C():
+		t.Fatal("expected the ticks of a slow receiver to be dropped")
+	default:
+	}
+	if now := clock.Now(); !now.Equal(start.Add(3*time.Minute + 59*time.Second)) {
+		t.Errorf("unexpected time %v", now)
+	}
+
+	ticker.Stop()
+	if clock.Tickers() != 0 {
+		t.Errorf("expected no tickers after Stop, got %d", clock.Tickers())
+	}
+	clock.Advance(time.Hour)
+	select {
+	case <-ticker.C():
+		t.Fatal("expected no tick after Stop")
+	default:
+	}
+}
+
+func TestFakeClockWatcher(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rid, _ := strconv.Atoi(r.URL.Query().Get("rid"))
+		w.Header().Set("Content-Type", "application/json")
+		if rid == 0 {
+			fmt.Fprint(w, `{"rid":1,"full_update":true}`)
+			return
+		}
+		fmt.Fprint(w, `{"rid":2,"torrents":{"abc":{"name":"new","state":"downloading"}}}`)
+	}))
+	defer mockServer.Close()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := qbtest.NewFakeClock(start)
+	client, err := qbittorrent.NewClientWithOptions("", "", "", "",
+		qbittorrent.WithBaseURL(mockServer.URL),
+		qbittorrent.WithBypassAuth(),
+		qbittorrent.WithClock(clock),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	events := make(chan qbittorrent.Event, 1)
+	watcher := qbittorrent.NewWatcher(client)
+	watcher.OnEvent(func(ctx context.Context, event qbittorrent.Event) error {
+		events <- event
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.Run(ctx, time.Hour)
+
+	for clock.Tickers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Hour)
+	select {
+	case event := <-events:
+		if event.Type != qbittorrent.TorrentAdded || event.Hash != "abc" {
+			t.Errorf("unexpected event %+v", event)
+		}
+		if !event.At.Equal(start.Add(time.Hour)) {
+			t.Errorf("expected the event at %v, got %v", start.Add(time.Hour), event.At)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected an event after advancing the clock")
+	}
+}
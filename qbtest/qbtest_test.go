@@ -0,0 +1,82 @@
+package qbtest_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cehbz/qbittorrent"
+	"github.com/cehbz/qbittorrent/qbtest"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/auth/login":
+			http.SetCookie(w, &http.Cookie{Name: "SID", Value: "secret-session"})
+			w.Write([]byte("Ok."))
+		case "/api/v2/sync/maindata":
+			w.Header().Set("Content-Type", "application/json")
+			rid, _ := strconv.Atoi(r.URL.Query().Get("rid"))
+			fmt.Fprintf(w, `{"rid":%d,"full_update":%t}`, rid+10, rid == 0)
+		}
+	}))
+	defer mockServer.Close()
+
+	recorder := &qbtest.Recorder{}
+	client, err := qbittorrent.NewClientWithOptions("user", "hunter2", "", "",
+		qbittorrent.WithBaseURL(mockServer.URL),
+		qbittorrent.WithHTTPClient(&http.Client{Transport: recorder}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.SyncMainData(0); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.SyncMainData(1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	golden := filepath.Join(t.TempDir(), "session.json")
+	if err := recorder.Save(golden); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	saved, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Contains(string(saved), "hunter2") || strings.Contains(string(saved), "secret-session") {
+		t.Errorf("expected credentials to be redacted, got %s", saved)
+	}
+
+	replayer, err := qbtest.LoadReplayer(golden)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	client, err = qbittorrent.NewClientWithOptions("user", "pass", "", "",
+		qbittorrent.WithBaseURL("http://replay.invalid"),
+		qbittorrent.WithHTTPClient(&http.Client{Transport: replayer}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	data, err := client.SyncMainData(1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if data.Rid != 11 {
+		t.Errorf("expected rid 11, got %d", data.Rid)
+	}
+	if _, err := client.SyncMainData(1); err == nil {
+		t.Errorf("expected error replaying an interaction twice, got none")
+	}
+	if remaining := replayer.Remaining(); remaining != 1 {
+		t.Errorf("expected 1 remaining interaction, got %d", remaining)
+	}
+}
@@ -0,0 +1,160 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Priority classifies requests for the request queue, see WithRequestQueue
+type Priority int
+
+const (
+	// PriorityInteractive is for requests a user is waiting on. It is the
+	// default for requests whose context has no priority.
+	PriorityInteractive Priority = iota
+	// PriorityBatch is for bulk traffic such as mass rechecks or tagging,
+	// which only runs when no interactive request is waiting
+	PriorityBatch
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityBatch:
+		return "batch"
+	default:
+		return "unknown"
+	}
+}
+
+// priorityKey is the context key of the request priority
+type priorityKey struct{}
+
+// ContextWithPriority returns a context whose requests are queued with priority p
+func ContextWithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the priority of requests made with ctx
+func PriorityFromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityInteractive
+}
+
+// WithRequestQueue limits the client to maxConcurrent requests in flight, of
+// which at most maxBatch may be batch requests, so some capacity is always left
+// for interactive requests. Waiting interactive requests are sent before
+// waiting batch requests; each class is served in order. A request holds its
+// slot until its response body is closed. Use ContextWithPriority to mark
+// batch requests.
+func WithRequestQueue(maxConcurrent, maxBatch int) Option {
+	return func(c *Client) error {
+		if maxBatch < 1 || maxBatch >= maxConcurrent {
+			return fmt.Errorf("invalid request queue limits: %d concurrent, %d batch", maxConcurrent, maxBatch)
+		}
+		c.queue = newRequestQueue(maxConcurrent, maxBatch)
+		return nil
+	}
+}
+
+// requestQueue hands out request slots by priority
+type requestQueue struct {
+	maxConcurrent int
+	maxBatch      int
+
+	mu          sync.Mutex
+	inFlight    int
+	batchFlight int
+	waiting     [2][]chan struct{} // waiting requests by priority, closed when granted
+}
+
+func newRequestQueue(maxConcurrent, maxBatch int) *requestQueue {
+	return &requestQueue{maxConcurrent: maxConcurrent, maxBatch: maxBatch}
+}
+
+// acquire waits for a slot for a request with the priority of ctx and returns
+// the function releasing it
+func (q *requestQueue) acquire(ctx context.Context) (func(), error) {
+	if q == nil {
+		return func() {}, nil
+	}
+	p := PriorityFromContext(ctx)
+	if p != PriorityBatch {
+		p = PriorityInteractive
+	}
+	release := func() { q.release(p) }
+
+	q.mu.Lock()
+	if len(q.waiting[p]) == 0 && q.available(p) {
+		q.take(p)
+		q.mu.Unlock()
+		return release, nil
+	}
+	granted := make(chan struct{})
+	q.waiting[p] = append(q.waiting[p], granted)
+	q.mu.Unlock()
+
+	select {
+	case <-granted:
+		return release, nil
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, waiter := range q.waiting[p] {
+		if waiter == granted {
+			q.waiting[p] = append(q.waiting[p][:i], q.waiting[p][i+1:]...)
+			return nil, ctx.Err()
+		}
+	}
+	// The slot was granted while the context was done, pass it on
+	q.put(p)
+	q.grant()
+	return nil, ctx.Err()
+}
+
+// release frees the slot of a request with priority p
+func (q *requestQueue) release(p Priority) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.put(p)
+	q.grant()
+}
+
+// available reports whether a request with priority p may be sent now
+func (q *requestQueue) available(p Priority) bool {
+	if q.inFlight >= q.maxConcurrent {
+		return false
+	}
+	return p == PriorityInteractive || (q.batchFlight < q.maxBatch && len(q.waiting[PriorityInteractive]) == 0)
+}
+
+func (q *requestQueue) take(p Priority) {
+	q.inFlight++
+	if p == PriorityBatch {
+		q.batchFlight++
+	}
+}
+
+func (q *requestQueue) put(p Priority) {
+	q.inFlight--
+	if p == PriorityBatch {
+		q.batchFlight--
+	}
+}
+
+// grant hands free slots to waiting requests, interactive ones first
+func (q *requestQueue) grant() {
+	for _, p := range []Priority{PriorityInteractive, PriorityBatch} {
+		for len(q.waiting[p]) > 0 && q.available(p) {
+			q.take(p)
+			close(q.waiting[p][0])
+			q.waiting[p] = q.waiting[p][1:]
+		}
+	}
+}
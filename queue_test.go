@@ -0,0 +1,143 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRequestQueue_InteractiveFirst(t *testing.T) {
+	q := newRequestQueue(2, 1)
+	batch := ContextWithPriority(context.Background(), PriorityBatch)
+
+	releaseBatch, err := q.acquire(batch)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// The batch limit is reached, but interactive requests still get a slot
+	releaseInteractive, err := q.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []Priority
+	var wg sync.WaitGroup
+	start := func(ctx context.Context) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := q.acquire(ctx)
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+				return
+			}
+			mu.Lock()
+			order = append(order, PriorityFromContext(ctx))
+			mu.Unlock()
+			release()
+		}()
+	}
+	waiting := func(p Priority, n int) {
+		t.Helper()
+		for deadline := time.Now().Add(time.Second); ; {
+			q.mu.Lock()
+			queued := len(q.waiting[p])
+			q.mu.Unlock()
+			if queued == n {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected %d waiting %s requests, got %d", n, p, queued)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	start(batch)
+	waiting(PriorityBatch, 1)
+	start(context.Background())
+	waiting(PriorityInteractive, 1)
+
+	// The freed slot goes to the interactive request, and only once that is
+	// done to the batch request
+	releaseBatch()
+	wg.Wait()
+	releaseInteractive()
+	if len(order) != 2 || order[0] != PriorityInteractive || order[1] != PriorityBatch {
+		t.Errorf("expected the interactive request first, got %v", order)
+	}
+	if q.inFlight != 0 || q.batchFlight != 0 {
+		t.Errorf("expected all slots to be released, got %d in flight", q.inFlight)
+	}
+}
+
+func TestRequestQueue_Canceled(t *testing.T) {
+	q := newRequestQueue(1, 0)
+	release, _ := q.acquire(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := q.acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	release()
+	if len(q.waiting[PriorityInteractive]) != 0 || q.inFlight != 0 {
+		t.Errorf("expected the canceled request to leave the queue, got %d waiting and %d in flight", len(q.waiting[PriorityInteractive]), q.inFlight)
+	}
+}
+
+func TestWithRequestQueue(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithBypassAuth(),
+		WithRequestQueue(3, 1),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 12; i++ {
+		ctx := context.Background()
+		if i%2 == 0 {
+			ctx = ContextWithPriority(ctx, PriorityBatch)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.TorrentsInfoCtx(ctx); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if maxInFlight > 3 {
+		t.Errorf("expected at most 3 requests in flight, got %d", maxInFlight)
+	}
+
+	if _, err := NewClientWithOptions("", "", "localhost", "8080", WithBypassAuth(), WithRequestQueue(2, 2)); err == nil {
+		t.Errorf("expected error when batch requests may take every slot, got none")
+	}
+}
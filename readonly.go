@@ -0,0 +1,56 @@
+package qbittorrent
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrReadOnly is returned for mutating calls on a client created with WithReadOnly
+var ErrReadOnly = errors.New("client is read-only")
+
+// readOnlyEndpoints are the endpoints a read-only client may call. Anything
+// not listed here, including endpoints added in the future, is refused.
+var readOnlyEndpoints = map[string]bool{
+	"/api/v2/auth/login":               true,
+	"/api/v2/app/version":              true,
+	"/api/v2/app/webapiVersion":        true,
+	"/api/v2/app/buildInfo":            true,
+	"/api/v2/app/preferences":          true,
+	"/api/v2/app/defaultSavePath":      true,
+	"/api/v2/log/main":                 true,
+	"/api/v2/log/peers":                true,
+	"/api/v2/sync/maindata":            true,
+	"/api/v2/sync/torrentPeers":        true,
+	"/api/v2/transfer/info":            true,
+	"/api/v2/transfer/speedLimitsMode": true,
+	"/api/v2/transfer/downloadLimit":   true,
+	"/api/v2/transfer/uploadLimit":     true,
+	"/api/v2/torrents/info":            true,
+	"/api/v2/torrents/count":           true,
+	"/api/v2/torrents/properties":      true,
+	"/api/v2/torrents/trackers":        true,
+	"/api/v2/torrents/webseeds":        true,
+	"/api/v2/torrents/files":           true,
+	"/api/v2/torrents/pieceStates":     true,
+	"/api/v2/torrents/pieceHashes":     true,
+	"/api/v2/torrents/downloadLimit":   true,
+	"/api/v2/torrents/uploadLimit":     true,
+	"/api/v2/torrents/categories":      true,
+	"/api/v2/torrents/tags":            true,
+	"/api/v2/torrents/export":          true,
+	"/api/v2/torrents/file":            true,
+	"/api/v2/rss/items":                true,
+	"/api/v2/rss/rules":                true,
+	"/api/v2/rss/matchingArticles":     true,
+	"/api/v2/search/status":            true,
+	"/api/v2/search/results":           true,
+	"/api/v2/search/plugins":           true,
+}
+
+// checkReadOnly refuses endpoints that are not known to be read-only
+func (c *Client) checkReadOnly(endpoint string) error {
+	if c.readOnly && !readOnlyEndpoints[endpoint] {
+		return fmt.Errorf("%s: %w", endpoint, ErrReadOnly)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithReadOnly(t *testing.T) {
+	var requests []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClientWithOptions("user", "pass", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithReadOnly(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := client.TorrentsInfoCtx(ctx); err != nil {
+		t.Errorf("TorrentsInfoCtx: expected no error, got %v", err)
+	}
+	if _, err := client.TorrentsGetAllTagsCtx(ctx); err != nil {
+		t.Errorf("TorrentsGetAllTagsCtx: expected no error, got %v", err)
+	}
+
+	mutating := map[string]func() error{
+		"add":      func() error { return client.TorrentsAddCtx(ctx, "test.torrent", []byte("data")) },
+		"delete":   func() error { return client.TorrentsDeleteCtx(ctx, "somehash") },
+		"addTags":  func() error { return client.TorrentsAddTagsCtx(ctx, "somehash", "tag1") },
+		"force":    func() error { return client.SetForceStartCtx(ctx, "somehash", true) },
+		"shutdown": func() error { return client.AppShutdownCtx(ctx) },
+	}
+	for name, call := range mutating {
+		if err := call(); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s: expected ErrReadOnly, got %v", name, err)
+		}
+	}
+
+	expected := []string{"/api/v2/auth/login", "/api/v2/torrents/info", "/api/v2/torrents/tags"}
+	if len(requests) != len(expected) {
+		t.Fatalf("expected requests %v, got %v", expected, requests)
+	}
+	for i := range expected {
+		if requests[i] != expected[i] {
+			t.Errorf("expected request %s, got %s", expected[i], requests[i])
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrReauthBudgetExhausted is returned when a request is rejected for an
+// expired session after the client has used up the re-authentications its
+// ReauthPolicy allows within the window
+var ErrReauthBudgetExhausted = errors.New("re-authentication budget exhausted")
+
+// ErrNotRetried is returned for POST requests rejected for an expired session
+// when the ReauthPolicy doesn't retry them. The session has been renewed, so the
+// caller may re-send the request if it is safe to do so.
+var ErrNotRetried = errors.New("session expired, request not retried")
+
+// ReauthPolicy controls how requests rejected with 403 Forbidden, which
+// qBittorrent returns for expired sessions, or 401 Unauthorized, which some
+// reverse proxies return instead, are retried after logging in again
+type ReauthPolicy struct {
+	// Attempts is the number of times a request is re-authenticated and
+	// re-sent before its 401 or 403 response is returned
+	Attempts int
+	// Backoff is the wait before the first re-authentication of a request,
+	// doubled for every further attempt
+	Backoff time.Duration
+	// MaxReauths limits the logins across all requests within Window, so a
+	// misconfigured client doesn't hammer the server (which bans IPs after
+	// repeated failures). Zero means unlimited.
+	MaxReauths int
+	Window     time.Duration
+	// RetryPOST re-sends POST requests after re-authenticating. Otherwise they
+	// fail with ErrNotRetried.
+	RetryPOST bool
+	// MaxSessionAge, if set, logs in again before sending a request on a
+	// session older than this, e.g. shorter than the WebUI session timeout,
+	// so that requests aren't rejected in the first place
+	MaxSessionAge time.Duration
+}
+
+// DefaultReauthPolicy re-authenticates and retries every request once
+var DefaultReauthPolicy = ReauthPolicy{Attempts: 1, RetryPOST: true}
+
+// WithReauthPolicy sets how requests rejected for an expired session are
+// retried, and how old sessions may get
+func WithReauthPolicy(policy ReauthPolicy) Option {
+	return func(c *Client) error {
+		if policy.Attempts < 0 || policy.Backoff < 0 || policy.MaxReauths < 0 || policy.MaxSessionAge < 0 {
+			return fmt.Errorf("invalid re-authentication policy %+v", policy)
+		}
+		if policy.MaxReauths > 0 && policy.Window <= 0 {
+			return errors.New("re-authentication budget requires a window")
+		}
+		c.reauth = &policy
+		return nil
+	}
+}
+
+// reauthPolicy returns the client's policy, or the default if none was set
+func (c *Client) reauthPolicy() ReauthPolicy {
+	if c.reauth == nil {
+		return DefaultReauthPolicy
+	}
+	return *c.reauth
+}
+
+// sessionRejected reports whether status means the session is no longer valid
+func sessionRejected(status int) bool {
+	return status == http.StatusForbidden || status == http.StatusUnauthorized
+}
+
+// refreshStaleSession logs in again if the session is older than the policy
+// allows. Failures are only logged: the request is sent on the old session and
+// rejections are handled as usual.
+func (c *Client) refreshStaleSession(ctx context.Context) {
+	maxAge := c.reauthPolicy().MaxSessionAge
+	if maxAge <= 0 {
+		return
+	}
+	c.mu.RLock()
+	sid, loginAt := c.sid, c.loginAt
+	c.mu.RUnlock()
+	age := c.Clock().Now().Sub(loginAt)
+	if sid == "" || age < maxAge {
+		return
+	}
+	if err := c.reauthenticate(ctx, sid); err != nil {
+		c.log().Warn("failed to refresh session", "age", age, "error", err)
+	}
+}
+
+// reauthenticate logs in again unless another goroutine has already replaced
+// the rejected session while we waited
+func (c *Client) reauthenticate(ctx context.Context, rejectedSID string) error {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	if c.session() != rejectedSID {
+		return nil
+	}
+
+	policy := c.reauthPolicy()
+	if policy.MaxReauths > 0 {
+		now := c.Clock().Now()
+		recent := c.reauths[:0]
+		for _, at := range c.reauths {
+			if now.Sub(at) < policy.Window {
+				recent = append(recent, at)
+			}
+		}
+		c.reauths = recent
+		if len(recent) >= policy.MaxReauths {
+			return fmt.Errorf("%w: %d logins within %s", ErrReauthBudgetExhausted, len(recent), policy.Window)
+		}
+		c.reauths = append(c.reauths, now)
+	}
+	return c.AuthLoginCtx(ctx)
+}
+
+// sleepCtx waits for d or until ctx is done
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,206 @@
+package qbittorrent
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReauthPolicy_Attempts(t *testing.T) {
+	var logins, requests int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/auth/login" {
+			atomic.AddInt32(&logins, 1)
+			return
+		}
+		// The first two retries are rejected as well
+		if atomic.AddInt32(&requests, 1) <= 3 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+
+	client := &Client{username: "user", password: "pass", baseURL: mockServer.URL, client: mockServer.Client()}
+	if _, err := client.TorrentsInfo(); err == nil {
+		t.Fatalf("expected error with the default policy, got none")
+	}
+	if logins != 1 {
+		t.Errorf("expected a single login, got %d", logins)
+	}
+
+	atomic.StoreInt32(&logins, 0)
+	atomic.StoreInt32(&requests, 0)
+	client.reauth = &ReauthPolicy{Attempts: 3, Backoff: time.Millisecond, RetryPOST: true}
+	if _, err := client.TorrentsInfo(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if logins != 3 || requests != 4 {
+		t.Errorf("expected 3 logins and 4 requests, got %d and %d", logins, requests)
+	}
+}
+
+func TestReauthPolicy_Budget(t *testing.T) {
+	var logins int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/auth/login" {
+			atomic.AddInt32(&logins, 1)
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithReauthPolicy(ReauthPolicy{Attempts: 1, MaxReauths: 2, Window: time.Hour, RetryPOST: true}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.TorrentsInfo(); err == nil || errors.Is(err, ErrReauthBudgetExhausted) {
+			t.Fatalf("expected the 403 status error, got %v", err)
+		}
+	}
+	_, err = client.TorrentsInfo()
+	if !errors.Is(err, ErrReauthBudgetExhausted) {
+		t.Fatalf("expected ErrReauthBudgetExhausted, got %v", err)
+	}
+	if logins != 2 {
+		t.Errorf("expected 2 logins, got %d", logins)
+	}
+
+	// Old logins leave the window
+	client.reauths[0] = client.reauths[0].Add(-2 * time.Hour)
+	if _, err := client.TorrentsInfo(); errors.Is(err, ErrReauthBudgetExhausted) {
+		t.Errorf("expected a login after the window, got %v", err)
+	}
+}
+
+func TestReauthPolicy_POSTNotRetried(t *testing.T) {
+	var logins, posts int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/auth/login" {
+			atomic.AddInt32(&logins, 1)
+			http.SetCookie(w, &http.Cookie{Name: "SID", Value: "fresh"})
+			return
+		}
+		atomic.AddInt32(&posts, 1)
+		if cookie, err := r.Cookie("SID"); err != nil || cookie.Value != "fresh" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer mockServer.Close()
+
+	client := &Client{
+		username: "user",
+		password: "pass",
+		baseURL:  mockServer.URL,
+		client:   mockServer.Client(),
+		reauth:   &ReauthPolicy{Attempts: 1},
+	}
+
+	err := client.TorrentsAddTags("abc", "tag")
+	if !errors.Is(err, ErrNotRetried) {
+		t.Fatalf("expected ErrNotRetried, got %v", err)
+	}
+	if logins != 1 || posts != 1 {
+		t.Errorf("expected a login without re-sending, got %d logins and %d posts", logins, posts)
+	}
+
+	// The session was renewed, so re-sending succeeds
+	if err := client.TorrentsAddTags("abc", "tag"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestWithReauthPolicy_Invalid(t *testing.T) {
+	for _, policy := range []ReauthPolicy{
+		{Attempts: -1},
+		{Attempts: 1, MaxReauths: 3},
+	} {
+		if _, err := NewClientWithOptions("", "", "localhost", "8080", WithBypassAuth(), WithReauthPolicy(policy)); err == nil {
+			t.Errorf("expected error for %+v, got none", policy)
+		}
+	}
+}
+
+func TestReauth_Unauthorized(t *testing.T) {
+	var logins int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/auth/login" {
+			atomic.AddInt32(&logins, 1)
+			http.SetCookie(w, &http.Cookie{Name: "SID", Value: "fresh"})
+			return
+		}
+		// A reverse proxy rejecting the expired session
+		if cookie, err := r.Cookie("SID"); err != nil || cookie.Value != "fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+
+	client := &Client{username: "user", password: "pass", baseURL: mockServer.URL, client: mockServer.Client(), sid: "expired"}
+	if _, err := client.TorrentsInfo(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if logins != 1 {
+		t.Errorf("expected a single login, got %d", logins)
+	}
+}
+
+func TestReauthPolicy_MaxSessionAge(t *testing.T) {
+	var logins, rejected int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/auth/login" {
+			n := atomic.AddInt32(&logins, 1)
+			http.SetCookie(w, &http.Cookie{Name: "SID", Value: fmt.Sprintf("sid-%d", n)})
+			return
+		}
+		if cookie, err := r.Cookie("SID"); err != nil || cookie.Value != fmt.Sprintf("sid-%d", atomic.LoadInt32(&logins)) {
+			atomic.AddInt32(&rejected, 1)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClientWithOptions("user", "pass", "", "",
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithReauthPolicy(ReauthPolicy{Attempts: 1, RetryPOST: true, MaxSessionAge: time.Hour}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := client.TorrentsInfo(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if logins != 1 {
+		t.Errorf("expected the fresh session to be used, got %d logins", logins)
+	}
+
+	// The session is refreshed before the server gets to reject it
+	client.mu.Lock()
+	client.loginAt = client.loginAt.Add(-2 * time.Hour)
+	client.mu.Unlock()
+	atomic.StoreInt32(&logins, 5)
+	if _, err := client.TorrentsInfo(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if logins != 6 || rejected != 0 {
+		t.Errorf("expected a proactive login without rejections, got %d logins and %d rejections", logins, rejected)
+	}
+}
@@ -0,0 +1,239 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// WithoutRedaction keeps passkeys and other secrets of URLs in error
+// messages, logs, audit records and debug dumps, for debugging. By default
+// they are replaced by [REDACTED], see Redact.
+func WithoutRedaction() Option {
+	return func(c *Client) error {
+		c.noRedaction = true
+		return nil
+	}
+}
+
+var (
+	// urlPattern matches URLs in text, including announce URLs and magnet links
+	urlPattern = regexp.MustCompile(`(?i)\b(?:https?|udp|wss?|magnet):[^\s"'<>]+`)
+	// secretPathSegment matches path segments that may be passkeys
+	secretPathSegment = regexp.MustCompile(`^[0-9A-Za-z]{16,}$`)
+	// magnetURLParams are the parameters of magnet links holding URLs
+	magnetURLParams = map[string]bool{"tr": true, "ws": true, "as": true, "xs": true}
+	// secretParam matches the names of query parameters holding secrets
+	secretParam = regexp.MustCompile(`(?i)^(?:passkey|pass_key|authkey|auth|torrent_pass|pk|key|apikey|api_key|token|secret|password|pass|sid)$`)
+	// jsonStringMember matches the string members of JSON documents
+	jsonStringMember = regexp.MustCompile(`"([A-Za-z_]+)"(\s*:\s*)"(?:[^"\\]|\\.)*"`)
+)
+
+// Redact replaces the secrets of the URLs in s, as RedactURL does, and
+// returns the rest unchanged
+func Redact(s string) string {
+	return urlPattern.ReplaceAllStringFunc(s, RedactURL)
+}
+
+// RedactURL replaces the secrets of an announce URL or magnet link with
+// [REDACTED]: passwords, query parameters such as passkey or authkey, and path
+// segments that look like passkeys, i.e. 16 or more letters and digits
+// including a digit. The trackers of magnet links are redacted the same way.
+// Strings that can't be parsed are returned unchanged.
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	if strings.EqualFold(u.Scheme, "magnet") {
+		u.RawQuery = redactQuery(u.RawQuery, func(key, value string) string {
+			if magnetURLParams[key] {
+				return RedactURL(value)
+			}
+			return value
+		})
+		return u.String()
+	}
+
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	segments := strings.Split(u.EscapedPath(), "/")
+	for i, segment := range segments {
+		if secretPathSegment.MatchString(segment) && strings.ContainsAny(segment, "0123456789") {
+			segments[i] = redacted
+		}
+	}
+	u.RawPath = strings.Join(segments, "/")
+	u.Path, _ = url.PathUnescape(u.RawPath)
+	u.RawQuery = redactQuery(u.RawQuery, func(key, value string) string {
+		if secretParam.MatchString(key) {
+			return redacted
+		}
+		return value
+	})
+	return u.String()
+}
+
+// redactQuery replaces the values of a raw query by redact, keeping the order
+// and encoding of the parameters
+func redactQuery(rawQuery string, redact func(key, value string) string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		rawKey, rawValue, found := strings.Cut(param, "=")
+		if !found {
+			continue
+		}
+		key, err1 := url.QueryUnescape(rawKey)
+		value, err2 := url.QueryUnescape(rawValue)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if redactedValue := redact(key, value); redactedValue != value {
+			params[i] = rawKey + "=" + url.QueryEscape(redactedValue)
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// sensitiveKey reports whether a form field or JSON member named key holds a
+// secret, e.g. password, web_ui_password, proxy_password or passkey
+func sensitiveKey(key string) bool {
+	return secretParam.MatchString(key) || strings.Contains(strings.ToLower(key), "password")
+}
+
+// redactJSONSecrets replaces the values of the sensitive string members of a
+// JSON document, e.g. the json parameter of setPreferences
+func redactJSONSecrets(s string) string {
+	return jsonStringMember.ReplaceAllStringFunc(s, func(member string) string {
+		m := jsonStringMember.FindStringSubmatch(member)
+		if !sensitiveKey(m[1]) {
+			return member
+		}
+		return `"` + m[1] + `"` + m[2] + `"` + redacted + `"`
+	})
+}
+
+// redactSecrets replaces the values of the sensitive fields of a URL-encoded
+// form and of the sensitive members of JSON documents, in form values or in
+// a body of contentType. Unlike Redact, it applies regardless of
+// WithoutRedaction: credentials are never needed for debugging.
+func redactSecrets(contentType, body string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		params := strings.Split(body, "&")
+		for i, param := range params {
+			rawKey, rawValue, found := strings.Cut(param, "=")
+			key, err := url.QueryUnescape(rawKey)
+			if !found || err != nil {
+				continue
+			}
+			if sensitiveKey(key) {
+				// Left unescaped, so dumps read password=[REDACTED]
+				params[i] = rawKey + "=" + redacted
+			} else if value, err := url.QueryUnescape(rawValue); err == nil {
+				if redactedValue := redactJSONSecrets(value); redactedValue != value {
+					params[i] = rawKey + "=" + url.QueryEscape(redactedValue)
+				}
+			}
+		}
+		return strings.Join(params, "&")
+	case "application/json":
+		return redactJSONSecrets(body)
+	}
+	return body
+}
+
+// redact applies Redact unless the client was created with WithoutRedaction
+func (c *Client) redact(s string) string {
+	if c.noRedaction {
+		return s
+	}
+	return Redact(s)
+}
+
+// redactForm applies Redact to text that may be a URL-encoded form, such as
+// a request body, unless the client was created with WithoutRedaction
+func (c *Client) redactForm(s string) string {
+	if c.noRedaction {
+		return s
+	}
+	return Redact(redactQuery(s, func(key, value string) string { return Redact(value) }))
+}
+
+// redactValues applies Redact to the values of params in place, unless the
+// client was created with WithoutRedaction
+func (c *Client) redactValues(params url.Values) {
+	if c.noRedaction {
+		return
+	}
+	for _, values := range params {
+		for i, value := range values {
+			values[i] = Redact(value)
+		}
+	}
+}
+
+// redactingHandler applies Redact to the messages and the string and error
+// attributes of log records, e.g. the add URLs logged by IdempotentAdder
+type redactingHandler struct {
+	handler slog.Handler
+}
+
+func (h redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redactedRecord := slog.NewRecord(record.Time, record.Level, Redact(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redactedRecord.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return h.handler.Handle(ctx, redactedRecord)
+}
+
+func (h redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redactedAttrs := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redactedAttrs[i] = redactAttr(attr)
+	}
+	return redactingHandler{h.handler.WithAttrs(redactedAttrs)}
+}
+
+func (h redactingHandler) WithGroup(name string) slog.Handler {
+	return redactingHandler{h.handler.WithGroup(name)}
+}
+
+// redactAttr applies Redact to string, error and Stringer values, including
+// those of groups
+func redactAttr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, Redact(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		attrs := make([]any, len(group))
+		for i, groupAttr := range group {
+			attrs[i] = redactAttr(groupAttr)
+		}
+		return slog.Group(attr.Key, attrs...)
+	case slog.KindAny:
+		switch v := value.Any().(type) {
+		case error:
+			return slog.String(attr.Key, Redact(v.Error()))
+		case fmt.Stringer:
+			return slog.String(attr.Key, Redact(v.String()))
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
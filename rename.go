@@ -0,0 +1,124 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+)
+
+// TorrentsRenameCtx changes the display name of a torrent
+func (c *Client) TorrentsRenameCtx(ctx context.Context, hash, name string) error {
+	data := url.Values{}
+	data.Set("hash", hash)
+	data.Set("name", name)
+
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/torrents/rename", data)
+	if err != nil {
+		return fmt.Errorf("TorrentsRename error: %w", err)
+	}
+	return nil
+}
+
+// TorrentsRenameFolderCtx renames a folder of a torrent's content on disk
+func (c *Client) TorrentsRenameFolderCtx(ctx context.Context, hash, oldPath, newPath string) error {
+	data := url.Values{}
+	data.Set("hash", hash)
+	data.Set("oldPath", oldPath)
+	data.Set("newPath", newPath)
+
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/torrents/renameFolder", data)
+	if err != nil {
+		return fmt.Errorf("TorrentsRenameFolder error: %w", err)
+	}
+	return nil
+}
+
+// RenameOptions configures RenameTorrentsCtx
+type RenameOptions struct {
+	RenameFolder bool // also rename the root folder of multi-file torrents
+	DryRun       bool // only compute the renames
+}
+
+// Rename is a rename computed by RenameTorrentsCtx. OldFolder and NewFolder
+// are empty unless the root folder is renamed.
+type Rename struct {
+	Hash      InfoHash
+	OldName   string
+	NewName   string
+	OldFolder string
+	NewFolder string
+}
+
+func (r Rename) String() string {
+	s := fmt.Sprintf("%s: %q -> %q", r.Hash, r.OldName, r.NewName)
+	if r.NewFolder != "" {
+		s += fmt.Sprintf(" (folder %q -> %q)", r.OldFolder, r.NewFolder)
+	}
+	return s
+}
+
+// RenameTorrentsCtx renames torrents to the result of executing tmpl with each
+// TorrentInfo, e.g. `{{.Category}} - {{.Name}}`, and returns the renames.
+// Torrents whose name doesn't change are left out. With DryRun nothing is
+// sent, so the renames can be previewed; otherwise renaming stops at the
+// first error and the renames applied so far are returned.
+func (c *Client) RenameTorrentsCtx(ctx context.Context, torrents []TorrentInfo, tmpl *template.Template, opts RenameOptions) ([]Rename, error) {
+	var renames []Rename
+	for _, torrent := range torrents {
+		var name strings.Builder
+		if err := tmpl.Execute(&name, torrent); err != nil {
+			return renames, fmt.Errorf("RenameTorrents error: %s: %w", torrent.Hash, err)
+		}
+		newName := strings.TrimSpace(name.String())
+		if newName == "" || strings.Contains(newName, "/") {
+			return renames, fmt.Errorf("RenameTorrents error: %s: invalid name %q", torrent.Hash, newName)
+		}
+		if newName == torrent.Name {
+			continue
+		}
+
+		rename := Rename{Hash: torrent.Hash, OldName: torrent.Name, NewName: newName}
+		if opts.RenameFolder {
+			folder, err := c.rootFolder(ctx, string(torrent.Hash))
+			if err != nil {
+				return renames, err
+			}
+			if folder != "" && folder != newName {
+				rename.OldFolder, rename.NewFolder = folder, newName
+			}
+		}
+
+		if !opts.DryRun {
+			if err := c.TorrentsRenameCtx(ctx, string(torrent.Hash), newName); err != nil {
+				return renames, err
+			}
+			if rename.NewFolder != "" {
+				if err := c.TorrentsRenameFolderCtx(ctx, string(torrent.Hash), rename.OldFolder, rename.NewFolder); err != nil {
+					return renames, err
+				}
+			}
+		}
+		renames = append(renames, rename)
+	}
+	return renames, nil
+}
+
+// rootFolder returns the folder containing all files of a torrent, or an
+// empty string if the files aren't in a common folder
+func (c *Client) rootFolder(ctx context.Context, hash string) (string, error) {
+	files, err := c.TorrentsFilesCtx(ctx, hash)
+	if err != nil {
+		return "", err
+	}
+	var root string
+	for _, file := range files {
+		folder, _, ok := strings.Cut(file.Name, "/")
+		if !ok || (root != "" && folder != root) {
+			return "", nil
+		}
+		root = folder
+	}
+	return root, nil
+}
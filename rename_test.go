@@ -0,0 +1,78 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestRenameTorrents(t *testing.T) {
+	var posts []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/torrents/files" {
+			switch r.URL.Query().Get("hash") {
+			case "multi":
+				w.Write([]byte(`[{"name":"Some.Show.S01/e01.mkv"},{"name":"Some.Show.S01/e02.mkv"}]`))
+			default:
+				w.Write([]byte(`[{"name":"movie.mkv"}]`))
+			}
+			return
+		}
+		r.ParseForm()
+		posts = append(posts, r.URL.Path+" "+r.PostForm.Encode())
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	tmpl := template.Must(template.New("name").Parse(`[{{.Category}}] {{.Name}}`))
+	torrents := []TorrentInfo{
+		{Hash: "multi", Name: "Some.Show.S01", Category: "tv"},
+		{Hash: "single", Name: "movie", Category: "movies"},
+	}
+
+	renames, err := client.RenameTorrentsCtx(context.Background(), torrents, tmpl, RenameOptions{RenameFolder: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(posts) != 0 {
+		t.Errorf("expected no requests in dry-run, got %v", posts)
+	}
+	expected := []string{
+		`multi: "Some.Show.S01" -> "[tv] Some.Show.S01" (folder "Some.Show.S01" -> "[tv] Some.Show.S01")`,
+		`single: "movie" -> "[movies] movie"`,
+	}
+	if len(renames) != len(expected) {
+		t.Fatalf("expected renames %v, got %v", expected, renames)
+	}
+	for i := range expected {
+		if renames[i].String() != expected[i] {
+			t.Errorf("expected rename %s, got %s", expected[i], renames[i])
+		}
+	}
+
+	if _, err := client.RenameTorrentsCtx(context.Background(), torrents, tmpl, RenameOptions{RenameFolder: true}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expectedPosts := []string{
+		"/api/v2/torrents/rename hash=multi&name=%5Btv%5D+Some.Show.S01",
+		"/api/v2/torrents/renameFolder hash=multi&newPath=%5Btv%5D+Some.Show.S01&oldPath=Some.Show.S01",
+		"/api/v2/torrents/rename hash=single&name=%5Bmovies%5D+movie",
+	}
+	if strings.Join(posts, "\n") != strings.Join(expectedPosts, "\n") {
+		t.Errorf("expected requests %v, got %v", expectedPosts, posts)
+	}
+
+	// Unchanged names are left out
+	identity := template.Must(template.New("name").Parse(`{{.Name}}`))
+	if renames, err := client.RenameTorrentsCtx(context.Background(), torrents, identity, RenameOptions{DryRun: true}); err != nil || len(renames) != 0 {
+		t.Errorf("expected no renames, got %v, %v", renames, err)
+	}
+
+	slash := template.Must(template.New("name").Parse(`{{.Category}}/{{.Name}}`))
+	if _, err := client.RenameTorrentsCtx(context.Background(), torrents, slash, RenameOptions{DryRun: true}); err == nil {
+		t.Errorf("expected error for a name containing a slash, got none")
+	}
+}
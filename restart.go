@@ -0,0 +1,102 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// restartPollInterval is how often RestartAndWaitCtx checks on the server
+var restartPollInterval = 2 * time.Second
+
+// RestartReport describes a restart by RestartAndWaitCtx
+type RestartReport struct {
+	Version  string        // version of the server after the restart, e.g. after an upgrade
+	Torrents int           // torrents after the restart
+	Downtime time.Duration // from the shutdown until the API answered again
+	Duration time.Duration // from the shutdown until no torrent was checking
+}
+
+// RestartAndWaitCtx restarts the server, e.g. for an automated upgrade. It
+// shuts qBittorrent down, waits until the API stops answering, and calls
+// restart, which starts it again, e.g. with systemctl or docker. It then waits
+// until the API answers, logs in again, and waits until no torrent is checking
+// its data or resume data. The server is polled until ctx is done, so give it
+// a deadline.
+func (c *Client) RestartAndWaitCtx(ctx context.Context, restart func(ctx context.Context) error) (*RestartReport, error) {
+	clock := c.Clock()
+	start := clock.Now()
+	loggedIn := !c.bypassAuth && c.session() != ""
+
+	if err := c.AppShutdownCtx(ctx); err != nil {
+		return nil, fmt.Errorf("RestartAndWait error: %w", err)
+	}
+	c.log().Info("waiting for shutdown")
+	if err := c.pollUntil(ctx, func() (bool, error) {
+		return c.PingCtx(ctx) != nil, nil
+	}); err != nil {
+		return nil, fmt.Errorf("RestartAndWait error: waiting for shutdown: %w", err)
+	}
+
+	if err := restart(ctx); err != nil {
+		return nil, fmt.Errorf("RestartAndWait error: restarting: %w", err)
+	}
+	if err := c.pollUntil(ctx, func() (bool, error) {
+		return c.PingCtx(ctx) == nil, nil
+	}); err != nil {
+		return nil, fmt.Errorf("RestartAndWait error: waiting for the server: %w", err)
+	}
+	report := &RestartReport{Downtime: clock.Now().Sub(start)}
+
+	// The sessions and cached responses of the old process are gone
+	c.InvalidateCache()
+	if loggedIn {
+		if err := c.ReLoginCtx(ctx); err != nil {
+			return nil, fmt.Errorf("RestartAndWait error: %w", err)
+		}
+	}
+	version, err := c.AppVersionCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("RestartAndWait error: %w", err)
+	}
+	report.Version = version
+
+	c.log().Info("waiting for torrents to be checked", "version", version, "downtime", report.Downtime)
+	if err := c.pollUntil(ctx, func() (bool, error) {
+		torrents, err := c.TorrentsInfoCtx(ctx)
+		if err != nil {
+			return false, err
+		}
+		report.Torrents = len(torrents)
+		for _, torrent := range torrents {
+			if checking(TorrentState(torrent.State)) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}); err != nil {
+		return nil, fmt.Errorf("RestartAndWait error: waiting for checks: %w", err)
+	}
+	report.Duration = clock.Now().Sub(start)
+	return report, nil
+}
+
+// pollUntil calls done every restartPollInterval until it returns true or an
+// error, or ctx is done
+func (c *Client) pollUntil(ctx context.Context, done func() (bool, error)) error {
+	ticker := c.Clock().NewTicker(restartPollInterval)
+	defer ticker.Stop()
+
+	for {
+		ok, err := done()
+		if err != nil || ok {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		}
+	}
+}
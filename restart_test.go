@@ -0,0 +1,129 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRestartAndWaitCtx(t *testing.T) {
+	restartPollInterval = time.Millisecond
+	defer func() { restartPollInterval = 2 * time.Second }()
+
+	var mu sync.Mutex
+	var requests []string
+	down, polls := false, 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.URL.Path)
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v2/app/shutdown":
+			down = true
+		case "/api/v2/app/version":
+			w.Write([]byte("v5.0.1"))
+		case "/api/v2/torrents/info":
+			polls++
+			state := "checkingResumeData"
+			if polls > 2 {
+				state = "stalledUP"
+			}
+			w.Write([]byte(`[{"hash":"abc","state":"` + state + `"},{"hash":"def","state":"stoppedUP"}]`))
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	restarted := false
+	report, err := client.RestartAndWaitCtx(context.Background(), func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		restarted = true
+		down = false
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !restarted {
+		t.Error("expected the restart hook to be called")
+	}
+	if report.Version != "v5.0.1" || report.Torrents != 2 {
+		t.Errorf("expected version v5.0.1 with 2 torrents, got %+v", report)
+	}
+	if report.Duration < report.Downtime {
+		t.Errorf("expected the duration to include the downtime, got %+v", report)
+	}
+	expected := []string{
+		"/api/v2/app/shutdown",
+		"/api/v2/app/version", // down
+		"/api/v2/app/version", // up again
+		"/api/v2/app/version",
+		"/api/v2/torrents/info",
+		"/api/v2/torrents/info",
+		"/api/v2/torrents/info",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected %v, got %v", expected, requests)
+	}
+}
+
+func TestRestartAndWaitCtx_RestartFails(t *testing.T) {
+	restartPollInterval = time.Millisecond
+	defer func() { restartPollInterval = 2 * time.Second }()
+
+	down := false
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/api/v2/app/shutdown" {
+			down = true
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	errRestart := errors.New("unit not found")
+	_, err := client.RestartAndWaitCtx(context.Background(), func(ctx context.Context) error {
+		return errRestart
+	})
+	if !errors.Is(err, errRestart) {
+		t.Errorf("expected the restart error, got %v", err)
+	}
+}
+
+func TestRestartAndWaitCtx_NeverComesBack(t *testing.T) {
+	restartPollInterval = time.Millisecond
+	defer func() { restartPollInterval = 2 * time.Second }()
+
+	down := false
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/api/v2/app/shutdown" {
+			down = true
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.RestartAndWaitCtx(ctx, func(ctx context.Context) error { return nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package qbittorrent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ridKeyMainData is the RIDTracker key of /api/v2/sync/maindata
+const ridKeyMainData = "maindata"
+
+// ridKeyPeers returns the RIDTracker key of /api/v2/sync/torrentPeers for hash
+func ridKeyPeers(hash string) string {
+	return "torrentPeers/" + hash
+}
+
+// SyncInfo describes a response of a sync endpoint
+type SyncInfo struct {
+	Rid  int  // response ID to request the next update with
+	Full bool // the response holds the complete data rather than changes
+	// Forced is set for full responses to requests for changes since a
+	// known response ID, e.g. because the server restarted or dropped the
+	// session. Consumers must discard the state they built from earlier responses.
+	Forced bool
+}
+
+// RIDTracker keeps the response IDs of the sync endpoints, per torrent for
+// torrent peers, so clients only receive changes across calls and reconnects.
+// If it has a file the IDs survive restarts, which is only correct if the
+// consumer persists the state it built from the responses as well.
+// An RIDTracker is safe for concurrent use.
+type RIDTracker struct {
+	path string
+
+	mu   sync.Mutex
+	rids map[string]int
+}
+
+// NewRIDTracker returns a tracker. If path is not empty the response IDs are
+// loaded from and saved to that file.
+func NewRIDTracker(path string) (*RIDTracker, error) {
+	t := &RIDTracker{path: path, rids: make(map[string]int)}
+	if path == "" {
+		return t, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read response IDs: %w", err)
+	}
+	if err := json.Unmarshal(data, &t.rids); err != nil {
+		return nil, fmt.Errorf("failed to decode response IDs: %w", err)
+	}
+	return t, nil
+}
+
+// SyncMainDataCtx retrieves the main data changes since the tracked response ID
+func (t *RIDTracker) SyncMainDataCtx(ctx context.Context, c *Client) (*MainData, SyncInfo, error) {
+	requested := t.rid(ridKeyMainData)
+	data, err := c.SyncMainDataCtx(ctx, requested)
+	if err != nil {
+		return nil, SyncInfo{}, err
+	}
+	info, err := t.record(c, ridKeyMainData, requested, data.Rid, data.FullUpdate)
+	return data, info, err
+}
+
+// SyncTorrentPeersCtx retrieves the peer changes of a torrent since its
+// tracked response ID
+func (t *RIDTracker) SyncTorrentPeersCtx(ctx context.Context, c *Client, hash string) (*TorrentPeers, SyncInfo, error) {
+	data, _, info, err := t.syncTorrentPeers(ctx, c, hash)
+	return data, info, err
+}
+
+// syncTorrentPeers retrieves the peer changes of a torrent along with the raw
+// response body
+func (t *RIDTracker) syncTorrentPeers(ctx context.Context, c *Client, hash string) (*TorrentPeers, []byte, SyncInfo, error) {
+	key := ridKeyPeers(hash)
+	requested := t.rid(key)
+	data, raw, err := c.syncTorrentPeers(ctx, hash, requested)
+	if err != nil {
+		return nil, nil, SyncInfo{}, err
+	}
+	info, err := t.record(c, key, requested, data.Rid, data.FullUpdate)
+	return data, raw, info, err
+}
+
+// Reset forgets all response IDs, so the next responses are full updates
+func (t *RIDTracker) Reset() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rids = make(map[string]int)
+	return t.save()
+}
+
+// ForgetPeers forgets the response ID of the peers of hash, e.g. once the
+// torrent was removed
+func (t *RIDTracker) ForgetPeers(hash string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.rids[ridKeyPeers(hash)]; !ok {
+		return nil
+	}
+	delete(t.rids, ridKeyPeers(hash))
+	return t.save()
+}
+
+// PeerHashes returns the hashes of the torrents whose peers are tracked
+func (t *RIDTracker) PeerHashes() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var hashes []string
+	for key := range t.rids {
+		if hash, ok := strings.CutPrefix(key, ridKeyPeers("")); ok {
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes
+}
+
+// rid returns the response ID to request the next update of key with
+func (t *RIDTracker) rid(key string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.rids[key]
+}
+
+// record stores the response ID of a response to a request made with requested
+func (t *RIDTracker) record(c *Client, key string, requested, rid int, full bool) (SyncInfo, error) {
+	info := SyncInfo{Rid: rid, Full: full, Forced: full && requested != 0}
+	if info.Forced {
+		c.log().Info("server forced a full sync update", "endpoint", key, "rid", requested)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.rids[key] == rid {
+		return info, nil
+	}
+	t.rids[key] = rid
+	return info, t.save()
+}
+
+// save writes the response IDs to the tracker's file, if any. t.mu must be held.
+func (t *RIDTracker) save() error {
+	if t.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(t.rids, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode response IDs: %w", err)
+	}
+	if err := writeFileAtomic(t.path, data); err != nil {
+		return fmt.Errorf("failed to save response IDs: %w", err)
+	}
+	return nil
+}
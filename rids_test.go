@@ -0,0 +1,91 @@
+package qbittorrent
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestRIDTracker(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	serverRid := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		rid := r.URL.Query().Get("rid")
+		requests = append(requests, r.URL.Path+" "+r.URL.Query().Get("hash")+" "+rid)
+		serverRid++
+		// The server only knows the response IDs it handed out itself
+		full := rid == "0" || rid == "99"
+		w.Write([]byte(`{"rid":` + strconv.Itoa(serverRid) + `,"full_update":` + strconv.FormatBool(full) + `}`))
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	path := filepath.Join(t.TempDir(), "rids.json")
+	tracker, err := NewRIDTracker(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ctx := context.Background()
+	if _, info, err := tracker.SyncMainDataCtx(ctx, client); err != nil || !info.Full || info.Forced || info.Rid != 1 {
+		t.Errorf("expected initial full update, got %+v, %v", info, err)
+	}
+	if _, info, err := tracker.SyncMainDataCtx(ctx, client); err != nil || info.Full {
+		t.Errorf("expected partial update, got %+v, %v", info, err)
+	}
+	if _, info, err := tracker.SyncTorrentPeersCtx(ctx, client, "hash1"); err != nil || !info.Full {
+		t.Errorf("expected full peers update, got %+v, %v", info, err)
+	}
+
+	// A new tracker continues from the saved response IDs
+	reloaded, err := NewRIDTracker(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, info, err := reloaded.SyncTorrentPeersCtx(ctx, client, "hash1"); err != nil || info.Full {
+		t.Errorf("expected partial peers update after reload, got %+v, %v", info, err)
+	}
+	if hashes := reloaded.PeerHashes(); len(hashes) != 1 || hashes[0] != "hash1" {
+		t.Errorf("expected peers of hash1 to be tracked, got %v", hashes)
+	}
+
+	expected := []string{
+		"/api/v2/sync/maindata  0",
+		"/api/v2/sync/maindata  1",
+		"/api/v2/sync/torrentPeers hash1 0",
+		"/api/v2/sync/torrentPeers hash1 3",
+	}
+	if len(requests) != len(expected) {
+		t.Fatalf("expected requests %v, got %v", expected, requests)
+	}
+	for i := range expected {
+		if requests[i] != expected[i] {
+			t.Errorf("expected request %q, got %q", expected[i], requests[i])
+		}
+	}
+
+	// The server answers an unknown response ID with a full update
+	reloaded.rids[ridKeyMainData] = 99
+	if _, info, err := reloaded.SyncMainDataCtx(ctx, client); err != nil || !info.Forced {
+		t.Errorf("expected forced full update, got %+v, %v", info, err)
+	}
+
+	if err := reloaded.ForgetPeers("hash1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := reloaded.Reset(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if hashes := reloaded.PeerHashes(); len(hashes) != 0 {
+		t.Errorf("expected no tracked peers, got %v", hashes)
+	}
+}
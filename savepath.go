@@ -0,0 +1,205 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// SavePathIssue is a torrent whose data isn't where automatic torrent
+// management would keep it
+type SavePathIssue struct {
+	Hash        InfoHash
+	Name        string
+	Category    string
+	AutoTMM     bool
+	SavePath    string
+	ContentPath string
+	// Expected is the save path automatic torrent management gives the
+	// torrent, from its category and the default save path
+	Expected string
+	// WouldMove is set if the save path differs from Expected: enabling
+	// automatic torrent management moves the data of the torrent. With it
+	// enabled already, a move failed or is still pending.
+	WouldMove bool
+	// ContentOutside is set if the content of a completed torrent isn't
+	// below its save path, e.g. because it was moved outside qBittorrent
+	ContentOutside bool
+}
+
+// CategorySavePath returns the save path automatic torrent management uses
+// for torrents of category. Like qBittorrent, categories without a save path
+// use the default save path joined with their name, relative save paths are
+// below the default save path, and torrents without a category use the default
+// save path.
+func CategorySavePath(categories map[string]Category, category, defaultSavePath string) string {
+	if category == "" {
+		return defaultSavePath
+	}
+	savePath, _ := categories[category]["savePath"].(string)
+	if savePath == "" {
+		savePath = category
+	}
+	if isAbsPath(savePath) {
+		return savePath
+	}
+	return strings.TrimSuffix(defaultSavePath, "/") + "/" + savePath
+}
+
+// AuditSavePaths reports the torrents whose save path differs from the one
+// their category gives them under automatic torrent management, and completed
+// torrents whose content isn't below their save path, in the order of
+// torrents. Torrents with automatic management disabled are the usual source
+// of surprise data moves when it gets enabled, for the torrent, by a category
+// change, or by the auto_tmm_enabled preference.
+func AuditSavePaths(torrents []TorrentInfo, categories map[string]Category, defaultSavePath string) []SavePathIssue {
+	var issues []SavePathIssue
+	for _, t := range torrents {
+		expected := CategorySavePath(categories, t.Category, defaultSavePath)
+		issue := SavePathIssue{
+			Hash:           t.Hash,
+			Name:           t.Name,
+			Category:       t.Category,
+			AutoTMM:        t.AutoTMM,
+			SavePath:       t.SavePath,
+			ContentPath:    t.ContentPath,
+			Expected:       expected,
+			WouldMove:      !samePath(t.SavePath, expected),
+			ContentOutside: t.Progress == 1 && t.ContentPath != "" && !pathWithin(t.ContentPath, t.SavePath),
+		}
+		if issue.WouldMove || issue.ContentOutside {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// AuditSavePathsCtx fetches the torrents, categories and default save path
+// and reports their issues, see AuditSavePaths
+func (c *Client) AuditSavePathsCtx(ctx context.Context) ([]SavePathIssue, error) {
+	prefs, err := c.AppPreferencesCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("AuditSavePaths error: %w", err)
+	}
+	categories, err := c.TorrentsCategoriesCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("AuditSavePaths error: %w", err)
+	}
+	torrents, err := c.TorrentsInfoCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("AuditSavePaths error: %w", err)
+	}
+	return AuditSavePaths(torrents, categories, prefs.SavePath), nil
+}
+
+// CategoryChangeAction is what SetCategorySafeCtx did to a torrent
+type CategoryChangeAction string
+
+const (
+	// CategoryChanged means the category changed without moving data, since
+	// the torrent isn't under automatic torrent management or the new
+	// category has the same save path
+	CategoryChanged CategoryChangeAction = "changed"
+	// CategoryChangedMoving means the category changed and, as allowed, the
+	// data is moving to the save path of the new category
+	CategoryChangedMoving CategoryChangeAction = "moving"
+	// CategoryChangedUnmanaged means automatic torrent management was disabled
+	// before changing the category, so the data stayed. Enabling it again
+	// moves the data to the save path of the category.
+	CategoryChangedUnmanaged CategoryChangeAction = "unmanaged"
+)
+
+// ErrTorrentMoving is returned by SetCategorySafeCtx for torrents that are
+// moving their data
+var ErrTorrentMoving = errors.New("torrent is moving")
+
+// SetCategorySafeCtx changes the category of a torrent without surprise data
+// moves. If allowMove is false and the new category would move the data, the
+// torrent ends up without automatic torrent management, as reported by
+// CategoryChangedUnmanaged: management is disabled before the category
+// changes, and isn't enabled again since that would move the data to the save
+// path of the new category after all. Torrents that are moving already are
+// refused with ErrTorrentMoving, and categories that don't exist with
+// ErrInvalidCategory before anything is changed. If the category can't be
+// changed, automatic management is enabled again.
+func (c *Client) SetCategorySafeCtx(ctx context.Context, hash, category string, allowMove bool) (CategoryChangeAction, error) {
+	torrent, err := c.GetTorrentCtx(ctx, hash)
+	if err != nil {
+		return "", fmt.Errorf("SetCategorySafe error: %w", err)
+	}
+	if TorrentState(torrent.State) == StateMoving {
+		return "", fmt.Errorf("SetCategorySafe error: %s: %w", hash, ErrTorrentMoving)
+	}
+
+	action := CategoryChanged
+	if torrent.AutoTMM {
+		prefs, err := c.AppPreferencesCtx(ctx)
+		if err != nil {
+			return "", fmt.Errorf("SetCategorySafe error: %w", err)
+		}
+		categories, err := c.TorrentsCategoriesCtx(ctx)
+		if err != nil {
+			return "", fmt.Errorf("SetCategorySafe error: %w", err)
+		}
+		if _, ok := categories[category]; !ok && category != "" {
+			return "", fmt.Errorf("SetCategorySafe error: %q: %w", category, ErrInvalidCategory)
+		}
+		if !samePath(torrent.SavePath, CategorySavePath(categories, category, prefs.SavePath)) {
+			action = CategoryChangedMoving
+			if !allowMove {
+				if err := c.TorrentsSetAutoManagementCtx(ctx, []string{hash}, false); err != nil {
+					return "", fmt.Errorf("SetCategorySafe error: %w", err)
+				}
+				action = CategoryChangedUnmanaged
+			}
+		}
+	}
+
+	if err := c.TorrentsSetCategoryCtx(ctx, []string{hash}, category); err != nil {
+		if action == CategoryChangedUnmanaged {
+			// The torrent keeps its category, and with it its save path
+			if restoreErr := c.TorrentsSetAutoManagementCtx(context.WithoutCancel(ctx), []string{hash}, true); restoreErr != nil {
+				return "", fmt.Errorf("SetCategorySafe error: %w; automatic management left disabled: %w", err, restoreErr)
+			}
+		}
+		return "", fmt.Errorf("SetCategorySafe error: %w", err)
+	}
+	return action, nil
+}
+
+// normalizePath cleans a path reported by the server, which uses backslashes
+// on Windows
+func normalizePath(p string) string {
+	return path.Clean(strings.ReplaceAll(p, `\`, "/"))
+}
+
+// isWindowsPath reports whether p starts with a drive letter, like "C:/"
+func isWindowsPath(p string) bool {
+	return len(p) >= 2 && p[1] == ':' && ('a' <= p[0] && p[0] <= 'z' || 'A' <= p[0] && p[0] <= 'Z')
+}
+
+// isAbsPath reports whether p is absolute on Unix or Windows
+func isAbsPath(p string) bool {
+	p = strings.ReplaceAll(p, `\`, "/")
+	return strings.HasPrefix(p, "/") || isWindowsPath(p)
+}
+
+// samePath compares paths, ignoring trailing separators, and case on Windows
+func samePath(a, b string) bool {
+	a, b = normalizePath(a), normalizePath(b)
+	if isWindowsPath(a) {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// pathWithin reports whether p is dir or below it
+func pathWithin(p, dir string) bool {
+	p, dir = normalizePath(p), normalizePath(dir)
+	if isWindowsPath(p) {
+		p, dir = strings.ToLower(p), strings.ToLower(dir)
+	}
+	return p == dir || strings.HasPrefix(p, strings.TrimSuffix(dir, "/")+"/")
+}
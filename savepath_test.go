@@ -0,0 +1,182 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCategorySavePath(t *testing.T) {
+	categories := map[string]Category{
+		"movies":  {"name": "movies", "savePath": "/data/films"},
+		"tv":      {"name": "tv", "savePath": ""},
+		"tv/kids": {"name": "tv/kids", "savePath": ""},
+		"music":   {"name": "music", "savePath": "audio"},
+	}
+	tests := map[string]string{
+		"":        "/downloads/",
+		"movies":  "/data/films",
+		"tv":      "/downloads/tv",
+		"tv/kids": "/downloads/tv/kids",
+		"music":   "/downloads/audio",
+		"unknown": "/downloads/unknown",
+	}
+	for category, expected := range tests {
+		if got := CategorySavePath(categories, category, "/downloads/"); got != expected {
+			t.Errorf("%q: expected %q, got %q", category, expected, got)
+		}
+	}
+	if got := CategorySavePath(map[string]Category{"tv": {"savePath": `D:\TV`}}, "tv", `C:\Downloads`); got != `D:\TV` {
+		t.Errorf("expected the absolute Windows path, got %q", got)
+	}
+}
+
+func TestAuditSavePaths(t *testing.T) {
+	categories := map[string]Category{"movies": {"savePath": "/data/films"}}
+	torrents := []TorrentInfo{
+		{Hash: "a", Category: "movies", SavePath: "/data/films/", ContentPath: "/data/films/A", Progress: 1},
+		{Hash: "b", Category: "movies", SavePath: "/downloads", ContentPath: "/downloads/B", Progress: 1},
+		{Hash: "c", Category: "movies", AutoTMM: true, SavePath: "/downloads", ContentPath: "/downloads/C", Progress: 0.5},
+		{Hash: "d", SavePath: "/downloads", ContentPath: "/elsewhere/D", Progress: 1},
+		{Hash: "e", SavePath: "/downloads", ContentPath: "/incomplete/E", Progress: 0.5},
+		{Hash: "f", Category: "tv", SavePath: `C:\Downloads\tv\`, ContentPath: `c:\downloads\TV\F`, Progress: 1},
+	}
+	issues := AuditSavePaths(torrents, categories, "/downloads")
+	if len(issues) != 4 {
+		t.Fatalf("expected 4 issues, got %+v", issues)
+	}
+	if b := issues[0]; b.Hash != "b" || !b.WouldMove || b.ContentOutside || b.Expected != "/data/films" || b.AutoTMM {
+		t.Errorf("expected b to move to the category path, got %+v", b)
+	}
+	if c := issues[1]; c.Hash != "c" || !c.WouldMove || !c.AutoTMM {
+		t.Errorf("expected c to be reported with AutoTMM enabled, got %+v", c)
+	}
+	if d := issues[2]; d.Hash != "d" || d.WouldMove || !d.ContentOutside {
+		t.Errorf("expected d to have its content outside the save path, got %+v", d)
+	}
+	// Windows paths compare without case, and f's category has no save path
+	if f := issues[3]; f.Hash != "f" || !f.WouldMove || f.ContentOutside {
+		t.Errorf("expected f to move below the default save path only, got %+v", f)
+	}
+
+	issues = AuditSavePaths(torrents[5:], nil, `C:\Downloads`)
+	if len(issues) != 0 {
+		t.Errorf("expected matching Windows paths to pass, got %+v", issues)
+	}
+}
+
+func TestAuditSavePathsCtx(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/app/preferences":
+			w.Write([]byte(`{"save_path":"/downloads"}`))
+		case "/api/v2/torrents/categories":
+			w.Write([]byte(`{"movies":{"name":"movies","savePath":"/data/films"}}`))
+		case "/api/v2/torrents/info":
+			w.Write([]byte(`[{"hash":"a","category":"movies","save_path":"/downloads","content_path":"/downloads/A","progress":1}]`))
+		}
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+	issues, err := client.AuditSavePathsCtx(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(issues) != 1 || issues[0].Hash != "a" || issues[0].Expected != "/data/films" {
+		t.Errorf("unexpected issues %+v", issues)
+	}
+}
+
+func TestSetCategorySafeCtx(t *testing.T) {
+	var calls []string
+	torrent := `{"hash":"abc","auto_tmm":true,"save_path":"/downloads/tv","state":"uploading"}`
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/torrents/info":
+			w.Write([]byte("[" + torrent + "]"))
+		case "/api/v2/app/preferences":
+			w.Write([]byte(`{"save_path":"/downloads"}`))
+		case "/api/v2/torrents/categories":
+			w.Write([]byte(`{"tv":{"savePath":""},"shows":{"savePath":"/downloads/tv"},"movies":{"savePath":"/data/films"},"broken":{"savePath":"/data/broken"}}`))
+		case "/api/v2/torrents/setCategory":
+			r.ParseForm()
+			calls = append(calls, r.URL.Path+"?"+r.PostForm.Encode())
+			if r.PostForm.Get("category") == "broken" {
+				w.WriteHeader(http.StatusConflict)
+			}
+		default:
+			r.ParseForm()
+			calls = append(calls, r.URL.Path+"?"+r.PostForm.Encode())
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		category  string
+		allowMove bool
+		action    CategoryChangeAction
+		calls     []string
+	}{
+		{"same save path", "shows", false, CategoryChanged, []string{
+			"/api/v2/torrents/setCategory?category=shows&hashes=abc",
+		}},
+		{"move allowed", "movies", true, CategoryChangedMoving, []string{
+			"/api/v2/torrents/setCategory?category=movies&hashes=abc",
+		}},
+		{"move refused", "movies", false, CategoryChangedUnmanaged, []string{
+			"/api/v2/torrents/setAutoManagement?enable=false&hashes=abc",
+			"/api/v2/torrents/setCategory?category=movies&hashes=abc",
+		}},
+	}
+	for _, tt := range tests {
+		calls = nil
+		action, err := client.SetCategorySafeCtx(ctx, "abc", tt.category, tt.allowMove)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.name, err)
+		}
+		if action != tt.action {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.action, action)
+		}
+		if strings.Join(calls, " ") != strings.Join(tt.calls, " ") {
+			t.Errorf("%s: expected calls %v, got %v", tt.name, tt.calls, calls)
+		}
+	}
+
+	calls = nil
+	if _, err := client.SetCategorySafeCtx(ctx, "abc", "unknown", false); !errors.Is(err, ErrInvalidCategory) || len(calls) > 0 {
+		t.Errorf("expected ErrInvalidCategory without changes, got %v, %v", err, calls)
+	}
+
+	// A failed category change doesn't leave the torrent unmanaged
+	calls = nil
+	if _, err := client.SetCategorySafeCtx(ctx, "abc", "broken", false); err == nil {
+		t.Error("expected an error")
+	}
+	expected := []string{
+		"/api/v2/torrents/setAutoManagement?enable=false&hashes=abc",
+		"/api/v2/torrents/setCategory?category=broken&hashes=abc",
+		"/api/v2/torrents/setAutoManagement?enable=true&hashes=abc",
+	}
+	if strings.Join(calls, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected calls %v, got %v", expected, calls)
+	}
+
+	// Without automatic management the category changes without a move
+	torrent = `{"hash":"abc","auto_tmm":false,"save_path":"/elsewhere","state":"uploading"}`
+	calls = nil
+	if action, err := client.SetCategorySafeCtx(ctx, "abc", "movies", false); err != nil || action != CategoryChanged || len(calls) != 1 {
+		t.Errorf("expected a plain category change, got %s, %v, %v", action, err, calls)
+	}
+
+	torrent = `{"hash":"abc","auto_tmm":true,"save_path":"/downloads/tv","state":"moving"}`
+	if _, err := client.SetCategorySafeCtx(ctx, "abc", "movies", true); !errors.Is(err, ErrTorrentMoving) {
+		t.Errorf("expected ErrTorrentMoving, got %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ScheduleDays are the days on which the bandwidth schedule applies, in the
+// scheduler_days encoding of qBittorrent. The encoding doesn't depend on the
+// locale of the server, only the names shown for it do, see
+// ScheduleDayNames.
+type ScheduleDays int
+
+const (
+	ScheduleEveryDay ScheduleDays = iota
+	ScheduleWeekdays
+	ScheduleWeekends
+	ScheduleMonday
+	ScheduleTuesday
+	ScheduleWednesday
+	ScheduleThursday
+	ScheduleFriday
+	ScheduleSaturday
+	ScheduleSunday
+)
+
+// ScheduleDayNames are the names of the ScheduleDays values by language, in
+// the order of the encoding. Add languages as needed; English is the
+// fallback.
+var ScheduleDayNames = map[string][]string{
+	"en": {"every day", "weekdays", "weekends",
+		"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"},
+	"de": {"jeden tag", "wochentage", "wochenenden",
+		"montag", "dienstag", "mittwoch", "donnerstag", "freitag", "samstag", "sonntag"},
+	"es": {"todos los días", "días laborables", "fines de semana",
+		"lunes", "martes", "miércoles", "jueves", "viernes", "sábado", "domingo"},
+	"fr": {"tous les jours", "jours de semaine", "week-ends",
+		"lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi", "dimanche"},
+	"ru": {"каждый день", "будни", "выходные",
+		"понедельник", "вторник", "среда", "четверг", "пятница", "суббота", "воскресенье"},
+}
+
+func (d ScheduleDays) String() string {
+	return d.Name("en")
+}
+
+// Name returns the name of d in the language of locale, e.g. "ru" or the
+// Preferences.Locale "pt_BR", falling back to the base language and English
+func (d ScheduleDays) Name(locale string) string {
+	names, ok := ScheduleDayNames[locale]
+	if !ok {
+		language, _, _ := strings.Cut(locale, "_")
+		if names, ok = ScheduleDayNames[language]; !ok {
+			names = ScheduleDayNames["en"]
+		}
+	}
+	if d < 0 || int(d) >= len(names) {
+		return "unknown"
+	}
+	return names[d]
+}
+
+// ParseScheduleDays parses the names of ScheduleDayNames in any language,
+// ignoring case
+func ParseScheduleDays(s string) (ScheduleDays, error) {
+	s = strings.TrimSpace(s)
+	for _, names := range ScheduleDayNames {
+		for i, name := range names {
+			if strings.EqualFold(s, name) {
+				return ScheduleDays(i), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unknown schedule days %q", s)
+}
+
+// ScheduleDaysOf returns the single day encoding of a weekday. qBittorrent
+// counts from Monday while time.Weekday counts from Sunday.
+func ScheduleDaysOf(day time.Weekday) ScheduleDays {
+	if day == time.Sunday {
+		return ScheduleSunday
+	}
+	return ScheduleMonday + ScheduleDays(day-time.Monday)
+}
+
+// Includes reports whether the schedule applies on day
+func (d ScheduleDays) Includes(day time.Weekday) bool {
+	weekend := day == time.Saturday || day == time.Sunday
+	switch d {
+	case ScheduleEveryDay:
+		return true
+	case ScheduleWeekdays:
+		return !weekend
+	case ScheduleWeekends:
+		return weekend
+	default:
+		return d == ScheduleDaysOf(day)
+	}
+}
+
+// BandwidthSchedule is when the alternative rate limits apply. Start and End
+// are times of day as durations since midnight, with minute precision. Like
+// in qBittorrent, a schedule ending before it starts runs past midnight, e.g.
+// from 22:00 to 06:00. Days are matched against the current day, so such a
+// schedule on Fridays applies from Friday 00:00 to 06:00 and from 22:00.
+type BandwidthSchedule struct {
+	Enabled bool
+	Days    ScheduleDays
+	Start   time.Duration
+	End     time.Duration
+}
+
+// Active reports whether the schedule applies at t, in t's location
+func (s BandwidthSchedule) Active(t time.Time) bool {
+	if !s.Enabled || !s.Days.Includes(t.Weekday()) {
+		return false
+	}
+	sinceMidnight := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if s.End <= s.Start {
+		return sinceMidnight >= s.Start || sinceMidnight < s.End
+	}
+	return sinceMidnight >= s.Start && sinceMidnight < s.End
+}
+
+// GetBandwidthScheduleCtx returns the bandwidth schedule from the preferences
+func (c *Client) GetBandwidthScheduleCtx(ctx context.Context) (*BandwidthSchedule, error) {
+	prefs, err := c.AppPreferencesCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return bandwidthSchedule(prefs.SchedulerPreferences), nil
+}
+
+// SetBandwidthScheduleCtx sets the bandwidth schedule. The times must be
+// whole minutes within a day.
+func (c *Client) SetBandwidthScheduleCtx(ctx context.Context, schedule BandwidthSchedule) error {
+	prefs, err := schedulerPreferences(schedule)
+	if err != nil {
+		return fmt.Errorf("SetBandwidthSchedule error: %w", err)
+	}
+	return c.AppSetPreferencesCtx(ctx, map[string]interface{}{
+		"scheduler_enabled":  prefs.SchedulerEnabled,
+		"schedule_from_hour": prefs.ScheduleFromHour,
+		"schedule_from_min":  prefs.ScheduleFromMin,
+		"schedule_to_hour":   prefs.ScheduleToHour,
+		"schedule_to_min":    prefs.ScheduleToMin,
+		"scheduler_days":     prefs.SchedulerDays,
+	})
+}
+
+// bandwidthSchedule converts the scheduler preferences
+func bandwidthSchedule(prefs SchedulerPreferences) *BandwidthSchedule {
+	return &BandwidthSchedule{
+		Enabled: prefs.SchedulerEnabled,
+		Days:    ScheduleDays(prefs.SchedulerDays),
+		Start:   time.Duration(prefs.ScheduleFromHour)*time.Hour + time.Duration(prefs.ScheduleFromMin)*time.Minute,
+		End:     time.Duration(prefs.ScheduleToHour)*time.Hour + time.Duration(prefs.ScheduleToMin)*time.Minute,
+	}
+}
+
+// schedulerPreferences converts a schedule, rejecting invalid ones
+func schedulerPreferences(schedule BandwidthSchedule) (SchedulerPreferences, error) {
+	for _, d := range []time.Duration{schedule.Start, schedule.End} {
+		if d < 0 || d >= 24*time.Hour || d%time.Minute != 0 {
+			return SchedulerPreferences{}, fmt.Errorf("%s is not a whole minute within a day", d)
+		}
+	}
+	if schedule.Days.String() == "unknown" {
+		return SchedulerPreferences{}, fmt.Errorf("unknown schedule days %d", schedule.Days)
+	}
+	prefs := SchedulerPreferences{
+		SchedulerEnabled: schedule.Enabled,
+		ScheduleFromHour: int(schedule.Start / time.Hour),
+		ScheduleFromMin:  int(schedule.Start % time.Hour / time.Minute),
+		ScheduleToHour:   int(schedule.End / time.Hour),
+		ScheduleToMin:    int(schedule.End % time.Hour / time.Minute),
+		SchedulerDays:    int(schedule.Days),
+	}
+	return prefs, nil
+}
@@ -0,0 +1,186 @@
+package qbittorrent
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScheduleDaysOf(t *testing.T) {
+	expected := map[time.Weekday]ScheduleDays{
+		time.Sunday:    ScheduleSunday,
+		time.Monday:    ScheduleMonday,
+		time.Wednesday: ScheduleWednesday,
+		time.Saturday:  ScheduleSaturday,
+	}
+	for day, days := range expected {
+		if got := ScheduleDaysOf(day); got != days {
+			t.Errorf("%s: expected %s, got %s", day, days, got)
+		}
+	}
+}
+
+func TestScheduleDays_Includes(t *testing.T) {
+	tests := []struct {
+		days     ScheduleDays
+		included []time.Weekday
+	}{
+		{ScheduleEveryDay, []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}},
+		{ScheduleWeekdays, []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}},
+		{ScheduleWeekends, []time.Weekday{time.Sunday, time.Saturday}},
+		{ScheduleSunday, []time.Weekday{time.Sunday}},
+		{ScheduleFriday, []time.Weekday{time.Friday}},
+	}
+	for _, tt := range tests {
+		var included []time.Weekday
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if tt.days.Includes(day) {
+				included = append(included, day)
+			}
+		}
+		if !reflect.DeepEqual(included, tt.included) {
+			t.Errorf("%s: expected %v, got %v", tt.days, tt.included, included)
+		}
+	}
+}
+
+func TestParseScheduleDays(t *testing.T) {
+	for days := ScheduleEveryDay; days <= ScheduleSunday; days++ {
+		parsed, err := ParseScheduleDays(days.String())
+		if err != nil || parsed != days {
+			t.Errorf("expected %s to round-trip, got %s and %v", days, parsed, err)
+		}
+	}
+	if days, err := ParseScheduleDays(" Weekends "); err != nil || days != ScheduleWeekends {
+		t.Errorf("expected weekends, got %s and %v", days, err)
+	}
+	if _, err := ParseScheduleDays("someday"); err == nil {
+		t.Errorf("expected error for an unknown name, got none")
+	}
+}
+
+func TestScheduleDays_Locales(t *testing.T) {
+	for language, names := range ScheduleDayNames {
+		if len(names) != int(ScheduleSunday)+1 {
+			t.Errorf("%s: expected a name for every value, got %v", language, names)
+		}
+		for days := ScheduleEveryDay; days <= ScheduleSunday; days++ {
+			parsed, err := ParseScheduleDays(days.Name(language))
+			if err != nil || parsed != days {
+				t.Errorf("%s: expected %s to round-trip, got %s and %v", language, days, parsed, err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name     string
+		expected ScheduleDays
+	}{
+		{"Понедельник", ScheduleMonday},
+		{"ВЫХОДНЫЕ", ScheduleWeekends},
+		{"Sonntag", ScheduleSunday},
+		{"Días laborables", ScheduleWeekdays},
+		{"tous les jours", ScheduleEveryDay},
+	}
+	for _, tt := range tests {
+		if days, err := ParseScheduleDays(tt.name); err != nil || days != tt.expected {
+			t.Errorf("%s: expected %s, got %s and %v", tt.name, tt.expected, days, err)
+		}
+	}
+
+	names := map[string]string{"ru": "среда", "de_CH": "mittwoch", "pt_BR": "wednesday", "": "wednesday"}
+	for locale, expected := range names {
+		if name := ScheduleWednesday.Name(locale); name != expected {
+			t.Errorf("%q: expected %s, got %s", locale, expected, name)
+		}
+	}
+}
+
+func TestBandwidthSchedule_Active(t *testing.T) {
+	schedule := BandwidthSchedule{Enabled: true, Days: ScheduleWeekdays, Start: 8 * time.Hour, End: 17*time.Hour + 30*time.Minute}
+	monday := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	tests := map[time.Time]bool{
+		monday.Add(7*time.Hour + 59*time.Minute):  false,
+		monday.Add(8 * time.Hour):                 true,
+		monday.Add(17*time.Hour + 29*time.Minute): true,
+		monday.Add(17*time.Hour + 30*time.Minute): false,
+		monday.Add(5*24*time.Hour + 9*time.Hour):  false, // Saturday
+	}
+	for at, active := range tests {
+		if schedule.Active(at) != active {
+			t.Errorf("%s: expected active %v", at, active)
+		}
+	}
+}
+
+func TestBandwidthSchedule_ActivePastMidnight(t *testing.T) {
+	schedule := BandwidthSchedule{Enabled: true, Days: ScheduleEveryDay, Start: 22 * time.Hour, End: 6 * time.Hour}
+	monday := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	tests := map[time.Time]bool{
+		monday:                                    true,
+		monday.Add(5*time.Hour + 59*time.Minute):  true,
+		monday.Add(6 * time.Hour):                 false,
+		monday.Add(21*time.Hour + 59*time.Minute): false,
+		monday.Add(22 * time.Hour):                true,
+		monday.Add(23*time.Hour + 59*time.Minute): true,
+	}
+	for at, active := range tests {
+		if schedule.Active(at) != active {
+			t.Errorf("%s: expected active %v", at, active)
+		}
+	}
+
+	always := BandwidthSchedule{Enabled: true, Start: 8 * time.Hour, End: 8 * time.Hour}
+	if !always.Active(monday) || !always.Active(monday.Add(8*time.Hour)) {
+		t.Error("expected a schedule ending at its start to apply all day")
+	}
+}
+
+func TestBandwidthScheduleCtx(t *testing.T) {
+	var updates []map[string]interface{}
+	mockServer := newPreferencesServer(t, map[string]interface{}{
+		"scheduler_enabled":  false,
+		"schedule_from_hour": 8,
+		"schedule_from_min":  0,
+		"schedule_to_hour":   20,
+		"schedule_to_min":    0,
+		"scheduler_days":     0,
+	}, &updates)
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+	ctx := context.Background()
+
+	expected := BandwidthSchedule{Enabled: true, Days: ScheduleSunday, Start: 1*time.Hour + 15*time.Minute, End: 23*time.Hour + 45*time.Minute}
+	if err := client.SetBandwidthScheduleCtx(ctx, expected); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	schedule, err := client.GetBandwidthScheduleCtx(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if *schedule != expected {
+		t.Errorf("expected %+v, got %+v", expected, *schedule)
+	}
+
+	overnight := BandwidthSchedule{Enabled: true, Days: ScheduleWeekdays, Start: 22 * time.Hour, End: 6 * time.Hour}
+	if err := client.SetBandwidthScheduleCtx(ctx, overnight); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if schedule, err := client.GetBandwidthScheduleCtx(ctx); err != nil || *schedule != overnight {
+		t.Errorf("expected %+v, got %+v and %v", overnight, schedule, err)
+	}
+
+	for _, invalid := range []BandwidthSchedule{
+		{Start: 90 * time.Second, End: time.Hour},
+		{End: 24 * time.Hour},
+		{Days: 10},
+	} {
+		if err := client.SetBandwidthScheduleCtx(ctx, invalid); err == nil {
+			t.Errorf("expected error for %+v, got none", invalid)
+		}
+	}
+	if len(updates) != 2 {
+		t.Errorf("expected invalid schedules not to be sent, got %v", updates)
+	}
+}
@@ -0,0 +1,272 @@
+package qbittorrent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrOutOfScope is returned when a ScopedClient is asked to act on torrents outside its scope
+var ErrOutOfScope = errors.New("torrent outside client scope")
+
+// Scope restricts a ScopedClient to torrents in a category and/or carrying a tag.
+// Empty fields don't restrict.
+type Scope struct {
+	Category string
+	Tag      string
+}
+
+// contains reports whether a torrent with the given category and tags is in scope
+func (s Scope) contains(category string, tags []string) bool {
+	if s.Category != "" && category != s.Category {
+		return false
+	}
+	if s.Tag != "" && !slices.Contains(tags, s.Tag) {
+		return false
+	}
+	return true
+}
+
+// ScopedClient wraps a Client so that it only sees and mutates torrents within
+// a Scope, letting multi-tenant tools hand out safe handles per user.
+// A ScopedClient is safe for concurrent use, but SyncMainDataCtx tracks
+// membership across calls and expects a single sync loop per ScopedClient.
+type ScopedClient struct {
+	client *Client
+	scope  Scope
+
+	mu      sync.Mutex
+	members map[string]scopeMember // sync state per torrent hash
+}
+
+// scopeMember is the last known scope-relevant state of a torrent
+type scopeMember struct {
+	category string
+	tags     []string
+	inScope  bool
+}
+
+// NewScopedClient returns a client restricted to torrents within scope
+func NewScopedClient(client *Client, scope Scope) *ScopedClient {
+	return &ScopedClient{
+		client:  client,
+		scope:   scope,
+		members: make(map[string]scopeMember),
+	}
+}
+
+// Scope returns the scope of the client
+func (s *ScopedClient) Scope() Scope {
+	return s.scope
+}
+
+// TorrentsInfoCtx retrieves the torrents within scope. Category and Tag in
+// params must be empty or match the scope.
+func (s *ScopedClient) TorrentsInfoCtx(ctx context.Context, params ...*TorrentsInfoParams) ([]TorrentInfo, error) {
+	var p TorrentsInfoParams
+	if len(params) > 0 && params[0] != nil {
+		p = *params[0]
+	}
+	if p.Category != "" && s.scope.Category != "" && p.Category != s.scope.Category {
+		return nil, fmt.Errorf("category %q: %w", p.Category, ErrOutOfScope)
+	}
+	if p.Tag != "" && s.scope.Tag != "" && p.Tag != s.scope.Tag {
+		return nil, fmt.Errorf("tag %q: %w", p.Tag, ErrOutOfScope)
+	}
+	if s.scope.Category != "" {
+		p.Category = s.scope.Category
+	}
+	if s.scope.Tag != "" {
+		p.Tag = s.scope.Tag
+	}
+
+	torrents, err := s.client.TorrentsInfoCtx(ctx, &p)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter again in case the server ignores a filter it doesn't support
+	scoped := torrents[:0]
+	for _, torrent := range torrents {
+		if s.scope.contains(torrent.Category, torrent.Tags) {
+			scoped = append(scoped, torrent)
+		}
+	}
+	return scoped, nil
+}
+
+// SyncMainDataCtx retrieves the main data changes since rid, limited to the
+// torrents within scope. Torrents leaving the scope are reported in
+// TorrentsRemoved, and torrents entering it are reported with full info.
+func (s *ScopedClient) SyncMainDataCtx(ctx context.Context, rid int) (*MainData, error) {
+	data, raw, err := s.client.syncMainData(ctx, rid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Partial updates only carry changed fields, so look at which ones are present
+	var fields struct {
+		Torrents map[string]map[string]json.RawMessage `json:"torrents"`
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	s.mu.Lock()
+	if data.FullUpdate {
+		s.members = make(map[string]scopeMember)
+	}
+	var removed []string
+	for _, hash := range data.TorrentsRemoved {
+		if s.members[hash].inScope {
+			removed = append(removed, hash)
+		}
+		delete(s.members, hash)
+	}
+	var entered []string
+	for hash, torrent := range data.Torrents {
+		member, known := s.members[hash]
+		wasInScope := member.inScope
+		if _, ok := fields.Torrents[hash]["category"]; ok || !known {
+			member.category = torrent.Category
+		}
+		if _, ok := fields.Torrents[hash]["tags"]; ok || !known {
+			member.tags = torrent.Tags
+		}
+		member.inScope = s.scope.contains(member.category, member.tags)
+		s.members[hash] = member
+
+		switch {
+		case !member.inScope:
+			delete(data.Torrents, hash)
+			if wasInScope {
+				removed = append(removed, hash)
+			}
+		case known && !wasInScope:
+			entered = append(entered, hash)
+		}
+	}
+	data.TorrentsRemoved = removed
+	for tracker, hashes := range data.Trackers {
+		data.Trackers[tracker] = slices.DeleteFunc(hashes, func(hash InfoHash) bool {
+			return !s.members[string(hash)].inScope
+		})
+	}
+	s.mu.Unlock()
+
+	if s.scope.Category != "" {
+		for name := range data.Categories {
+			if name != s.scope.Category {
+				delete(data.Categories, name)
+			}
+		}
+	}
+
+	// Torrents entering the scope in a partial update lack most fields
+	if len(entered) > 0 {
+		torrents, err := s.client.TorrentsInfoCtx(ctx, &TorrentsInfoParams{Hashes: []string{strings.Join(entered, "|")}})
+		if err != nil {
+			return nil, err
+		}
+		for _, torrent := range torrents {
+			data.Torrents[string(torrent.Hash)] = torrent
+		}
+	}
+
+	return data, nil
+}
+
+// checkScope verifies that all of the "|" separated hashes are within scope
+func (s *ScopedClient) checkScope(ctx context.Context, hashes string) error {
+	requested := strings.Split(hashes, "|")
+	if slices.Contains(requested, "all") {
+		return fmt.Errorf("all torrents: %w", ErrOutOfScope)
+	}
+	torrents, err := s.TorrentsInfoCtx(ctx, &TorrentsInfoParams{Hashes: []string{hashes}})
+	if err != nil {
+		return err
+	}
+	found := make(map[string]bool, len(torrents))
+	for _, torrent := range torrents {
+		found[strings.ToLower(string(torrent.Hash))] = true
+	}
+	for _, hash := range requested {
+		if !found[strings.ToLower(hash)] {
+			return fmt.Errorf("torrent %s: %w", hash, ErrOutOfScope)
+		}
+	}
+	return nil
+}
+
+// TorrentsTrackersCtx retrieves the tracker info for a torrent within scope
+func (s *ScopedClient) TorrentsTrackersCtx(ctx context.Context, hash string) ([]TrackerInfo, error) {
+	if err := s.checkScope(ctx, hash); err != nil {
+		return nil, err
+	}
+	return s.client.TorrentsTrackersCtx(ctx, hash)
+}
+
+// TorrentsDeleteCtx deletes a torrent within scope
+func (s *ScopedClient) TorrentsDeleteCtx(ctx context.Context, infohash string) error {
+	if err := s.checkScope(ctx, infohash); err != nil {
+		return err
+	}
+	return s.client.TorrentsDeleteCtx(ctx, infohash)
+}
+
+// SetForceStartCtx enables force start for torrents within scope
+func (s *ScopedClient) SetForceStartCtx(ctx context.Context, hash string, value bool) error {
+	if err := s.checkScope(ctx, hash); err != nil {
+		return err
+	}
+	return s.client.SetForceStartCtx(ctx, hash, value)
+}
+
+// SetForceStartMapCtx sets the force start state of torrents within scope
+func (s *ScopedClient) SetForceStartMapCtx(ctx context.Context, values map[InfoHash]bool) error {
+	if len(values) == 0 {
+		return nil
+	}
+	hashes := make([]string, 0, len(values))
+	for hash := range values {
+		hashes = append(hashes, string(hash))
+	}
+	if err := s.checkScope(ctx, strings.Join(hashes, "|")); err != nil {
+		return err
+	}
+	return s.client.SetForceStartMapCtx(ctx, values)
+}
+
+// TorrentsSetLocationCtx moves the data of torrents within scope
+func (s *ScopedClient) TorrentsSetLocationCtx(ctx context.Context, hashes, location string) error {
+	if err := s.checkScope(ctx, hashes); err != nil {
+		return err
+	}
+	return s.client.TorrentsSetLocationCtx(ctx, hashes, location)
+}
+
+// TorrentsAddTagsCtx adds tags to torrents within scope
+func (s *ScopedClient) TorrentsAddTagsCtx(ctx context.Context, hashes, tags string) error {
+	if err := s.checkScope(ctx, hashes); err != nil {
+		return err
+	}
+	return s.client.TorrentsAddTagsCtx(ctx, hashes, tags)
+}
+
+// TorrentsRemoveTagsCtx removes tags from torrents within scope. Removing the
+// scope's own tag is refused since it would move the torrents out of scope,
+// and so is an empty list, which removes every tag.
+func (s *ScopedClient) TorrentsRemoveTagsCtx(ctx context.Context, hashes, tags string) error {
+	// The server trims the tag names
+	if s.scope.Tag != "" && (strings.TrimSpace(tags) == "" || slices.Contains(splitTags(tags), s.scope.Tag)) {
+		return fmt.Errorf("removing scope tag %q: %w", s.scope.Tag, ErrOutOfScope)
+	}
+	if err := s.checkScope(ctx, hashes); err != nil {
+		return err
+	}
+	return s.client.TorrentsRemoveTagsCtx(ctx, hashes, tags)
+}
@@ -0,0 +1,126 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newScopedTestServer(t *testing.T, deleted *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/torrents/info":
+			// Simulate a server that ignores the filters
+			switch r.URL.Query().Get("hashes") {
+			case "hashb":
+				w.Write([]byte(`[{"hash":"hashb","name":"b","category":"movies"}]`))
+			default:
+				w.Write([]byte(`[{"hash":"hasha","name":"a","category":"movies"},{"hash":"hashb","name":"b","category":"tv"}]`))
+			}
+		case "/api/v2/sync/maindata":
+			switch r.URL.Query().Get("rid") {
+			case "0":
+				w.Write([]byte(`{"rid":1,"full_update":true,
+					"torrents":{"hasha":{"name":"a","category":"movies"},"hashb":{"name":"b","category":"tv"}},
+					"categories":{"movies":{"name":"movies"},"tv":{"name":"tv"}},
+					"trackers":{"http://tracker":["hasha","hashb"]}}`))
+			case "1":
+				w.Write([]byte(`{"rid":2,
+					"torrents":{"hasha":{"category":"tv"},"hashb":{"category":"movies"},"hashc":{"name":"c","category":"tv"}}}`))
+			}
+		case "/api/v2/torrents/delete":
+			r.ParseForm()
+			*deleted = append(*deleted, r.PostForm.Get("hashes"))
+		}
+	}))
+}
+
+func TestScopedClient_TorrentsInfo(t *testing.T) {
+	mockServer := newScopedTestServer(t, nil)
+	defer mockServer.Close()
+
+	scoped := NewScopedClient(&Client{baseURL: mockServer.URL, client: mockServer.Client()}, Scope{Category: "movies"})
+
+	ctx := context.Background()
+	torrents, err := scoped.TorrentsInfoCtx(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(torrents) != 1 || torrents[0].Name != "a" {
+		t.Errorf("expected only torrent a, got %v", torrents)
+	}
+
+	_, err = scoped.TorrentsInfoCtx(ctx, &TorrentsInfoParams{Category: "tv"})
+	if !errors.Is(err, ErrOutOfScope) {
+		t.Errorf("expected ErrOutOfScope, got %v", err)
+	}
+}
+
+func TestScopedClient_SyncMainData(t *testing.T) {
+	mockServer := newScopedTestServer(t, nil)
+	defer mockServer.Close()
+
+	scoped := NewScopedClient(&Client{baseURL: mockServer.URL, client: mockServer.Client()}, Scope{Category: "movies"})
+
+	ctx := context.Background()
+	data, err := scoped.SyncMainDataCtx(ctx, 0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(data.Torrents) != 1 || data.Torrents["hasha"].Name != "a" {
+		t.Errorf("expected only torrent a, got %v", data.Torrents)
+	}
+	if len(data.Categories) != 1 || data.Categories["movies"] == nil {
+		t.Errorf("expected only the movies category, got %v", data.Categories)
+	}
+	if hashes := data.Trackers["http://tracker"]; len(hashes) != 1 || hashes[0] != "hasha" {
+		t.Errorf("expected tracker to list only hasha, got %v", hashes)
+	}
+
+	// a moves out of scope, b moves in and c is added outside of it
+	data, err = scoped.SyncMainDataCtx(ctx, data.Rid)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(data.TorrentsRemoved) != 1 || data.TorrentsRemoved[0] != "hasha" {
+		t.Errorf("expected hasha to be removed, got %v", data.TorrentsRemoved)
+	}
+	if len(data.Torrents) != 1 || data.Torrents["hashb"].Name != "b" {
+		t.Errorf("expected full info for torrent b, got %v", data.Torrents)
+	}
+}
+
+func TestScopedClient_Mutations(t *testing.T) {
+	var deleted []string
+	mockServer := newScopedTestServer(t, &deleted)
+	defer mockServer.Close()
+
+	scoped := NewScopedClient(&Client{baseURL: mockServer.URL, client: mockServer.Client()}, Scope{Category: "movies"})
+
+	ctx := context.Background()
+	if err := scoped.TorrentsDeleteCtx(ctx, "hasha"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := scoped.TorrentsDeleteCtx(ctx, "hasha|hashb"); !errors.Is(err, ErrOutOfScope) {
+		t.Errorf("expected ErrOutOfScope, got %v", err)
+	}
+	if err := scoped.TorrentsDeleteCtx(ctx, "all"); !errors.Is(err, ErrOutOfScope) {
+		t.Errorf("expected ErrOutOfScope, got %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "hasha" {
+		t.Errorf("expected only hasha to be deleted, got %v", deleted)
+	}
+
+	tagged := NewScopedClient(scoped.client, Scope{Tag: "user1"})
+	if err := tagged.TorrentsRemoveTagsCtx(ctx, "hasha", "other,user1"); !errors.Is(err, ErrOutOfScope) {
+		t.Errorf("expected ErrOutOfScope removing the scope tag, got %v", err)
+	}
+	for _, tags := range []string{"other, user1", " user1 ", "", " "} {
+		if err := tagged.TorrentsRemoveTagsCtx(ctx, "hasha", tags); !errors.Is(err, ErrOutOfScope) {
+			t.Errorf("%q: expected ErrOutOfScope removing the scope tag, got %v", tags, err)
+		}
+	}
+}
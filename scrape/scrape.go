@@ -0,0 +1,240 @@
+// Package scrape queries HTTP and UDP trackers directly for the swarm
+// statistics of torrents, independently of qBittorrent, e.g. to confirm that a
+// torrent is really dead before deleting it.
+package scrape
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cehbz/qbittorrent/bencode"
+)
+
+// DefaultTimeout bounds a scrape unless the context has an earlier deadline
+const DefaultTimeout = 15 * time.Second
+
+// MaxResponseSize limits the scrape responses of HTTP trackers, far above the
+// size of replies of about 50 bytes per torrent
+const MaxResponseSize = 1 << 20
+
+// ErrUnsupported is returned for trackers that don't support scraping
+var ErrUnsupported = errors.New("tracker doesn't support scraping")
+
+// Stats are the swarm statistics reported by a tracker for a torrent
+type Stats struct {
+	Seeders   int64
+	Completed int64 // number of times the torrent was downloaded
+	Leechers  int64
+}
+
+// Scraper scrapes trackers. The zero value is ready to use.
+type Scraper struct {
+	HTTPClient *http.Client // used for HTTP trackers, http.DefaultClient if nil
+	Timeout    time.Duration
+}
+
+// Scrape asks the tracker at announceURL for the statistics of the torrents
+// with the given hex infohashes. Torrents unknown to the tracker are missing
+// from the result.
+func Scrape(ctx context.Context, announceURL string, infohashes []string) (map[string]Stats, error) {
+	return (&Scraper{}).Scrape(ctx, announceURL, infohashes)
+}
+
+// Scrape asks the tracker at announceURL for the statistics of the torrents
+// with the given hex infohashes. Torrents unknown to the tracker are missing
+// from the result.
+func (s *Scraper) Scrape(ctx context.Context, announceURL string, infohashes []string) (map[string]Stats, error) {
+	u, err := url.Parse(announceURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tracker URL: %w", err)
+	}
+	hashes := make([][]byte, len(infohashes))
+	for i, infohash := range infohashes {
+		hash, err := hex.DecodeString(infohash)
+		if err != nil || len(hash) != 20 {
+			return nil, fmt.Errorf("invalid infohash %q", infohash)
+		}
+		hashes[i] = hash
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch u.Scheme {
+	case "http", "https":
+		return s.scrapeHTTP(ctx, u, hashes)
+	case "udp":
+		return scrapeUDP(ctx, u.Host, hashes)
+	default:
+		return nil, fmt.Errorf("%w: scheme %q", ErrUnsupported, u.Scheme)
+	}
+}
+
+// scrapeHTTP scrapes an HTTP tracker following BEP 48
+func (s *Scraper) scrapeHTTP(ctx context.Context, announce *url.URL, hashes [][]byte) (map[string]Stats, error) {
+	// The scrape URL is derived by replacing "announce" in the last path element
+	i := strings.LastIndex(announce.Path, "/")
+	if !strings.HasPrefix(announce.Path[i+1:], "announce") {
+		return nil, ErrUnsupported
+	}
+	scrapeURL := *announce
+	scrapeURL.Path = announce.Path[:i+1] + "scrape" + strings.TrimPrefix(announce.Path[i+1:], "announce")
+	query := scrapeURL.RawQuery
+	for _, hash := range hashes {
+		if query != "" {
+			query += "&"
+		}
+		query += "info_hash=" + url.QueryEscape(string(hash))
+	}
+	scrapeURL.RawQuery = query
+
+	req, err := http.NewRequestWithContext(ctx, "GET", scrapeURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("scrape error: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("scrape error: %w", err)
+	}
+	if len(body) > MaxResponseSize {
+		return nil, fmt.Errorf("scrape error: response exceeds %d bytes", MaxResponseSize)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("scrape error (%d): %s", resp.StatusCode, body)
+	}
+
+	v, err := bencode.Decode(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode scrape response: %w", err)
+	}
+	dict, _ := v.(map[string]interface{})
+	if reason, ok := dict["failure reason"].(string); ok {
+		return nil, fmt.Errorf("scrape error: %s", reason)
+	}
+	files, _ := dict["files"].(map[string]interface{})
+	stats := make(map[string]Stats, len(files))
+	for hash, f := range files {
+		file, _ := f.(map[string]interface{})
+		complete, _ := file["complete"].(int64)
+		downloaded, _ := file["downloaded"].(int64)
+		incomplete, _ := file["incomplete"].(int64)
+		stats[hex.EncodeToString([]byte(hash))] = Stats{Seeders: complete, Completed: downloaded, Leechers: incomplete}
+	}
+	return stats, nil
+}
+
+// UDP tracker protocol constants from BEP 15
+const (
+	udpProtocolID    = 0x41727101980
+	udpActionConnect = 0
+	udpActionScrape  = 2
+	udpActionError   = 3
+	udpMaxHashes     = 74 // per scrape request, to fit a packet
+)
+
+// scrapeUDP scrapes a UDP tracker following BEP 15
+func scrapeUDP(ctx context.Context, host string, hashes [][]byte) (map[string]Stats, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", host)
+	if err != nil {
+		return nil, fmt.Errorf("scrape error: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	var connect bytes.Buffer
+	binary.Write(&connect, binary.BigEndian, uint64(udpProtocolID))
+	binary.Write(&connect, binary.BigEndian, uint32(udpActionConnect))
+	resp, err := udpRoundTrip(conn, connect.Bytes(), udpActionConnect, 16)
+	if err != nil {
+		return nil, err
+	}
+	connectionID := binary.BigEndian.Uint64(resp[8:16])
+
+	stats := make(map[string]Stats, len(hashes))
+	for start := 0; start < len(hashes); start += udpMaxHashes {
+		batch := hashes[start:min(start+udpMaxHashes, len(hashes))]
+		var scrape bytes.Buffer
+		binary.Write(&scrape, binary.BigEndian, connectionID)
+		binary.Write(&scrape, binary.BigEndian, uint32(udpActionScrape))
+		for _, hash := range batch {
+			scrape.Write(hash)
+		}
+		resp, err := udpRoundTrip(conn, scrape.Bytes(), udpActionScrape, 8+12*len(batch))
+		if err != nil {
+			return nil, err
+		}
+		for i, hash := range batch {
+			entry := resp[8+12*i:]
+			stats[hex.EncodeToString(hash)] = Stats{
+				Seeders:   int64(binary.BigEndian.Uint32(entry[0:4])),
+				Completed: int64(binary.BigEndian.Uint32(entry[4:8])),
+				Leechers:  int64(binary.BigEndian.Uint32(entry[8:12])),
+			}
+		}
+	}
+	return stats, nil
+}
+
+// udpRoundTrip sends a request, whose action field is at offset 8, with a new
+// transaction ID and reads the response of at least size bytes
+func udpRoundTrip(conn net.Conn, req []byte, action uint32, size int) ([]byte, error) {
+	var transactionID [4]byte
+	if _, err := rand.Read(transactionID[:]); err != nil {
+		return nil, err
+	}
+	req = append(append(append([]byte{}, req[:12]...), transactionID[:]...), req[12:]...)
+	if _, err := conn.Write(req); err != nil {
+		return nil, fmt.Errorf("scrape error: %w", err)
+	}
+
+	buf := make([]byte, 2048)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, fmt.Errorf("scrape error: %w", err)
+		}
+		resp := buf[:n]
+		// Ignore stray responses to earlier requests
+		if n < 8 || !bytes.Equal(resp[4:8], transactionID[:]) {
+			continue
+		}
+		switch binary.BigEndian.Uint32(resp[0:4]) {
+		case action:
+			if n < size {
+				return nil, fmt.Errorf("scrape error: short response of %d bytes", n)
+			}
+			return resp, nil
+		case udpActionError:
+			return nil, fmt.Errorf("scrape error: %s", resp[8:])
+		default:
+			return nil, fmt.Errorf("scrape error: unexpected action %d", binary.BigEndian.Uint32(resp[0:4]))
+		}
+	}
+}
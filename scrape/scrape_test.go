@@ -0,0 +1,114 @@
+package scrape_test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cehbz/qbittorrent/bencode"
+	"github.com/cehbz/qbittorrent/scrape"
+)
+
+const (
+	hashA = "8d7a9c4fbd0e0ab2f6c6d7b1c4b0e8e3a5f1c2d3"
+	hashB = "c1f0a2e3b4d5c6e7f8091a2b3c4d5e6f7a8bc8d9"
+)
+
+func TestScrapeHTTP(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/PASSKEY/scrape" {
+			t.Errorf("expected scrape path, got %s", r.URL.Path)
+		}
+		files := map[string]interface{}{}
+		for _, infohash := range r.URL.Query()["info_hash"] {
+			if hex.EncodeToString([]byte(infohash)) == hashA {
+				files[infohash] = map[string]interface{}{"complete": int64(5), "downloaded": int64(50), "incomplete": int64(2)}
+			}
+		}
+		data, _ := bencode.Encode(map[string]interface{}{"files": files})
+		w.Write(data)
+	}))
+	defer mockServer.Close()
+
+	stats, err := scrape.Scrape(context.Background(), mockServer.URL+"/PASSKEY/announce", []string{hashA, hashB})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(stats) != 1 || stats[hashA] != (scrape.Stats{Seeders: 5, Completed: 50, Leechers: 2}) {
+		t.Errorf("expected stats for %s only, got %+v", hashA, stats)
+	}
+
+	if _, err := scrape.Scrape(context.Background(), mockServer.URL+"/tracker.php", []string{hashA}); !errors.Is(err, scrape.ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := scrape.Scrape(context.Background(), mockServer.URL+"/announce", []string{"nothex"}); err == nil {
+		t.Errorf("expected error for invalid infohash, got none")
+	}
+}
+
+func TestScrapeHTTP_TooLarge(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("d5:files" + strings.Repeat("l", scrape.MaxResponseSize)))
+	}))
+	defer mockServer.Close()
+
+	if _, err := scrape.Scrape(context.Background(), mockServer.URL+"/announce", []string{hashA}); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("expected the response to exceed the limit, got %v", err)
+	}
+}
+
+func TestScrapeUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer conn.Close()
+
+	const connectionID = 0x1122334455667788
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			req := buf[:n]
+			resp := make([]byte, 8, 64)
+			copy(resp[4:8], req[12:16]) // transaction ID
+			switch binary.BigEndian.Uint32(req[8:12]) {
+			case 0:
+				binary.BigEndian.PutUint32(resp[0:4], 0)
+				resp = binary.BigEndian.AppendUint64(resp, connectionID)
+			case 2:
+				if binary.BigEndian.Uint64(req[0:8]) != connectionID {
+					t.Errorf("expected connection ID to be sent back")
+				}
+				binary.BigEndian.PutUint32(resp[0:4], 2)
+				for i := 16; i+20 <= n; i += 20 {
+					seeders := uint32(0)
+					if hex.EncodeToString(req[i:i+20]) == hashA {
+						seeders = 7
+					}
+					resp = binary.BigEndian.AppendUint32(resp, seeders)
+					resp = binary.BigEndian.AppendUint32(resp, 70)
+					resp = binary.BigEndian.AppendUint32(resp, 1)
+				}
+			}
+			conn.WriteTo(resp, addr)
+		}
+	}()
+
+	stats, err := scrape.Scrape(context.Background(), "udp://"+conn.LocalAddr().String()+"/announce", []string{hashA, hashB})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if stats[hashA] != (scrape.Stats{Seeders: 7, Completed: 70, Leechers: 1}) || stats[hashB].Seeders != 0 || len(stats) != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,270 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SearchResult is a result of a search job from /api/v2/search/results
+type SearchResult struct {
+	DescrLink  string `json:"descrLink"`
+	FileName   string `json:"fileName"`
+	FileSize   int64  `json:"fileSize"`
+	FileURL    string `json:"fileUrl"`
+	NbLeechers int64  `json:"nbLeechers"`
+	NbSeeders  int64  `json:"nbSeeders"`
+	SiteURL    string `json:"siteUrl"`
+}
+
+// InfoHash returns the infohash of a magnet link result, lowercased, or an
+// empty string if the result isn't a magnet link or has none
+func (r SearchResult) InfoHash() string {
+	return magnetInfoHash(r.FileURL)
+}
+
+// magnetInfoHash returns the lowercased infohash of a magnet link, or an empty
+// string if link isn't a magnet link or has none
+func magnetInfoHash(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "magnet" {
+		return ""
+	}
+	for _, xt := range u.Query()["xt"] {
+		if hash, ok := strings.CutPrefix(xt, "urn:btih:"); ok {
+			return strings.ToLower(hash)
+		}
+	}
+	return ""
+}
+
+// SearchResults is a page of results of a search job
+type SearchResults struct {
+	Results []SearchResult `json:"results"`
+	Status  string         `json:"status"` // "Running" or "Stopped"
+	Total   int            `json:"total"`
+}
+
+// SearchStartCtx starts a search job and returns its ID. Plugins and category
+// default to "all" when empty.
+func (c *Client) SearchStartCtx(ctx context.Context, pattern string, plugins []string, category string) (int, error) {
+	if len(plugins) == 0 {
+		plugins = []string{"all"}
+	}
+	if category == "" {
+		category = "all"
+	}
+	data := url.Values{}
+	data.Set("pattern", pattern)
+	data.Set("plugins", strings.Join(plugins, "|"))
+	data.Set("category", category)
+
+	respData, err := c.doPostValuesCtx(ctx, "/api/v2/search/start", data)
+	if err != nil {
+		return 0, fmt.Errorf("SearchStart error: %w", err)
+	}
+
+	var job struct {
+		ID int `json:"id"`
+	}
+	if err := c.decodeJSON("/api/v2/search/start", respData, &job); err != nil {
+		return 0, fmt.Errorf("failed to decode search start response: %w", err)
+	}
+	return job.ID, nil
+}
+
+// SearchResultsCtx retrieves the results of a search job. A limit of zero
+// returns all results from offset.
+func (c *Client) SearchResultsCtx(ctx context.Context, id, limit, offset int) (*SearchResults, error) {
+	params := url.Values{}
+	params.Set("id", strconv.Itoa(id))
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+	if offset != 0 {
+		params.Set("offset", strconv.Itoa(offset))
+	}
+
+	respData, err := c.doGetCtx(ctx, "/api/v2/search/results", params)
+	if err != nil {
+		return nil, fmt.Errorf("SearchResults error: %w", err)
+	}
+
+	var results SearchResults
+	if err := c.decodeJSON("/api/v2/search/results", respData, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode search results response: %w", err)
+	}
+	return &results, nil
+}
+
+// SearchStopCtx stops a running search job
+func (c *Client) SearchStopCtx(ctx context.Context, id int) error {
+	data := url.Values{}
+	data.Set("id", strconv.Itoa(id))
+
+	if _, err := c.doPostValuesCtx(ctx, "/api/v2/search/stop", data); err != nil {
+		return fmt.Errorf("SearchStop error: %w", err)
+	}
+	return nil
+}
+
+// SearchDeleteCtx deletes a search job and its results
+func (c *Client) SearchDeleteCtx(ctx context.Context, id int) error {
+	data := url.Values{}
+	data.Set("id", strconv.Itoa(id))
+
+	if _, err := c.doPostValuesCtx(ctx, "/api/v2/search/delete", data); err != nil {
+		return fmt.Errorf("SearchDelete error: %w", err)
+	}
+	return nil
+}
+
+// SearchPolicy filters and ranks search results
+type SearchPolicy struct {
+	MinSeeders int64
+	MinSize    int64 // zero for no minimum
+	MaxSize    int64 // zero for no maximum
+	// Score ranks results, higher is better. By default results are ranked
+	// by seeders, then by leechers.
+	Score func(SearchResult) float64
+}
+
+// accepts reports whether a result passes the policy's filters
+func (p SearchPolicy) accepts(r SearchResult) bool {
+	return r.NbSeeders >= p.MinSeeders &&
+		(p.MinSize <= 0 || r.FileSize >= p.MinSize) &&
+		(p.MaxSize <= 0 || r.FileSize <= p.MaxSize)
+}
+
+// score returns the policy's score of a result
+func (p SearchPolicy) score(r SearchResult) float64 {
+	if p.Score != nil {
+		return p.Score(r)
+	}
+	// Leechers only break ties between results with equal seeders
+	return float64(r.NbSeeders) + float64(r.NbLeechers)/(float64(r.NbLeechers)+1)
+}
+
+// RankSearchResults removes duplicates and results rejected by the policy and
+// sorts the rest best first. Results are duplicates if they have the same
+// infohash, or the same size and a similar name, as the same torrent is
+// often listed by several sites. Of duplicates, the best scored one is kept.
+func RankSearchResults(results []SearchResult, policy SearchPolicy) []SearchResult {
+	var accepted []SearchResult
+	for _, r := range results {
+		if policy.accepts(r) {
+			accepted = append(accepted, r)
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return policy.score(accepted[i]) > policy.score(accepted[j])
+	})
+
+	var ranked []SearchResult
+	for _, r := range accepted {
+		duplicate := false
+		for _, kept := range ranked {
+			if duplicateResults(r, kept) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			ranked = append(ranked, r)
+		}
+	}
+	return ranked
+}
+
+// duplicateResults reports whether two results are likely the same torrent
+func duplicateResults(a, b SearchResult) bool {
+	if ha, hb := a.InfoHash(), b.InfoHash(); ha != "" && hb != "" {
+		return ha == hb
+	}
+	return a.FileSize == b.FileSize && nameSimilarity(a.FileName, b.FileName) >= 0.8
+}
+
+// nameSimilarity returns the Jaccard similarity of the words of two names,
+// ignoring case and punctuation such as dots used instead of spaces
+func nameSimilarity(a, b string) float64 {
+	words := func(s string) map[string]bool {
+		set := make(map[string]bool)
+		for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			set[w] = true
+		}
+		return set
+	}
+	wa, wb := words(a), words(b)
+	if len(wa) == 0 && len(wb) == 0 {
+		return 1
+	}
+	common := 0
+	for w := range wa {
+		if wb[w] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(wa)+len(wb)-common)
+}
+
+// ErrNoSearchResults is returned by SearchAndAddCtx when no result passes the policy
+var ErrNoSearchResults = errors.New("no acceptable search results")
+
+// searchPollInterval is how often SearchAndAddCtx checks on a search job
+var searchPollInterval = time.Second
+
+// SearchAndAddCtx searches for pattern with the given plugins, waits for the
+// search to finish, and adds the best result according to policy. The search
+// job is deleted afterwards, even if ctx is done first.
+func (c *Client) SearchAndAddCtx(ctx context.Context, pattern string, plugins []string, policy SearchPolicy, params ...*TorrentsAddParams) (*SearchResult, error) {
+	id, err := c.SearchStartCtx(ctx, pattern, plugins, "")
+	if err != nil {
+		return nil, err
+	}
+	// Clean up even if ctx is done
+	defer c.SearchDeleteCtx(context.WithoutCancel(ctx), id)
+
+	results, err := c.waitForSearch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ranked := RankSearchResults(results, policy)
+	if len(ranked) == 0 {
+		return nil, ErrNoSearchResults
+	}
+	best := ranked[0]
+	if err := c.TorrentsAddURLsCtx(ctx, []string{best.FileURL}, params...); err != nil {
+		return nil, err
+	}
+	return &best, nil
+}
+
+// waitForSearch polls a search job until it stops and returns its results
+func (c *Client) waitForSearch(ctx context.Context, id int) ([]SearchResult, error) {
+	ticker := c.Clock().NewTicker(searchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		results, err := c.SearchResultsCtx(ctx, id, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		if results.Status == "Stopped" {
+			return results.Results, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C():
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRankSearchResults(t *testing.T) {
+	results := []SearchResult{
+		{FileName: "Some.Linux.ISO.2024", FileSize: 100, NbSeeders: 10, FileURL: "https://site-a/1.torrent"},
+		{FileName: "some linux iso 2024", FileSize: 100, NbSeeders: 50, FileURL: "https://site-b/1.torrent"},
+		{FileName: "Other", FileSize: 200, NbSeeders: 30, FileURL: "magnet:?xt=urn:btih:ABCDEF&dn=Other"},
+		{FileName: "Other (mirror)", FileSize: 200, NbSeeders: 5, FileURL: "magnet:?xt=urn:btih:abcdef"},
+		{FileName: "Dead", FileSize: 100, NbSeeders: 0},
+		{FileName: "Huge", FileSize: 1 << 40, NbSeeders: 1000},
+	}
+
+	ranked := RankSearchResults(results, SearchPolicy{MinSeeders: 1, MaxSize: 1 << 30})
+	if len(ranked) != 2 {
+		t.Fatalf("expected 2 results, got %+v", ranked)
+	}
+	if ranked[0].FileURL != "https://site-b/1.torrent" || ranked[1].FileURL != "magnet:?xt=urn:btih:ABCDEF&dn=Other" {
+		t.Errorf("expected best scored duplicates first, got %+v", ranked)
+	}
+
+	// A custom score prefers smaller results
+	ranked = RankSearchResults(results, SearchPolicy{MinSeeders: 1, Score: func(r SearchResult) float64 { return -float64(r.FileSize) }})
+	if ranked[0].FileSize != 100 {
+		t.Errorf("expected smallest result first, got %+v", ranked[0])
+	}
+}
+
+func TestSearchAndAdd(t *testing.T) {
+	searchPollInterval = time.Millisecond
+	defer func() { searchPollInterval = time.Second }()
+
+	var polls int
+	var added, deleted string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/search/start":
+			if r.FormValue("pattern") != "linux" || r.FormValue("plugins") != "all" {
+				t.Errorf("unexpected search %v", r.Form)
+			}
+			w.Write([]byte(`{"id":12}`))
+		case "/api/v2/search/results":
+			polls++
+			status := "Running"
+			if polls > 1 {
+				status = "Stopped"
+			}
+			w.Write([]byte(`{"status":"` + status + `","total":2,"results":[
+				{"fileName":"linux a","fileSize":10,"fileUrl":"https://a/1.torrent","nbSeeders":3},
+				{"fileName":"linux b","fileSize":20,"fileUrl":"https://b/2.torrent","nbSeeders":9}]}`))
+		case "/api/v2/torrents/add":
+			r.ParseMultipartForm(1 << 20)
+			added = r.FormValue("urls") + " " + r.FormValue("category")
+		case "/api/v2/search/delete":
+			deleted = r.FormValue("id")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	best, err := client.SearchAndAddCtx(context.Background(), "linux", nil, SearchPolicy{}, &TorrentsAddParams{Category: "iso"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if best.FileName != "linux b" || added != "https://b/2.torrent iso" {
+		t.Errorf("expected best result to be added, got %+v and %q", best, added)
+	}
+	if polls != 2 || deleted != "12" {
+		t.Errorf("expected search to be polled until stopped and deleted, got %d polls and deleted %q", polls, deleted)
+	}
+
+	_, err = client.SearchAndAddCtx(context.Background(), "linux", nil, SearchPolicy{MinSeeders: 100})
+	if !errors.Is(err, ErrNoSearchResults) {
+		t.Errorf("expected ErrNoSearchResults, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package qbittorrent
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultSeedingRateWindow is the upload rate window used by NewSeedingGoals
+const DefaultSeedingRateWindow = time.Hour
+
+// SeedingGoal is the share ratio and seeding time a torrent should reach.
+// Zero values mean no goal.
+type SeedingGoal struct {
+	Ratio       float64
+	SeedingTime time.Duration
+}
+
+// ShareLimitGoal returns the goal set by the share limits of torrent, its own
+// or the global ones
+func ShareLimitGoal(torrent TorrentInfo) SeedingGoal {
+	var goal SeedingGoal
+	// The max_ fields hold the effective limits, the others may be -2 for the
+	// global limit or -1 for none
+	if torrent.MaxRatio > 0 {
+		goal.Ratio = torrent.MaxRatio
+	} else if torrent.RatioLimit > 0 {
+		goal.Ratio = torrent.RatioLimit
+	}
+	if torrent.MaxSeedingTime > 0 {
+		goal.SeedingTime = time.Duration(torrent.MaxSeedingTime) * time.Minute
+	} else if torrent.SeedingTimeLimit > 0 {
+		goal.SeedingTime = time.Duration(torrent.SeedingTimeLimit) * time.Minute
+	}
+	return goal
+}
+
+// GoalProgress is the progress of a torrent toward its SeedingGoal
+type GoalProgress struct {
+	Hash        InfoHash
+	Name        string
+	Goal        SeedingGoal
+	Ratio       float64
+	SeedingTime time.Duration
+	UploadRate  float64       // recent average in bytes per second
+	Progress    float64       // toward the goal reached first, from 0 to 1
+	ETA         time.Duration // until a goal is reached, or -1 if unknown
+}
+
+// Met reports whether the torrent reached its goal
+func (p GoalProgress) Met() bool {
+	return p.Progress >= 1
+}
+
+// SeedingGoals tracks the progress of torrents toward their seeding goals.
+// Like SessionStats it is fed by polling, e.g. from a Watcher, and computes
+// the upload rates for its estimates from recent observations.
+// A SeedingGoals is safe for concurrent use.
+type SeedingGoals struct {
+	mu       sync.Mutex
+	window   time.Duration
+	policy   func(TorrentInfo) SeedingGoal
+	torrents map[InfoHash]*seedingTorrent
+}
+
+type seedingTorrent struct {
+	info    TorrentInfo
+	samples []uploadSample // ordered by time, within the window
+}
+
+type uploadSample struct {
+	at       time.Time
+	uploaded int64
+}
+
+// NewSeedingGoals returns a tracker estimating upload rates over window, or
+// DefaultSeedingRateWindow if it isn't positive. policy returns the goal of a
+// torrent; without it the share limits apply, see ShareLimitGoal.
+func NewSeedingGoals(window time.Duration, policy func(TorrentInfo) SeedingGoal) *SeedingGoals {
+	if window <= 0 {
+		window = DefaultSeedingRateWindow
+	}
+	if policy == nil {
+		policy = ShareLimitGoal
+	}
+	return &SeedingGoals{
+		window:   window,
+		policy:   policy,
+		torrents: make(map[InfoHash]*seedingTorrent),
+	}
+}
+
+// Observe records the torrents sampled at the given time. Torrents missing
+// from the list are forgotten.
+func (g *SeedingGoals) Observe(at time.Time, torrents []TorrentInfo) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	seen := make(map[InfoHash]bool, len(torrents))
+	for _, torrent := range torrents {
+		seen[torrent.Hash] = true
+		t := g.torrents[torrent.Hash]
+		if t == nil {
+			t = &seedingTorrent{}
+			g.torrents[torrent.Hash] = t
+		}
+		if n := len(t.samples); n > 0 && at.Before(t.samples[n-1].at) {
+			continue
+		}
+		t.info = torrent
+		t.samples = append(t.samples, uploadSample{at: at, uploaded: torrent.Uploaded})
+
+		// Keep one sample at or before the cutoff as the baseline for the window
+		cutoff := at.Add(-g.window)
+		drop := 0
+		for drop+1 < len(t.samples) && !t.samples[drop+1].at.After(cutoff) {
+			drop++
+		}
+		t.samples = t.samples[drop:]
+	}
+	for hash := range g.torrents {
+		if !seen[hash] {
+			delete(g.torrents, hash)
+		}
+	}
+}
+
+// Progress returns the progress of the torrents that have a goal, sorted by
+// ETA with unknown ones last
+func (g *SeedingGoals) Progress() []GoalProgress {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var progress []GoalProgress
+	for _, t := range g.torrents {
+		goal := g.policy(t.info)
+		if goal.Ratio <= 0 && goal.SeedingTime <= 0 {
+			continue
+		}
+		progress = append(progress, t.progress(goal))
+	}
+	sort.Slice(progress, func(i, j int) bool {
+		a, b := progress[i].ETA, progress[j].ETA
+		if (a < 0) != (b < 0) {
+			return b < 0
+		}
+		if a != b {
+			return a < b
+		}
+		return progress[i].Hash < progress[j].Hash
+	})
+	return progress
+}
+
+// progress computes the progress toward goal. qBittorrent applies the share
+// limit reached first, so the goal closest to being reached counts.
+func (t *seedingTorrent) progress(goal SeedingGoal) GoalProgress {
+	first, last := t.samples[0], t.samples[len(t.samples)-1]
+	p := GoalProgress{
+		Hash:        t.info.Hash,
+		Name:        t.info.Name,
+		Goal:        goal,
+		Ratio:       t.info.Ratio,
+		SeedingTime: time.Duration(t.info.SeedingTime) * time.Second,
+		UploadRate:  rate(max(last.uploaded-first.uploaded, 0), last.at.Sub(first.at)),
+		ETA:         -1,
+	}
+
+	if goal.SeedingTime > 0 {
+		p.Progress = clamp01(p.SeedingTime.Seconds() / goal.SeedingTime.Seconds())
+		// Seeding time only passes once the download is complete
+		if t.info.Progress >= 1 {
+			p.ETA = max(goal.SeedingTime-p.SeedingTime, 0)
+		}
+	}
+	if goal.Ratio > 0 {
+		p.Progress = math.Max(p.Progress, clamp01(p.Ratio/goal.Ratio))
+		if eta, ok := t.ratioETA(goal.Ratio, p.UploadRate); ok && (p.ETA < 0 || eta < p.ETA) {
+			p.ETA = eta
+		}
+	}
+	return p
+}
+
+// ratioETA estimates the time to reach ratio at the upload rate
+func (t *seedingTorrent) ratioETA(ratio, uploadRate float64) (time.Duration, bool) {
+	if t.info.Ratio >= ratio {
+		return 0, true
+	}
+	// The ratio is relative to the downloaded bytes, or the size of the
+	// content if it was added complete
+	basis := max(t.info.Downloaded, t.info.Completed)
+	if basis <= 0 || uploadRate <= 0 {
+		return 0, false
+	}
+	remaining := ratio*float64(basis) - float64(t.info.Uploaded)
+	if remaining <= 0 {
+		return 0, true
+	}
+	return time.Duration(remaining / uploadRate * float64(time.Second)), true
+}
@@ -0,0 +1,71 @@
+package qbittorrent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShareLimitGoal(t *testing.T) {
+	tests := []struct {
+		torrent  TorrentInfo
+		expected SeedingGoal
+	}{
+		{TorrentInfo{RatioLimit: -2, MaxRatio: 2, SeedingTimeLimit: -2, MaxSeedingTime: 60}, SeedingGoal{Ratio: 2, SeedingTime: time.Hour}},
+		{TorrentInfo{RatioLimit: 1.5, MaxRatio: -1, SeedingTimeLimit: 30, MaxSeedingTime: -1}, SeedingGoal{Ratio: 1.5, SeedingTime: 30 * time.Minute}},
+		{TorrentInfo{RatioLimit: -1, MaxRatio: -1, SeedingTimeLimit: -1, MaxSeedingTime: -1}, SeedingGoal{}},
+	}
+	for _, tt := range tests {
+		if goal := ShareLimitGoal(tt.torrent); goal != tt.expected {
+			t.Errorf("expected %+v, got %+v", tt.expected, goal)
+		}
+	}
+}
+
+func TestSeedingGoals(t *testing.T) {
+	goals := NewSeedingGoals(time.Hour, nil)
+	start := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+	observe := func(at time.Time, uploadedA int64) {
+		goals.Observe(at, []TorrentInfo{
+			// 1000 bytes downloaded, ratio goal 2: 2000 bytes to upload
+			{Hash: "a", Name: "by ratio", Progress: 1, Downloaded: 1000, Uploaded: uploadedA, Ratio: float64(uploadedA) / 1000, MaxRatio: 2},
+			// Seeded 30 of 60 minutes, uploading nothing
+			{Hash: "b", Name: "by time", Progress: 1, Downloaded: 1000, SeedingTime: 1800, MaxSeedingTime: 60, MaxRatio: -1},
+			{Hash: "c", Name: "no goal", MaxRatio: -1, MaxSeedingTime: -1},
+		})
+	}
+	observe(start, 1000)
+	observe(start.Add(100*time.Second), 1500)
+
+	progress := goals.Progress()
+	if len(progress) != 2 {
+		t.Fatalf("expected progress for the torrents with goals, got %+v", progress)
+	}
+	byRatio, byTime := progress[0], progress[1]
+	if byRatio.Hash != "a" || byTime.Hash != "b" {
+		t.Fatalf("expected the torrents ordered by ETA, got %s and %s", byRatio.Hash, byTime.Hash)
+	}
+	if byRatio.UploadRate != 5 {
+		t.Errorf("expected 5 bytes/s, got %v", byRatio.UploadRate)
+	}
+	if byRatio.ETA != 100*time.Second || byRatio.Progress != 0.75 {
+		t.Errorf("expected 3/4 of the ratio with 100s to go, got %v and %v", byRatio.Progress, byRatio.ETA)
+	}
+	if byTime.ETA != 30*time.Minute || byTime.Progress != 0.5 {
+		t.Errorf("expected half of the seeding time with 30 minutes to go, got %v and %v", byTime.Progress, byTime.ETA)
+	}
+
+	goals.Observe(start.Add(200*time.Second), nil)
+	if progress := goals.Progress(); len(progress) != 0 {
+		t.Errorf("expected removed torrents to be forgotten, got %+v", progress)
+	}
+}
+
+func TestSeedingGoals_UnknownETA(t *testing.T) {
+	goals := NewSeedingGoals(0, func(TorrentInfo) SeedingGoal { return SeedingGoal{Ratio: 1} })
+	goals.Observe(time.Now(), []TorrentInfo{{Hash: "a", Downloaded: 1000, Ratio: 0.2, Uploaded: 200}})
+
+	progress := goals.Progress()
+	if len(progress) != 1 || progress[0].ETA != -1 || progress[0].Met() {
+		t.Errorf("expected an unknown ETA without an upload rate, got %+v", progress)
+	}
+}
@@ -0,0 +1,166 @@
+package qbittorrent
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultStatsResolution is the bucket size used by NewSessionStats
+const DefaultStatsResolution = time.Minute
+
+// TransferDelta is the amount transferred over a period
+type TransferDelta struct {
+	Since      time.Time
+	Until      time.Time
+	Downloaded int64
+	Uploaded   int64
+}
+
+// DownloadRate returns the average download rate in bytes per second
+func (d TransferDelta) DownloadRate() float64 {
+	return rate(d.Downloaded, d.Until.Sub(d.Since))
+}
+
+// UploadRate returns the average upload rate in bytes per second
+func (d TransferDelta) UploadRate() float64 {
+	return rate(d.Uploaded, d.Until.Sub(d.Since))
+}
+
+func rate(bytes int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(bytes) / elapsed.Seconds()
+}
+
+// SessionStats aggregates the transfer counters of ServerState across polls,
+// so upload slowdowns can be detected without an external time-series
+// database. At most one sample per resolution period is kept for the
+// retention period. Counter resets, e.g. when statistics are cleared, are not
+// counted as negative transfers; the bytes counted since a reset are.
+// A SessionStats is safe for concurrent use.
+type SessionStats struct {
+	mu         sync.Mutex
+	resolution time.Duration
+	retention  time.Duration
+	samples    []counterSample // ordered by time, one per resolution period
+	latest     counterSample
+	observed   bool
+	allTimeDL  int64
+	allTimeUL  int64
+	sessionDL  int64
+	sessionUL  int64
+}
+
+// counterSample holds the bytes transferred since the first observation
+type counterSample struct {
+	at         time.Time
+	downloaded int64
+	uploaded   int64
+}
+
+// NewSessionStats returns statistics kept for retention, e.g. 8 days for weekly deltas
+func NewSessionStats(retention time.Duration) *SessionStats {
+	return NewSessionStatsWithResolution(retention, DefaultStatsResolution)
+}
+
+// NewSessionStatsWithResolution returns statistics kept for retention with
+// one sample per resolution period
+func NewSessionStatsWithResolution(retention, resolution time.Duration) *SessionStats {
+	if resolution <= 0 {
+		resolution = DefaultStatsResolution
+	}
+	return &SessionStats{
+		resolution: resolution,
+		retention:  retention,
+	}
+}
+
+// Observe records the counters of a server state sampled at the given time
+func (s *SessionStats) Observe(at time.Time, state ServerState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.observed && at.Before(s.latest.at) {
+		return
+	}
+	if s.observed {
+		s.latest = counterSample{
+			at:         at,
+			downloaded: s.latest.downloaded + increment(s.allTimeDL, state.AllTimeDL),
+			uploaded:   s.latest.uploaded + increment(s.allTimeUL, state.AllTimeUL),
+		}
+	} else {
+		s.observed = true
+		s.latest = counterSample{at: at}
+	}
+	s.allTimeDL, s.allTimeUL = state.AllTimeDL, state.AllTimeUL
+	s.sessionDL, s.sessionUL = state.DLInfoData, state.UpInfoData
+
+	if n := len(s.samples); n == 0 || !s.samples[n-1].at.Truncate(s.resolution).Equal(at.Truncate(s.resolution)) {
+		s.samples = append(s.samples, s.latest)
+	}
+
+	// Drop samples that fell out of the retention period, keeping one at or
+	// before the cutoff as the baseline for the full period
+	cutoff := at.Add(-s.retention)
+	drop := 0
+	for drop+1 < len(s.samples) && !s.samples[drop+1].at.After(cutoff) {
+		drop++
+	}
+	s.samples = s.samples[drop:]
+}
+
+// increment returns the growth of a counter. A decrease is a reset, after
+// which the counter holds the bytes transferred since.
+func increment(previous, current int64) int64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
+
+// Delta returns the bytes transferred during the window ending at the latest
+// observation. The window starts at the latest sample at or before its
+// beginning, or at the oldest sample if the window exceeds the observed period.
+func (s *SessionStats) Delta(window time.Duration) TransferDelta {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.samples) == 0 {
+		return TransferDelta{}
+	}
+	since := s.latest.at.Add(-window)
+	baseline := s.samples[0]
+	for _, sample := range s.samples[1:] {
+		if sample.at.After(since) {
+			break
+		}
+		baseline = sample
+	}
+	return TransferDelta{
+		Since:      baseline.at,
+		Until:      s.latest.at,
+		Downloaded: s.latest.downloaded - baseline.downloaded,
+		Uploaded:   s.latest.uploaded - baseline.uploaded,
+	}
+}
+
+// Daily returns the bytes transferred in the last 24 hours
+func (s *SessionStats) Daily() TransferDelta {
+	return s.Delta(24 * time.Hour)
+}
+
+// Weekly returns the bytes transferred in the last 7 days
+func (s *SessionStats) Weekly() TransferDelta {
+	return s.Delta(7 * 24 * time.Hour)
+}
+
+// Totals returns the latest all-time and session counters
+func (s *SessionStats) Totals() (allTime, session TransferDelta) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	allTime = TransferDelta{Until: s.latest.at, Downloaded: s.allTimeDL, Uploaded: s.allTimeUL}
+	session = TransferDelta{Until: s.latest.at, Downloaded: s.sessionDL, Uploaded: s.sessionUL}
+	return allTime, session
+}
@@ -0,0 +1,88 @@
+package qbittorrent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionStats(t *testing.T) {
+	stats := NewSessionStats(8 * 24 * time.Hour)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Upload 1 MiB per hour for two days, download 2 MiB per hour
+	for h := 0; h <= 48; h++ {
+		stats.Observe(start.Add(time.Duration(h)*time.Hour), ServerState{
+			AllTimeDL:  int64(h) * 2 << 20,
+			AllTimeUL:  int64(h) << 20,
+			DLInfoData: int64(h) * 2 << 20,
+			UpInfoData: int64(h) << 20,
+		})
+	}
+
+	daily := stats.Daily()
+	if daily.Uploaded != 24<<20 || daily.Downloaded != 48<<20 {
+		t.Errorf("expected 24 MiB up and 48 MiB down in the last day, got %d and %d", daily.Uploaded, daily.Downloaded)
+	}
+	if rate := daily.UploadRate(); rate != float64(1<<20)/3600 {
+		t.Errorf("expected upload rate of 1 MiB/h, got %f B/s", rate)
+	}
+
+	// The weekly window is limited to the two observed days
+	weekly := stats.Weekly()
+	if weekly.Uploaded != 48<<20 || !weekly.Since.Equal(start) {
+		t.Errorf("expected 48 MiB since start, got %d since %v", weekly.Uploaded, weekly.Since)
+	}
+
+	allTime, session := stats.Totals()
+	if allTime.Uploaded != 48<<20 || session.Downloaded != 96<<20 {
+		t.Errorf("unexpected totals %+v %+v", allTime, session)
+	}
+}
+
+func TestSessionStats_CounterReset(t *testing.T) {
+	stats := NewSessionStatsWithResolution(time.Hour, time.Second)
+	start := time.Unix(1700000000, 0)
+
+	stats.Observe(start, ServerState{AllTimeUL: 1000})
+	stats.Observe(start.Add(time.Second), ServerState{AllTimeUL: 1500})
+	stats.Observe(start.Add(2*time.Second), ServerState{AllTimeUL: 100}) // statistics cleared
+	stats.Observe(start.Add(3*time.Second), ServerState{AllTimeUL: 400})
+
+	if delta := stats.Delta(time.Hour); delta.Uploaded != 900 {
+		t.Errorf("expected 900 bytes uploaded across the reset, got %d", delta.Uploaded)
+	}
+}
+
+func TestSessionStats_ResetInWindow(t *testing.T) {
+	stats := NewSessionStats(8 * 24 * time.Hour)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// 1 MiB per hour, with the counters restarting from zero after 30 hours
+	for h := 0; h <= 48; h++ {
+		uploaded := int64(h) << 20
+		if h > 30 {
+			uploaded = int64(h-30) << 20
+		}
+		stats.Observe(start.Add(time.Duration(h)*time.Hour), ServerState{AllTimeUL: uploaded, UpInfoData: uploaded})
+	}
+
+	// The traffic since the restart counts, so the rate is unchanged
+	if daily := stats.Daily(); daily.Uploaded != 24<<20 {
+		t.Errorf("expected 24 MiB uploaded in the last day, got %d", daily.Uploaded)
+	}
+}
+
+func TestSessionStats_Retention(t *testing.T) {
+	stats := NewSessionStatsWithResolution(10*time.Minute, time.Minute)
+	start := time.Unix(1700000000, 0).Truncate(time.Minute)
+
+	for m := 0; m <= 60; m++ {
+		stats.Observe(start.Add(time.Duration(m)*time.Minute), ServerState{AllTimeUL: int64(m) * 100})
+	}
+	if len(stats.samples) > 11 {
+		t.Errorf("expected old samples to be dropped, got %d", len(stats.samples))
+	}
+	if delta := stats.Delta(5 * time.Minute); delta.Uploaded != 500 {
+		t.Errorf("expected 500 bytes in the last 5 minutes, got %d", delta.Uploaded)
+	}
+}
@@ -0,0 +1,179 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshot is a consistent view of all torrents at one point in time
+type Snapshot struct {
+	At       time.Time     `json:"at"`
+	Rid      int           `json:"rid"`      // response ID of the sync data, see SyncMainDataCtx
+	Torrents []TorrentInfo `json:"torrents"` // sorted by hash
+}
+
+// SnapshotTorrentsCtx captures all torrents from a single full sync update.
+// Unlike paging through TorrentsInfoCtx, torrents added or removed meanwhile
+// can't be seen twice or missed. Rid allows following up with incremental
+// SyncMainDataCtx requests.
+func (c *Client) SnapshotTorrentsCtx(ctx context.Context) (*Snapshot, error) {
+	at := c.Clock().Now()
+	data, err := c.SyncMainDataCtx(ctx, 0)
+	if err != nil {
+		return nil, fmt.Errorf("SnapshotTorrents error: %w", err)
+	}
+
+	snapshot := &Snapshot{At: at, Rid: data.Rid, Torrents: make([]TorrentInfo, 0, len(data.Torrents))}
+	for hash, torrent := range data.Torrents {
+		// The sync API keys torrents by hash and leaves out the field
+		torrent.Hash = InfoHash(hash)
+		snapshot.Torrents = append(snapshot.Torrents, torrent)
+	}
+	sort.Slice(snapshot.Torrents, func(i, j int) bool { return snapshot.Torrents[i].Hash < snapshot.Torrents[j].Hash })
+	return snapshot, nil
+}
+
+// Changelog lists the changes between two snapshots, e.g. for nightly
+// reports or detecting drift. Torrents are listed in the order of the
+// snapshots, by hash.
+type Changelog struct {
+	From, To      time.Time
+	Added         []TorrentInfo
+	Removed       []TorrentInfo
+	StateChanges  []StateChange
+	Moved         []PathChange
+	Retagged      []TagChange
+	Recategorized []CategoryChange
+}
+
+// StateChange is a torrent that changed state
+type StateChange struct {
+	Hash     InfoHash
+	Name     string
+	From, To TorrentState
+}
+
+// PathChange is a torrent whose save path changed
+type PathChange struct {
+	Hash     InfoHash
+	Name     string
+	From, To string
+}
+
+// TagChange is a torrent whose tags changed
+type TagChange struct {
+	Hash           InfoHash
+	Name           string
+	Added, Removed []string
+}
+
+// CategoryChange is a torrent whose category changed
+type CategoryChange struct {
+	Hash     InfoHash
+	Name     string
+	From, To string
+}
+
+// DiffSnapshots returns the changes from one snapshot to a later one
+func DiffSnapshots(from, to *Snapshot) Changelog {
+	log := Changelog{From: from.At, To: to.At}
+	previous := make(map[InfoHash]TorrentInfo, len(from.Torrents))
+	for _, torrent := range from.Torrents {
+		previous[torrent.Hash] = torrent
+	}
+
+	for _, torrent := range to.Torrents {
+		old, ok := previous[torrent.Hash]
+		if !ok {
+			log.Added = append(log.Added, torrent)
+			continue
+		}
+		delete(previous, torrent.Hash)
+
+		if old.State != torrent.State {
+			log.StateChanges = append(log.StateChanges, StateChange{Hash: torrent.Hash, Name: torrent.Name, From: TorrentState(old.State), To: TorrentState(torrent.State)})
+		}
+		if old.SavePath != torrent.SavePath {
+			log.Moved = append(log.Moved, PathChange{Hash: torrent.Hash, Name: torrent.Name, From: old.SavePath, To: torrent.SavePath})
+		}
+		if added, removed := tagChanges(old.Tags, torrent.Tags); len(added) > 0 || len(removed) > 0 {
+			log.Retagged = append(log.Retagged, TagChange{Hash: torrent.Hash, Name: torrent.Name, Added: added, Removed: removed})
+		}
+		if old.Category != torrent.Category {
+			log.Recategorized = append(log.Recategorized, CategoryChange{Hash: torrent.Hash, Name: torrent.Name, From: old.Category, To: torrent.Category})
+		}
+	}
+	for _, torrent := range from.Torrents {
+		if _, ok := previous[torrent.Hash]; ok {
+			log.Removed = append(log.Removed, torrent)
+		}
+	}
+
+	return log
+}
+
+// tagChanges returns the sorted tags only in current and only in previous
+func tagChanges(previous, current []string) (added, removed []string) {
+	had := make(map[string]bool, len(previous))
+	for _, tag := range previous {
+		had[tag] = true
+	}
+	for _, tag := range current {
+		if !had[tag] {
+			added = append(added, tag)
+		}
+		delete(had, tag)
+	}
+	for tag := range had {
+		removed = append(removed, tag)
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// ChangesSinceCtx takes a new snapshot and returns the changes since from,
+// along with the new snapshot for the next comparison
+func (c *Client) ChangesSinceCtx(ctx context.Context, from *Snapshot) (Changelog, *Snapshot, error) {
+	to, err := c.SnapshotTorrentsCtx(ctx)
+	if err != nil {
+		return Changelog{}, nil, err
+	}
+	return DiffSnapshots(from, to), to, nil
+}
+
+// Empty reports whether nothing changed
+func (l Changelog) Empty() bool {
+	return len(l.Added) == 0 && len(l.Removed) == 0 && len(l.StateChanges) == 0 &&
+		len(l.Moved) == 0 && len(l.Retagged) == 0 && len(l.Recategorized) == 0
+}
+
+// String formats the changes one per line
+func (l Changelog) String() string {
+	if l.Empty() {
+		return "no changes"
+	}
+	var lines []string
+	for _, torrent := range l.Added {
+		lines = append(lines, fmt.Sprintf("added %s (%s)", torrent.Name, torrent.Hash))
+	}
+	for _, torrent := range l.Removed {
+		lines = append(lines, fmt.Sprintf("removed %s (%s)", torrent.Name, torrent.Hash))
+	}
+	for _, change := range l.StateChanges {
+		lines = append(lines, fmt.Sprintf("%s: state %s -> %s", change.Name, change.From, change.To))
+	}
+	for _, change := range l.Moved {
+		lines = append(lines, fmt.Sprintf("%s: moved %s -> %s", change.Name, change.From, change.To))
+	}
+	for _, change := range l.Retagged {
+		lines = append(lines, fmt.Sprintf("%s: tags +%v -%v", change.Name, change.Added, change.Removed))
+	}
+	for _, change := range l.Recategorized {
+		lines = append(lines, fmt.Sprintf("%s: category %q -> %q", change.Name, change.From, change.To))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,85 @@
+package qbittorrent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotTorrentsCtx(t *testing.T) {
+	var requests int
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if rid := r.URL.Query().Get("rid"); rid != "0" {
+			t.Errorf("expected a full update, got rid %s", rid)
+		}
+		w.Write([]byte(`{"rid":7,"full_update":true,"torrents":{"bb":{"name":"two"},"aa":{"name":"one"}}}`))
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	snapshot, err := client.SnapshotTorrentsCtx(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if requests != 1 || snapshot.Rid != 7 || snapshot.At.IsZero() {
+		t.Errorf("expected a single request at rid 7, got %d and %+v", requests, snapshot)
+	}
+	if len(snapshot.Torrents) != 2 || snapshot.Torrents[0].Hash != "aa" || snapshot.Torrents[1].Name != "two" {
+		t.Errorf("expected the torrents sorted by hash, got %+v", snapshot.Torrents)
+	}
+}
+
+func TestSnapshot_JSON(t *testing.T) {
+	snapshot := Snapshot{Rid: 3, Torrents: []TorrentInfo{{Hash: "aa", Name: "one", Tags: []string{"hd", "tv"}}}}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var decoded Snapshot
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(decoded, snapshot) {
+		t.Errorf("expected %+v, got %+v", snapshot, decoded)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	from := &Snapshot{Torrents: []TorrentInfo{
+		{Hash: "a", Name: "one", State: "downloading", SavePath: "/incomplete", Tags: []string{"new"}},
+		{Hash: "b", Name: "two", State: "stalledUP", Category: "tv"},
+		{Hash: "c", Name: "three", State: "stalledUP"},
+	}}
+	to := &Snapshot{Torrents: []TorrentInfo{
+		{Hash: "a", Name: "one", State: "stalledUP", SavePath: "/data", Tags: []string{"done", "hd"}},
+		{Hash: "b", Name: "two", State: "stalledUP", Category: "tv/kids"},
+		{Hash: "d", Name: "four", State: "metaDL"},
+	}}
+
+	log := DiffSnapshots(from, to)
+	if len(log.Added) != 1 || log.Added[0].Hash != "d" || len(log.Removed) != 1 || log.Removed[0].Hash != "c" {
+		t.Errorf("unexpected added %v and removed %v", log.Added, log.Removed)
+	}
+	expected := Changelog{
+		Added:         log.Added,
+		Removed:       log.Removed,
+		StateChanges:  []StateChange{{Hash: "a", Name: "one", From: StateDownloading, To: StateStalledUP}},
+		Moved:         []PathChange{{Hash: "a", Name: "one", From: "/incomplete", To: "/data"}},
+		Retagged:      []TagChange{{Hash: "a", Name: "one", Added: []string{"done", "hd"}, Removed: []string{"new"}}},
+		Recategorized: []CategoryChange{{Hash: "b", Name: "two", From: "tv", To: "tv/kids"}},
+	}
+	if !reflect.DeepEqual(log, expected) {
+		t.Errorf("expected %+v, got %+v", expected, log)
+	}
+	if !DiffSnapshots(to, to).Empty() {
+		t.Errorf("expected no changes between equal snapshots")
+	}
+	if s := log.String(); !strings.Contains(s, "one: state downloading -> stalledUP") || !strings.Contains(s, "removed three (c)") {
+		t.Errorf("unexpected changelog\n%s", s)
+	}
+}
@@ -0,0 +1,72 @@
+package qbittorrent
+
+import (
+	"cmp"
+	"slices"
+)
+
+// TorrentKey compares two torrents by a field, like cmp.Compare
+type TorrentKey func(a, b TorrentInfo) int
+
+// Key returns a TorrentKey comparing the values selected by field
+func Key[T cmp.Ordered](field func(t TorrentInfo) T) TorrentKey {
+	return func(a, b TorrentInfo) int {
+		return cmp.Compare(field(a), field(b))
+	}
+}
+
+// Keys of commonly sorted fields
+var (
+	ByName         = Key(func(t TorrentInfo) string { return t.Name })
+	ByHash         = Key(func(t TorrentInfo) InfoHash { return t.Hash })
+	ByState        = Key(func(t TorrentInfo) string { return t.State })
+	ByCategory     = Key(func(t TorrentInfo) string { return t.Category })
+	ByTracker      = Key(func(t TorrentInfo) string { return t.Tracker })
+	BySavePath     = Key(func(t TorrentInfo) string { return t.SavePath })
+	BySize         = Key(func(t TorrentInfo) int64 { return t.Size })
+	ByProgress     = Key(func(t TorrentInfo) float64 { return t.Progress })
+	ByRatio        = Key(func(t TorrentInfo) float64 { return t.Ratio })
+	ByDLSpeed      = Key(func(t TorrentInfo) int64 { return t.DLSpeed })
+	ByUpSpeed      = Key(func(t TorrentInfo) int64 { return t.UpSpeed })
+	ByDownloaded   = Key(func(t TorrentInfo) int64 { return t.Downloaded })
+	ByUploaded     = Key(func(t TorrentInfo) int64 { return t.Uploaded })
+	ByETA          = Key(func(t TorrentInfo) int64 { return t.ETA })
+	ByNumSeeds     = Key(func(t TorrentInfo) int64 { return t.NumSeeds })
+	ByNumLeechs    = Key(func(t TorrentInfo) int64 { return t.NumLeechs })
+	ByAddedOn      = Key(func(t TorrentInfo) int64 { return t.AddedOn })
+	ByCompletionOn = Key(func(t TorrentInfo) int64 { return t.CompletionOn })
+	ByLastActivity = Key(func(t TorrentInfo) int64 { return t.LastActivity })
+	BySeedingTime  = Key(func(t TorrentInfo) int64 { return t.SeedingTime })
+	ByPriority     = Key(func(t TorrentInfo) int64 { return t.Priority })
+)
+
+// Then returns a key ordering by k, then by next for torrents k considers equal
+func (k TorrentKey) Then(next TorrentKey) TorrentKey {
+	return func(a, b TorrentInfo) int {
+		if c := k(a, b); c != 0 {
+			return c
+		}
+		return next(a, b)
+	}
+}
+
+// SortBy sorts torrents in place by key, keeping the order of equal torrents
+func SortBy(torrents []TorrentInfo, key TorrentKey, desc bool) {
+	slices.SortStableFunc(torrents, func(a, b TorrentInfo) int {
+		if desc {
+			return key(b, a)
+		}
+		return key(a, b)
+	})
+}
+
+// Filter returns the torrents for which pred returns true, in order
+func Filter(torrents []TorrentInfo, pred func(t TorrentInfo) bool) []TorrentInfo {
+	var matched []TorrentInfo
+	for _, t := range torrents {
+		if pred(t) {
+			matched = append(matched, t)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,40 @@
+package qbittorrent
+
+import "testing"
+
+func TestSortBy(t *testing.T) {
+	torrents := []TorrentInfo{
+		{Hash: "a", Name: "b", Size: 10, Ratio: 1},
+		{Hash: "b", Name: "a", Size: 30, Ratio: 2},
+		{Hash: "c", Name: "c", Size: 10, Ratio: 0.5},
+	}
+	hashes := func() string {
+		var s string
+		for _, torrent := range torrents {
+			s += string(torrent.Hash)
+		}
+		return s
+	}
+
+	SortBy(torrents, ByName, false)
+	if got := hashes(); got != "bac" {
+		t.Errorf("expected bac sorted by name, got %s", got)
+	}
+	SortBy(torrents, BySize, true)
+	if got := hashes(); got != "bac" {
+		t.Errorf("expected stable bac sorted by size descending, got %s", got)
+	}
+	SortBy(torrents, BySize.Then(ByRatio), false)
+	if got := hashes(); got != "cab" {
+		t.Errorf("expected cab sorted by size then ratio, got %s", got)
+	}
+	SortBy(torrents, Key(func(t TorrentInfo) float64 { return t.Ratio }), true)
+	if got := hashes(); got != "bac" {
+		t.Errorf("expected bac sorted by ratio descending, got %s", got)
+	}
+
+	small := Filter(torrents, func(t TorrentInfo) bool { return t.Size < 20 })
+	if len(small) != 2 || small[0].Hash != "a" || small[1].Hash != "c" {
+		t.Errorf("unexpected filtered torrents %+v", small)
+	}
+}
@@ -0,0 +1,132 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SpeedRule gives the torrents it matches upload and download limits in
+// bytes/s, zero for unlimited. Empty fields match every torrent.
+type SpeedRule struct {
+	Category      string `json:"category,omitempty"` // the category or one of its parents, e.g. "tv" matches "tv/kids"
+	Tracker       string `json:"tracker,omitempty"`  // host of the current tracker or a parent domain, e.g. "example.org"
+	UploadLimit   int64  `json:"upload_limit"`
+	DownloadLimit int64  `json:"download_limit"`
+}
+
+// Matches reports whether the rule applies to torrent
+func (r SpeedRule) Matches(torrent TorrentInfo) bool {
+	if r.Category != "" && !matchCategory(torrent.Category, r.Category) {
+		return false
+	}
+	if r.Tracker != "" && !matchTrackerHost(torrent.Tracker, r.Tracker) {
+		return false
+	}
+	return true
+}
+
+// matchCategory reports whether category is parent or one of its subcategories
+func matchCategory(category, parent string) bool {
+	return category == parent || strings.HasPrefix(category, parent+"/")
+}
+
+// matchTrackerHost reports whether the host of tracker is domain or below it
+func matchTrackerHost(tracker, domain string) bool {
+	host := trackerDomain(tracker)
+	domain = strings.ToLower(domain)
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// SpeedChange is a limit changed by ApplySpeedPolicyCtx
+type SpeedChange struct {
+	Hash     InfoHash
+	Name     string
+	Upload   bool // the upload limit changed, otherwise the download limit
+	Old, New int64
+}
+
+// ApplySpeedPolicyCtx gives every torrent the limits of the first rule that
+// matches it and returns the changes, sorted by hash. Torrents no rule matches
+// keep their limits. Limits are only set where they differ, with one request
+// per distinct limit.
+func (c *Client) ApplySpeedPolicyCtx(ctx context.Context, rules []SpeedRule) ([]SpeedChange, error) {
+	torrents, err := c.TorrentsInfoCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ApplySpeedPolicy error: %w", err)
+	}
+
+	matched := make(map[InfoHash]SpeedRule)
+	names := make(map[InfoHash]string)
+	var hashes []string
+	for _, torrent := range torrents {
+		for _, rule := range rules {
+			if rule.Matches(torrent) {
+				matched[torrent.Hash] = rule
+				names[torrent.Hash] = torrent.Name
+				hashes = append(hashes, string(torrent.Hash))
+				break
+			}
+		}
+	}
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	sort.Strings(hashes)
+
+	uploads, err := c.TorrentsUploadLimitCtx(ctx, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("ApplySpeedPolicy error: %w", err)
+	}
+	downloads, err := c.TorrentsDownloadLimitCtx(ctx, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("ApplySpeedPolicy error: %w", err)
+	}
+
+	var changes []SpeedChange
+	uploadGroups := make(map[int64][]string)
+	downloadGroups := make(map[int64][]string)
+	for _, hash := range hashes {
+		h := InfoHash(hash)
+		upload, download := unlimited(matched[h].UploadLimit), unlimited(matched[h].DownloadLimit)
+		if old := unlimited(uploads[h]); old != upload {
+			changes = append(changes, SpeedChange{Hash: h, Name: names[h], Upload: true, Old: old, New: upload})
+			uploadGroups[upload] = append(uploadGroups[upload], hash)
+		}
+		if old := unlimited(downloads[h]); old != download {
+			changes = append(changes, SpeedChange{Hash: h, Name: names[h], Old: old, New: download})
+			downloadGroups[download] = append(downloadGroups[download], hash)
+		}
+	}
+
+	for _, limit := range sortedLimits(uploadGroups) {
+		if err := c.TorrentsSetUploadLimitCtx(ctx, uploadGroups[limit], limit); err != nil {
+			return nil, fmt.Errorf("ApplySpeedPolicy error: %w", err)
+		}
+	}
+	for _, limit := range sortedLimits(downloadGroups) {
+		if err := c.TorrentsSetDownloadLimitCtx(ctx, downloadGroups[limit], limit); err != nil {
+			return nil, fmt.Errorf("ApplySpeedPolicy error: %w", err)
+		}
+	}
+	return changes, nil
+}
+
+// unlimited normalizes the ways qBittorrent spells no limit to zero
+func unlimited(limit int64) int64 {
+	if limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// sortedLimits returns the limits of groups in ascending order
+func sortedLimits(groups map[int64][]string) []int64 {
+	limits := make([]int64, 0, len(groups))
+	for limit := range groups {
+		limits = append(limits, limit)
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i] < limits[j] })
+	return limits
+}
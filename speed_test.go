@@ -0,0 +1,83 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSpeedRule_Matches(t *testing.T) {
+	torrent := TorrentInfo{Category: "tv/kids", Tracker: "https://Tracker.Example.org:443/announce"}
+	tests := []struct {
+		rule     SpeedRule
+		expected bool
+	}{
+		{SpeedRule{}, true},
+		{SpeedRule{Category: "tv"}, true},
+		{SpeedRule{Category: "tv/kids"}, true},
+		{SpeedRule{Category: "t"}, false},
+		{SpeedRule{Tracker: "example.org"}, true},
+		{SpeedRule{Tracker: "tracker.example.org"}, true},
+		{SpeedRule{Tracker: "ample.org"}, false},
+		{SpeedRule{Category: "tv", Tracker: "other.net"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Matches(torrent); got != tt.expected {
+			t.Errorf("%+v: expected %v, got %v", tt.rule, tt.expected, got)
+		}
+	}
+}
+
+func TestApplySpeedPolicyCtx(t *testing.T) {
+	var requests []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.URL.Path {
+		case "/api/v2/torrents/info":
+			w.Write([]byte(`[
+				{"hash":"a","name":"one","category":"tv"},
+				{"hash":"b","name":"two","category":"tv/kids"},
+				{"hash":"c","name":"three","category":"movies","tracker":"http://t.example.org/announce"},
+				{"hash":"d","name":"four","category":"music"}
+			]`))
+		case "/api/v2/torrents/uploadLimit":
+			requests = append(requests, r.URL.Path+" "+r.FormValue("hashes"))
+			w.Write([]byte(`{"a":1000,"b":-1,"c":0}`))
+		case "/api/v2/torrents/downloadLimit":
+			requests = append(requests, r.URL.Path+" "+r.FormValue("hashes"))
+			w.Write([]byte(`{"a":0,"b":0,"c":500}`))
+		default:
+			requests = append(requests, r.URL.Path+" "+r.FormValue("hashes")+" "+r.FormValue("limit"))
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	changes, err := client.ApplySpeedPolicyCtx(context.Background(), []SpeedRule{
+		{Tracker: "example.org", UploadLimit: 2000},
+		{Category: "tv", UploadLimit: 1000},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expectedChanges := []SpeedChange{
+		{Hash: "b", Name: "two", Upload: true, Old: 0, New: 1000},
+		{Hash: "c", Name: "three", Upload: true, Old: 0, New: 2000},
+		{Hash: "c", Name: "three", Old: 500, New: 0},
+	}
+	if !reflect.DeepEqual(changes, expectedChanges) {
+		t.Errorf("expected %+v, got %+v", expectedChanges, changes)
+	}
+	expected := []string{
+		"/api/v2/torrents/uploadLimit a|b|c",
+		"/api/v2/torrents/downloadLimit a|b|c",
+		"/api/v2/torrents/setUploadLimit b 1000",
+		"/api/v2/torrents/setUploadLimit c 2000",
+		"/api/v2/torrents/setDownloadLimit c 0",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected %v, got %v", expected, requests)
+	}
+}
@@ -0,0 +1,97 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// TorrentState is the state of a torrent as reported in TorrentInfo.State
+type TorrentState string
+
+const (
+	StateError              TorrentState = "error"
+	StateMissingFiles       TorrentState = "missingFiles"
+	StateUploading          TorrentState = "uploading"
+	StatePausedUP           TorrentState = "pausedUP" // stoppedUP since qBittorrent 5
+	StateStoppedUP          TorrentState = "stoppedUP"
+	StateQueuedUP           TorrentState = "queuedUP"
+	StateStalledUP          TorrentState = "stalledUP"
+	StateCheckingUP         TorrentState = "checkingUP"
+	StateForcedUP           TorrentState = "forcedUP"
+	StateAllocating         TorrentState = "allocating"
+	StateDownloading        TorrentState = "downloading"
+	StateMetaDL             TorrentState = "metaDL"
+	StateForcedMetaDL       TorrentState = "forcedMetaDL"
+	StatePausedDL           TorrentState = "pausedDL" // stoppedDL since qBittorrent 5
+	StateStoppedDL          TorrentState = "stoppedDL"
+	StateQueuedDL           TorrentState = "queuedDL"
+	StateStalledDL          TorrentState = "stalledDL"
+	StateCheckingDL         TorrentState = "checkingDL"
+	StateForcedDL           TorrentState = "forcedDL"
+	StateCheckingResumeData TorrentState = "checkingResumeData"
+	StateMoving             TorrentState = "moving"
+	StateUnknown            TorrentState = "unknown"
+)
+
+// Stopped reports whether the state is one of a stopped (paused) torrent
+func (s TorrentState) Stopped() bool {
+	switch s {
+	case StatePausedDL, StatePausedUP, StateStoppedDL, StateStoppedUP:
+		return true
+	}
+	return false
+}
+
+// TorrentsCountCtx returns the number of torrents. Servers without
+// /api/v2/torrents/count, added in qBittorrent 5.1, are answered by counting
+// the torrent list.
+func (c *Client) TorrentsCountCtx(ctx context.Context) (int, error) {
+	resp, err := c.doRequestCtx(ctx, "GET", "/api/v2/torrents/count", nil, "")
+	if err != nil {
+		return 0, fmt.Errorf("TorrentsCount error: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := c.readBody("/api/v2/torrents/count", resp)
+	if err != nil {
+		return 0, fmt.Errorf("TorrentsCount error: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		count, err := strconv.Atoi(strings.TrimSpace(string(respBody)))
+		if err != nil {
+			return 0, fmt.Errorf("failed to decode TorrentsCount response: %w", err)
+		}
+		return count, nil
+	case http.StatusNotFound:
+		torrents, err := c.TorrentsInfoCtx(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("TorrentsCount error: %w", err)
+		}
+		return len(torrents), nil
+	default:
+		return 0, fmt.Errorf("TorrentsCount error: %w", c.newAPIError("GET", "/api/v2/torrents/count", resp.StatusCode, respBody))
+	}
+}
+
+// StateSummaryCtx returns the number of torrents in each state, from a single
+// sync request. States without torrents are omitted.
+func (c *Client) StateSummaryCtx(ctx context.Context) (map[TorrentState]int, error) {
+	data, err := c.SyncMainDataCtx(ctx, 0)
+	if err != nil {
+		return nil, fmt.Errorf("StateSummary error: %w", err)
+	}
+	return StateSummary(data.Torrents), nil
+}
+
+// StateSummary counts the torrents in each state
+func StateSummary(torrents map[string]TorrentInfo) map[TorrentState]int {
+	summary := make(map[TorrentState]int)
+	for _, torrent := range torrents {
+		summary[TorrentState(torrent.State)]++
+	}
+	return summary
+}
@@ -0,0 +1,56 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// TorrentsToggleSequentialDownloadCtx toggles sequential download for the
+// specified torrents, separated by "|"
+func (c *Client) TorrentsToggleSequentialDownloadCtx(ctx context.Context, hashes string) error {
+	data := url.Values{}
+	data.Set("hashes", hashes)
+
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/torrents/toggleSequentialDownload", data)
+	if err != nil {
+		return fmt.Errorf("TorrentsToggleSequentialDownload error: %w", err)
+	}
+	return nil
+}
+
+// TorrentsToggleFirstLastPiecePrioCtx toggles prioritizing the first and last
+// pieces of the files of the specified torrents, separated by "|"
+func (c *Client) TorrentsToggleFirstLastPiecePrioCtx(ctx context.Context, hashes string) error {
+	data := url.Values{}
+	data.Set("hashes", hashes)
+
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/torrents/toggleFirstLastPiecePrio", data)
+	if err != nil {
+		return fmt.Errorf("TorrentsToggleFirstLastPiecePrio error: %w", err)
+	}
+	return nil
+}
+
+// EnableStreamingModeCtx prepares a torrent for playback of one of its files
+// while downloading: it enables sequential download and first and last piece
+// priority, and gives the file maximum priority. Since the server only offers
+// toggles, the current settings are read first so calling it twice is harmless.
+func (c *Client) EnableStreamingModeCtx(ctx context.Context, hash string, fileIndex int) error {
+	torrent, err := c.GetTorrentCtx(ctx, hash)
+	if err != nil {
+		return fmt.Errorf("EnableStreamingMode error: %w", err)
+	}
+
+	if !torrent.SequentialDownload {
+		if err := c.TorrentsToggleSequentialDownloadCtx(ctx, hash); err != nil {
+			return err
+		}
+	}
+	if !torrent.FirstLastPiecePrio {
+		if err := c.TorrentsToggleFirstLastPiecePrioCtx(ctx, hash); err != nil {
+			return err
+		}
+	}
+	return c.TorrentsFilePrioCtx(ctx, hash, []int{fileIndex}, FilePriorityMaximum)
+}
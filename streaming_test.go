@@ -0,0 +1,55 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEnableStreamingMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		torrent  string
+		expected []string
+	}{
+		{
+			name:    "toggles settings that are off",
+			torrent: `[{"hash":"abc","seq_dl":false,"f_l_piece_prio":false}]`,
+			expected: []string{
+				"/api/v2/torrents/toggleSequentialDownload hashes=abc",
+				"/api/v2/torrents/toggleFirstLastPiecePrio hashes=abc",
+				"/api/v2/torrents/filePrio hash=abc&id=2&priority=7",
+			},
+		},
+		{
+			name:     "keeps settings that are on",
+			torrent:  `[{"hash":"abc","seq_dl":true,"f_l_piece_prio":true}]`,
+			expected: []string{"/api/v2/torrents/filePrio hash=abc&id=2&priority=7"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posts []string
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v2/torrents/info" {
+					w.Write([]byte(tt.torrent))
+					return
+				}
+				r.ParseForm()
+				posts = append(posts, r.URL.Path+" "+r.PostForm.Encode())
+			}))
+			defer mockServer.Close()
+
+			client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+			if err := client.EnableStreamingModeCtx(context.Background(), "abc", 2); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if strings.Join(posts, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expected requests %v, got %v", tt.expected, posts)
+			}
+		})
+	}
+}
@@ -0,0 +1,401 @@
+package qbittorrent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// SyncState maintains the complete main data of an instance by applying the
+// incremental responses of /api/v2/sync/maindata. Partial updates only carry
+// the fields that changed, so the state is merged field by field.
+// A SyncState is safe for concurrent use.
+type SyncState struct {
+	mu          sync.RWMutex
+	rid         int
+	torrents    map[string]TorrentInfo
+	serverState ServerState
+	categories  map[string]Category
+	tags        map[string]struct{}
+	trackers    map[string][]InfoHash
+}
+
+// NewSyncState returns an empty state that requests a full update first
+func NewSyncState() *SyncState {
+	s := &SyncState{}
+	s.reset()
+	return s
+}
+
+// reset clears the state ahead of a full update
+func (s *SyncState) reset() {
+	s.torrents = make(map[string]TorrentInfo)
+	s.serverState = ServerState{}
+	s.categories = make(map[string]Category)
+	s.tags = make(map[string]struct{})
+	s.trackers = make(map[string][]InfoHash)
+}
+
+// Update fetches the changes since the last update and applies them. It
+// returns the response as received, i.e. only what changed.
+func (s *SyncState) Update(ctx context.Context, c *Client) (*MainData, error) {
+	raw, err := c.syncMainDataRaw(ctx, s.Rid())
+	if err != nil {
+		return nil, err
+	}
+	defer c.observeDecode("/api/v2/sync/maindata", c.Clock().Now())
+	var data MainData
+	if err := c.checkFields("/api/v2/sync/maindata", raw, &data); err != nil {
+		return nil, err
+	}
+	if _, err := s.apply(raw, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Run updates the state every interval until ctx is done, calling onUpdate,
+// if not nil, with each response. Requests that time out while ctx is still
+// live are logged and retried at the next interval rather than ending the
+// loop, so a slow response doesn't stop a long-running poll; bound them with
+// WithSyncTimeout. Other errors, and those of onUpdate, end the loop.
+func (s *SyncState) Run(ctx context.Context, c *Client, interval time.Duration, onUpdate func(*MainData) error) error {
+	ticker := c.Clock().NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		data, err := s.Update(ctx, c)
+		switch {
+		case err == nil:
+			if onUpdate != nil {
+				if err := onUpdate(data); err != nil {
+					return err
+				}
+			}
+		case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+			c.log().Warn("sync request timed out", "rid", s.Rid(), "error", err)
+		default:
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		}
+	}
+}
+
+// Apply merges a raw /api/v2/sync/maindata response into the state
+func (s *SyncState) Apply(raw []byte) error {
+	_, err := s.ApplyDiff(raw)
+	return err
+}
+
+// UpdateDiff fetches the changes since the last update, applies them and
+// returns what changed
+func (s *SyncState) UpdateDiff(ctx context.Context, c *Client) (MainDataDiff, error) {
+	raw, err := c.syncMainDataRaw(ctx, s.Rid())
+	if err != nil {
+		return MainDataDiff{}, err
+	}
+	defer c.observeDecode("/api/v2/sync/maindata", c.Clock().Now())
+	return s.ApplyDiff(raw)
+}
+
+// ApplyDiff merges a raw /api/v2/sync/maindata response into the state and
+// returns what changed. Full updates are compared with the state they
+// replace, so only actual changes are reported. Invalid responses leave the
+// state unchanged.
+func (s *SyncState) ApplyDiff(raw []byte) (MainDataDiff, error) {
+	return s.apply(raw, nil)
+}
+
+// apply merges a raw response into the state like ApplyDiff and, if received
+// is not nil, stores the response in it as decoded, so it is decoded only once
+func (s *SyncState) apply(raw []byte, received *MainData) (MainDataDiff, error) {
+	var resp mainDataResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return MainDataDiff{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Decode the torrents and the server state before changing anything, so
+	// an invalid response leaves the state unchanged
+	torrents := make(map[string]TorrentInfo, len(resp.Torrents))
+	for hash, fields := range resp.Torrents {
+		// Unmarshaling only overwrites the fields present, which merges
+		// partial updates into the previous state
+		var torrent TorrentInfo
+		if !resp.FullUpdate {
+			torrent = s.torrents[hash]
+		}
+		if err := json.Unmarshal(fields, &torrent); err != nil {
+			return MainDataDiff{}, fmt.Errorf("failed to decode torrent %s: %w", hash, err)
+		}
+		torrent.Hash = InfoHash(hash)
+		torrents[hash] = torrent
+	}
+	serverState := s.serverState
+	if resp.FullUpdate {
+		serverState = ServerState{}
+	}
+	if len(resp.ServerState) > 0 {
+		if err := json.Unmarshal(resp.ServerState, &serverState); err != nil {
+			return MainDataDiff{}, fmt.Errorf("failed to decode server state: %w", err)
+		}
+	}
+	if received != nil {
+		if err := resp.received(received, torrents, serverState); err != nil {
+			return MainDataDiff{}, err
+		}
+	}
+
+	// A full update replaces the maps, keep the previous ones to compare with
+	prevTorrents, prevCategories := s.torrents, s.categories
+	prevTags, prevTrackers := s.tags, s.trackers
+	diff := MainDataDiff{Rid: resp.Rid, Full: resp.FullUpdate}
+	if resp.FullUpdate {
+		s.reset()
+	}
+	s.rid = resp.Rid
+	s.serverState = serverState
+
+	for hash, torrent := range torrents {
+		previous, existed := prevTorrents[hash]
+		s.torrents[hash] = torrent
+
+		if !existed {
+			diff.TorrentsAdded = append(diff.TorrentsAdded, torrent)
+		} else if changed := changedFields(previous, torrent); len(changed) > 0 {
+			diff.TorrentsUpdated = append(diff.TorrentsUpdated, TorrentUpdate{Torrent: torrent, Previous: previous, Fields: changed})
+		}
+	}
+	for _, hash := range resp.TorrentsRemoved {
+		if torrent, ok := s.torrents[hash]; ok {
+			diff.TorrentsRemoved = append(diff.TorrentsRemoved, torrent)
+		}
+		delete(s.torrents, hash)
+	}
+
+	for name, fields := range resp.Categories {
+		old, existed := prevCategories[name]
+		updated := false
+		for key, value := range fields {
+			if existed && !reflect.DeepEqual(old[key], value) {
+				updated = true
+			}
+		}
+		merged, ok := s.categories[name]
+		if !ok {
+			merged = make(Category, len(fields))
+			s.categories[name] = merged
+		}
+		for key, value := range fields {
+			merged[key] = value
+		}
+
+		if !existed {
+			diff.CategoriesAdded = append(diff.CategoriesAdded, name)
+		} else if updated {
+			diff.CategoriesUpdated = append(diff.CategoriesUpdated, name)
+		}
+	}
+	for _, name := range resp.CategoriesRemoved {
+		if _, ok := s.categories[name]; ok {
+			diff.CategoriesRemoved = append(diff.CategoriesRemoved, name)
+		}
+		delete(s.categories, name)
+	}
+
+	for _, tag := range resp.Tags {
+		if _, ok := prevTags[tag]; !ok {
+			diff.TagsAdded = append(diff.TagsAdded, tag)
+		}
+		s.tags[tag] = struct{}{}
+	}
+	for _, tag := range resp.TagsRemoved {
+		if _, ok := s.tags[tag]; ok {
+			diff.TagsRemoved = append(diff.TagsRemoved, tag)
+		}
+		delete(s.tags, tag)
+	}
+
+	for tracker, hashes := range resp.Trackers {
+		if old, ok := prevTrackers[tracker]; !ok {
+			diff.TrackersAdded = append(diff.TrackersAdded, tracker)
+		} else if !reflect.DeepEqual(old, hashes) {
+			diff.TrackersUpdated = append(diff.TrackersUpdated, tracker)
+		}
+		s.trackers[tracker] = hashes
+	}
+	for _, tracker := range resp.TrackersRemoved {
+		if _, ok := s.trackers[tracker]; ok {
+			diff.TrackersRemoved = append(diff.TrackersRemoved, tracker)
+		}
+		delete(s.trackers, tracker)
+	}
+
+	// Whatever a full update doesn't mention anymore was removed
+	if resp.FullUpdate {
+		for hash, torrent := range prevTorrents {
+			if _, ok := s.torrents[hash]; !ok {
+				diff.TorrentsRemoved = append(diff.TorrentsRemoved, torrent)
+			}
+		}
+		for name := range prevCategories {
+			if _, ok := s.categories[name]; !ok {
+				diff.CategoriesRemoved = append(diff.CategoriesRemoved, name)
+			}
+		}
+		for tag := range prevTags {
+			if _, ok := s.tags[tag]; !ok {
+				diff.TagsRemoved = append(diff.TagsRemoved, tag)
+			}
+		}
+		for tracker := range prevTrackers {
+			if _, ok := s.trackers[tracker]; !ok {
+				diff.TrackersRemoved = append(diff.TrackersRemoved, tracker)
+			}
+		}
+	}
+
+	diff.sort()
+	return diff, nil
+}
+
+// mainDataResponse is a /api/v2/sync/maindata response with the torrents and
+// the server state left to decode, since partial updates are merged into the
+// state field by field
+type mainDataResponse struct {
+	Rid               int                        `json:"rid"`
+	FullUpdate        bool                       `json:"full_update"`
+	Torrents          map[string]json.RawMessage `json:"torrents"`
+	TorrentsRemoved   []string                   `json:"torrents_removed"`
+	Categories        map[string]Category        `json:"categories"`
+	CategoriesRemoved []string                   `json:"categories_removed"`
+	ServerState       json.RawMessage            `json:"server_state"`
+	Tags              []string                   `json:"tags"`
+	TagsRemoved       []string                   `json:"tags_removed"`
+	Trackers          map[string][]InfoHash      `json:"trackers"`
+	TrackersRemoved   []string                   `json:"trackers_removed"`
+}
+
+// received stores the response in data as MainData, given its torrents and
+// server state as merged into the state
+func (resp *mainDataResponse) received(data *MainData, torrents map[string]TorrentInfo, serverState ServerState) error {
+	*data = MainData{
+		Categories:        resp.Categories,
+		CategoriesRemoved: resp.CategoriesRemoved,
+		FullUpdate:        resp.FullUpdate,
+		Rid:               resp.Rid,
+		ServerState:       serverState,
+		Tags:              resp.Tags,
+		TagsRemoved:       resp.TagsRemoved,
+		Torrents:          torrents,
+		TorrentsRemoved:   resp.TorrentsRemoved,
+		Trackers:          make(map[string][]InfoHash, len(resp.Trackers)),
+		TrackersRemoved:   resp.TrackersRemoved,
+	}
+	for tracker, hashes := range resp.Trackers {
+		data.Trackers[tracker] = append([]InfoHash(nil), hashes...)
+	}
+	if resp.FullUpdate {
+		// Full updates aren't merged, the state holds them as received
+		return nil
+	}
+
+	// Partial updates only carry the fields that changed
+	data.Torrents = make(map[string]TorrentInfo, len(resp.Torrents))
+	for hash, fields := range resp.Torrents {
+		var torrent TorrentInfo
+		if err := json.Unmarshal(fields, &torrent); err != nil {
+			return fmt.Errorf("failed to decode torrent %s: %w", hash, err)
+		}
+		torrent.Hash = InfoHash(hash)
+		data.Torrents[hash] = torrent
+	}
+	data.ServerState = ServerState{}
+	if len(resp.ServerState) > 0 {
+		if err := json.Unmarshal(resp.ServerState, &data.ServerState); err != nil {
+			return fmt.Errorf("failed to decode server state: %w", err)
+		}
+	}
+	return nil
+}
+
+// Rid returns the response ID to request the next update with
+func (s *SyncState) Rid() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rid
+}
+
+// Torrents returns a copy of all torrents keyed by hash
+func (s *SyncState) Torrents() map[string]TorrentInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	torrents := make(map[string]TorrentInfo, len(s.torrents))
+	for hash, torrent := range s.torrents {
+		torrents[hash] = torrent
+	}
+	return torrents
+}
+
+// Torrent returns a single torrent by hash
+func (s *SyncState) Torrent(hash string) (TorrentInfo, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	torrent, ok := s.torrents[hash]
+	return torrent, ok
+}
+
+// ServerState returns the merged server state
+func (s *SyncState) ServerState() ServerState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.serverState
+}
+
+// Categories returns a copy of all categories keyed by name
+func (s *SyncState) Categories() map[string]Category {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	categories := make(map[string]Category, len(s.categories))
+	for name, category := range s.categories {
+		copied := make(Category, len(category))
+		for key, value := range category {
+			copied[key] = value
+		}
+		categories[name] = copied
+	}
+	return categories
+}
+
+// Tags returns all tags
+func (s *SyncState) Tags() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tags := make([]string, 0, len(s.tags))
+	for tag := range s.tags {
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// Trackers returns a copy of the map of tracker URLs to infohashes
+func (s *SyncState) Trackers() map[string][]InfoHash {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	trackers := make(map[string][]InfoHash, len(s.trackers))
+	for tracker, hashes := range s.trackers {
+		trackers[tracker] = append([]InfoHash(nil), hashes...)
+	}
+	return trackers
+}
@@ -0,0 +1,190 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSyncState_Apply(t *testing.T) {
+	state := NewSyncState()
+
+	full := `{"rid":1,"full_update":true,
+		"torrents":{"hash1":{"name":"a","dlspeed":100,"tags":"x, y"},"hash2":{"name":"b"}},
+		"categories":{"movies":{"name":"movies","savePath":"/data/movies"},"tv":{"name":"tv"}},
+		"server_state":{"alltime_dl":10,"free_space_on_disk":1000},
+		"tags":["x","y"],
+		"trackers":{"http://tracker":["hash1"]}}`
+	if err := state.Apply([]byte(full)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	partial := `{"rid":2,
+		"torrents":{"hash1":{"dlspeed":200}},
+		"torrents_removed":["hash2"],
+		"categories":{"movies":{"savePath":"/data/films"}},
+		"categories_removed":["tv"],
+		"server_state":{"free_space_on_disk":500},
+		"tags_removed":["y"],
+		"trackers_removed":["http://tracker"]}`
+	if err := state.Apply([]byte(partial)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if state.Rid() != 2 {
+		t.Errorf("expected rid 2, got %d", state.Rid())
+	}
+	torrents := state.Torrents()
+	if len(torrents) != 1 {
+		t.Fatalf("expected 1 torrent, got %d", len(torrents))
+	}
+	torrent := torrents["hash1"]
+	if torrent.Name != "a" || torrent.DLSpeed != 200 || torrent.Hash != "hash1" {
+		t.Errorf("expected merged torrent, got %+v", torrent)
+	}
+	if len(torrent.Tags) != 2 {
+		t.Errorf("expected tags to survive the partial update, got %v", torrent.Tags)
+	}
+	if ss := state.ServerState(); ss.AllTimeDL != 10 || ss.FreeSpaceOnDisk != 500 {
+		t.Errorf("expected merged server state, got %+v", ss)
+	}
+	categories := state.Categories()
+	if len(categories) != 1 || categories["movies"]["name"] != "movies" || categories["movies"]["savePath"] != "/data/films" {
+		t.Errorf("expected merged movies category, got %v", categories)
+	}
+	if tags := state.Tags(); len(tags) != 1 || tags[0] != "x" {
+		t.Errorf("expected tags [x], got %v", tags)
+	}
+	if len(state.Trackers()) != 0 {
+		t.Errorf("expected trackers to be removed, got %v", state.Trackers())
+	}
+
+	// A full update replaces everything
+	if err := state.Apply([]byte(`{"rid":3,"full_update":true,"torrents":{"hash3":{"name":"c"}}}`)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if torrents := state.Torrents(); len(torrents) != 1 || torrents["hash3"].Name != "c" {
+		t.Errorf("expected only torrent c, got %v", torrents)
+	}
+	if len(state.Categories()) != 0 {
+		t.Errorf("expected no categories, got %v", state.Categories())
+	}
+}
+
+func TestSyncState_ApplyInvalid(t *testing.T) {
+	state := NewSyncState()
+	if err := state.Apply([]byte(`{"rid":1,"full_update":true,"torrents":{"hash1":{"name":"a"}},"server_state":{"alltime_dl":10}}`)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Neither a full update nor a partial one is applied in part
+	for _, raw := range []string{
+		`{"rid":2,"full_update":true,"torrents":{"hash2":{"name":"b"},"hash3":{"name":3}}}`,
+		`{"rid":2,"torrents":{"hash1":{"name":"b"}},"server_state":{"alltime_dl":"x"}}`,
+	} {
+		if err := state.Apply([]byte(raw)); err == nil {
+			t.Errorf("expected an error for %s", raw)
+		}
+		if state.Rid() != 1 {
+			t.Errorf("expected rid 1, got %d", state.Rid())
+		}
+		if torrents := state.Torrents(); len(torrents) != 1 || torrents["hash1"].Name != "a" {
+			t.Errorf("expected the state to be unchanged, got %v", torrents)
+		}
+		if ss := state.ServerState(); ss.AllTimeDL != 10 {
+			t.Errorf("expected the server state to be unchanged, got %+v", ss)
+		}
+	}
+}
+
+func TestSyncState_Update(t *testing.T) {
+	var rids []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rids = append(rids, r.URL.Query().Get("rid"))
+		switch r.URL.Query().Get("rid") {
+		case "0":
+			w.Write([]byte(`{"rid":5,"full_update":true,"torrents":{"hash1":{"name":"a"}},"tags":["x","y"]}`))
+		default:
+			w.Write([]byte(`{"rid":6,"torrents":{"hash2":{"name":"b"}},"categories_removed":["tv"]}`))
+		}
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client()}
+	state := NewSyncState()
+	ctx := context.Background()
+	if _, err := state.Update(ctx, client); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	data, err := state.Update(ctx, client)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(data.Torrents) != 1 || len(data.CategoriesRemoved) != 1 {
+		t.Errorf("expected the partial response, got %+v", data)
+	}
+	if len(rids) != 2 || rids[0] != "0" || rids[1] != "5" {
+		t.Errorf("expected rids [0 5], got %v", rids)
+	}
+	hashes := make([]string, 0)
+	for hash := range state.Torrents() {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+	if len(hashes) != 2 || hashes[0] != "hash1" || hashes[1] != "hash2" {
+		t.Errorf("expected both torrents, got %v", hashes)
+	}
+}
+
+func TestSyncState_Run(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		n := requests
+		mu.Unlock()
+		if n == 1 {
+			// The first response is too slow and must not end the loop
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.Write([]byte(`{"rid":` + strconv.Itoa(n) + `,"torrents":{"hash` + strconv.Itoa(n) + `":{"name":"a"}}}`))
+	}))
+	defer mockServer.Close()
+
+	timeout := 20 * time.Millisecond
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), syncTimeout: &timeout, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	state := NewSyncState()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := 0
+	err := state.Run(ctx, client, time.Millisecond, func(data *MainData) error {
+		updates++
+		if updates == 2 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+	if updates != 2 || state.Rid() != 3 {
+		t.Errorf("expected 2 updates up to rid 3, got %d updates and rid %d", updates, state.Rid())
+	}
+
+	failing := errors.New("stop")
+	err = NewSyncState().Run(context.Background(), client, time.Millisecond, func(data *MainData) error { return failing })
+	if !errors.Is(err, failing) {
+		t.Errorf("expected the handler error, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package qbittorrent
+
+import (
+	"context"
+	"strings"
+)
+
+// TagNamespaceSeparator separates the namespace of a tag from its value, as in
+// "site:red" or "state:cleanup"
+const TagNamespaceSeparator = ":"
+
+// NamespacedTag returns the tag for value in namespace
+func NamespacedTag(namespace, value string) string {
+	return namespace + TagNamespaceSeparator + value
+}
+
+// SplitTag splits a tag into its namespace and value. Tags without a
+// namespace have an empty one.
+func SplitTag(tag string) (namespace, value string) {
+	namespace, value, ok := strings.Cut(tag, TagNamespaceSeparator)
+	if !ok {
+		return "", tag
+	}
+	return namespace, value
+}
+
+// TagsInNamespace returns the values of the tags in namespace
+func TagsInNamespace(tags []string, namespace string) []string {
+	var values []string
+	for _, tag := range tags {
+		if ns, value, ok := strings.Cut(tag, TagNamespaceSeparator); ok && ns == namespace {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// SetNamespaceTagsCtx makes values the only tags of namespace on the torrents,
+// removing their other tags in the namespace and adding the missing ones.
+// Tags outside the namespace are left alone; without values the namespace is
+// cleared. Requests are only sent for tags that change.
+func (c *Client) SetNamespaceTagsCtx(ctx context.Context, hashes []string, namespace string, values ...string) error {
+	torrents, err := c.TorrentsInfoCtx(ctx, &TorrentsInfoParams{Hashes: hashes})
+	if err != nil {
+		return err
+	}
+
+	wanted := make([]string, len(values))
+	for i, value := range values {
+		wanted[i] = NamespacedTag(namespace, value)
+	}
+	return c.reconcileTags(ctx, hashes, torrents, wanted, func(tag string) bool {
+		ns, _ := SplitTag(tag)
+		return ns == namespace
+	})
+}
@@ -0,0 +1,63 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSplitTag(t *testing.T) {
+	tests := map[string][2]string{
+		"site:red":      {"site", "red"},
+		"state:cleanup": {"state", "cleanup"},
+		"url:http://x":  {"url", "http://x"},
+		"plain":         {"", "plain"},
+	}
+	for tag, expected := range tests {
+		if ns, value := SplitTag(tag); ns != expected[0] || value != expected[1] {
+			t.Errorf("%s: expected %v, got %q and %q", tag, expected, ns, value)
+		}
+	}
+	if tag := NamespacedTag("site", "red"); tag != "site:red" {
+		t.Errorf("expected site:red, got %s", tag)
+	}
+	if values := TagsInNamespace([]string{"site:red", "hd", "site:ops", "state:new"}, "site"); !reflect.DeepEqual(values, []string{"red", "ops"}) {
+		t.Errorf("unexpected values %v", values)
+	}
+}
+
+func TestSetNamespaceTagsCtx(t *testing.T) {
+	var requests []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/torrents/info":
+			w.Write([]byte(`[{"hash":"a","tags":"hd, site:red, state:new"},{"hash":"b","tags":"site:ops"}]`))
+		default:
+			r.ParseForm()
+			requests = append(requests, r.URL.Path+" "+r.FormValue("hashes")+" "+r.FormValue("tags"))
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	if err := client.SetNamespaceTagsCtx(context.Background(), []string{"a", "b"}, "site", "ops"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []string{
+		"/api/v2/torrents/removeTags a|b site:red",
+		"/api/v2/torrents/addTags a|b site:ops",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected %v, got %v", expected, requests)
+	}
+
+	requests = nil
+	if err := client.SetNamespaceTagsCtx(context.Background(), []string{"a", "b"}, "state"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(requests, []string{"/api/v2/torrents/removeTags a|b state:new"}) {
+		t.Errorf("expected the namespace to be cleared, got %v", requests)
+	}
+}
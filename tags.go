@@ -0,0 +1,101 @@
+package qbittorrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// TorrentsSetTagsCtx makes tags the exact tags of the torrents. Servers with
+// the setTags endpoint, added in qBittorrent 5.1, do this in one request;
+// otherwise the current tags are compared and only the missing ones are added
+// and the extra ones removed.
+func (c *Client) TorrentsSetTagsCtx(ctx context.Context, hashes []string, tags []string) error {
+	data := url.Values{}
+	data.Set("hashes", strings.Join(hashes, "|"))
+	data.Set("tags", strings.Join(tags, ","))
+
+	_, err := c.doPostValuesCtx(ctx, "/api/v2/torrents/setTags", data)
+	if err == nil {
+		return nil
+	} else if !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("TorrentsSetTags error: %w", err)
+	}
+
+	torrents, err := c.TorrentsInfoCtx(ctx, &TorrentsInfoParams{Hashes: hashes})
+	if err != nil {
+		return fmt.Errorf("TorrentsSetTags error: %w", err)
+	}
+	if err := c.reconcileTags(ctx, hashes, torrents, tags, func(string) bool { return true }); err != nil {
+		return fmt.Errorf("TorrentsSetTags error: %w", err)
+	}
+	return nil
+}
+
+// TorrentsTagsByHashCtx returns the tags of each of the torrents, unlike
+// TorrentsGetTagsCtx which merges them. Torrents without tags map to an empty
+// slice; unknown hashes are left out.
+func (c *Client) TorrentsTagsByHashCtx(ctx context.Context, hashes []string) (map[InfoHash][]string, error) {
+	torrents, err := c.TorrentsInfoCtx(ctx, &TorrentsInfoParams{Hashes: hashes})
+	if err != nil {
+		return nil, fmt.Errorf("TorrentsTagsByHash error: %w", err)
+	}
+
+	tags := make(map[InfoHash][]string, len(torrents))
+	for _, torrent := range torrents {
+		tags[torrent.Hash] = append([]string{}, torrent.Tags...)
+	}
+	return tags, nil
+}
+
+// reconcileTags removes the tags of torrents for which managed returns true
+// unless they are wanted, and adds the wanted tags they are missing, with at
+// most one removeTags and one addTags request
+func (c *Client) reconcileTags(ctx context.Context, hashes []string, torrents []TorrentInfo, wanted []string, managed func(string) bool) error {
+	wantedSet := make(map[string]bool)
+	for _, tag := range wanted {
+		wantedSet[tag] = true
+	}
+	removed := make(map[string]bool)
+	added := make(map[string]bool)
+	for _, torrent := range torrents {
+		has := make(map[string]bool)
+		for _, tag := range torrent.Tags {
+			has[tag] = true
+			if managed(tag) && !wantedSet[tag] {
+				removed[tag] = true
+			}
+		}
+		for tag := range wantedSet {
+			if !has[tag] {
+				added[tag] = true
+			}
+		}
+	}
+
+	joined := strings.Join(hashes, "|")
+	if len(removed) > 0 {
+		if err := c.TorrentsRemoveTagsCtx(ctx, joined, joinTagSet(removed)); err != nil {
+			return err
+		}
+	}
+	if len(added) > 0 {
+		if err := c.TorrentsAddTagsCtx(ctx, joined, joinTagSet(added)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinTagSet joins tags into the comma separated form the tag endpoints take
+func joinTagSet(set map[string]bool) string {
+	tags := make([]string, 0, len(set))
+	for tag := range set {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return strings.Join(tags, ",")
+}
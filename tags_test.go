@@ -0,0 +1,80 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestTorrentsSetTagsCtx(t *testing.T) {
+	tests := []struct {
+		name     string
+		setTags  bool // whether the server has the setTags endpoint
+		expected []string
+	}{
+		{
+			name:     "native",
+			setTags:  true,
+			expected: []string{"/api/v2/torrents/setTags a|b hd,tv"},
+		},
+		{
+			name:    "fallback",
+			setTags: false,
+			expected: []string{
+				"/api/v2/torrents/setTags a|b hd,tv",
+				"/api/v2/torrents/removeTags a|b old",
+				"/api/v2/torrents/addTags a|b tv",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v2/torrents/info" {
+					w.Write([]byte(`[{"hash":"a","tags":"hd, old"},{"hash":"b","tags":"hd, tv"}]`))
+					return
+				}
+				r.ParseForm()
+				requests = append(requests, r.URL.Path+" "+r.FormValue("hashes")+" "+r.FormValue("tags"))
+				if r.URL.Path == "/api/v2/torrents/setTags" && !tt.setTags {
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer mockServer.Close()
+			client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+			if err := client.TorrentsSetTagsCtx(context.Background(), []string{"a", "b"}, []string{"hd", "tv"}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(requests, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, requests)
+			}
+		})
+	}
+}
+
+func TestTorrentsTagsByHashCtx(t *testing.T) {
+	var query string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`[{"hash":"a","tags":"hd, tv"},{"hash":"b","tags":""}]`))
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	tags, err := client.TorrentsTagsByHashCtx(context.Background(), []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := map[InfoHash][]string{"a": {"hd", "tv"}, "b": {}}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("expected %v, got %v", expected, tags)
+	}
+	if query != "hashes=a%7Cb%7Cc" {
+		t.Errorf("expected a single request for all hashes, got %q", query)
+	}
+}
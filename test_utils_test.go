@@ -93,3 +93,8 @@ func newMockClient(responses map[string]mockResponse, expectedRequests []expecte
 	client, err := NewClient("user", "pass", "localhost", "8080", httpClient)
 	return client, transport, err
 }
+
+// testTorrentFile is a minimal multi-file .torrent with a passkey in its tracker URL
+const testTorrentFile = "d8:announce43:http://tracker.example.org/PASSKEY/announce" +
+	"4:infod5:filesld6:lengthi10e4:pathl5:a.mkveed6:lengthi5e4:pathl5:b.nfoeee" +
+	"4:name4:Show12:piece lengthi16384e6:pieces20:aaaaaaaaaaaaaaaaaaaa7:privatei1eee"
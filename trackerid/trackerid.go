@@ -0,0 +1,120 @@
+// Package trackerid identifies trackers by their announce URLs. Announce URLs
+// of private trackers hold a passkey, in the path or the query, and large
+// trackers announce on several domains; Canonical strips the secrets and a
+// Resolver maps the domains of one tracker to one name, so trackers can be
+// grouped, logged and counted without leaking passkeys.
+package trackerid
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cehbz/qbittorrent"
+)
+
+// secretSegment matches path segments that may be passkeys: long runs of
+// letters and digits
+var secretSegment = regexp.MustCompile(`^[0-9A-Za-z]{16,}$`)
+
+// isSecret reports whether a path segment looks like a passkey
+func isSecret(segment string) bool {
+	return secretSegment.MatchString(segment) && strings.ContainsAny(segment, "0123456789")
+}
+
+// Canonical returns an announce URL without its secrets: the user info and
+// query are dropped, as are path segments that look like passkeys, and the
+// scheme and host are lowercased. Strings that aren't URLs are returned empty.
+//
+//	https://Tracker.example.org/0123456789abcdef0123456789abcdef/announce?uid=1
+//
+// becomes https://tracker.example.org/announce.
+func Canonical(announce string) string {
+	u, err := url.Parse(announce)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	segments := strings.Split(u.EscapedPath(), "/")
+	kept := segments[:0]
+	for _, segment := range segments {
+		if !isSecret(segment) {
+			kept = append(kept, segment)
+		}
+	}
+	canonical := url.URL{
+		Scheme: strings.ToLower(u.Scheme),
+		Host:   strings.ToLower(u.Host),
+	}
+	return canonical.String() + strings.Join(kept, "/")
+}
+
+// Domain returns the registrable domain of an announce URL, e.g. "example.org"
+// for "udp://tracker.example.org:6969/announce"
+func Domain(announce string) string {
+	return qbittorrent.RegistrableDomain(announce)
+}
+
+// Resolver maps the domains of trackers to their names. A Resolver is safe
+// for concurrent use, as it isn't modified after NewResolver.
+type Resolver struct {
+	aliases map[string]string
+}
+
+// NewResolver returns a resolver naming trackers by aliases, which maps
+// domains to names, e.g. {"example.org": "Example", "example-cdn.net":
+// "Example"}. A domain also matches its subdomains.
+func NewResolver(aliases map[string]string) *Resolver {
+	r := &Resolver{aliases: make(map[string]string, len(aliases))}
+	for domain, name := range aliases {
+		r.aliases[strings.ToLower(strings.TrimSuffix(domain, "."))] = name
+	}
+	return r
+}
+
+// Identify returns the name of the tracker of an announce URL: the alias of
+// its host or the closest parent domain, or else its registrable domain
+func (r *Resolver) Identify(announce string) string {
+	u, err := url.Parse(announce)
+	if err != nil {
+		return Domain(announce)
+	}
+	host := strings.ToLower(u.Hostname())
+	for host != "" {
+		if name, ok := r.aliases[host]; ok {
+			return name
+		}
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			break
+		}
+		host = parent
+	}
+	return Domain(announce)
+}
+
+// Group groups the torrents of a tracker map, as in MainData.Trackers, by the
+// names of their trackers. A torrent appears once per name, hashes are
+// sorted.
+func (r *Resolver) Group(trackers map[string][]qbittorrent.InfoHash) map[string][]qbittorrent.InfoHash {
+	seen := make(map[string]map[qbittorrent.InfoHash]bool)
+	for tracker, hashes := range trackers {
+		name := r.Identify(tracker)
+		if seen[name] == nil {
+			seen[name] = make(map[qbittorrent.InfoHash]bool)
+		}
+		for _, hash := range hashes {
+			seen[name][hash] = true
+		}
+	}
+	groups := make(map[string][]qbittorrent.InfoHash, len(seen))
+	for name, hashes := range seen {
+		sorted := make([]qbittorrent.InfoHash, 0, len(hashes))
+		for hash := range hashes {
+			sorted = append(sorted, hash)
+		}
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		groups[name] = sorted
+	}
+	return groups
+}
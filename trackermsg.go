@@ -0,0 +1,90 @@
+package qbittorrent
+
+import "regexp"
+
+// TrackerMessageKind classifies the message a tracker answered an announce
+// with, see ClassifyTrackerMessage
+type TrackerMessageKind int
+
+const (
+	// TrackerMessageNone is an empty message
+	TrackerMessageNone TrackerMessageKind = iota
+	// TrackerMessageOther is a message not recognized
+	TrackerMessageOther
+	// TrackerMessageUnregistered means the tracker doesn't know the torrent,
+	// usually because it was deleted from the site
+	TrackerMessageUnregistered
+	// TrackerMessageRateLimited means announces are sent too often
+	TrackerMessageRateLimited
+	// TrackerMessagePasskeyInvalid means the passkey of the announce URL was
+	// rejected, e.g. after it was reset
+	TrackerMessagePasskeyInvalid
+	// TrackerMessageClientBanned means the client or its version is not allowed
+	TrackerMessageClientBanned
+	// TrackerMessageTimeout means the tracker didn't answer
+	TrackerMessageTimeout
+	// TrackerMessageUnreachable means the tracker couldn't be connected to
+	TrackerMessageUnreachable
+)
+
+func (k TrackerMessageKind) String() string {
+	switch k {
+	case TrackerMessageNone:
+		return "none"
+	case TrackerMessageOther:
+		return "other"
+	case TrackerMessageUnregistered:
+		return "unregistered"
+	case TrackerMessageRateLimited:
+		return "rate limited"
+	case TrackerMessagePasskeyInvalid:
+		return "passkey invalid"
+	case TrackerMessageClientBanned:
+		return "client banned"
+	case TrackerMessageTimeout:
+		return "timeout"
+	case TrackerMessageUnreachable:
+		return "unreachable"
+	default:
+		return "unknown"
+	}
+}
+
+// Permanent reports whether the message won't go away by announcing again:
+// the torrent is unregistered, the passkey invalid or the client banned
+func (k TrackerMessageKind) Permanent() bool {
+	return k == TrackerMessageUnregistered || k == TrackerMessagePasskeyInvalid || k == TrackerMessageClientBanned
+}
+
+// trackerMessagePatterns are checked in order, so the passkey patterns come
+// before the generic "not found" of unregistered torrents
+var trackerMessagePatterns = []struct {
+	kind    TrackerMessageKind
+	pattern *regexp.Regexp
+}{
+	{TrackerMessagePasskeyInvalid, regexp.MustCompile(`(?i)(passkey|pass key|authkey|torrent_pass|user ?key)\b.*\b(invalid|not found|incorrect|unknown|wrong|disabled)|(invalid|unknown|incorrect|wrong) (passkey|pass key|authkey|user)`)},
+	{TrackerMessageUnregistered, regexp.MustCompile(`(?i)unregistered|not registered|torrent (is )?not (found|exist|authorized)|unknown (torrent|info ?hash)|(torrent|info ?hash) (has been )?(deleted|removed|trumped|nuked)|infohash not found`)},
+	{TrackerMessageRateLimited, regexp.MustCompile(`(?i)rate ?limit|too many requests|announcing too (fast|often|frequently)|slow down|min(imum)? interval`)},
+	{TrackerMessageClientBanned, regexp.MustCompile(`(?i)(client|version|peer ?id).*(banned|not (allowed|whitelisted|approved)|blacklisted|unsupported)|banned client`)},
+	{TrackerMessageTimeout, regexp.MustCompile(`(?i)timed? ?out|timeout`)},
+	{TrackerMessageUnreachable, regexp.MustCompile(`(?i)connection refused|host not found|could not resolve|name resolution|no route to host|network is unreachable|connection reset|ssl|tls|certificate|bad gateway|service unavailable|\b50[234]\b`)},
+}
+
+// ClassifyTrackerMessage recognizes the common messages of trackers, in
+// particular private ones, so monitors and cleanup policies treat them alike
+func ClassifyTrackerMessage(msg string) TrackerMessageKind {
+	if msg == "" {
+		return TrackerMessageNone
+	}
+	for _, p := range trackerMessagePatterns {
+		if p.pattern.MatchString(msg) {
+			return p.kind
+		}
+	}
+	return TrackerMessageOther
+}
+
+// MessageKind classifies the message of the tracker
+func (t TrackerInfo) MessageKind() TrackerMessageKind {
+	return ClassifyTrackerMessage(t.Msg)
+}
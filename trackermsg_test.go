@@ -0,0 +1,41 @@
+package qbittorrent
+
+import "testing"
+
+func TestClassifyTrackerMessage(t *testing.T) {
+	tests := map[string]TrackerMessageKind{
+		"":                     TrackerMessageNone,
+		"Unregistered torrent": TrackerMessageUnregistered,
+		"torrent not registered with this tracker": TrackerMessageUnregistered,
+		"Torrent not found":                        TrackerMessageUnregistered,
+		"Unknown torrent":                          TrackerMessageUnregistered,
+		"Torrent has been deleted.":                TrackerMessageUnregistered,
+		"Trumped":                                  TrackerMessageOther,
+		"torrent has been trumped":                 TrackerMessageUnregistered,
+		"Invalid passkey":                          TrackerMessagePasskeyInvalid,
+		"passkey not found":                        TrackerMessagePasskeyInvalid,
+		"Your authkey is incorrect":                TrackerMessagePasskeyInvalid,
+		"Rate limit exceeded":                      TrackerMessageRateLimited,
+		"You are announcing too fast":              TrackerMessageRateLimited,
+		"Your client is banned":                    TrackerMessageClientBanned,
+		"Client version not whitelisted":           TrackerMessageClientBanned,
+		"Timed out":                                TrackerMessageTimeout,
+		"Connection refused":                       TrackerMessageUnreachable,
+		"Host not found (authoritative)":           TrackerMessageUnreachable,
+		"SSL handshake failed":                     TrackerMessageUnreachable,
+		"tracker is down for maintenance":          TrackerMessageOther,
+	}
+	for msg, expected := range tests {
+		if kind := ClassifyTrackerMessage(msg); kind != expected {
+			t.Errorf("%q: expected %s, got %s", msg, expected, kind)
+		}
+	}
+
+	tracker := TrackerInfo{Status: TrackerNotWorking, Msg: "Unregistered torrent"}
+	if kind := tracker.MessageKind(); kind != TrackerMessageUnregistered || !kind.Permanent() {
+		t.Errorf("expected a permanent unregistered message, got %s", kind)
+	}
+	if TrackerMessageRateLimited.Permanent() {
+		t.Error("expected rate limiting not to be permanent")
+	}
+}
@@ -0,0 +1,120 @@
+package qbittorrent
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+// multiLabelSuffixes are common public suffixes of more than one label, so
+// RegistrableDomain doesn't stop at e.g. "co.uk"
+var multiLabelSuffixes = map[string]bool{
+	"co.uk": true, "org.uk": true, "me.uk": true, "ac.uk": true,
+	"com.au": true, "net.au": true, "org.au": true,
+	"co.nz": true, "co.jp": true, "co.kr": true, "co.in": true, "co.za": true,
+	"com.br": true, "com.cn": true, "com.mx": true, "com.tr": true, "com.tw": true,
+	"com.ar": true, "com.ua": true, "com.pl": true,
+}
+
+// RegistrableDomain returns the domain a tracker URL or host was registered
+// under, e.g. "example.org" for "https://tracker.example.org:443/announce".
+// Without the public suffix list it knows only common suffixes such as
+// "co.uk"; IP addresses and single labels are returned as they are.
+func RegistrableDomain(tracker string) string {
+	host := trackerDomain(tracker)
+	if host == "" {
+		// not a URL, or one without a scheme
+		host = strings.ToLower(tracker)
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+	}
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	labels := strings.Split(strings.TrimSuffix(host, "."), ".")
+	n := 2
+	if len(labels) >= 3 && multiLabelSuffixes[strings.Join(labels[len(labels)-2:], ".")] {
+		n = 3
+	}
+	if len(labels) <= n {
+		return strings.Join(labels, ".")
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
+
+// InvertTrackers turns the tracker map of MainData or SyncState, which maps
+// tracker URLs to the torrents using them, into the sorted tracker URLs of
+// every torrent
+func InvertTrackers(trackers map[string][]InfoHash) map[InfoHash][]string {
+	byTorrent := make(map[InfoHash][]string)
+	for tracker, hashes := range trackers {
+		for _, hash := range hashes {
+			byTorrent[hash] = append(byTorrent[hash], tracker)
+		}
+	}
+	for _, urls := range byTorrent {
+		sort.Strings(urls)
+	}
+	return byTorrent
+}
+
+// GroupTrackersByDomain groups the torrents of a tracker map by the
+// RegistrableDomain of their trackers. A torrent appears once per domain,
+// hashes are sorted.
+func GroupTrackersByDomain(trackers map[string][]InfoHash) map[string][]InfoHash {
+	seen := make(map[string]map[InfoHash]bool)
+	for tracker, hashes := range trackers {
+		domain := RegistrableDomain(tracker)
+		if seen[domain] == nil {
+			seen[domain] = make(map[InfoHash]bool)
+		}
+		for _, hash := range hashes {
+			seen[domain][hash] = true
+		}
+	}
+	byDomain := make(map[string][]InfoHash, len(seen))
+	for domain, hashes := range seen {
+		sorted := make([]InfoHash, 0, len(hashes))
+		for hash := range hashes {
+			sorted = append(sorted, hash)
+		}
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		byDomain[domain] = sorted
+	}
+	return byDomain
+}
+
+// TorrentsOnTracker returns the torrents with a tracker on domain or one of
+// its subdomains, sorted by hash. Unlike TorrentInfo.Tracker, which is only set
+// while a tracker works, the tracker map lists every tracker of a torrent.
+func TorrentsOnTracker(torrents map[string]TorrentInfo, trackers map[string][]InfoHash, domain string) []TorrentInfo {
+	matched := make(map[InfoHash]bool)
+	for tracker, hashes := range trackers {
+		if !matchTrackerHost(tracker, domain) {
+			continue
+		}
+		for _, hash := range hashes {
+			matched[hash] = true
+		}
+	}
+
+	var result []TorrentInfo
+	for hash, torrent := range torrents {
+		if !matched[InfoHash(hash)] {
+			continue
+		}
+		if torrent.Hash == "" {
+			torrent.Hash = InfoHash(hash)
+		}
+		result = append(result, torrent)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Hash < result[j].Hash })
+	return result
+}
+
+// TorrentsOnTracker returns the torrents of a full update with a tracker on
+// domain or one of its subdomains, see TorrentsOnTracker
+func (m *MainData) TorrentsOnTracker(domain string) []TorrentInfo {
+	return TorrentsOnTracker(m.Torrents, m.Trackers, domain)
+}
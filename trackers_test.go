@@ -0,0 +1,72 @@
+package qbittorrent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegistrableDomain(t *testing.T) {
+	tests := map[string]string{
+		"https://tracker.example.org:443/announce": "example.org",
+		"udp://open.Tracker.example.co.uk:6969":    "example.co.uk",
+		"http://example.org/announce":              "example.org",
+		"http://10.0.0.1:8080/announce":            "10.0.0.1",
+		"tracker.example.org":                      "example.org",
+		"tracker.example.org:6969":                 "example.org",
+		"localhost":                                "localhost",
+		"co.uk":                                    "co.uk",
+	}
+	for tracker, expected := range tests {
+		if domain := RegistrableDomain(tracker); domain != expected {
+			t.Errorf("%s: expected %s, got %s", tracker, expected, domain)
+		}
+	}
+}
+
+var testTrackerMap = map[string][]InfoHash{
+	"https://tracker.example.org/announce": {"b", "a"},
+	"udp://backup.example.org:6969":        {"a"},
+	"http://other.example.net/announce":    {"c", "a"},
+}
+
+func TestInvertTrackers(t *testing.T) {
+	expected := map[InfoHash][]string{
+		"a": {"http://other.example.net/announce", "https://tracker.example.org/announce", "udp://backup.example.org:6969"},
+		"b": {"https://tracker.example.org/announce"},
+		"c": {"http://other.example.net/announce"},
+	}
+	if byTorrent := InvertTrackers(testTrackerMap); !reflect.DeepEqual(byTorrent, expected) {
+		t.Errorf("expected %v, got %v", expected, byTorrent)
+	}
+}
+
+func TestGroupTrackersByDomain(t *testing.T) {
+	expected := map[string][]InfoHash{
+		"example.org": {"a", "b"},
+		"example.net": {"a", "c"},
+	}
+	if byDomain := GroupTrackersByDomain(testTrackerMap); !reflect.DeepEqual(byDomain, expected) {
+		t.Errorf("expected %v, got %v", expected, byDomain)
+	}
+}
+
+func TestTorrentsOnTracker(t *testing.T) {
+	data := &MainData{
+		Torrents: map[string]TorrentInfo{
+			"a": {Name: "one"},
+			"b": {Name: "two"},
+			"c": {Name: "three"},
+		},
+		Trackers: testTrackerMap,
+	}
+	torrents := data.TorrentsOnTracker("Example.org")
+	if len(torrents) != 2 || torrents[0].Hash != "a" || torrents[0].Name != "one" || torrents[1].Hash != "b" {
+		t.Errorf("expected torrents a and b, got %+v", torrents)
+	}
+	if torrents := data.TorrentsOnTracker("other.example.net"); len(torrents) != 2 || torrents[1].Hash != "c" {
+		t.Errorf("expected torrents a and c, got %+v", torrents)
+	}
+	if torrents := data.TorrentsOnTracker("example.com"); len(torrents) != 0 {
+		t.Errorf("expected no torrents, got %+v", torrents)
+	}
+}
@@ -0,0 +1,50 @@
+package qbittorrent
+
+import (
+	"errors"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// modulePath is the import path of this module
+const modulePath = "github.com/cehbz/qbittorrent"
+
+// DefaultUserAgent identifies requests of the client unless WithUserAgent
+// replaces it, e.g. "cehbz-qbittorrent/v1.2.0 Go/1.22.5". The version is the
+// one of the module in the build, or "devel" if it is unknown.
+var DefaultUserAgent = "cehbz-qbittorrent/" + moduleVersion() + " Go/" + strings.TrimPrefix(runtime.Version(), "go")
+
+// moduleVersion returns the version of this module in the build
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	var version string
+	if info.Main.Path == modulePath {
+		version = info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			version = dep.Version
+		}
+	}
+	if version == "" || version == "(devel)" {
+		return "devel"
+	}
+	return version
+}
+
+// WithUserAgent sends userAgent as the User-Agent header of every request
+// instead of DefaultUserAgent, e.g. to identify the tool built on the client
+// to the server operator or a reverse proxy filtering user agents
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) error {
+		if userAgent == "" {
+			return errors.New("empty user agent")
+		}
+		c.userAgent = userAgent
+		return nil
+	}
+}
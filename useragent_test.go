@@ -0,0 +1,51 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestDefaultUserAgent(t *testing.T) {
+	if !regexp.MustCompile(`^cehbz-qbittorrent/\S+ Go/\S+$`).MatchString(DefaultUserAgent) {
+		t.Errorf("unexpected default user agent %q", DefaultUserAgent)
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	var userAgent string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		w.Write([]byte("v5.0.0"))
+	}))
+	defer mockServer.Close()
+
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+	if _, err := client.AppVersionCtx(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if userAgent != DefaultUserAgent {
+		t.Errorf("expected %q, got %q", DefaultUserAgent, userAgent)
+	}
+
+	client, err := NewClientWithOptions("", "", "", "",
+		WithBaseURL(mockServer.URL),
+		WithBypassAuth(),
+		WithUserAgent("seedbox-janitor/1.0"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.AppVersionCtx(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if userAgent != "seedbox-janitor/1.0" {
+		t.Errorf("expected the custom user agent, got %q", userAgent)
+	}
+
+	if _, err := NewClientWithOptions("", "", "localhost", "8080", WithUserAgent("")); err == nil {
+		t.Error("expected an error for an empty user agent")
+	}
+}
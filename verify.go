@@ -0,0 +1,102 @@
+package qbittorrent
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// VerifyReport is the result of rechecking a torrent
+type VerifyReport struct {
+	Torrent TorrentInfo   // the torrent after the recheck
+	Damaged []TorrentFile // wanted files that are missing or incomplete
+	Skipped []int         // indexes of the complete files skipped to repair the damaged ones
+}
+
+// OK reports whether every wanted file is complete
+func (r *VerifyReport) OK() bool {
+	return len(r.Damaged) == 0
+}
+
+// verifyWaitInterval is how often VerifyCtx checks whether the recheck is done
+var verifyWaitInterval = time.Second
+
+// verifyStartPolls is how many polls VerifyCtx waits for the recheck to show
+// up in the state of the torrent before assuming it is already over
+const verifyStartPolls = 5
+
+// checking reports whether the torrent is checking its data
+func checking(state TorrentState) bool {
+	return state == StateCheckingUP || state == StateCheckingDL || state == StateCheckingResumeData
+}
+
+// VerifyCtx rechecks the data of a torrent, waits for the recheck to finish,
+// and reports the wanted files that are missing or incomplete
+func (c *Client) VerifyCtx(ctx context.Context, hash string) (*VerifyReport, error) {
+	report, _, err := c.verify(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("Verify error: %w", err)
+	}
+	return report, nil
+}
+
+// verify implements VerifyCtx, also returning all files of the torrent
+func (c *Client) verify(ctx context.Context, hash string) (*VerifyReport, []TorrentFile, error) {
+	if err := c.TorrentsRecheckCtx(ctx, []string{hash}); err != nil {
+		return nil, nil, err
+	}
+
+	polls, started := 0, false
+	torrent, err := c.pollTorrent(ctx, hash, verifyWaitInterval, func(torrent TorrentInfo) bool {
+		polls++
+		if checking(TorrentState(torrent.State)) {
+			started = true
+			return false
+		}
+		return started || polls >= verifyStartPolls
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files, err := c.TorrentsFilesCtx(ctx, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	report := &VerifyReport{Torrent: *torrent}
+	for _, file := range files {
+		if file.Priority != FilePrioritySkip && file.Progress < 1 {
+			report.Damaged = append(report.Damaged, file)
+		}
+	}
+	return report, files, nil
+}
+
+// VerifyAndRepairCtx verifies the torrent like VerifyCtx and, when files are
+// damaged, skips its complete files and starts it so that only the damaged
+// files are downloaded again. The skipped files are listed in the report, to
+// be given their priority back once the repair is done.
+func (c *Client) VerifyAndRepairCtx(ctx context.Context, hash string) (*VerifyReport, error) {
+	report, files, err := c.verify(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("VerifyAndRepair error: %w", err)
+	}
+	if report.OK() {
+		return report, nil
+	}
+
+	for _, file := range files {
+		if file.Priority != FilePrioritySkip && file.Progress >= 1 {
+			report.Skipped = append(report.Skipped, file.Index)
+		}
+	}
+	if len(report.Skipped) > 0 {
+		if err := c.TorrentsFilePrioCtx(ctx, hash, report.Skipped, FilePrioritySkip); err != nil {
+			return nil, fmt.Errorf("VerifyAndRepair error: %w", err)
+		}
+	}
+	if err := c.TorrentsStartCtx(ctx, []string{hash}); err != nil {
+		return nil, fmt.Errorf("VerifyAndRepair error: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,92 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVerifyAndRepairCtx(t *testing.T) {
+	verifyWaitInterval = time.Millisecond
+	defer func() { verifyWaitInterval = time.Second }()
+
+	var requests []string
+	polls := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/torrents/info":
+			polls++
+			state := "checkingUP"
+			if polls > 2 {
+				state = "stoppedDL"
+			}
+			w.Write([]byte(`[{"hash":"abc","state":"` + state + `"}]`))
+		case "/api/v2/torrents/files":
+			w.Write([]byte(`[
+				{"index":0,"name":"a.mkv","progress":1,"priority":1},
+				{"index":1,"name":"b.mkv","progress":0.5,"priority":1},
+				{"index":2,"name":"c.nfo","progress":0,"priority":0},
+				{"index":3,"name":"d.mkv","progress":1,"priority":6}
+			]`))
+		default:
+			r.ParseForm()
+			requests = append(requests, r.URL.Path+" "+r.Form.Encode())
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	report, err := client.VerifyAndRepairCtx(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if report.OK() || len(report.Damaged) != 1 || report.Damaged[0].Name != "b.mkv" {
+		t.Errorf("expected b.mkv to be damaged, got %+v", report.Damaged)
+	}
+	if report.Torrent.State != "stoppedDL" {
+		t.Errorf("expected the torrent after the recheck, got %s", report.Torrent.State)
+	}
+	if !reflect.DeepEqual(report.Skipped, []int{0, 3}) {
+		t.Errorf("expected the complete files to be skipped, got %v", report.Skipped)
+	}
+	expected := []string{
+		"/api/v2/torrents/recheck hashes=abc",
+		"/api/v2/torrents/filePrio hash=abc&id=0%7C3&priority=0",
+		"/api/v2/torrents/start hashes=abc",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected %v, got %v", expected, requests)
+	}
+}
+
+func TestVerifyCtx_CheckAlreadyOver(t *testing.T) {
+	verifyWaitInterval = time.Millisecond
+	defer func() { verifyWaitInterval = time.Second }()
+
+	polls := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/torrents/info":
+			polls++
+			w.Write([]byte(`[{"hash":"abc","state":"stalledUP"}]`))
+		case "/api/v2/torrents/files":
+			w.Write([]byte(`[{"index":0,"name":"a.mkv","progress":1,"priority":1}]`))
+		}
+	}))
+	defer mockServer.Close()
+	client := &Client{baseURL: mockServer.URL, client: mockServer.Client(), bypassAuth: true}
+
+	report, err := client.VerifyCtx(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("expected no damaged files, got %+v", report.Damaged)
+	}
+	if polls != verifyStartPolls {
+		t.Errorf("expected %d polls, got %d", verifyStartPolls, polls)
+	}
+}
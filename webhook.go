@@ -0,0 +1,94 @@
+package qbittorrent
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WebhookSecretHeader carries the shared secret of webhook calls. The secret
+// may also be passed as the "secret" parameter.
+const WebhookSecretHeader = "X-Webhook-Secret"
+
+// WebhookHandler receives the calls of qBittorrent's "Run external program"
+// settings and dispatches them as events to a Watcher's handlers, so torrents
+// are handled the moment they are added or complete rather than at the next
+// poll. Configure qBittorrent to call it with e.g.
+//
+//	curl -s "http://host:port/hook?event=completed&secret=SECRET&hash=%I&name=%N&category=%L&tags=%G&save_path=%D&size=%Z"
+//
+// The event parameter is "added" or "completed", the default. Only the hash
+// is required. Calls are answered with 204 No Content once the handlers
+// returned, 401 if the secret doesn't match and 400 if they are malformed.
+type WebhookHandler struct {
+	watcher *Watcher
+	secret  string
+}
+
+// NewWebhookHandler returns a handler dispatching to the handlers of w. An
+// empty secret accepts all calls, which is only safe on a trusted network.
+func NewWebhookHandler(w *Watcher, secret string) *WebhookHandler {
+	return &WebhookHandler{watcher: w, secret: secret}
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if h.secret != "" {
+		secret := r.Header.Get(WebhookSecretHeader)
+		if secret == "" {
+			secret = r.Form.Get("secret")
+		}
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(h.secret)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+
+	event := Event{At: h.watcher.client.Clock().Now()}
+	switch r.Form.Get("event") {
+	case "added":
+		event.Type = TorrentAdded
+	case "completed", "":
+		event.Type = TorrentCompleted
+	default:
+		http.Error(w, "unknown event", http.StatusBadRequest)
+		return
+	}
+	hash := strings.ToLower(r.Form.Get("hash"))
+	if hash == "" {
+		http.Error(w, "missing hash", http.StatusBadRequest)
+		return
+	}
+	event.Hash = InfoHash(hash)
+	event.Torrent = TorrentInfo{
+		Hash:     event.Hash,
+		Name:     r.Form.Get("name"),
+		Category: r.Form.Get("category"),
+		SavePath: r.Form.Get("save_path"),
+	}
+	if tags := r.Form.Get("tags"); tags != "" {
+		event.Torrent.Tags = splitTags(tags)
+	}
+	if size := r.Form.Get("size"); size != "" {
+		n, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid size", http.StatusBadRequest)
+			return
+		}
+		event.Torrent.Size = n
+	}
+	if event.Type == TorrentCompleted {
+		event.Torrent.Progress = 1
+	}
+
+	h.watcher.Dispatch(r.Context(), event)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,60 @@
+package qbittorrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	watcher := NewWatcher(&Client{})
+	var events []Event
+	watcher.OnEvent(func(ctx context.Context, event Event) error {
+		events = append(events, event)
+		return nil
+	})
+	handler := NewWebhookHandler(watcher, "s3cret")
+
+	tests := []struct {
+		name   string
+		target string
+		header string
+		status int
+	}{
+		{"missing secret", "/hook?hash=abc", "", http.StatusUnauthorized},
+		{"wrong secret", "/hook?hash=abc&secret=nope", "", http.StatusUnauthorized},
+		{"missing hash", "/hook?secret=s3cret", "", http.StatusBadRequest},
+		{"unknown event", "/hook?secret=s3cret&hash=abc&event=deleted", "", http.StatusBadRequest},
+		{"invalid size", "/hook?secret=s3cret&hash=abc&size=big", "", http.StatusBadRequest},
+		{"completed", "/hook?secret=s3cret&hash=ABC&name=Show&category=tv&tags=hd,%20kids&size=15", "", http.StatusNoContent},
+		{"added with header", "/hook?event=added&hash=def", "s3cret", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set(WebhookSecretHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	completed := events[0]
+	if completed.Type != TorrentCompleted || completed.Hash != "abc" || completed.Torrent.Name != "Show" || completed.Torrent.Category != "tv" || completed.Torrent.Size != 15 || completed.Torrent.Progress != 1 {
+		t.Errorf("unexpected event %+v", completed)
+	}
+	if len(completed.Torrent.Tags) != 2 || completed.Torrent.Tags[1] != "kids" {
+		t.Errorf("expected tags [hd kids], got %v", completed.Torrent.Tags)
+	}
+	if events[1].Type != TorrentAdded || events[1].Hash != "def" {
+		t.Errorf("unexpected event %+v", events[1])
+	}
+}